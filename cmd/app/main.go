@@ -6,15 +6,26 @@ import (
 	"db-worker/internal/config"
 	"db-worker/internal/config/operation"
 	"db-worker/internal/server"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/deadletter"
+	"db-worker/internal/service/dispatcher"
+	"db-worker/internal/service/event"
+	"db-worker/internal/service/idempotency"
 	"db-worker/internal/service/metrics"
 	migration_srv "db-worker/internal/service/migration"
 	operation_srv "db-worker/internal/service/operation"
 	"db-worker/internal/service/redis"
 	"db-worker/internal/service/uow"
 	"db-worker/internal/service/worker"
+	fileworker "db-worker/internal/service/worker/file"
+	httpworker "db-worker/internal/service/worker/http"
+	"db-worker/internal/service/worker/kafka"
+	natsworker "db-worker/internal/service/worker/nats"
 	"db-worker/internal/service/worker/rabbit"
+	"db-worker/internal/service/worker/redisstream"
 	"db-worker/internal/storage"
 	"db-worker/internal/storage/model"
+	"db-worker/internal/storage/postgres/checkpoint"
 	"db-worker/internal/storage/postgres/message"
 	"db-worker/internal/storage/postgres/migration"
 	postgres "db-worker/internal/storage/postgres/repo"
@@ -25,7 +36,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	_ "db-worker/docs" // swagger docs
@@ -88,8 +101,27 @@ func main() {
 		logrus.WithError(err).Fatalf("error loading migrations")
 	}
 
+	metricsService := initMetricsService()
+
+	// redis нужен до воркеров: из него читают соединения redis_stream
+	redis := initRedisStorage(notifyCtx, cfg.Storage.Redis)
+	defer butler.stop(notifyCtx, redis)
+
+	redisClient, err := redis.Client()
+	if err != nil {
+		logrus.WithError(err).Fatalf("error getting redis client")
+	}
+
+	// позиции чтения соединений file
+	checkpointRepo := initCheckpointRepo(cfg.Storage.Postgres)
+	defer butler.stop(notifyCtx, checkpointRepo)
+
+	go butler.start(func() error {
+		return checkpointRepo.Run(notifyCtx)
+	})
+
 	// запуск воркеров для получения сообщений
-	connections, err := initWorkers(cfg)
+	connections, err := initWorkers(cfg, metricsService, redisClient, checkpointRepo)
 	if err != nil {
 		logrus.WithError(err).Fatalf("error initializing workers")
 	}
@@ -131,8 +163,6 @@ func main() {
 
 	defer butler.stop(notifyCtx, messageRepo)
 
-	metricsService := initMetricsService()
-
 	transactionRepo := initTransactionRepo(notifyCtx, cfg.Storage.Postgres)
 
 	go butler.start(func() error {
@@ -140,7 +170,78 @@ func main() {
 	})
 	defer butler.stop(notifyCtx, transactionRepo)
 
-	operations, err := initOperationServices(notifyCtx, cfg, connections, storagesMap, txRepo, messageRepo, metricsService, transactionRepo)
+	deadLetters, err := initDeadLetters(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing dead letters")
+	}
+
+	for _, deadLetter := range deadLetters {
+		go butler.start(func() error {
+			return deadLetter.Run(notifyCtx)
+		})
+
+		defer butler.stop(notifyCtx, deadLetter)
+	}
+
+	unmatchedDeadLetters, err := initUnmatchedDeadLetters(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing unmatched dead letters")
+	}
+
+	for _, deadLetter := range unmatchedDeadLetters {
+		go butler.start(func() error {
+			return deadLetter.Run(notifyCtx)
+		})
+
+		defer butler.stop(notifyCtx, deadLetter)
+	}
+
+	decodeDeadLetters, err := initDecodeDeadLetters(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing decode dead letters")
+	}
+
+	for _, deadLetter := range decodeDeadLetters {
+		go butler.start(func() error {
+			return deadLetter.Run(notifyCtx)
+		})
+
+		defer butler.stop(notifyCtx, deadLetter)
+	}
+
+	// распределение сообщений соединений по операциям
+	dispatchers, err := initDispatchers(cfg, connections, unmatchedDeadLetters, decodeDeadLetters, metricsService)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing dispatchers")
+	}
+
+	for _, dispatcher := range dispatchers {
+		go butler.start(func() error {
+			return dispatcher.Run(notifyCtx)
+		})
+
+		defer butler.stop(notifyCtx, dispatcher)
+	}
+
+	idempotencyStores, err := initIdempotencyStores(cfg, redisClient)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing idempotency stores")
+	}
+
+	events, err := initEvents(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatalf("error initializing events")
+	}
+
+	for _, publisher := range events {
+		go butler.start(func() error {
+			return publisher.Run(notifyCtx)
+		})
+
+		defer butler.stop(notifyCtx, publisher)
+	}
+
+	operations, err := initOperationServices(notifyCtx, cfg, dispatchers, storagesMap, txRepo, messageRepo, metricsService, transactionRepo, deadLetters, idempotencyStores, events)
 	if err != nil {
 		logrus.WithError(err).Fatalf("error initializing operation services")
 	}
@@ -153,11 +254,8 @@ func main() {
 		defer butler.stop(notifyCtx, operation)
 	}
 
-	redis := initRedisStorage(notifyCtx, cfg.Storage.Redis)
-	defer butler.stop(notifyCtx, redis)
-
-	handlerV0 := initHandlerV0(butler.BuildInfo)
-	server := initServer(handlerV0, cfg.Server)
+	handlerV0 := initHandlerV0(butler.BuildInfo, connections)
+	server := initServer(handlerV0, cfg.Server, httpWorkers(connections))
 
 	go butler.start(func() error {
 		return server.Start(notifyCtx)
@@ -177,12 +275,17 @@ func main() {
 }
 
 // создает подключения из списка подключений. Сохраняет в map[string]*rabbit.Worker.
-func initWorkers(cfg *config.Config) (map[string]worker.Worker, error) {
+func initWorkers(
+	cfg *config.Config,
+	metricsService *metrics.Service,
+	redisClient goredis.UniversalClient,
+	checkpointRepo *checkpoint.Repo,
+) (map[string]worker.Worker, error) {
 	connections := make(map[string]worker.Worker)
 
 	var err error
 	for _, connection := range cfg.Operations.Connections {
-		connections[connection.Name], err = initWorker(connection)
+		connections[connection.Name], err = initWorker(connection, metricsService, redisClient, checkpointRepo, cfg.InstanceID)
 		if err != nil {
 			return nil, fmt.Errorf("error initializing worker %s: %w", connection.Name, err)
 		}
@@ -191,35 +294,57 @@ func initWorkers(cfg *config.Config) (map[string]worker.Worker, error) {
 	return connections, nil
 }
 
-func initHandlerV0(buildInfo *BuildInfo) *handlerV0.Handler {
+func initHandlerV0(buildInfo *BuildInfo, connections map[string]worker.Worker) *handlerV0.Handler {
 	logrus.WithFields(logrus.Fields{
 		"version":   buildInfo.Version,
 		"buildDate": buildInfo.BuildDate,
 		"gitCommit": buildInfo.GitCommit,
 	}).Info("initializing handler v0")
 
-	return start(
-		handlerV0.New(
-			handlerV0.WithVersion(buildInfo.Version),
-			handlerV0.WithBuildDate(buildInfo.BuildDate),
-			handlerV0.WithGitCommit(buildInfo.GitCommit),
-		),
-	)
+	opts := []handlerV0.Option{
+		handlerV0.WithVersion(buildInfo.Version),
+		handlerV0.WithBuildDate(buildInfo.BuildDate),
+		handlerV0.WithGitCommit(buildInfo.GitCommit),
+	}
+
+	for _, connection := range connections {
+		opts = append(opts, handlerV0.WithConnections(connection))
+	}
+
+	return start(handlerV0.New(opts...))
 }
 
-func initServer(handlerV0 *handlerV0.Handler, cfg config.Server) *server.Server {
+func initServer(handlerV0 *handlerV0.Handler, cfg config.Server, httpWorkers []*httpworker.Worker) *server.Server {
 	logrus.WithFields(logrus.Fields{
 		"port":            cfg.Port,
 		"shutdownTimeout": cfg.ShutdownTimeout,
+		"httpWorkers":     len(httpWorkers),
 	}).Info("initializing server")
 
-	return start(
-		server.New(
-			server.WithHandlerV0(handlerV0),
-			server.WithPort(cfg.Port),
-			server.WithShutdownTimeout(cfg.ShutdownTimeout),
-		),
-	)
+	opts := []server.Option{
+		server.WithHandlerV0(handlerV0),
+		server.WithPort(cfg.Port),
+		server.WithShutdownTimeout(cfg.ShutdownTimeout),
+	}
+
+	for _, w := range httpWorkers {
+		opts = append(opts, server.WithHTTPWorkers(w))
+	}
+
+	return start(server.New(opts...))
+}
+
+// httpWorkers возвращает соединения, принимающие сообщения через сервер приложения.
+func httpWorkers(connections map[string]worker.Worker) []*httpworker.Worker {
+	workers := make([]*httpworker.Worker, 0)
+
+	for _, connection := range connections {
+		if w, ok := connection.(*httpworker.Worker); ok {
+			workers = append(workers, w)
+		}
+	}
+
+	return workers
 }
 
 func initMetricsService() *metrics.Service {
@@ -231,39 +356,267 @@ func initMetricsService() *metrics.Service {
 	)
 }
 
-func initWorker(worker operation.Connection) (worker.Worker, error) {
+func initWorker(
+	worker operation.Connection,
+	metricsService *metrics.Service,
+	redisClient goredis.UniversalClient,
+	checkpointRepo *checkpoint.Repo,
+	instanceID int,
+) (worker.Worker, error) {
+	registry, err := initCodec(worker)
+	if err != nil {
+		return nil, err
+	}
+
 	switch worker.Type {
 	case operation.ConnectionTypeRabbitMQ:
-		return initRabbit(worker), nil
+		return initRabbit(worker, metricsService, registry), nil
+	case operation.ConnectionTypeHTTP:
+		return initHTTP(worker, registry)
+	case operation.ConnectionTypeRedisStream:
+		return initRedisStream(worker, metricsService, redisClient, instanceID, registry)
+	case operation.ConnectionTypeKafka:
+		return initKafka(worker, metricsService, registry)
+	case operation.ConnectionTypeNATS:
+		return initNATS(worker, metricsService, registry)
+	case operation.ConnectionTypeFile:
+		return initFile(worker, metricsService, checkpointRepo)
 	default:
 		return nil, fmt.Errorf("unknown worker type: %s", worker.Type)
 	}
 }
 
+// initCodec создает декодер тел сообщений соединения.
+func initCodec(connection operation.Connection) (*codec.Registry, error) {
+	opts := []codec.Option{codec.WithCodec(connection.Codec)}
+
+	if connection.Protobuf != nil {
+		opts = append(opts, codec.WithProtobuf(connection.Protobuf.DescriptorSet, connection.Protobuf.Message))
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":  connection.Name,
+		"codec": connection.Codec,
+	}).Info("initializing codec")
+
+	registry, err := codec.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing codec: %w", err)
+	}
+
+	return registry, nil
+}
+
+// defaultRabbitExchange - exchange, который используется, если он не задан в конфигурации соединения.
+const defaultRabbitExchange = "exchange"
+
 // initRabbit создает подключение для исполнения отдельной операции.
-func initRabbit(connection operation.Connection) worker.Worker {
+func initRabbit(connection operation.Connection, metricsService *metrics.Service, registry *codec.Registry) worker.Worker {
+	exchange := connection.Exchange
+	if exchange == "" {
+		exchange = defaultRabbitExchange
+	}
+
 	logrus.WithFields(logrus.Fields{
-		"address":        connection.Address,
-		"name":           connection.Name,
-		"exchange":       connection.Queue,
-		"routing_key":    connection.RoutingKey,
-		"insert_timeout": connection.InsertTimeout,
-		"read_timeout":   connection.ReadTimeout,
+		"address":                connection.Address,
+		"name":                   connection.Name,
+		"exchange":               exchange,
+		"exchange_type":          connection.ExchangeType,
+		"queue":                  connection.Queue,
+		"routing_key":            connection.RoutingKey,
+		"binding_keys":           connection.BindingKeys,
+		"passive":                connection.Passive,
+		"insert_timeout":         connection.InsertTimeout,
+		"read_timeout":           connection.ReadTimeout,
+		"ack_policy":             connection.AckPolicy,
+		"requeue":                connection.Requeue,
+		"max_in_flight":          connection.MaxInFlight,
+		"prefetch":               connection.Prefetch,
+		"order_key":              connection.OrderKey,
+		"reconnect_interval":     connection.ReconnectInterval,
+		"reconnect_max_interval": connection.ReconnectMaxInterval,
 	}).Info("connecting rabbit")
 
 	rabbit := start(rabbit.New(
 		rabbit.WithAddress(connection.Address),
 		rabbit.WithName(connection.Name),
-		rabbit.WithExchange("exchange"),
+		rabbit.WithExchange(exchange),
+		rabbit.WithExchangeType(rabbit.ExchangeType(connection.ExchangeType)),
 		rabbit.WithQueue(connection.Queue),
 		rabbit.WithRoutingKey(connection.RoutingKey),
+		rabbit.WithBindingKeys(connection.BindingKeys...),
+		rabbit.WithBindingArgs(connection.BindingArgs),
+		rabbit.WithQueueArgs(connection.QueueArgs),
+		rabbit.WithPassive(connection.Passive),
 		rabbit.WithInsertTimeout(connection.InsertTimeout),
 		rabbit.WithReadTimeout(connection.ReadTimeout),
+		rabbit.WithAckPolicy(rabbit.AckPolicy(connection.AckPolicy)),
+		rabbit.WithRequeue(connection.Requeue),
+		rabbit.WithMaxInFlight(connection.MaxInFlight),
+		rabbit.WithPrefetch(connection.Prefetch),
+		rabbit.WithOrderKey(connection.OrderKey),
+		rabbit.WithMetrics(metricsService),
+		rabbit.WithCodec(registry),
+		rabbit.WithReconnectInterval(
+			time.Duration(connection.ReconnectInterval)*time.Millisecond,
+			time.Duration(connection.ReconnectMaxInterval)*time.Millisecond,
+		),
 	))
 
 	return rabbit
 }
 
+// initHTTP создает соединение, принимающее сообщения через сервер приложения.
+func initHTTP(connection operation.Connection, registry *codec.Registry) (worker.Worker, error) {
+	logrus.WithFields(logrus.Fields{
+		"name":           connection.Name,
+		"path":           connection.Path,
+		"mode":           connection.Mode,
+		"insert_timeout": connection.InsertTimeout,
+		"read_timeout":   connection.ReadTimeout,
+		"max_body_size":  connection.MaxBodySize,
+	}).Info("initializing http connection")
+
+	return httpworker.New(
+		httpworker.WithName(connection.Name),
+		httpworker.WithPath(connection.Path),
+		httpworker.WithMode(httpworker.Mode(connection.Mode)),
+		httpworker.WithInsertTimeout(connection.InsertTimeout),
+		httpworker.WithReadTimeout(connection.ReadTimeout),
+		httpworker.WithMaxBodySize(connection.MaxBodySize),
+		httpworker.WithCodec(registry),
+	)
+}
+
+func initRedisStream(
+	connection operation.Connection,
+	metricsService *metrics.Service,
+	redisClient goredis.UniversalClient,
+	instanceID int,
+	registry *codec.Registry,
+) (worker.Worker, error) {
+	// имя потребителя должно быть уникальным в группе, поэтому к нему добавляется instance_id
+	consumer := connection.Consumer
+	if consumer == "" {
+		consumer = connection.Name
+	}
+
+	consumer = fmt.Sprintf("%s-%d", consumer, instanceID)
+
+	logrus.WithFields(logrus.Fields{
+		"name":           connection.Name,
+		"stream":         connection.Stream,
+		"group":          connection.Group,
+		"consumer":       consumer,
+		"batch_size":     connection.BatchSize,
+		"claim_min_idle": connection.ClaimMinIdle,
+		"claim_interval": connection.ClaimInterval,
+		"insert_timeout": connection.InsertTimeout,
+		"read_timeout":   connection.ReadTimeout,
+	}).Info("initializing redis stream connection")
+
+	return redisstream.New(
+		redisstream.WithName(connection.Name),
+		redisstream.WithClient(redisClient),
+		redisstream.WithStream(connection.Stream),
+		redisstream.WithGroup(connection.Group),
+		redisstream.WithConsumer(consumer),
+		redisstream.WithField(connection.Field),
+		redisstream.WithBatchSize(connection.BatchSize),
+		redisstream.WithClaim(
+			time.Duration(connection.ClaimMinIdle)*time.Millisecond,
+			time.Duration(connection.ClaimInterval)*time.Millisecond,
+		),
+		redisstream.WithRetryInterval(time.Duration(connection.ReconnectInterval)*time.Millisecond),
+		redisstream.WithMetrics(metricsService),
+		redisstream.WithCodec(registry),
+		redisstream.WithInsertTimeout(connection.InsertTimeout),
+		redisstream.WithReadTimeout(connection.ReadTimeout),
+	)
+}
+
+func initKafka(connection operation.Connection, metricsService *metrics.Service, registry *codec.Registry) (worker.Worker, error) {
+	logrus.WithFields(logrus.Fields{
+		"name":            connection.Name,
+		"brokers":         connection.Brokers,
+		"topic":           connection.Topic,
+		"group":           connection.Group,
+		"commit_interval": connection.CommitInterval,
+		"insert_timeout":  connection.InsertTimeout,
+		"read_timeout":    connection.ReadTimeout,
+	}).Info("initializing kafka connection")
+
+	return kafka.New(
+		kafka.WithName(connection.Name),
+		kafka.WithBrokers(connection.Brokers...),
+		kafka.WithTopic(connection.Topic),
+		kafka.WithGroup(connection.Group),
+		kafka.WithCommitInterval(time.Duration(connection.CommitInterval)*time.Millisecond),
+		kafka.WithRetryInterval(time.Duration(connection.ReconnectInterval)*time.Millisecond),
+		kafka.WithMetrics(metricsService),
+		kafka.WithCodec(registry),
+		kafka.WithInsertTimeout(connection.InsertTimeout),
+		kafka.WithReadTimeout(connection.ReadTimeout),
+	)
+}
+
+func initNATS(connection operation.Connection, metricsService *metrics.Service, registry *codec.Registry) (worker.Worker, error) {
+	logrus.WithFields(logrus.Fields{
+		"name":           connection.Name,
+		"address":        connection.Address,
+		"stream":         connection.Stream,
+		"durable":        connection.Durable,
+		"subjects":       connection.Subjects,
+		"max_deliver":    connection.MaxDeliver,
+		"ack_wait":       connection.AckWait,
+		"nak_delay":      connection.NakDelay,
+		"insert_timeout": connection.InsertTimeout,
+		"read_timeout":   connection.ReadTimeout,
+	}).Info("initializing nats connection")
+
+	return natsworker.New(
+		natsworker.WithName(connection.Name),
+		natsworker.WithAddress(connection.Address),
+		natsworker.WithStream(connection.Stream),
+		natsworker.WithDurable(connection.Durable),
+		natsworker.WithSubjects(connection.Subjects...),
+		natsworker.WithBatchSize(int(connection.BatchSize)),
+		natsworker.WithMaxDeliver(connection.MaxDeliver),
+		natsworker.WithAckWait(time.Duration(connection.AckWait)*time.Millisecond),
+		natsworker.WithNakDelay(time.Duration(connection.NakDelay)*time.Millisecond),
+		natsworker.WithRetryInterval(time.Duration(connection.ReconnectInterval)*time.Millisecond),
+		natsworker.WithMetrics(metricsService),
+		natsworker.WithCodec(registry),
+		natsworker.WithInsertTimeout(connection.InsertTimeout),
+		natsworker.WithReadTimeout(connection.ReadTimeout),
+	)
+}
+
+func initFile(connection operation.Connection, metricsService *metrics.Service, checkpointRepo *checkpoint.Repo) (worker.Worker, error) {
+	logrus.WithFields(logrus.Fields{
+		"name":                connection.Name,
+		"path":                connection.Path,
+		"follow":              connection.Follow,
+		"poll_interval":       connection.PollInterval,
+		"checkpoint_interval": connection.CheckpointInterval,
+		"insert_timeout":      connection.InsertTimeout,
+		"read_timeout":        connection.ReadTimeout,
+	}).Info("initializing file connection")
+
+	return fileworker.New(
+		fileworker.WithName(connection.Name),
+		fileworker.WithPath(connection.Path),
+		fileworker.WithFollow(connection.Follow),
+		fileworker.WithPollInterval(time.Duration(connection.PollInterval)*time.Millisecond),
+		fileworker.WithCheckpointInterval(time.Duration(connection.CheckpointInterval)*time.Millisecond),
+		fileworker.WithRetryInterval(time.Duration(connection.ReconnectInterval)*time.Millisecond),
+		fileworker.WithCheckpoints(checkpointRepo),
+		fileworker.WithMetrics(metricsService),
+		fileworker.WithInsertTimeout(connection.InsertTimeout),
+		fileworker.WithReadTimeout(connection.ReadTimeout),
+	)
+}
+
 func initStoragesMap(ctx context.Context, cfg *config.Config) (map[string]storage.Driver, error) {
 	storagesMap := make(map[string]storage.Driver)
 
@@ -343,6 +696,14 @@ func initMessageRepo(ctx context.Context, cfg config.Postgres) *message.Repo {
 	))
 }
 
+func initCheckpointRepo(cfg config.Postgres) *checkpoint.Repo {
+	return start(checkpoint.New(
+		checkpoint.WithAddr(formatPostgresAddr(cfg)),
+		checkpoint.WithInsertTimeout(cfg.InsertTimeout),
+		checkpoint.WithReadTimeout(cfg.ReadTimeout),
+	))
+}
+
 func initMigrationRepo(ctx context.Context, cfg config.Postgres) *migration.Repo {
 	return start(migration.New(ctx,
 		migration.WithAddr(formatPostgresAddr(cfg)),
@@ -354,12 +715,15 @@ func initMigrationRepo(ctx context.Context, cfg config.Postgres) *migration.Repo
 func initOperationServices(
 	ctx context.Context,
 	cfg *config.Config,
-	connections map[string]worker.Worker,
+	dispatchers map[string]*dispatcher.Service,
 	storagesMap map[string]storage.Driver,
 	txRepo storage.Driver,
 	messageRepo *message.Repo,
 	metricsService *metrics.Service,
 	transactionRepo *transaction.Repo,
+	deadLetters map[string]deadLetter,
+	idempotencyStores map[string]*idempotency.Store,
+	events map[string]*event.Rabbit,
 ) (map[string]*operation_srv.Service, error) {
 	operations := make(map[string]*operation_srv.Service, len(cfg.Operations.Operations))
 
@@ -394,7 +758,7 @@ func initOperationServices(
 	systemStorageConfigs = append(systemStorageConfigs, transactionsPostgresDBCfg, requestssPostgresDBCfg)
 
 	for _, operationCfg := range cfg.Operations.Operations {
-		conn, ok := connections[operationCfg.Request.From]
+		dispatcher, ok := dispatchers[operationCfg.Request.From]
 		if !ok {
 			return nil, fmt.Errorf("connection %s not found", operationCfg.Request.From)
 		}
@@ -420,7 +784,7 @@ func initOperationServices(
 			return nil, fmt.Errorf("error loading on startup: %w", err)
 		}
 
-		op := initOperation(operationCfg, conn, uow, messageRepo, driversMap, cfg.InstanceID, metricsService, operationCfg.Buffer)
+		op := initOperation(operationCfg, dispatcher.MsgChan(operationCfg.Name), uow, messageRepo, driversMap, cfg.InstanceID, metricsService, operationCfg.Buffer, deadLetters[operationCfg.Name], idempotencyStores[operationCfg.Name], events[operationCfg.Name])
 
 		operations[operationCfg.Name] = op
 	}
@@ -428,6 +792,229 @@ func initOperationServices(
 	return operations, nil
 }
 
+// initDispatchers создает распределители сообщений для соединений, из которых читают операции. Ключ - название соединения.
+// Операции получают сообщения в порядке объявления в конфигурации: сообщение уходит в первую подходящую операцию.
+// Сообщения, тело которых не удалось декодировать, диспетчер считает и отправляет в dead_letter соединения.
+func initDispatchers(
+	cfg *config.Config,
+	connections map[string]worker.Worker,
+	deadLetters map[string]deadLetter,
+	decodeDeadLetters map[string]deadLetter,
+	metricsService *metrics.Service,
+) (map[string]*dispatcher.Service, error) {
+	routes := make(map[string][]dispatcher.Option)
+
+	for _, operationCfg := range cfg.Operations.Operations {
+		routes[operationCfg.Request.From] = append(routes[operationCfg.Request.From],
+			dispatcher.WithRoute(operationCfg.Name, operationCfg.Request.Match))
+	}
+
+	dispatchers := make(map[string]*dispatcher.Service, len(routes))
+
+	for name, routeOpts := range routes {
+		conn, ok := connections[name]
+		if !ok {
+			return nil, fmt.Errorf("connection %s not found", name)
+		}
+
+		opts := append([]dispatcher.Option{
+			dispatcher.WithName(name),
+			dispatcher.WithMsgChan(conn.MsgChan()),
+			dispatcher.WithMetrics(metricsService),
+		}, routeOpts...)
+
+		if unmatched := cfg.Operations.ConnectionsMap[name].Unmatched; unmatched != nil {
+			opts = append(opts, dispatcher.WithUnmatched(unmatched.Action))
+		}
+
+		// dead_letter не обязателен: не передаем nil в интерфейс, чтобы сервис мог его проверить
+		if deadLetter, ok := deadLetters[name]; ok {
+			opts = append(opts, dispatcher.WithDeadLetter(deadLetter))
+		}
+
+		if deadLetter, ok := decodeDeadLetters[name]; ok {
+			opts = append(opts, dispatcher.WithDecodeDeadLetter(deadLetter))
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"connection": name,
+			"routes":     len(routeOpts),
+		}).Info("initializing dispatcher")
+
+		d, err := dispatcher.New(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing dispatcher %s: %w", name, err)
+		}
+
+		dispatchers[name] = d
+	}
+
+	return dispatchers, nil
+}
+
+// initUnmatchedDeadLetters создает dead letter для сообщений, не подошедших ни одной операции соединения.
+// Ключ - название соединения.
+func initUnmatchedDeadLetters(cfg *config.Config) (map[string]deadLetter, error) {
+	deadLetters := make(map[string]deadLetter)
+
+	for _, connection := range cfg.Operations.Connections {
+		if connection.Unmatched == nil || connection.Unmatched.DeadLetter == nil {
+			continue
+		}
+
+		deadLetter, err := initDeadLetter(*connection.Unmatched.DeadLetter, cfg.Storage.Postgres)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing unmatched dead letter for connection %s: %w", connection.Name, err)
+		}
+
+		deadLetters[connection.Name] = deadLetter
+	}
+
+	return deadLetters, nil
+}
+
+// initDecodeDeadLetters создает dead letter для сообщений, тело которых не удалось декодировать.
+// Ключ - название соединения.
+func initDecodeDeadLetters(cfg *config.Config) (map[string]deadLetter, error) {
+	deadLetters := make(map[string]deadLetter)
+
+	for _, connection := range cfg.Operations.Connections {
+		if connection.DeadLetter == nil {
+			continue
+		}
+
+		deadLetter, err := initDeadLetter(*connection.DeadLetter, cfg.Storage.Postgres)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing decode dead letter for connection %s: %w", connection.Name, err)
+		}
+
+		deadLetters[connection.Name] = deadLetter
+	}
+
+	return deadLetters, nil
+}
+
+// deadLetter - хранилище для сообщений, которые не удалось обработать.
+type deadLetter interface {
+	Run(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Publish(ctx context.Context, letter deadletter.Letter) error
+}
+
+// initDeadLetters создает dead letter для операций, у которых он настроен. Ключ - название операции.
+func initDeadLetters(cfg *config.Config) (map[string]deadLetter, error) {
+	deadLetters := make(map[string]deadLetter)
+
+	for _, operationCfg := range cfg.Operations.Operations {
+		if operationCfg.DeadLetter == nil {
+			continue
+		}
+
+		deadLetter, err := initDeadLetter(*operationCfg.DeadLetter, cfg.Storage.Postgres)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing dead letter for operation %s: %w", operationCfg.Name, err)
+		}
+
+		deadLetters[operationCfg.Name] = deadLetter
+	}
+
+	return deadLetters, nil
+}
+
+// initIdempotencyStores создает хранилища ключей обработанных сообщений для операций, у которых настроена идемпотентность.
+// Ключ - название операции.
+func initIdempotencyStores(cfg *config.Config, redisClient goredis.UniversalClient) (map[string]*idempotency.Store, error) {
+	stores := make(map[string]*idempotency.Store)
+
+	for _, operationCfg := range cfg.Operations.Operations {
+		if operationCfg.Idempotency == nil {
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"operation": operationCfg.Name,
+			"key":       operationCfg.Idempotency.Key,
+			"ttl":       operationCfg.Idempotency.TTL,
+		}).Info("initializing idempotency store")
+
+		store, err := idempotency.New(
+			idempotency.WithClient(redisClient),
+			idempotency.WithOperation(operationCfg.Name),
+			idempotency.WithCfg(operationCfg.Idempotency),
+			idempotency.WithInsertTimeout(cfg.Storage.Redis.InsertTimeout),
+			idempotency.WithReadTimeout(cfg.Storage.Redis.ReadTimeout),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing idempotency store for operation %s: %w", operationCfg.Name, err)
+		}
+
+		stores[operationCfg.Name] = store
+	}
+
+	return stores, nil
+}
+
+// initEvents создает публикацию итогов транзакций для операций, у которых настроены events. Ключ - название операции.
+func initEvents(cfg *config.Config) (map[string]*event.Rabbit, error) {
+	events := make(map[string]*event.Rabbit)
+
+	for _, operationCfg := range cfg.Operations.Operations {
+		if operationCfg.Events == nil {
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"operation":   operationCfg.Name,
+			"exchange":    operationCfg.Events.Exchange,
+			"routing_key": operationCfg.Events.RoutingKey,
+		}).Info("initializing events")
+
+		publisher, err := event.NewRabbit(
+			event.WithAddress(operationCfg.Events.Address),
+			event.WithExchange(operationCfg.Events.Exchange),
+			event.WithRoutingKey(operationCfg.Events.RoutingKey),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing events for operation %s: %w", operationCfg.Name, err)
+		}
+
+		events[operationCfg.Name] = publisher
+	}
+
+	return events, nil
+}
+
+func initDeadLetter(cfg operation.DeadLetter, postgresCfg config.Postgres) (deadLetter, error) {
+	logrus.WithFields(logrus.Fields{
+		"type":        cfg.Type,
+		"exchange":    cfg.Exchange,
+		"routing_key": cfg.RoutingKey,
+		"table":       cfg.Table,
+	}).Info("initializing dead letter")
+
+	switch cfg.Type {
+	case operation.DeadLetterTypeRabbitMQ:
+		return deadletter.NewRabbit(
+			deadletter.WithAddress(cfg.Address),
+			deadletter.WithExchange(cfg.Exchange),
+			deadletter.WithRoutingKey(cfg.RoutingKey),
+		)
+	case operation.DeadLetterTypePostgres:
+		table := cfg.Table
+		if table == "" {
+			table = operation.DefaultDeadLetterTable
+		}
+
+		return deadletter.NewPostgres(
+			deadletter.WithAddr(formatPostgresAddr(postgresCfg)),
+			deadletter.WithTable(table),
+			deadletter.WithInsertTimeout(postgresCfg.InsertTimeout),
+		)
+	default:
+		return nil, fmt.Errorf("unknown dead letter type: %s", cfg.Type)
+	}
+}
+
 func initMigrationService(loader *migration.Repo) *migration_srv.Service {
 	return start(migration_srv.New(migration_srv.WithMigrationLoader(loader)))
 }
@@ -467,17 +1054,45 @@ func initRedisStorage(ctx context.Context, cfg config.Redis) *redis.Service {
 	return redis
 }
 
-func initOperation(operationCfg operation.Operation, connection worker.Worker, uow *uow.Service, messageRepo *message.Repo, driversMap map[string]model.Configurator, instanceID int, metricsService *metrics.Service, bufferSize int) *operation_srv.Service {
-	op := start(operation_srv.New(
+func initOperation(
+	operationCfg operation.Operation,
+	msgChan chan worker.Message,
+	uow *uow.Service,
+	messageRepo *message.Repo,
+	driversMap map[string]model.Configurator,
+	instanceID int,
+	metricsService *metrics.Service,
+	bufferSize int,
+	deadLetter deadLetter,
+	idempotencyStore *idempotency.Store,
+	events *event.Rabbit,
+) *operation_srv.Service {
+	opts := []operation_srv.Option{
 		operation_srv.WithCfg(&operationCfg),
-		operation_srv.WithMsgChan(connection.MsgChan()),
+		operation_srv.WithMsgChan(msgChan),
 		operation_srv.WithUow(uow),
 		operation_srv.WithMessageRepo(messageRepo),
 		operation_srv.WithDriversMap(driversMap),
 		operation_srv.WithInstanceID(instanceID),
 		operation_srv.WithMetricsService(metricsService),
 		operation_srv.WithBuffer(bufferSize),
-	))
+		operation_srv.WithBufferMetrics(metricsService),
+	}
+
+	// dead_letter не обязателен: не передаем nil в интерфейс, чтобы сервис мог его проверить
+	if deadLetter != nil {
+		opts = append(opts, operation_srv.WithDeadLetter(deadLetter))
+	}
+
+	if idempotencyStore != nil {
+		opts = append(opts, operation_srv.WithIdempotency(idempotencyStore))
+	}
+
+	if events != nil {
+		opts = append(opts, operation_srv.WithEvents(events))
+	}
+
+	op := start(operation_srv.New(opts...))
 
 	return op
 }
@@ -22,6 +22,9 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "503": {
+                        "description": "Service Unavailable"
                     }
                 }
             }
@@ -13,33 +13,49 @@ type Handler struct {
 	gitCommit string
 
 	apiVersion string
+
+	connections []connection
+}
+
+// connection - соединение, состояние которого отдает Health.
+type connection interface {
+	Name() string
+	Connected() bool
 }
 
-type handlerOption func(*Handler)
+// Option определяет опции для Handler.
+type Option func(*Handler)
 
 // WithVersion устанавливает version.
-func WithVersion(version string) handlerOption {
+func WithVersion(version string) Option {
 	return func(h *Handler) {
 		h.version = version
 	}
 }
 
 // WithBuildDate устанавливает build date.
-func WithBuildDate(buildDate string) handlerOption {
+func WithBuildDate(buildDate string) Option {
 	return func(h *Handler) {
 		h.buildDate = buildDate
 	}
 }
 
 // WithGitCommit устанавливает git commit.
-func WithGitCommit(gitCommit string) handlerOption {
+func WithGitCommit(gitCommit string) Option {
 	return func(h *Handler) {
 		h.gitCommit = gitCommit
 	}
 }
 
+// WithConnections устанавливает соединения, состояние которых отдает Health.
+func WithConnections(connections ...connection) Option {
+	return func(h *Handler) {
+		h.connections = append(h.connections, connections...)
+	}
+}
+
 // New создает новый хендлер. Автоматически устанавливает версию хендлера на Version0.
-func New(opts ...handlerOption) (*Handler, error) {
+func New(opts ...Option) (*Handler, error) {
 	h := &Handler{}
 
 	for _, opt := range opts {
@@ -6,19 +6,47 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// Health необходим для проверки работоспособности сервера.
-// Всегда отвечает 200 ОК
+// Health необходим для проверки работоспособности сервера и соединений.
+// Отвечает 200 ОК, если все соединения установлены, иначе 503.
 //
 // Health godoc
 //
 //	@Summary		Проверить состояние сервера и соединения
 //	@Description	Проверить состояние сервера и соединения
 //	@Success		200
+//	@Failure		503
 //	@Router			/health [get]
 func (s *Handler) Health(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
+	body := map[string]any{
 		"version":   s.version,
 		"buildDate": s.buildDate,
 		"gitCommit": s.gitCommit,
-	})
+	}
+
+	if len(s.connections) == 0 {
+		return c.JSON(http.StatusOK, body)
+	}
+
+	status := http.StatusOK
+	connections := make(map[string]string, len(s.connections))
+
+	for _, conn := range s.connections {
+		if !conn.Connected() {
+			status = http.StatusServiceUnavailable
+			connections[conn.Name()] = connectionStatusDisconnected
+
+			continue
+		}
+
+		connections[conn.Name()] = connectionStatusConnected
+	}
+
+	body["connections"] = connections
+
+	return c.JSON(status, body)
 }
+
+const (
+	connectionStatusConnected    = "connected"
+	connectionStatusDisconnected = "disconnected"
+)
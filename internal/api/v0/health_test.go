@@ -46,6 +46,90 @@ func TestHealth(t *testing.T) {
 	assertResponse(t, resp, expectedBody)
 }
 
+type fakeConnection struct {
+	name      string
+	connected bool
+}
+
+func (c fakeConnection) Name() string {
+	return c.name
+}
+
+func (c fakeConnection) Connected() bool {
+	return c.connected
+}
+
+func TestHealth_Connections(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		connections []connection
+		wantStatus  int
+		want        map[string]string
+	}{
+		{
+			name: "all connections are connected",
+			connections: []connection{
+				fakeConnection{name: "rabbit", connected: true},
+				fakeConnection{name: "http", connected: true},
+			},
+			wantStatus: http.StatusOK,
+			want: map[string]string{
+				"rabbit": "connected",
+				"http":   "connected",
+			},
+		},
+		{
+			name: "one connection is disconnected",
+			connections: []connection{
+				fakeConnection{name: "rabbit", connected: false},
+				fakeConnection{name: "http", connected: true},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			want: map[string]string{
+				"rabbit": "disconnected",
+				"http":   "connected",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(
+				WithVersion("1.0.0"),
+				WithBuildDate("2021-01-01"),
+				WithGitCommit("1234567890"),
+				WithConnections(tt.connections...),
+			)
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(runTestServer(t, handler))
+			defer ts.Close()
+
+			resp := testRequest(t, ts, http.MethodGet, "/api/v0/health", "", nil)
+
+			defer func() {
+				require.NoError(t, resp.Body.Close())
+			}()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			var body struct {
+				Version     string            `json:"version"`
+				Connections map[string]string `json:"connections"`
+			}
+
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+			assert.Equal(t, "1.0.0", body.Version)
+			assert.Equal(t, tt.want, body.Connections)
+		})
+	}
+}
+
 func assertResponse(t *testing.T, resp *http.Response, body map[string]string) {
 	t.Helper()
 
@@ -0,0 +1,34 @@
+package operation
+
+import "fmt"
+
+// Codec - формат тела сообщения.
+type Codec string
+
+const (
+	// CodecJSON - JSON.
+	CodecJSON Codec = "json"
+	// CodecMsgPack - MessagePack.
+	CodecMsgPack Codec = "msgpack"
+	// CodecCBOR - CBOR.
+	CodecCBOR Codec = "cbor"
+	// CodecProtobuf - protobuf. Схема сообщения задается в protobuf.
+	CodecProtobuf Codec = "protobuf"
+)
+
+// Protobuf - схема protobuf-сообщений соединения.
+type Protobuf struct {
+	DescriptorSet string `yaml:"descriptor_set" validate:"required"` // путь к FileDescriptorSet (protoc --descriptor_set_out --include_imports)
+	Message       string `yaml:"message" validate:"required"`        // полное имя сообщения, например orders.v1.Order
+}
+
+// validateCodecs проверяет, что формат тела поддерживается источником: файлы читаются только как JSONL.
+func (oc *OperationConfig) validateCodecs() error {
+	for _, conn := range oc.Connections {
+		if conn.Type == ConnectionTypeFile && conn.Codec != "" && conn.Codec != CodecJSON {
+			return fmt.Errorf("connection %q: codec %q is not supported for file connection", conn.Name, conn.Codec)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_ValidateCodec(t *testing.T) {
+	t.Parallel()
+
+	base := Connection{Name: "orders", Type: ConnectionTypeHTTP, Path: "orders", InsertTimeout: 1, ReadTimeout: 1}
+
+	tests := []struct {
+		name     string
+		codec    Codec
+		protobuf *Protobuf
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: default codec",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: msgpack",
+			codec:   CodecMsgPack,
+			wantErr: require.NoError,
+		},
+		{
+			name:     "positive case: protobuf",
+			codec:    CodecProtobuf,
+			protobuf: &Protobuf{DescriptorSet: "orders.pb", Message: "orders.v1.Order"},
+			wantErr:  require.NoError,
+		},
+		{
+			name:    "negative case: protobuf without schema",
+			codec:   CodecProtobuf,
+			wantErr: require.Error,
+		},
+		{
+			name:     "negative case: protobuf without message",
+			codec:    CodecProtobuf,
+			protobuf: &Protobuf{DescriptorSet: "orders.pb"},
+			wantErr:  require.Error,
+		},
+		{
+			name:    "negative case: unknown codec",
+			codec:   "xml",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conn := base
+			conn.Codec = tt.codec
+			conn.Protobuf = tt.protobuf
+
+			tt.wantErr(t, validator.New().Struct(conn))
+		})
+	}
+}
+
+func TestOperationConfig_ValidateCodecs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		connection Connection
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{
+			name:       "positive case: file with json",
+			connection: Connection{Name: "replay", Type: ConnectionTypeFile, Codec: CodecJSON},
+			wantErr:    require.NoError,
+		},
+		{
+			name:       "positive case: kafka with cbor",
+			connection: Connection{Name: "events", Type: ConnectionTypeKafka, Codec: CodecCBOR},
+			wantErr:    require.NoError,
+		},
+		{
+			name:       "negative case: file with msgpack",
+			connection: Connection{Name: "replay", Type: ConnectionTypeFile, Codec: CodecMsgPack},
+			wantErr:    require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			oc := OperationConfig{Connections: []Connection{tt.connection}}
+
+			tt.wantErr(t, oc.validateCodecs())
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package operation
+
+import "fmt"
+
+// validatePartitionKey проверяет, что поле ключа партиции есть среди полей операции.
+func (oc *Operation) validatePartitionKey() error {
+	if oc.PartitionKey == "" {
+		return nil
+	}
+
+	if _, ok := oc.FieldsMap[oc.PartitionKey]; !ok {
+		return fmt.Errorf("partition key: field %q not found in operation fields", oc.PartitionKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		concurrency int
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: concurrency not set",
+			wantErr: require.NoError,
+		},
+		{
+			name:        "positive case: concurrency",
+			concurrency: 8,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "negative case: concurrency is negative",
+			concurrency: -1,
+			wantErr:     require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			op := Operation{
+				Name:        "test",
+				Buffer:      1,
+				Timeout:     1,
+				Concurrency: tt.concurrency,
+				Type:        OperationTypeCreate,
+				Storages: []StorageCfg{
+					{Name: "postgres"},
+				},
+				Fields: []Field{
+					{Name: "text", Type: FieldTypeString},
+				},
+				Request: Request{From: "rabbit"},
+			}
+
+			tt.wantErr(t, validator.New().Struct(op))
+		})
+	}
+}
+
+func TestOperation_ValidatePartitionKey(t *testing.T) {
+	t.Parallel()
+
+	fieldsMap := map[string]Field{
+		"user_id": {Name: "user_id", Type: FieldTypeInt64},
+		"text":    {Name: "text", Type: FieldTypeString},
+	}
+
+	tests := []struct {
+		name         string
+		partitionKey string
+		wantErr      require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: not configured",
+			wantErr: require.NoError,
+		},
+		{
+			name:         "positive case: operation field",
+			partitionKey: "user_id",
+			wantErr:      require.NoError,
+		},
+		{
+			name:         "negative case: unknown field",
+			partitionKey: "tenant_id",
+			wantErr:      require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			op := &Operation{Name: "test", FieldsMap: fieldsMap, PartitionKey: tt.partitionKey}
+
+			tt.wantErr(t, op.validatePartitionKey())
+		})
+	}
+}
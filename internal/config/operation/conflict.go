@@ -0,0 +1,49 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Conflict - что делает upsert операция, если запись с таким ключом уже есть: INSERT ... ON CONFLICT.
+// Ключ задается колонками (columns) или названием ограничения (constraint).
+// При конфликте обновляются поля операции с update: true, с do_nothing запись остается как есть.
+type Conflict struct {
+	Columns    []string `yaml:"columns" validate:"required_without=Constraint,excluded_with=Constraint,omitempty,dive,required"` // колонки уникального ключа
+	Constraint string   `yaml:"constraint"`                                                                                      // название ограничения уникальности
+	DoNothing  bool     `yaml:"do_nothing"`                                                                                      // не обновлять существующую запись
+}
+
+// validateConflict проверяет conflict upsert операции: колонки ключа есть среди полей операции,
+// а при конфликте либо обновляется хотя бы одно поле, либо задан do_nothing.
+//
+// WARNING: запускать после того, как отработали методы mapFieldsByOperation и mapFieldsUpdate.
+func (oc *Operation) validateConflict() error {
+	if oc.Type != OperationTypeUpsert {
+		if oc.Conflict != nil {
+			return fmt.Errorf("conflict: allowed only for %s operation", OperationTypeUpsert)
+		}
+
+		return nil
+	}
+
+	if oc.Conflict == nil {
+		return errors.New("conflict: required for upsert operation")
+	}
+
+	for _, name := range oc.Conflict.Columns {
+		if _, ok := oc.FieldsMap[name]; !ok {
+			return fmt.Errorf("conflict: column %q not found in operation fields", name)
+		}
+	}
+
+	if oc.Conflict.DoNothing && len(oc.UpdateFieldsMap) > 0 {
+		return errors.New("conflict: do_nothing is set, but there are fields with update: true")
+	}
+
+	if !oc.Conflict.DoNothing && len(oc.UpdateFieldsMap) == 0 {
+		return errors.New("conflict: no fields with update: true and do_nothing is not set")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflict_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		conflict Conflict
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:     "positive case: columns",
+			conflict: Conflict{Columns: []string{"user_id", "note_id"}},
+			wantErr:  require.NoError,
+		},
+		{
+			name:     "positive case: constraint",
+			conflict: Conflict{Constraint: "notes_pkey", DoNothing: true},
+			wantErr:  require.NoError,
+		},
+		{
+			name:     "negative case: no columns and constraint",
+			conflict: Conflict{DoNothing: true},
+			wantErr:  require.Error,
+		},
+		{
+			name:     "negative case: columns and constraint",
+			conflict: Conflict{Columns: []string{"user_id"}, Constraint: "notes_pkey"},
+			wantErr:  require.Error,
+		},
+		{
+			name:     "negative case: empty column",
+			conflict: Conflict{Columns: []string{""}},
+			wantErr:  require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validator.New().Struct(tt.conflict))
+		})
+	}
+}
+
+//nolint:funlen // много тест-кейсов
+func TestOperation_ValidateConflict(t *testing.T) {
+	t.Parallel()
+
+	fieldsMap := map[string]Field{
+		"user_id": {Name: "user_id", Type: FieldTypeInt64},
+		"text":    {Name: "text", Type: FieldTypeString, Update: true},
+	}
+
+	updateFieldsMap := map[string]Field{
+		"text": fieldsMap["text"],
+	}
+
+	tests := []struct {
+		name            string
+		opType          Type
+		conflict        *Conflict
+		updateFieldsMap map[string]Field
+		wantErr         require.ErrorAssertionFunc
+	}{
+		{
+			name:            "positive case: update on conflict",
+			opType:          OperationTypeUpsert,
+			conflict:        &Conflict{Columns: []string{"user_id"}},
+			updateFieldsMap: updateFieldsMap,
+			wantErr:         require.NoError,
+		},
+		{
+			name:     "positive case: do nothing on conflict",
+			opType:   OperationTypeUpsert,
+			conflict: &Conflict{Constraint: "notes_pkey", DoNothing: true},
+			wantErr:  require.NoError,
+		},
+		{
+			name:    "positive case: not upsert operation",
+			opType:  OperationTypeCreate,
+			wantErr: require.NoError,
+		},
+		{
+			name:     "negative case: conflict for create operation",
+			opType:   OperationTypeCreate,
+			conflict: &Conflict{Columns: []string{"user_id"}, DoNothing: true},
+			wantErr:  require.Error,
+		},
+		{
+			name:            "negative case: upsert without conflict",
+			opType:          OperationTypeUpsert,
+			updateFieldsMap: updateFieldsMap,
+			wantErr:         require.Error,
+		},
+		{
+			name:            "negative case: unknown conflict column",
+			opType:          OperationTypeUpsert,
+			conflict:        &Conflict{Columns: []string{"note_id"}},
+			updateFieldsMap: updateFieldsMap,
+			wantErr:         require.Error,
+		},
+		{
+			name:     "negative case: no update fields",
+			opType:   OperationTypeUpsert,
+			conflict: &Conflict{Columns: []string{"user_id"}},
+			wantErr:  require.Error,
+		},
+		{
+			name:            "negative case: do nothing with update fields",
+			opType:          OperationTypeUpsert,
+			conflict:        &Conflict{Columns: []string{"user_id"}, DoNothing: true},
+			updateFieldsMap: updateFieldsMap,
+			wantErr:         require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			op := &Operation{
+				Name:            "test",
+				Type:            tt.opType,
+				FieldsMap:       fieldsMap,
+				UpdateFieldsMap: tt.updateFieldsMap,
+				Conflict:        tt.conflict,
+			}
+
+			tt.wantErr(t, op.validateConflict())
+		})
+	}
+}
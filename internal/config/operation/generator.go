@@ -0,0 +1,109 @@
+package operation
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Generator - как вычисляется значение поля вместо тела сообщения.
+type Generator string
+
+const (
+	// GeneratorNow - время обработки сообщения: RFC 3339 для string, миллисекунды Unix для int64.
+	GeneratorNow Generator = "now()"
+	// GeneratorUUIDv4 - случайный UUID.
+	GeneratorUUIDv4 Generator = "uuid_v4"
+	// GeneratorUUIDv7 - UUID, упорядоченный по времени.
+	GeneratorUUIDv7 Generator = "uuid_v7"
+	// GeneratorInstanceID - айди экземпляра приложения.
+	GeneratorInstanceID Generator = "instance_id"
+	// GeneratorOperationHash - хеш операции (hex).
+	GeneratorOperationHash Generator = "operation_hash"
+	// GeneratorTxID - айди транзакции, в которой записывается сообщение.
+	GeneratorTxID Generator = "tx_id"
+	// GeneratorPayload - исходное тело сообщения в JSON (для колонок json/jsonb).
+	GeneratorPayload Generator = "payload"
+)
+
+// allows возвращает true, если значение генератора можно записать в поле типа t.
+func (g Generator) allows(t FieldType) bool {
+	switch g {
+	case GeneratorNow:
+		return t == FieldTypeString || t == FieldTypeInt64 || t == FieldTypeTimestamp || t == FieldTypeDate
+	case GeneratorUUIDv4, GeneratorUUIDv7:
+		return t == FieldTypeUUID || t == FieldTypeString
+	case GeneratorInstanceID:
+		return t == FieldTypeInt64
+	case GeneratorOperationHash, GeneratorTxID, GeneratorPayload:
+		return t == FieldTypeString
+	default:
+		return false
+	}
+}
+
+// validateGenerated проверяет значение по умолчанию и генератор поля.
+func validateGenerated(f Field) error {
+	if f.Generator != "" {
+		if f.Default != nil {
+			return fmt.Errorf("field %s: default and generator are mutually exclusive", f.Name)
+		}
+
+		if f.Source != "" || f.Path != "" {
+			return fmt.Errorf("field %s: generated field can't have source or path", f.Name)
+		}
+
+		if !f.Generator.allows(f.Type) {
+			return fmt.Errorf("field %s: generator %q is not allowed for type %q", f.Name, f.Generator, f.Type)
+		}
+	}
+
+	if f.Default != nil && !defaultMatches(f, f.Default) {
+		return fmt.Errorf("field %s: default %v is not a %s", f.Name, f.Default, f.Type)
+	}
+
+	return nil
+}
+
+// defaultMatches проверяет, что значение по умолчанию из yaml подходит к типу поля.
+//
+//nolint:cyclop // один switch по типам поля
+func defaultMatches(f Field, val any) bool {
+	switch f.Type {
+	case FieldTypeString:
+		_, ok := val.(string)
+		return ok
+	case FieldTypeInt64:
+		_, ok := val.(int)
+		return ok
+	case FieldTypeFloat64:
+		switch val.(type) {
+		case int, float64:
+			return true
+		}
+	case FieldTypeBool:
+		_, ok := val.(bool)
+		return ok
+	case FieldTypeUUID:
+		str, ok := val.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := uuid.Parse(str)
+
+		return err == nil
+	case FieldTypeTimestamp, FieldTypeDate:
+		_, err := f.ParseTime(val)
+		return err == nil
+	case FieldTypeDecimal:
+		_, err := ParseDecimal(boundString(val))
+		return err == nil
+	case FieldTypeEnum:
+		str, ok := val.(string)
+		return ok && slices.Contains(f.Enum, str)
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestValidateGenerated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: no default and generator",
+			field:   Field{Name: "text", Type: FieldTypeString},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: now as string",
+			field:   Field{Name: "created_at", Type: FieldTypeString, Generator: GeneratorNow},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: now as int64",
+			field:   Field{Name: "created_at", Type: FieldTypeInt64, Generator: GeneratorNow},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: uuid v7",
+			field:   Field{Name: "id", Type: FieldTypeUUID, Generator: GeneratorUUIDv7},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: payload",
+			field:   Field{Name: "raw", Type: FieldTypeString, Generator: GeneratorPayload},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: defaults",
+			field:   Field{Name: "status", Type: FieldTypeString, Default: "new"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: int default for float",
+			field:   Field{Name: "price", Type: FieldTypeFloat64, Default: 10},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: uuid default",
+			field:   Field{Name: "id", Type: FieldTypeUUID, Default: "0191f1a2-8a2c-7cc3-9b7e-0d2f5a6b7c8d"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: default and generator",
+			field:   Field{Name: "id", Type: FieldTypeUUID, Default: "0191f1a2-8a2c-7cc3-9b7e-0d2f5a6b7c8d", Generator: GeneratorUUIDv4},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: generator and source",
+			field:   Field{Name: "id", Type: FieldTypeString, Generator: GeneratorTxID, Source: "header.X-Tx-Id"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: generator and path",
+			field:   Field{Name: "id", Type: FieldTypeString, Generator: GeneratorTxID, Path: "tx.id"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: generator type",
+			field:   Field{Name: "instance", Type: FieldTypeString, Generator: GeneratorInstanceID},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: default type",
+			field:   Field{Name: "count", Type: FieldTypeInt64, Default: "ten"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: invalid uuid default",
+			field:   Field{Name: "id", Type: FieldTypeUUID, Default: "not-uuid"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validateGenerated(tt.field))
+		})
+	}
+}
+
+func TestGeneratorTag(t *testing.T) {
+	t.Parallel()
+
+	validate := validator.New()
+
+	for _, generator := range []Generator{
+		GeneratorNow, GeneratorUUIDv4, GeneratorUUIDv7, GeneratorInstanceID,
+		GeneratorOperationHash, GeneratorTxID, GeneratorPayload,
+	} {
+		require.NoError(t, validate.Struct(Field{Name: "id", Type: FieldTypeString, Generator: generator}), generator)
+	}
+
+	require.Error(t, validate.Struct(Field{Name: "id", Type: FieldTypeString, Generator: "uuid"}))
+}
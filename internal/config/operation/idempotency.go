@@ -0,0 +1,44 @@
+package operation
+
+import "fmt"
+
+// IdempotencyKey - откуда берется ключ идемпотентности сообщения.
+type IdempotencyKey string
+
+const (
+	// IdempotencyKeyField - значение поля сообщения.
+	IdempotencyKeyField IdempotencyKey = "field"
+	// IdempotencyKeyMessageID - айди сообщения у источника (например, message_id AMQP).
+	IdempotencyKeyMessageID IdempotencyKey = "message_id"
+	// IdempotencyKeyHash - хеш значений выбранных полей сообщения.
+	IdempotencyKeyHash IdempotencyKey = "hash"
+)
+
+// Idempotency - защита операции от повторной обработки одного и того же сообщения.
+// Ключи обработанных сообщений хранятся в Redis вместе с айди транзакции.
+type Idempotency struct {
+	Key    IdempotencyKey `yaml:"key" validate:"required,oneof=field message_id hash"`
+	Field  string         `yaml:"field" validate:"required_if=Key field"`                // поле с ключом (key: field)
+	Fields []string       `yaml:"fields" validate:"required_if=Key hash,omitempty,dive"` // поля, из значений которых считается хеш (key: hash)
+	TTL    int            `yaml:"ttl" validate:"required,min=1"`                         // сколько хранить ключ обработанного сообщения (с)
+}
+
+// validateIdempotency проверяет, что поля ключа идемпотентности есть среди полей операции.
+func (oc *Operation) validateIdempotency() error {
+	if oc.Idempotency == nil {
+		return nil
+	}
+
+	fields := oc.Idempotency.Fields
+	if oc.Idempotency.Key == IdempotencyKeyField {
+		fields = []string{oc.Idempotency.Field}
+	}
+
+	for _, name := range fields {
+		if _, ok := oc.FieldsMap[name]; !ok {
+			return fmt.Errorf("idempotency: field %q not found in operation fields", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		idempotency Idempotency
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:        "positive case: field",
+			idempotency: Idempotency{Key: IdempotencyKeyField, Field: "request_id", TTL: 3600},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: message id",
+			idempotency: Idempotency{Key: IdempotencyKeyMessageID, TTL: 3600},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: hash",
+			idempotency: Idempotency{Key: IdempotencyKeyHash, Fields: []string{"user_id", "text"}, TTL: 3600},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "negative case: field without field name",
+			idempotency: Idempotency{Key: IdempotencyKeyField, TTL: 3600},
+			wantErr:     require.Error,
+		},
+		{
+			name:        "negative case: hash without fields",
+			idempotency: Idempotency{Key: IdempotencyKeyHash, TTL: 3600},
+			wantErr:     require.Error,
+		},
+		{
+			name:        "negative case: unknown key",
+			idempotency: Idempotency{Key: "header", TTL: 3600},
+			wantErr:     require.Error,
+		},
+		{
+			name:        "negative case: no ttl",
+			idempotency: Idempotency{Key: IdempotencyKeyMessageID},
+			wantErr:     require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validator.New().Struct(tt.idempotency))
+		})
+	}
+}
+
+func TestOperation_ValidateIdempotency(t *testing.T) {
+	t.Parallel()
+
+	fieldsMap := map[string]Field{
+		"user_id": {Name: "user_id", Type: FieldTypeInt64},
+		"text":    {Name: "text", Type: FieldTypeString},
+	}
+
+	tests := []struct {
+		name        string
+		idempotency *Idempotency
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:        "positive case: not configured",
+			idempotency: nil,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: field",
+			idempotency: &Idempotency{Key: IdempotencyKeyField, Field: "user_id"},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: message id",
+			idempotency: &Idempotency{Key: IdempotencyKeyMessageID},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "negative case: unknown field",
+			idempotency: &Idempotency{Key: IdempotencyKeyField, Field: "request_id"},
+			wantErr:     require.Error,
+		},
+		{
+			name:        "negative case: unknown hash field",
+			idempotency: &Idempotency{Key: IdempotencyKeyHash, Fields: []string{"user_id", "request_id"}},
+			wantErr:     require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			op := &Operation{Name: "test", FieldsMap: fieldsMap, Idempotency: tt.idempotency}
+
+			tt.wantErr(t, op.validateIdempotency())
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package operation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path - путь к значению во вложенном теле сообщения: ключи объектов и индексы массивов.
+type Path []string
+
+// ParsePath разбирает путь к значению: note.text, $.note.text, items[0].id. Пустая строка - пустой путь.
+func ParsePath(path string) (Path, error) {
+	rest := strings.TrimPrefix(path, "$")
+	rest = strings.TrimPrefix(rest, ".")
+
+	if rest == "" {
+		if path != "" && path != "$" {
+			return nil, fmt.Errorf("path %q: key is required", path)
+		}
+
+		return nil, nil
+	}
+
+	// индексы массивов записываются как ключи: items[0].id -> items.0.id
+	var sb strings.Builder
+
+	for {
+		open := strings.IndexByte(rest, '[')
+		if open < 0 {
+			sb.WriteString(rest)
+			break
+		}
+
+		closing := strings.IndexByte(rest[open:], ']')
+		if closing < 0 {
+			return nil, fmt.Errorf("path %q: unclosed bracket", path)
+		}
+
+		index := rest[open+1 : open+closing]
+		if index == "" || strings.Trim(index, "0123456789") != "" {
+			return nil, fmt.Errorf("path %q: index %q is not a number", path, index)
+		}
+
+		sb.WriteString(rest[:open])
+		sb.WriteString(".")
+		sb.WriteString(index)
+
+		rest = rest[open+closing+1:]
+	}
+
+	segments := strings.Split(sb.String(), ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("path %q: empty key", path)
+		}
+	}
+
+	return segments, nil
+}
+
+// Lookup возвращает значение по пути во вложенном теле сообщения. false - значения по пути нет.
+func (p Path) Lookup(data any) (any, bool) {
+	for _, key := range p {
+		switch node := data.(type) {
+		case map[string]any:
+			val, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+
+			data = val
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+
+			data = node[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return data, true
+}
+
+// ColumnName возвращает колонку, в которую записывается поле: column, если задана, иначе название поля.
+func (f Field) ColumnName() string {
+	if f.Column != "" {
+		return f.Column
+	}
+
+	return f.Name
+}
+
+// validatePath проверяет путь к значению поля.
+func validatePath(f Field) error {
+	if f.Path == "" {
+		return nil
+	}
+
+	if f.Source != "" {
+		return fmt.Errorf("field %s: path and source are mutually exclusive", f.Name)
+	}
+
+	path, err := ParsePath(f.Path)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", f.Name, err)
+	}
+
+	if len(path) == 0 {
+		return fmt.Errorf("field %s: path %q points to the whole message", f.Name, f.Path)
+	}
+
+	return nil
+}
+
+// validateMapping проверяет корень тела сообщения и то, что поля операции не пишутся в одну колонку.
+func (oc *Operation) validateMapping() error {
+	if _, err := ParsePath(oc.Root); err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+
+	columns := make(map[string]string, len(oc.Fields))
+
+	for _, field := range oc.Fields {
+		column := field.ColumnName()
+
+		if other, ok := columns[column]; ok {
+			return fmt.Errorf("fields %s and %s: same column %q", other, field.Name, column)
+		}
+
+		columns[column] = field.Name
+	}
+
+	return nil
+}
+
+// mapWhereColumns проставляет полям условия where колонки полей операции с тем же названием.
+// WARNING: запускать после mapFieldsByOperation.
+func (oc *Operation) mapWhereColumns(where []Where) {
+	for i := range where {
+		for j, field := range where[i].Fields {
+			declared, ok := oc.FieldsMap[field.Name]
+			if ok && field.Column == "" {
+				where[i].Fields[j].Column = declared.Column
+			}
+		}
+
+		oc.mapWhereColumns(where[i].Conditions)
+	}
+}
@@ -0,0 +1,241 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Path
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: empty",
+			path:    "",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: root only",
+			path:    "$",
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: key",
+			path:    "data",
+			want:    Path{"data"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: dot path",
+			path:    "note.text",
+			want:    Path{"note", "text"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: json path",
+			path:    "$.note.text",
+			want:    Path{"note", "text"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: array index",
+			path:    "$.items[0].id",
+			want:    Path{"items", "0", "id"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: nested arrays",
+			path:    "matrix[1][2]",
+			want:    Path{"matrix", "1", "2"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: empty key",
+			path:    "note..text",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: trailing dot",
+			path:    "note.",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unclosed bracket",
+			path:    "items[0",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: index is not a number",
+			path:    "items[first].id",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: only dot after root",
+			path:    "$.",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParsePath(tt.path)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPath_Lookup(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"items": []any{map[string]any{"id": 1}},
+		"note":  map[string]any{"text": nil},
+	}
+
+	val, ok := Path{"items", "0", "id"}.Lookup(data)
+	require.True(t, ok)
+	assert.Equal(t, 1, val)
+
+	// null - значение есть
+	val, ok = Path{"note", "text"}.Lookup(data)
+	require.True(t, ok)
+	assert.Nil(t, val)
+
+	for _, path := range []Path{
+		{"items", "1", "id"},
+		{"items", "first"},
+		{"note", "text", "value"},
+		{"missing"},
+	} {
+		_, ok = path.Lookup(data)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestField_ColumnName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "text", Field{Name: "text"}.ColumnName())
+	assert.Equal(t, "note_text", Field{Name: "text", Column: "note_text"}.ColumnName())
+}
+
+func TestValidatePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: no path",
+			field:   Field{Name: "text"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: path",
+			field:   Field{Name: "text", Path: "note.text"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: invalid path",
+			field:   Field{Name: "text", Path: "note..text"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: path to the whole message",
+			field:   Field{Name: "text", Path: "$"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: path and source",
+			field:   Field{Name: "text", Path: "note.text", Source: "header.text"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validatePath(tt.field))
+		})
+	}
+}
+
+func TestValidateMapping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		op      Operation
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: columns",
+			op: Operation{
+				Root:   "data",
+				Fields: []Field{{Name: "text", Column: "note_text"}, {Name: "user_id"}},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: invalid root",
+			op: Operation{
+				Root:   "data[x]",
+				Fields: []Field{{Name: "text"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: same column",
+			op: Operation{
+				Fields: []Field{{Name: "text", Column: "body"}, {Name: "body"}},
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, tt.op.validateMapping())
+		})
+	}
+}
+
+func TestMapWhereColumns(t *testing.T) {
+	t.Parallel()
+
+	op := Operation{
+		Fields: []Field{{Name: "user_id", Column: "owner_id"}, {Name: "text"}},
+		Where: []Where{
+			{
+				Fields: []WhereField{{Field: Field{Name: "user_id"}, Operator: OperatorEqual}},
+				Conditions: []Where{
+					{Fields: []WhereField{{Field: Field{Name: "text"}, Operator: OperatorEqual}}},
+					{Fields: []WhereField{{Field: Field{Name: "user_id", Column: "author_id"}, Operator: OperatorEqual}}},
+				},
+			},
+		},
+	}
+
+	op.mapFieldsByOperation()
+	op.mapWhereColumns(op.Where)
+
+	assert.Equal(t, "owner_id", op.Where[0].Fields[0].ColumnName())
+	assert.Equal(t, "text", op.Where[0].Conditions[0].Fields[0].ColumnName())
+	// колонка, заданная в условии, не перезаписывается
+	assert.Equal(t, "author_id", op.Where[0].Conditions[1].Fields[0].ColumnName())
+}
@@ -3,7 +3,9 @@ package operation
 import (
 	"crypto/sha256"
 	"fmt"
+	"math/big"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
@@ -20,6 +22,8 @@ const (
 	OperationTypeUpdate Type = "update"
 	// OperationTypeDelete - удаление.
 	OperationTypeDelete Type = "delete"
+	// OperationTypeUpsert - создание или обновление, если запись с таким ключом уже есть.
+	OperationTypeUpsert Type = "upsert"
 )
 
 // OperationConfig - конфигурация операций, которые будут выполнены над моделью.
@@ -37,14 +41,29 @@ type OperationConfig struct {
 // Operation - операция, которая будет выполнена над моделью.
 type Operation struct {
 	Name     string       `yaml:"name" validate:"required"`
-	Buffer   int          `yaml:"buffer" validate:"required,min=1"`  // размер буфера для операций
-	Timeout  int          `yaml:"timeout" validate:"required,min=1"` // время ожидания операции в миллисекундах
-	Type     Type         `yaml:"type" validate:"required,oneof=create update delete"`
+	Buffer   int          `yaml:"buffer" validate:"required,min=1"`  // сколько сообщений обрабатывать за раз
+	Timeout  int          `yaml:"timeout" validate:"required,min=1"` // сколько миллисекунд сообщение ждет в буфере, прежде чем буфер будет обработан
+	Type     Type         `yaml:"type" validate:"required,oneof=create update delete upsert"`
 	Storages []StorageCfg `yaml:"storage" validate:"required,dive"` // куда сохранять модели. если несколько - будет сохраняться транзакцией
 	Fields   []Field      `yaml:"fields" validate:"required,dive"`
 	Request  Request      `yaml:"request" validate:"required"`
 	Where    []Where      `yaml:"where" validate:"omitempty"` // условие, по которому будет выполнена операция. Только для операций update и delete
 
+	Conflict *Conflict `yaml:"conflict" validate:"omitempty"` // что делать, если запись уже есть. Только для операции upsert
+
+	UnknownFields UnknownFields `yaml:"unknown_fields" validate:"omitempty,oneof=ignore reject"` // что делать с ключами сообщения, которых нет в fields. По умолчанию ignore
+	Root          string        `yaml:"root"`                                                    // путь к телу модели внутри сообщения, например data для {"data": {...}}. Если не задан - все сообщение
+
+	BufferBytes   int `yaml:"buffer_bytes" validate:"min=0"`   // суммарный размер сообщений в JSON (байт), после которого буфер обрабатывается. 0 - без ограничения
+	CopyThreshold int `yaml:"copy_threshold" validate:"min=0"` // со скольких сообщений буфер create операции записывается через COPY. 0 - только INSERT
+
+	Concurrency  int    `yaml:"concurrency" validate:"min=0"` // сколько воркеров обрабатывают сообщения операции параллельно. 0 и 1 - последовательно
+	PartitionKey string `yaml:"partition_key"`                // поле сообщения: сообщения с одним значением обрабатываются одним воркером по порядку
+
+	DeadLetter  *DeadLetter  `yaml:"dead_letter" validate:"omitempty"` // куда отправлять сообщения, которые не удалось обработать
+	Idempotency *Idempotency `yaml:"idempotency" validate:"omitempty"` // как отбрасывать повторно доставленные сообщения
+	Events      *Events      `yaml:"events" validate:"omitempty"`      // куда публиковать итоги выполненных транзакций
+
 	FieldsMap       map[string]Field      `yaml:"-" validate:"-"`
 	WhereFieldsMap  map[string]WhereField `yaml:"-" validate:"-"`
 	UpdateFieldsMap map[string]Field      `yaml:"-" validate:"-"` // поля, которые будут обновляться (при update операции)
@@ -52,23 +71,159 @@ type Operation struct {
 	Hash []byte `yaml:"-" validate:"-"` // поле для вычисления хеша операции (для версионирования)
 }
 
+// DeadLetterType - тип получателя необработанных сообщений.
+type DeadLetterType string
+
+const (
+	// DeadLetterTypeRabbitMQ - публикация в exchange RabbitMQ.
+	DeadLetterTypeRabbitMQ DeadLetterType = "rabbitmq"
+	// DeadLetterTypePostgres - запись в таблицу PostgreSQL (в базе сервиса).
+	DeadLetterTypePostgres DeadLetterType = "postgres"
+)
+
+// DefaultDeadLetterTable - таблица для необработанных сообщений по умолчанию.
+const DefaultDeadLetterTable = "messages.dead_letters"
+
+// DeadLetter - куда отправлять сообщения, которые не прошли валидацию или не были выполнены.
+type DeadLetter struct {
+	Type DeadLetterType `yaml:"type" validate:"required,oneof=rabbitmq postgres"`
+
+	// rabbitmq
+	Address    string `yaml:"address" validate:"required_if=Type rabbitmq"`
+	Exchange   string `yaml:"exchange" validate:"required_if=Type rabbitmq"` // exchange должен существовать
+	RoutingKey string `yaml:"routing_key"`
+
+	// postgres
+	Table string `yaml:"table"` // таблица в базе сервиса. По умолчанию messages.dead_letters
+}
+
+// Events - exchange RabbitMQ, в который публикуются итоги выполненных транзакций операции:
+// айди и статус транзакции, драйвер, на котором она упала, и ошибка.
+type Events struct {
+	Address    string `yaml:"address" validate:"required"`
+	Exchange   string `yaml:"exchange" validate:"required"` // exchange должен существовать
+	RoutingKey string `yaml:"routing_key"`                  // по умолчанию название операции
+}
+
 // ConnectionType - тип соединения.
 type ConnectionType string
 
 const (
 	// ConnectionTypeRabbitMQ - RabbitMQ.
 	ConnectionTypeRabbitMQ ConnectionType = "rabbitmq"
+	// ConnectionTypeHTTP - HTTP (POST-запросы к серверу приложения).
+	ConnectionTypeHTTP ConnectionType = "http"
+	// ConnectionTypeRedisStream - Redis Stream (группа потребителей). Использует подключение из storage.redis.
+	ConnectionTypeRedisStream ConnectionType = "redis_stream"
+	// ConnectionTypeKafka - Kafka (группа потребителей).
+	ConnectionTypeKafka ConnectionType = "kafka"
+	// ConnectionTypeNATS - NATS JetStream (durable pull consumer).
+	ConnectionTypeNATS ConnectionType = "nats"
+	// ConnectionTypeFile - JSONL-файлы (в том числе .gz). Позиции чтения хранятся в базе сервиса.
+	ConnectionTypeFile ConnectionType = "file"
+)
+
+// HTTPMode - режим ответа HTTP-соединения.
+type HTTPMode string
+
+const (
+	// HTTPModeAsync - ответ 202 сразу после сохранения сообщения.
+	HTTPModeAsync HTTPMode = "async"
+	// HTTPModeSync - ответ после завершения транзакции.
+	HTTPModeSync HTTPMode = "sync"
+)
+
+// ExchangeType - тип exchange RabbitMQ.
+type ExchangeType string
+
+const (
+	// ExchangeTypeDirect - direct exchange.
+	ExchangeTypeDirect ExchangeType = "direct"
+	// ExchangeTypeTopic - topic exchange.
+	ExchangeTypeTopic ExchangeType = "topic"
+	// ExchangeTypeFanout - fanout exchange.
+	ExchangeTypeFanout ExchangeType = "fanout"
+	// ExchangeTypeHeaders - headers exchange.
+	ExchangeTypeHeaders ExchangeType = "headers"
+)
+
+// AckPolicy - момент подтверждения сообщения RabbitMQ.
+type AckPolicy string
+
+const (
+	// AckPolicyOnReceive - сообщение подтверждается сразу после передачи в операцию.
+	AckPolicyOnReceive AckPolicy = "on_receive"
+	// AckPolicyOnPersisted - сообщение подтверждается после сохранения в messages.messages.
+	AckPolicyOnPersisted AckPolicy = "on_persisted"
+	// AckPolicyOnCommit - сообщение подтверждается после успешного завершения транзакции.
+	AckPolicyOnCommit AckPolicy = "on_commit"
 )
 
 // Connection - соединение, откуда будет получен запрос на операцию.
 type Connection struct {
-	Name          string         `yaml:"name" validate:"required"`
-	Type          ConnectionType `yaml:"type" validate:"required,oneof=rabbitmq"`
-	Address       string         `yaml:"address" validate:"required"`
-	Queue         string         `yaml:"queue" validate:"required"`
-	RoutingKey    string         `yaml:"routing_key" validate:"required"`
-	InsertTimeout int            `yaml:"insert_timeout" validate:"min=1"`
-	ReadTimeout   int            `yaml:"read_timeout" validate:"min=1"`
+	Name string         `yaml:"name" validate:"required"`
+	Type ConnectionType `yaml:"type" validate:"required,oneof=rabbitmq http redis_stream kafka nats file"`
+
+	// rabbitmq
+	Address      string         `yaml:"address" validate:"required_if=Type rabbitmq,required_if=Type nats"`   // адрес брокера (rabbitmq, nats)
+	Exchange     string         `yaml:"exchange"`                                                             // название exchange. По умолчанию exchange
+	ExchangeType ExchangeType   `yaml:"exchange_type" validate:"omitempty,oneof=direct topic fanout headers"` // тип exchange. По умолчанию topic
+	Queue        string         `yaml:"queue" validate:"required_if=Type rabbitmq"`
+	RoutingKey   string         `yaml:"routing_key"`                                                             // ключ привязки очереди к exchange. Обязателен (или binding_keys) для direct и topic
+	BindingKeys  []string       `yaml:"binding_keys"`                                                            // дополнительные ключи привязки очереди к exchange
+	BindingArgs  map[string]any `yaml:"binding_args"`                                                            // аргументы привязки. Для headers: x-match и заголовки для сравнения
+	QueueArgs    map[string]any `yaml:"queue_args"`                                                              // аргументы очереди: x-queue-type, x-message-ttl, x-max-length, x-dead-letter-exchange и т.д.
+	Passive      bool           `yaml:"passive"`                                                                 // не объявлять exchange, очередь и привязки: читать существующую очередь
+	AckPolicy    AckPolicy      `yaml:"ack_policy" validate:"omitempty,oneof=on_receive on_persisted on_commit"` // когда подтверждать сообщение. По умолчанию on_commit
+	Requeue      bool           `yaml:"requeue"`                                                                 // возвращать ли сообщение в очередь при ошибке обработки. Иначе сообщение отклоняется (попадает в DLX, если она настроена)
+	MaxInFlight  int            `yaml:"max_in_flight" validate:"min=0"`                                          // сколько сообщений одновременно передано в операцию и не обработано. По умолчанию 100
+	Prefetch     int            `yaml:"prefetch" validate:"min=0"`                                               // сколько неподтвержденных сообщений брокер присылает заранее (basic.qos). По умолчанию max_in_flight
+	OrderKey     string         `yaml:"order_key"`                                                               // поле сообщения: сообщения с одним значением обрабатываются строго в порядке получения
+
+	ReconnectInterval    int `yaml:"reconnect_interval" validate:"min=0"`                                     // задержка перед первой попыткой переподключения в миллисекундах. По умолчанию 1000
+	ReconnectMaxInterval int `yaml:"reconnect_max_interval" validate:"min=0,gtefield=ReconnectInterval|eq=0"` // максимальная задержка между попытками переподключения в миллисекундах. По умолчанию 30000
+
+	// http
+	Path string   `yaml:"path" validate:"required_if=Type http,required_if=Type file"` // путь относительно /api/v0/, на который принимаются POST-запросы (http); путь к файлу или glob (file)
+	Mode HTTPMode `yaml:"mode" validate:"omitempty,oneof=async sync"`                  // режим ответа. По умолчанию async
+	// максимальный размер тела запроса в байтах. Запрос с телом больше получает ответ 413. По умолчанию 1048576 (1 МБ)
+	MaxBodySize int64 `yaml:"max_body_size" validate:"min=0"`
+
+	// redis_stream
+	Stream        string `yaml:"stream" validate:"required_if=Type redis_stream,required_if=Type nats"` // название стрима (redis_stream, nats)
+	Group         string `yaml:"group" validate:"required_if=Type redis_stream,required_if=Type kafka"` // группа потребителей (redis_stream, kafka)
+	Consumer      string `yaml:"consumer"`                                                              // префикс имени потребителя, к нему добавляется instance_id. По умолчанию название соединения
+	Field         string `yaml:"field"`                                                                 // поле записи с телом сообщения (JSON). По умолчанию data
+	BatchSize     int64  `yaml:"batch_size" validate:"min=0"`                                           // сколько записей читать за один запрос (redis_stream, nats). По умолчанию 10
+	ClaimMinIdle  int    `yaml:"claim_min_idle" validate:"min=0"`                                       // через сколько миллисекунд неподтвержденная запись забирается у другого потребителя. По умолчанию 60000
+	ClaimInterval int    `yaml:"claim_interval" validate:"min=0"`                                       // как часто (в миллисекундах) проверять неподтвержденные записи. По умолчанию 30000
+
+	// kafka
+	Brokers        []string `yaml:"brokers" validate:"required_if=Type kafka"`    // адреса брокеров
+	Topic          string   `yaml:"topic" validate:"required_if=Type kafka"`      // топик
+	CommitInterval int      `yaml:"commit_interval" validate:"omitempty,min=100"` // как часто (в миллисекундах) коммитить обработанные offset. По умолчанию 1000
+
+	// nats
+	Durable    string   `yaml:"durable" validate:"required_if=Type nats"` // имя durable consumer. Экземпляры сервиса с одним именем делят сообщения
+	Subjects   []string `yaml:"subjects"`                                 // фильтр субъектов стрима, сообщения которых получает соединение
+	MaxDeliver int      `yaml:"max_deliver" validate:"min=-1"`            // сколько раз доставлять сообщение. По умолчанию без ограничений
+	AckWait    int      `yaml:"ack_wait" validate:"min=0"`                // сколько миллисекунд сервер ждет подтверждения. По умолчанию 30000
+	NakDelay   int      `yaml:"nak_delay" validate:"min=0"`               // через сколько миллисекунд доставить сообщение повторно после ошибки. По умолчанию 5000
+
+	// file
+	Follow             bool `yaml:"follow"`                               // читать последний файл дальше по мере дописывания (как tail -f). Не поддерживается для .gz
+	PollInterval       int  `yaml:"poll_interval" validate:"min=0"`       // как часто (в миллисекундах) проверять, дописан ли файл. По умолчанию 1000
+	CheckpointInterval int  `yaml:"checkpoint_interval" validate:"min=0"` // как часто (в миллисекундах) сохранять позицию чтения. По умолчанию 1000
+
+	Unmatched *Unmatched `yaml:"unmatched" validate:"omitempty"` // что делать с сообщениями, которые не подошли ни одной операции. По умолчанию fail
+
+	// формат тела сообщения
+	Codec      Codec       `yaml:"codec" validate:"omitempty,oneof=json msgpack cbor protobuf"` // формат тела по умолчанию. Отправитель может указать другой через content-type. По умолчанию json
+	Protobuf   *Protobuf   `yaml:"protobuf" validate:"required_if=Codec protobuf,omitempty"`    // схема protobuf-сообщений
+	DeadLetter *DeadLetter `yaml:"dead_letter" validate:"omitempty"`                            // куда отправлять сообщения, тело которых не удалось декодировать
+
+	InsertTimeout int `yaml:"insert_timeout" validate:"min=1"`
+	ReadTimeout   int `yaml:"read_timeout" validate:"min=1"`
 }
 
 // StorageType - тип хранилища.
@@ -117,16 +272,38 @@ const (
 	FieldTypeBool FieldType = "bool"
 	// FieldTypeUUID - UUID.
 	FieldTypeUUID FieldType = "uuid"
+	// FieldTypeTimestamp - время с часовым поясом (timestamptz).
+	FieldTypeTimestamp FieldType = "timestamp"
+	// FieldTypeDate - дата без времени.
+	FieldTypeDate FieldType = "date"
+	// FieldTypeDecimal - десятичное число произвольной точности (numeric).
+	FieldTypeDecimal FieldType = "decimal"
+	// FieldTypeEnum - строка из списка допустимых значений (enum).
+	FieldTypeEnum FieldType = "enum"
 )
 
 // Field - поле сообщения.
 type Field struct {
 	Name            string               `yaml:"name"`
-	Type            FieldType            `yaml:"type" validate:"required,oneof=string int64 float64 bool uuid"`
+	Type            FieldType            `yaml:"type" validate:"required,oneof=string int64 float64 bool uuid timestamp date decimal enum"`
 	Required        bool                 `yaml:"required"`
 	ValidationsList []Validation         `yaml:"validation" validate:"omitempty,dive"`
-	Validation      AggregatedValidation `yaml:"-" validate:"-"` // все валидации, которые будут применены к полю
-	Update          bool                 `yaml:"update"`         // будет ли поле обновляться (при update операции)
+	Validation      AggregatedValidation `yaml:"-" validate:"-"`   // все валидации, которые будут применены к полю
+	Update          bool                 `yaml:"update"`           // будет ли поле обновляться (при update операции)
+	Source          string               `yaml:"source,omitempty"` // откуда взять значение: header.<имя> или meta.<ключ>. Если не задано - из тела сообщения
+
+	Column string `yaml:"column,omitempty"` // колонка в хранилище. Если не задана - совпадает с названием поля
+	Path   string `yaml:"path,omitempty"`   // путь к значению во вложенном теле: note.text, $.items[0].id. Если не задан - ключ с названием поля
+
+	Default   any       `yaml:"default,omitempty"`                                                                                             // значение, если поля нет в сообщении
+	Generator Generator `yaml:"generator,omitempty" validate:"omitempty,oneof=now() uuid_v4 uuid_v7 instance_id operation_hash tx_id payload"` // значение вычисляется, а не берется из сообщения
+
+	Transforms []Transform `yaml:"transform,omitempty" validate:"omitempty,dive"` // преобразования значения перед валидацией, по порядку
+
+	Format   string         `yaml:"format,omitempty"`   // формат timestamp и date: rfc3339, unix, unix_ms или layout Go. По умолчанию RFC 3339 и 2006-01-02
+	Timezone string         `yaml:"timezone,omitempty"` // часовой пояс timestamp и date, например Europe/Moscow. По умолчанию UTC
+	Location *time.Location `yaml:"-" validate:"-"`     // загруженный timezone
+	Enum     []string       `yaml:"enum,omitempty"`     // допустимые значения enum
 }
 
 // AggregatedValidation - все валидации, которые будут применены к полю.
@@ -137,11 +314,17 @@ type AggregatedValidation struct {
 	MinLength     *int // минимальная длина
 	NotEmpty      bool // не пустое значение
 	ExpectedValue any  // ожидаемое значение
+
+	MinTime    *time.Time // минимальное значение timestamp и date
+	MaxTime    *time.Time // максимальное значение timestamp и date
+	MinDecimal *big.Rat   // минимальное значение decimal
+	MaxDecimal *big.Rat   // максимальное значение decimal
 }
 
 // Request - откуда будет получен запрос на операцию.
 type Request struct {
-	From string `yaml:"from"`
+	From  string `yaml:"from"`
+	Match *Match `yaml:"match,omitempty" validate:"omitempty"` // какие сообщения соединения обрабатывает операция. Если не задано - все
 }
 
 // LoadOperation загружает конфигурацию операции.
@@ -171,6 +354,16 @@ func LoadOperation(path string) (OperationConfig, error) {
 				return OperationConfig{}, fmt.Errorf("error aggregating validation: %w", err)
 			}
 
+			field, err = compileTransforms(operation.Name, field)
+			if err != nil {
+				return OperationConfig{}, fmt.Errorf("error compiling transforms: %w", err)
+			}
+
+			field, err = loadLocation(operation.Name, field)
+			if err != nil {
+				return OperationConfig{}, fmt.Errorf("error loading timezone: %w", err)
+			}
+
 			operation.Fields[j] = field
 		}
 
@@ -193,6 +386,23 @@ func LoadOperation(path string) (OperationConfig, error) {
 		// создаем мапу полей для быстрого доступа
 		operation.mapFieldsByOperation()
 
+		err = operation.validateIdempotency()
+		if err != nil {
+			return OperationConfig{}, fmt.Errorf("operation %q: %w", operation.Name, err)
+		}
+
+		err = operation.validatePartitionKey()
+		if err != nil {
+			return OperationConfig{}, fmt.Errorf("operation %q: %w", operation.Name, err)
+		}
+
+		err = operation.validateMapping()
+		if err != nil {
+			return OperationConfig{}, fmt.Errorf("operation %q: %w", operation.Name, err)
+		}
+
+		operation.mapWhereColumns(operation.Where)
+
 		// валидируем условие where
 		err = operation.validateWhereCondition()
 		if err != nil {
@@ -208,6 +418,11 @@ func LoadOperation(path string) (OperationConfig, error) {
 
 		operation.mapFieldsUpdate()
 
+		err = operation.validateConflict()
+		if err != nil {
+			return OperationConfig{}, fmt.Errorf("operation %q: %w", operation.Name, err)
+		}
+
 		if operation.Type == OperationTypeUpdate && len(operation.Where) > 0 {
 			if len(operation.UpdateFieldsMap) == 0 {
 				return OperationConfig{}, fmt.Errorf("operation %q: no update fields", operation.Name)
@@ -239,6 +454,14 @@ func LoadOperation(path string) (OperationConfig, error) {
 		return OperationConfig{}, fmt.Errorf("error validating operation config: %w", err)
 	}
 
+	if err := operationConfig.validateRoutes(); err != nil {
+		return OperationConfig{}, fmt.Errorf("error validating routes: %w", err)
+	}
+
+	if err := operationConfig.validateCodecs(); err != nil {
+		return OperationConfig{}, fmt.Errorf("error validating codecs: %w", err)
+	}
+
 	for i, op := range operationConfig.Operations {
 		if err := op.calculateHash(); err != nil {
 			return OperationConfig{}, fmt.Errorf("operation %q: error calculating hash: %w", op.Name, err)
@@ -279,6 +502,16 @@ func aggregateValidation(opName string, field Field) (Field, error) {
 	for i, validation := range field.ValidationsList {
 		value := validation.Value
 
+		// у timestamp, date и decimal границы не целые числа
+		ok, err := aggregateBound(&field, validation.Type, value)
+		if err != nil {
+			return field, fmt.Errorf("operation %s: field %s: %w", opName, field.Name, err)
+		}
+
+		if ok {
+			continue
+		}
+
 		switch validation.Type {
 		case ValidationTypeMax:
 			v, ok := value.(int)
@@ -331,6 +564,7 @@ func (oc *Operation) calculateHash() error {
 		Fields        []Field      `yaml:"fields" validate:"required,dive"`
 		Request       Request      `yaml:"request" validate:"required"`
 		Where         []Where      `yaml:"where" validate:"omitempty"` // условие, по которому будет выполнена операция. Только для операций update и delete
+		Conflict      *Conflict    `yaml:"conflict,omitempty"`         // не меняет хеш операций без conflict
 	}
 
 	copy := operation{
@@ -341,6 +575,7 @@ func (oc *Operation) calculateHash() error {
 		Fields:        oc.Fields,
 		Request:       oc.Request,
 		Where:         oc.Where,
+		Conflict:      oc.Conflict,
 	}
 
 	data, err := yaml.Marshal(copy)
@@ -0,0 +1,59 @@
+package operation
+
+import "fmt"
+
+// Match - правило, по которому сообщения соединения направляются в операцию.
+// Если заданы и поле, и ключ маршрутизации - должны совпасть оба.
+type Match struct {
+	Field      string `yaml:"field,omitempty" validate:"required_with=Value"`          // путь к полю с признаком операции от корня сообщения (root операции не учитывается)
+	Value      string `yaml:"value,omitempty" validate:"required_with=Field"`          // значение поля (сравнивается строкой)
+	RoutingKey string `yaml:"routing_key,omitempty" validate:"required_without=Field"` // шаблон ключа маршрутизации: слова через точку, * - одно слово, # - любое количество слов
+}
+
+// UnmatchedAction - что делать с сообщением, которое не подошло ни одной операции соединения.
+type UnmatchedAction string
+
+const (
+	// UnmatchedActionDrop - сообщение подтверждается и отбрасывается.
+	UnmatchedActionDrop UnmatchedAction = "drop"
+	// UnmatchedActionDeadLetter - сообщение отправляется в dead_letter соединения и подтверждается.
+	UnmatchedActionDeadLetter UnmatchedAction = "dead_letter"
+	// UnmatchedActionFail - сообщение завершается ошибкой: источник обработает его как ошибку обработки
+	// (например, RabbitMQ отклонит сообщение или вернет в очередь при requeue).
+	UnmatchedActionFail UnmatchedAction = "fail"
+)
+
+// Unmatched - что делать с сообщениями, которые не подошли ни одной операции соединения.
+type Unmatched struct {
+	Action     UnmatchedAction `yaml:"action" validate:"required,oneof=drop dead_letter fail"`
+	DeadLetter *DeadLetter     `yaml:"dead_letter" validate:"required_if=Action dead_letter,omitempty"`
+}
+
+// validateRoutes проверяет, что каждое сообщение соединения однозначно направляется в операцию:
+// операция без правила match принимает все сообщения соединения, поэтому такая операция может быть только одна.
+// Также проверяется путь к полю match.
+func (oc *OperationConfig) validateRoutes() error {
+	catchAll := make(map[string]string) // соединение -> операция без match
+
+	for _, op := range oc.Operations {
+		if _, ok := oc.ConnectionsMap[op.Request.From]; !ok {
+			return fmt.Errorf("operation %q: connection %q not found", op.Name, op.Request.From)
+		}
+
+		if op.Request.Match != nil {
+			if _, err := ParsePath(op.Request.Match.Field); err != nil {
+				return fmt.Errorf("operation %q: match field: %w", op.Name, err)
+			}
+
+			continue
+		}
+
+		if other, ok := catchAll[op.Request.From]; ok {
+			return fmt.Errorf("operations %q and %q: both read connection %q without match", other, op.Name, op.Request.From)
+		}
+
+		catchAll[op.Request.From] = op.Name
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		match   Match
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: field",
+			match:   Match{Field: "operation", Value: "create"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: routing key",
+			match:   Match{RoutingKey: "notes.*.create"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: field without value",
+			match:   Match{Field: "operation"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: value without field",
+			match:   Match{Value: "create", RoutingKey: "notes.create"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: empty",
+			match:   Match{},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validator.New().Struct(tt.match))
+		})
+	}
+}
+
+func TestOperationConfig_ValidateRoutes(t *testing.T) {
+	t.Parallel()
+
+	connections := map[string]Connection{"notes": {Name: "notes"}}
+	create := &Match{Field: "operation", Value: "create"}
+
+	tests := []struct {
+		name       string
+		operations []Operation
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: one operation without match",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "notes"}},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: operations with match and fallback",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "notes", Match: create}},
+				{Name: "update", Request: Request{From: "notes", Match: &Match{Field: "operation", Value: "update"}}},
+				{Name: "other", Request: Request{From: "notes"}},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: two operations without match",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "notes"}},
+				{Name: "update", Request: Request{From: "notes"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "positive case: match field path",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "notes", Match: &Match{Field: "meta.type", Value: "create"}}},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: invalid match field path",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "notes", Match: &Match{Field: "meta..type", Value: "create"}}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: unknown connection",
+			operations: []Operation{
+				{Name: "create", Request: Request{From: "users", Match: create}},
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			oc := OperationConfig{Operations: tt.operations, ConnectionsMap: connections}
+
+			tt.wantErr(t, oc.validateRoutes())
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package operation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetaKey - метаданные сообщения, из которых поле может взять значение.
+type MetaKey string
+
+const (
+	// MetaMessageID - айди сообщения у источника.
+	MetaMessageID MetaKey = "message_id"
+	// MetaCorrelationID - сквозной айди запроса отправителя.
+	MetaCorrelationID MetaKey = "correlation_id"
+	// MetaRoutingKey - ключ маршрутизации (routing key, субъект NATS, ключ записи Kafka).
+	MetaRoutingKey MetaKey = "routing_key"
+	// MetaTimestamp - время отправки сообщения (RFC 3339).
+	MetaTimestamp MetaKey = "timestamp"
+)
+
+const (
+	sourceHeaderPrefix = "header."
+	sourceMetaPrefix   = "meta."
+)
+
+// Source - откуда поле берет значение вместо тела сообщения: заголовок (header.<имя>)
+// или метаданные сообщения (meta.message_id, meta.correlation_id, meta.routing_key, meta.timestamp).
+type Source struct {
+	Header string  // имя заголовка
+	Meta   MetaKey // ключ метаданных
+}
+
+// ParseSource разбирает источник значения поля. Пустая строка - значение берется из тела сообщения.
+func ParseSource(source string) (Source, error) {
+	if source == "" {
+		return Source{}, nil
+	}
+
+	if header, ok := strings.CutPrefix(source, sourceHeaderPrefix); ok {
+		if header == "" {
+			return Source{}, fmt.Errorf("source %q: header name is required", source)
+		}
+
+		return Source{Header: header}, nil
+	}
+
+	if key, ok := strings.CutPrefix(source, sourceMetaPrefix); ok {
+		switch MetaKey(key) {
+		case MetaMessageID, MetaCorrelationID, MetaRoutingKey, MetaTimestamp:
+			return Source{Meta: MetaKey(key)}, nil
+		default:
+			return Source{}, fmt.Errorf("source %q: unknown metadata key %q", source, key)
+		}
+	}
+
+	return Source{}, fmt.Errorf("source %q: must start with %q or %q", source, sourceHeaderPrefix, sourceMetaPrefix)
+}
+
+// validateSource проверяет источник значения поля.
+func validateSource(f Field) error {
+	if _, err := ParseSource(f.Source); err != nil {
+		return fmt.Errorf("field %s: %w", f.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		want    Source
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: empty",
+			source:  "",
+			want:    Source{},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: header",
+			source:  "header.user_id",
+			want:    Source{Header: "user_id"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: meta",
+			source:  "meta.correlation_id",
+			want:    Source{Meta: MetaCorrelationID},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: header without name",
+			source:  "header.",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unknown meta key",
+			source:  "meta.user_id",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unknown prefix",
+			source:  "body.user_id",
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseSource(tt.source)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateFieldConfig_Source(t *testing.T) {
+	t.Parallel()
+
+	err := validateFieldConfig(Field{Name: "user_id", Type: FieldTypeInt64, Source: "header.user_id"})
+	require.NoError(t, err)
+
+	err = validateFieldConfig(Field{Name: "user_id", Type: FieldTypeInt64, Source: "user_id"})
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package operation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Transform - преобразование значения поля перед валидацией.
+type Transform struct {
+	// Тип преобразования. Например, trim, lower, truncate.
+	Type TransformType `yaml:"type" validate:"required,oneof=trim lower upper truncate sha256 regex_replace default_if_empty to_int64 to_uuid unix_to_timestamp"`
+	// Длина для truncate, значение для default_if_empty.
+	Value any `yaml:"value,omitempty"`
+	// Регулярное выражение и замена для regex_replace. В замене доступны группы: $1, ${name}.
+	Pattern     string `yaml:"pattern,omitempty"`
+	Replacement string `yaml:"replacement,omitempty"`
+
+	Regexp *regexp.Regexp `yaml:"-" validate:"-"` // скомпилированный pattern
+}
+
+// TransformType - тип преобразования.
+type TransformType string
+
+const (
+	// TransformTrim - убрать пробелы в начале и в конце строки.
+	TransformTrim TransformType = "trim"
+	// TransformLower - привести строку к нижнему регистру.
+	TransformLower TransformType = "lower"
+	// TransformUpper - привести строку к верхнему регистру.
+	TransformUpper TransformType = "upper"
+	// TransformTruncate - обрезать строку до value символов.
+	TransformTruncate TransformType = "truncate"
+	// TransformSHA256 - заменить строку ее sha256 (hex).
+	TransformSHA256 TransformType = "sha256"
+	// TransformRegexReplace - заменить совпадения pattern на replacement.
+	TransformRegexReplace TransformType = "regex_replace"
+	// TransformDefaultIfEmpty - подставить value, если строка пустая или значение null.
+	TransformDefaultIfEmpty TransformType = "default_if_empty"
+	// TransformToInt64 - привести строку к int64.
+	TransformToInt64 TransformType = "to_int64"
+	// TransformToUUID - привести строку к UUID.
+	TransformToUUID TransformType = "to_uuid"
+	// TransformUnixToTimestamp - привести время Unix в секундах к строке RFC 3339 в UTC.
+	TransformUnixToTimestamp TransformType = "unix_to_timestamp"
+)
+
+// types возвращает тип значения, к которому применяется преобразование, и тип результата.
+func (t TransformType) types() (FieldType, FieldType, bool) {
+	switch t {
+	case TransformTrim, TransformLower, TransformUpper, TransformTruncate, TransformSHA256,
+		TransformRegexReplace, TransformDefaultIfEmpty:
+		return FieldTypeString, FieldTypeString, true
+	case TransformToInt64:
+		return FieldTypeString, FieldTypeInt64, true
+	case TransformToUUID:
+		return FieldTypeString, FieldTypeUUID, true
+	case TransformUnixToTimestamp:
+		return FieldTypeInt64, FieldTypeString, true
+	default:
+		return "", "", false
+	}
+}
+
+// validateTransforms проверяет цепочку преобразований поля: каждое преобразование применяется к результату
+// предыдущего, а результат последнего должен совпадать с типом поля или быть строкой, из которой разбирается
+// значение поля.
+func validateTransforms(f Field) error {
+	var current FieldType
+
+	for i, transform := range f.Transforms {
+		in, out, ok := transform.Type.types()
+		if !ok {
+			return fmt.Errorf("field %s: unknown transform %q", f.Name, transform.Type)
+		}
+
+		if current != "" && current != in {
+			return fmt.Errorf("field %s: transform %q is not applicable to %s", f.Name, transform.Type, current)
+		}
+
+		if err := validateTransformParams(transform); err != nil {
+			return fmt.Errorf("field %s: transform %d: %w", f.Name, i, err)
+		}
+
+		current = out
+	}
+
+	// timestamp, date, decimal и enum разбираются из строки после преобразований
+	if current != "" && current != f.Type && (current != FieldTypeString || !f.Type.fromString()) {
+		return fmt.Errorf("field %s: transforms produce %s, but field type is %q", f.Name, current, f.Type)
+	}
+
+	return nil
+}
+
+// validateTransformParams проверяет параметры преобразования.
+func validateTransformParams(t Transform) error {
+	switch t.Type {
+	case TransformTruncate:
+		n, ok := t.Value.(int)
+		if !ok || n <= 0 {
+			return fmt.Errorf("truncate: value must be a positive int, got %v", t.Value)
+		}
+	case TransformRegexReplace:
+		if t.Pattern == "" {
+			return fmt.Errorf("regex_replace: pattern is required")
+		}
+
+		if _, err := regexp.Compile(t.Pattern); err != nil {
+			return fmt.Errorf("regex_replace: %w", err)
+		}
+	case TransformDefaultIfEmpty:
+		if _, ok := t.Value.(string); !ok {
+			return fmt.Errorf("default_if_empty: value must be a string, got %v", t.Value)
+		}
+	}
+
+	return nil
+}
+
+// compileTransforms компилирует регулярные выражения преобразований поля.
+func compileTransforms(opName string, field Field) (Field, error) {
+	for i, transform := range field.Transforms {
+		if transform.Type != TransformRegexReplace {
+			continue
+		}
+
+		re, err := regexp.Compile(transform.Pattern)
+		if err != nil {
+			return field, fmt.Errorf("operation %s: field %s: %w", opName, field.Name, err)
+		}
+
+		field.Transforms[i].Regexp = re
+	}
+
+	return field, nil
+}
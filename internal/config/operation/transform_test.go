@@ -0,0 +1,120 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestValidateTransforms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: no transforms",
+			field:   Field{Name: "text", Type: FieldTypeString},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: string transforms",
+			field: Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{
+				{Type: TransformTrim},
+				{Type: TransformLower},
+				{Type: TransformRegexReplace, Pattern: `\s+`, Replacement: " "},
+				{Type: TransformTruncate, Value: 100},
+				{Type: TransformDefaultIfEmpty, Value: "empty"},
+			}},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: string to int64",
+			field: Field{Name: "user_id", Type: FieldTypeInt64, Transforms: []Transform{
+				{Type: TransformTrim},
+				{Type: TransformToInt64},
+			}},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: string to uuid",
+			field:   Field{Name: "id", Type: FieldTypeUUID, Transforms: []Transform{{Type: TransformToUUID}}},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: unix to timestamp",
+			field: Field{Name: "created_at", Type: FieldTypeString, Transforms: []Transform{
+				{Type: TransformUnixToTimestamp},
+			}},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: string transform for int64",
+			field:   Field{Name: "user_id", Type: FieldTypeInt64, Transforms: []Transform{{Type: TransformTrim}}},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: string transform after coercion",
+			field: Field{Name: "user_id", Type: FieldTypeInt64, Transforms: []Transform{
+				{Type: TransformToInt64},
+				{Type: TransformTrim},
+			}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: coercion to another type",
+			field:   Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{{Type: TransformToUUID}}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unknown transform",
+			field:   Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{{Type: "reverse"}}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: truncate without length",
+			field:   Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{{Type: TransformTruncate}}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: invalid pattern",
+			field:   Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{{Type: TransformRegexReplace, Pattern: "("}}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: default_if_empty without string value",
+			field:   Field{Name: "text", Type: FieldTypeString, Transforms: []Transform{{Type: TransformDefaultIfEmpty, Value: 1}}},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validateTransforms(tt.field))
+		})
+	}
+}
+
+func TestCompileTransforms(t *testing.T) {
+	t.Parallel()
+
+	field, err := compileTransforms("create_notes", Field{Name: "text", Transforms: []Transform{
+		{Type: TransformTrim},
+		{Type: TransformRegexReplace, Pattern: `\d+`},
+	}})
+	require.NoError(t, err)
+	assert.Nil(t, field.Transforms[0].Regexp)
+	require.NotNil(t, field.Transforms[1].Regexp)
+	assert.Equal(t, `\d+`, field.Transforms[1].Regexp.String())
+
+	_, err = compileTransforms("create_notes", Field{Name: "text", Transforms: []Transform{
+		{Type: TransformRegexReplace, Pattern: "("},
+	}})
+	require.Error(t, err)
+}
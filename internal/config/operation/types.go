@@ -0,0 +1,317 @@
+package operation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы значений timestamp и date. Любое другое значение format - layout Go, например 02.01.2006 15:04.
+const (
+	// FormatRFC3339 - RFC 3339, формат по умолчанию для timestamp.
+	FormatRFC3339 = "rfc3339"
+	// FormatUnix - время Unix в секундах.
+	FormatUnix = "unix"
+	// FormatUnixMs - время Unix в миллисекундах.
+	FormatUnixMs = "unix_ms"
+)
+
+// DateLayout - формат date по умолчанию и формат, в котором date записывается в хранилище.
+const DateLayout = "2006-01-02"
+
+// fromString возвращает true, если значение типа разбирается из строки: к такому полю применимы
+// строковые преобразования.
+func (t FieldType) fromString() bool {
+	switch t {
+	case FieldTypeString, FieldTypeTimestamp, FieldTypeDate, FieldTypeDecimal, FieldTypeEnum:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseTime разбирает значение поля timestamp или date по format поля. Время без зоны считается временем
+// в timezone поля, результат переводится в timezone поля.
+func (f Field) ParseTime(val any) (time.Time, error) {
+	loc := f.location()
+
+	if t, ok := val.(time.Time); ok {
+		return t.In(loc), nil
+	}
+
+	if f.Format == FormatUnix || f.Format == FormatUnixMs {
+		n, err := unixNumber(val)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if f.Format == FormatUnixMs {
+			return time.UnixMilli(n).In(loc), nil
+		}
+
+		return time.Unix(n, 0).In(loc), nil
+	}
+
+	str, ok := val.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("value %v is not a string", val)
+	}
+
+	t, err := time.ParseInLocation(f.layout(), strings.TrimSpace(str), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("value %q doesn't match format %q: %w", str, f.layout(), err)
+	}
+
+	return t.In(loc), nil
+}
+
+// layout возвращает layout Go для разбора строки по format поля.
+func (f Field) layout() string {
+	switch f.Format {
+	case "":
+		if f.Type == FieldTypeDate {
+			return DateLayout
+		}
+
+		return time.RFC3339Nano
+	case FormatRFC3339:
+		return time.RFC3339Nano
+	default:
+		return f.Format
+	}
+}
+
+// location возвращает часовой пояс поля: загруженный при чтении конфигурации или UTC.
+func (f Field) location() *time.Location {
+	if f.Location != nil {
+		return f.Location
+	}
+
+	if f.Timezone != "" {
+		if loc, err := time.LoadLocation(f.Timezone); err == nil {
+			return loc
+		}
+	}
+
+	return time.UTC
+}
+
+// unixNumber приводит время Unix к int64: из JSON приходит json.Number, из остальных форматов - float64,
+// из заголовков - строка.
+func unixNumber(val any) (int64, error) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("unix time %v is not a number", v)
+		}
+
+		return unixNumber(f)
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("unix time %v is not an integer", v)
+		}
+
+		return int64(v), nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unix time %q is not an integer", v)
+		}
+
+		return n, nil
+	default:
+		return 0, fmt.Errorf("unix time %v is not a number", val)
+	}
+}
+
+// ParseDecimal разбирает десятичное число произвольной точности: 12, -0.5, 1.25e3.
+// Дроби (1/3), шестнадцатеричная запись, Inf и NaN не допускаются.
+func ParseDecimal(s string) (*big.Rat, error) {
+	if !isDecimal(s) {
+		return nil, fmt.Errorf("value %q is not a decimal", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("value %q is not a decimal", s)
+	}
+
+	return r, nil
+}
+
+// isDecimal проверяет запись десятичного числа: знак, цифры с необязательной точкой и экспонента.
+func isDecimal(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(s), "e")
+	if hasExp {
+		exp = strings.TrimPrefix(strings.TrimPrefix(exp, "-"), "+")
+		if !isDigits(exp) {
+			return false
+		}
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" {
+		return false
+	}
+
+	return (intPart == "" || isDigits(intPart)) && (fracPart == "" || isDigits(fracPart))
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// validateTypeParams проверяет параметры типа поля: format и timezone у timestamp и date, список enum.
+func validateTypeParams(f Field) error {
+	isTime := f.Type == FieldTypeTimestamp || f.Type == FieldTypeDate
+
+	if !isTime && (f.Format != "" || f.Timezone != "") {
+		return fmt.Errorf("field %s: format and timezone are allowed only for timestamp and date", f.Name)
+	}
+
+	if f.Format != "" && f.Format != FormatRFC3339 && f.Format != FormatUnix && f.Format != FormatUnixMs {
+		// layout без элементов даты и времени форматируется сам в себя
+		if time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(f.Format) == f.Format {
+			return fmt.Errorf("field %s: format %q is not a time layout", f.Name, f.Format)
+		}
+	}
+
+	if f.Timezone != "" {
+		if _, err := time.LoadLocation(f.Timezone); err != nil {
+			return fmt.Errorf("field %s: timezone: %w", f.Name, err)
+		}
+	}
+
+	if f.Type != FieldTypeEnum {
+		if len(f.Enum) > 0 {
+			return fmt.Errorf("field %s: enum values are allowed only for enum type", f.Name)
+		}
+
+		return nil
+	}
+
+	if len(f.Enum) == 0 {
+		return fmt.Errorf("field %s: enum values are required", f.Name)
+	}
+
+	seen := make(map[string]struct{}, len(f.Enum))
+
+	for _, value := range f.Enum {
+		if value == "" {
+			return fmt.Errorf("field %s: enum value is empty", f.Name)
+		}
+
+		if _, ok := seen[value]; ok {
+			return fmt.Errorf("field %s: duplicate enum value %q", f.Name, value)
+		}
+
+		seen[value] = struct{}{}
+	}
+
+	return nil
+}
+
+// loadLocation загружает часовой пояс поля, чтобы не читать его при обработке каждого сообщения.
+func loadLocation(opName string, field Field) (Field, error) {
+	if field.Timezone == "" {
+		return field, nil
+	}
+
+	loc, err := time.LoadLocation(field.Timezone)
+	if err != nil {
+		return field, fmt.Errorf("operation %s: field %s: %w", opName, field.Name, err)
+	}
+
+	field.Location = loc
+
+	return field, nil
+}
+
+// aggregateBound записывает границу min или max поля timestamp, date или decimal.
+// false - у типа поля границы задаются целым числом.
+func aggregateBound(field *Field, validationType ValidationType, value any) (bool, error) {
+	if validationType != ValidationTypeMin && validationType != ValidationTypeMax {
+		return false, nil
+	}
+
+	isMin := validationType == ValidationTypeMin
+
+	switch field.Type {
+	case FieldTypeTimestamp, FieldTypeDate:
+		bound, err := parseTimeBound(field.Type, value)
+		if err != nil {
+			return true, fmt.Errorf("%s: %w", validationType, err)
+		}
+
+		if isMin {
+			field.Validation.MinTime = &bound
+		} else {
+			field.Validation.MaxTime = &bound
+		}
+	case FieldTypeDecimal:
+		bound, err := ParseDecimal(boundString(value))
+		if err != nil {
+			return true, fmt.Errorf("%s: %w", validationType, err)
+		}
+
+		if isMin {
+			field.Validation.MinDecimal = bound
+		} else {
+			field.Validation.MaxDecimal = bound
+		}
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// parseTimeBound разбирает границу timestamp (RFC 3339) или date (2006-01-02).
+func parseTimeBound(fieldType FieldType, value any) (time.Time, error) {
+	if t, ok := value.(time.Time); ok {
+		if fieldType == FieldTypeDate {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+
+		return t, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("value %v is not a string", value)
+	}
+
+	layout := time.RFC3339Nano
+	if fieldType == FieldTypeDate {
+		layout = DateLayout
+	}
+
+	return time.Parse(layout, str)
+}
+
+// boundString возвращает запись границы decimal: в yaml она может быть числом или строкой.
+func boundString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,280 @@
+package operation
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestField_ParseTime(t *testing.T) {
+	t.Parallel()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		field   Field
+		val     any
+		want    time.Time
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: rfc3339 by default",
+			field:   Field{Type: FieldTypeTimestamp},
+			val:     "2024-05-01T10:00:00+03:00",
+			want:    time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: rfc3339 with fraction",
+			field:   Field{Type: FieldTypeTimestamp, Format: FormatRFC3339},
+			val:     "2024-05-01T07:00:00.123Z",
+			want:    time.Date(2024, 5, 1, 7, 0, 0, 123000000, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: unix seconds",
+			field:   Field{Type: FieldTypeTimestamp, Format: FormatUnix},
+			val:     float64(1714546800),
+			want:    time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: unix seconds json number",
+			field:   Field{Type: FieldTypeTimestamp, Format: FormatUnix},
+			val:     json.Number("1714546800"),
+			want:    time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: unix millis string",
+			field:   Field{Type: FieldTypeTimestamp, Format: FormatUnixMs},
+			val:     "1714546800500",
+			want:    time.Date(2024, 5, 1, 7, 0, 0, 500000000, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: layout in timezone",
+			field:   Field{Type: FieldTypeTimestamp, Format: "02.01.2006 15:04", Timezone: "Europe/Moscow"},
+			val:     "01.05.2024 10:00",
+			want:    time.Date(2024, 5, 1, 10, 0, 0, 0, moscow),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: date by default",
+			field:   Field{Type: FieldTypeDate},
+			val:     "2024-05-01",
+			want:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: time value",
+			field:   Field{Type: FieldTypeTimestamp, Location: moscow},
+			val:     time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			want:    time.Date(2024, 5, 1, 10, 0, 0, 0, moscow),
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: wrong layout",
+			field:   Field{Type: FieldTypeDate},
+			val:     "01.05.2024",
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unix with fraction",
+			field:   Field{Type: FieldTypeTimestamp, Format: FormatUnix},
+			val:     1714546800.5,
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: not a string",
+			field:   Field{Type: FieldTypeTimestamp},
+			val:     true,
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.field.ParseTime(tt.val)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.True(t, tt.want.Equal(got), got)
+				assert.Equal(t, tt.want.Location().String(), got.Location().String())
+			}
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"0", "-12", "+3.5", "0.10", ".5", "5.", "1.25e3", "123456789012345678901234567890.123456789"} {
+		_, err := ParseDecimal(s)
+		require.NoError(t, err, s)
+	}
+
+	for _, s := range []string{"", "-", ".", "1/3", "0x10", "1e", "Inf", "NaN", "1.2.3", "12 "} {
+		_, err := ParseDecimal(s)
+		require.Error(t, err, s)
+	}
+
+	// точность не теряется
+	got, err := ParseDecimal("0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.Cmp(big.NewRat(1, 10)))
+}
+
+//nolint:funlen // много тест-кейсов
+func TestValidateTypeParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		field   Field
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: timestamp with layout and timezone",
+			field:   Field{Name: "due_at", Type: FieldTypeTimestamp, Format: "2006-01-02 15:04:05", Timezone: "Europe/Moscow"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: date unix",
+			field:   Field{Name: "day", Type: FieldTypeDate, Format: FormatUnix},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: enum",
+			field:   Field{Name: "status", Type: FieldTypeEnum, Enum: []string{"new", "done"}},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: format for string",
+			field:   Field{Name: "text", Type: FieldTypeString, Format: FormatUnix},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: layout without time elements",
+			field:   Field{Name: "due_at", Type: FieldTypeTimestamp, Format: "iso"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unknown timezone",
+			field:   Field{Name: "due_at", Type: FieldTypeTimestamp, Timezone: "Mars/Olympus"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: enum without values",
+			field:   Field{Name: "status", Type: FieldTypeEnum},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: duplicate enum value",
+			field:   Field{Name: "status", Type: FieldTypeEnum, Enum: []string{"new", "new"}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: enum values for string",
+			field:   Field{Name: "status", Type: FieldTypeString, Enum: []string{"new"}},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.wantErr(t, validateTypeParams(tt.field))
+		})
+	}
+}
+
+func TestAggregateBounds(t *testing.T) {
+	t.Parallel()
+
+	field, err := aggregateValidation("create_payments", Field{
+		Name: "amount",
+		Type: FieldTypeDecimal,
+		ValidationsList: []Validation{
+			{Type: ValidationTypeMin, Value: 0.01},
+			{Type: ValidationTypeMax, Value: "100000.00"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, field.Validation.Min)
+	assert.Equal(t, "1/100", field.Validation.MinDecimal.String())
+	assert.Equal(t, "100000/1", field.Validation.MaxDecimal.String())
+	require.NoError(t, validateFieldConfig(field))
+
+	field, err = aggregateValidation("create_notes", Field{
+		Name: "due_date",
+		Type: FieldTypeDate,
+		ValidationsList: []Validation{
+			{Type: ValidationTypeMin, Value: "2024-01-01"},
+			{Type: ValidationTypeMax, Value: "2023-01-01"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), *field.Validation.MinTime)
+	// max < min
+	require.Error(t, validateFieldConfig(field))
+
+	_, err = aggregateValidation("create_notes", Field{
+		Name:            "due_at",
+		Type:            FieldTypeTimestamp,
+		ValidationsList: []Validation{{Type: ValidationTypeMin, Value: "yesterday"}},
+	})
+	require.Error(t, err)
+
+	// границы int64 задаются как раньше
+	field, err = aggregateValidation("create_notes", Field{
+		Name:            "user_id",
+		Type:            FieldTypeInt64,
+		ValidationsList: []Validation{{Type: ValidationTypeMin, Value: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *field.Validation.Min)
+	assert.Nil(t, field.Validation.MinDecimal)
+}
+
+func TestFieldTypeRules(t *testing.T) {
+	t.Parallel()
+
+	// enum: допустимо только value из списка
+	require.NoError(t, validateFieldConfig(Field{
+		Name: "status", Type: FieldTypeEnum, Enum: []string{"new", "done"},
+		Validation: AggregatedValidation{ExpectedValue: "new"},
+	}))
+	require.Error(t, validateFieldConfig(Field{
+		Name: "status", Type: FieldTypeEnum, Enum: []string{"new", "done"},
+		Validation: AggregatedValidation{ExpectedValue: "archived"},
+	}))
+
+	maxLength := 10
+	require.Error(t, validateFieldConfig(Field{
+		Name: "amount", Type: FieldTypeDecimal, Validation: AggregatedValidation{MaxLength: &maxLength},
+	}))
+
+	// строковые преобразования перед разбором значения
+	require.NoError(t, validateFieldConfig(Field{
+		Name: "status", Type: FieldTypeEnum, Enum: []string{"new"},
+		Transforms: []Transform{{Type: TransformTrim}, {Type: TransformLower}},
+	}))
+
+	// значения по умолчанию и генераторы
+	require.NoError(t, validateFieldConfig(Field{Name: "amount", Type: FieldTypeDecimal, Default: "0.00"}))
+	require.NoError(t, validateFieldConfig(Field{Name: "status", Type: FieldTypeEnum, Enum: []string{"new"}, Default: "new"}))
+	require.NoError(t, validateFieldConfig(Field{Name: "created_at", Type: FieldTypeTimestamp, Generator: GeneratorNow}))
+	require.Error(t, validateFieldConfig(Field{Name: "status", Type: FieldTypeEnum, Enum: []string{"new"}, Default: "old"}))
+	require.Error(t, validateFieldConfig(Field{Name: "day", Type: FieldTypeDate, Default: "01.05.2024"}))
+}
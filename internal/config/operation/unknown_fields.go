@@ -0,0 +1,11 @@
+package operation
+
+// UnknownFields - что делать с ключами сообщения, которые не объявлены в fields операции.
+type UnknownFields string
+
+const (
+	// UnknownFieldsIgnore - ключи отбрасываются, в запрос попадают только объявленные поля.
+	UnknownFieldsIgnore UnknownFields = "ignore"
+	// UnknownFieldsReject - сообщение с такими ключами не проходит валидацию.
+	UnknownFieldsReject UnknownFields = "reject"
+)
@@ -2,6 +2,7 @@ package operation
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -57,6 +58,9 @@ const (
 //   - uuid: value
 //   - float64: min, max, value
 //   - bool: value
+//   - timestamp, date: min, max (RFC 3339 и 2006-01-02)
+//   - decimal: min, max (число или строка)
+//   - enum: value
 //
 //nolint:gochecknoglobals // глобальная мапа для избежания switch-case, приватная и используется только в этом модуле.
 var allowedByType = map[FieldType]map[Rule]bool{
@@ -100,6 +104,38 @@ var allowedByType = map[FieldType]map[Rule]bool{
 		RuleNotEmpty:  false,
 		RuleValue:     true,
 	},
+	FieldTypeTimestamp: {
+		RuleMin:       true,
+		RuleMax:       true,
+		RuleMinLength: false,
+		RuleMaxLength: false,
+		RuleNotEmpty:  false,
+		RuleValue:     false,
+	},
+	FieldTypeDate: {
+		RuleMin:       true,
+		RuleMax:       true,
+		RuleMinLength: false,
+		RuleMaxLength: false,
+		RuleNotEmpty:  false,
+		RuleValue:     false,
+	},
+	FieldTypeDecimal: {
+		RuleMin:       true,
+		RuleMax:       true,
+		RuleMinLength: false,
+		RuleMaxLength: false,
+		RuleNotEmpty:  false,
+		RuleValue:     false,
+	},
+	FieldTypeEnum: {
+		RuleMin:       false,
+		RuleMax:       false,
+		RuleMinLength: false,
+		RuleMaxLength: false,
+		RuleNotEmpty:  false,
+		RuleValue:     true,
+	},
 }
 
 func validateFieldConfig(f Field) error {
@@ -115,6 +151,26 @@ func validateFieldConfig(f Field) error {
 		return err
 	}
 
+	if err := validateSource(f); err != nil {
+		return err
+	}
+
+	if err := validatePath(f); err != nil {
+		return err
+	}
+
+	if err := validateGenerated(f); err != nil {
+		return err
+	}
+
+	if err := validateTransforms(f); err != nil {
+		return err
+	}
+
+	if err := validateTypeParams(f); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -124,6 +180,8 @@ func validateFieldConfig(f Field) error {
 //   - у float64 не может быть max, min, not_empty.
 //   - у uuid не может быть max, min.
 //   - у bool не может быть max, min, max_length, min_length, not_empty.
+//   - у timestamp, date, decimal может быть только max, min.
+//   - у enum может быть только value.
 func validateRuleCompatibility(f Field) error {
 	allowed := allowedByType[f.Type]
 	check := func(rule Rule, enabled bool) error {
@@ -134,11 +192,11 @@ func validateRuleCompatibility(f Field) error {
 		return nil
 	}
 
-	if err := check(RuleMin, f.Validation.Min != nil); err != nil {
+	if err := check(RuleMin, f.Validation.Min != nil || f.Validation.MinTime != nil || f.Validation.MinDecimal != nil); err != nil {
 		return err
 	}
 
-	if err := check(RuleMax, f.Validation.Max != nil); err != nil {
+	if err := check(RuleMax, f.Validation.Max != nil || f.Validation.MaxTime != nil || f.Validation.MaxDecimal != nil); err != nil {
 		return err
 	}
 
@@ -175,6 +233,14 @@ func validateBoundaryConsistency(f Field) error {
 		}
 	}
 
+	if f.Validation.MinTime != nil && f.Validation.MaxTime != nil && f.Validation.MaxTime.Before(*f.Validation.MinTime) {
+		return fmt.Errorf("field %s: max must be > min", f.Name)
+	}
+
+	if f.Validation.MinDecimal != nil && f.Validation.MaxDecimal != nil && f.Validation.MaxDecimal.Cmp(f.Validation.MinDecimal) < 0 {
+		return fmt.Errorf("field %s: max must be > min", f.Name)
+	}
+
 	return nil
 }
 
@@ -200,11 +266,27 @@ func validateExpectedValueConsistency(f Field) error {
 	case FieldTypeBool:
 		return validateExpectedValueBool(f)
 
+	case FieldTypeEnum:
+		return validateExpectedValueEnum(f)
+
 	default:
 		return fmt.Errorf("unsupported field type %q", f.Type)
 	}
 }
 
+func validateExpectedValueEnum(f Field) error {
+	s, ok := f.Validation.ExpectedValue.(string)
+	if !ok {
+		return fmt.Errorf("field %s: expected value is not string", f.Name)
+	}
+
+	if !slices.Contains(f.Enum, s) {
+		return fmt.Errorf("field %s: expected value is not in enum", f.Name)
+	}
+
+	return nil
+}
+
 func validateExpectedValueString(f Field) error {
 	s, ok := f.Validation.ExpectedValue.(string)
 	if !ok {
@@ -289,8 +371,8 @@ func validateExpectedValueBool(f Field) error {
 //
 // WARNING: запускать после того, как отработал метод mapFieldsByOperation.
 func (op *Operation) validateWhereCondition() error {
-	if op.Type == OperationTypeCreate && len(op.Where) > 0 {
-		return fmt.Errorf("where condition: operation %q: where is not allowed for create operation", op.Name)
+	if (op.Type == OperationTypeCreate || op.Type == OperationTypeUpsert) && len(op.Where) > 0 {
+		return fmt.Errorf("where condition: operation %q: where is not allowed for %s operation", op.Name, op.Type)
 	}
 
 	for i, w := range op.Where {
@@ -380,7 +462,7 @@ func validateMultipleWhereCondition(w Where, fieldsMap map[string]Field, opName
 
 func validateFieldValues(field Field, whereField WhereField, opName string, idx int) error {
 	switch field.Type {
-	case FieldTypeString, FieldTypeUUID:
+	case FieldTypeString, FieldTypeUUID, FieldTypeEnum:
 		if err := compareValues[string](whereField.Value, field.Validation.ExpectedValue); err != nil {
 			return fmt.Errorf("where condition %d: operation %q: %w", idx, opName, err)
 		}
@@ -135,3 +135,68 @@ func (mr *MockhealthHandlerMockRecorder) Health(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockhealthHandler)(nil).Health), c)
 }
+
+// MockhttpWorker is a mock of httpWorker interface.
+type MockhttpWorker struct {
+	ctrl     *gomock.Controller
+	recorder *MockhttpWorkerMockRecorder
+}
+
+// MockhttpWorkerMockRecorder is the mock recorder for MockhttpWorker.
+type MockhttpWorkerMockRecorder struct {
+	mock *MockhttpWorker
+}
+
+// NewMockhttpWorker creates a new mock instance.
+func NewMockhttpWorker(ctrl *gomock.Controller) *MockhttpWorker {
+	mock := &MockhttpWorker{ctrl: ctrl}
+	mock.recorder = &MockhttpWorkerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhttpWorker) EXPECT() *MockhttpWorkerMockRecorder {
+	return m.recorder
+}
+
+// Handle mocks base method.
+func (m *MockhttpWorker) Handle(c echo.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Handle", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Handle indicates an expected call of Handle.
+func (mr *MockhttpWorkerMockRecorder) Handle(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockhttpWorker)(nil).Handle), c)
+}
+
+// Name mocks base method.
+func (m *MockhttpWorker) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockhttpWorkerMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockhttpWorker)(nil).Name))
+}
+
+// Path mocks base method.
+func (m *MockhttpWorker) Path() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Path")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Path indicates an expected call of Path.
+func (mr *MockhttpWorkerMockRecorder) Path() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Path", reflect.TypeOf((*MockhttpWorker)(nil).Path))
+}
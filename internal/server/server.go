@@ -27,6 +27,8 @@ type Server struct {
 	api struct {
 		h0 handler
 	}
+
+	httpWorkers []httpWorker // воркеры, принимающие сообщения через POST-запросы
 }
 
 //go:generate mockgen -source=server.go -destination=mocks/handler_mock.go -package=mocks handler
@@ -44,6 +46,14 @@ type healthHandler interface {
 	Health(c echo.Context) error
 }
 
+// httpWorker - воркер, принимающий сообщения через POST-запросы к серверу.
+type httpWorker interface {
+	Name() string
+	// Path возвращает путь относительно /api/v0/.
+	Path() string
+	Handle(c echo.Context) error
+}
+
 // Option - опция для настройки сервера.
 type Option func(*Server)
 
@@ -68,12 +78,20 @@ func WithHandlerV0(handler handler) Option {
 	}
 }
 
+// WithHTTPWorkers - устанавливает воркеры, для которых нужно зарегистрировать маршруты.
+func WithHTTPWorkers(workers ...httpWorker) Option {
+	return func(s *Server) {
+		s.httpWorkers = append(s.httpWorkers, workers...)
+	}
+}
+
 // New - создает новый сервер. Принимает опции для настройки сервера.
 // Доступные опции:
 //
 //   - WithPort - устанавливает порт сервера.
 //   - WithHandlerV0 - устанавливает хендлер версии 0.
 //   - WithShutdownTimeout - устанавливает таймаут graceful shutdown.
+//   - WithHTTPWorkers - устанавливает воркеры, принимающие сообщения по HTTP.
 func New(opts ...Option) (*Server, error) {
 	s := &Server{}
 	for _, opt := range opts {
@@ -96,9 +114,38 @@ func New(opts ...Option) (*Server, error) {
 		return nil, fmt.Errorf("expected handler version is %s, got %s", handlerV0.Version0, s.api.h0.Version())
 	}
 
+	if err := checkWorkerPaths(s.httpWorkers); err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
+// checkWorkerPaths проверяет, что у воркеров не совпадают пути и они не пересекаются со служебными маршрутами.
+func checkWorkerPaths(workers []httpWorker) error {
+	paths := make(map[string]string, len(workers))
+
+	for _, w := range workers {
+		path := strings.Trim(w.Path(), "/")
+
+		if path == "" {
+			return fmt.Errorf("http worker %s: path is required", w.Name())
+		}
+
+		if path == "health" {
+			return fmt.Errorf("http worker %s: path %q is reserved", w.Name(), path)
+		}
+
+		if other, ok := paths[path]; ok {
+			return fmt.Errorf("http workers %s and %s: duplicate path %q", other, w.Name(), path)
+		}
+
+		paths[path] = w.Name()
+	}
+
+	return nil
+}
+
 func checkHandlerVersion(h versionHandler, expectedVersion string) bool {
 	return h.Version() == expectedVersion
 }
@@ -158,6 +205,10 @@ func (s *Server) createRoutes() error {
 
 	apiv0.GET("health", s.api.h0.Health)
 
+	for _, w := range s.httpWorkers {
+		apiv0.POST(strings.Trim(w.Path(), "/"), w.Handle)
+	}
+
 	s.e = e
 
 	if len(s.e.Routes()) == 0 {
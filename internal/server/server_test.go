@@ -144,10 +144,15 @@ func TestCreateRoutes(t *testing.T) {
 	h := mocks.NewMockhandler(ctrl)
 	h.EXPECT().Version().Return("v0").Times(1)
 
+	w := mocks.NewMockhttpWorker(ctrl)
+	w.EXPECT().Name().Return("http_notes_create").AnyTimes()
+	w.EXPECT().Path().Return("/notes/create/").AnyTimes()
+
 	server, err := New(
 		WithPort(8080),
 		WithShutdownTimeout(100*time.Millisecond),
 		WithHandlerV0(h),
+		WithHTTPWorkers(w),
 	)
 	require.NoError(t, err)
 
@@ -162,6 +167,11 @@ func TestCreateRoutes(t *testing.T) {
 			Path:   "/api/v0/health",
 			Name:   "webserver/internal/server.handler.Health-fm",
 		},
+		{
+			Method: http.MethodPost,
+			Path:   "/api/v0/notes/create",
+			Name:   "webserver/internal/server.httpWorker.Handle-fm",
+		},
 		{
 			Method: http.MethodGet,
 			Path:   "/metrics",
@@ -197,6 +207,58 @@ func TestCreateRoutes(t *testing.T) {
 	}
 }
 
+func TestCheckWorkerPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			paths:   []string{"notes/create", "notes/update"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: duplicate path",
+			paths:   []string{"notes/create", "/notes/create"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: empty path",
+			paths:   []string{"/"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: reserved path",
+			paths:   []string{"health"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			workers := make([]httpWorker, 0, len(tt.paths))
+
+			for i, path := range tt.paths {
+				w := mocks.NewMockhttpWorker(ctrl)
+				w.EXPECT().Name().Return(fmt.Sprintf("worker_%d", i)).AnyTimes()
+				w.EXPECT().Path().Return(path).AnyTimes()
+
+				workers = append(workers, w)
+			}
+
+			tt.wantErr(t, checkWorkerPaths(workers))
+		})
+	}
+}
+
 func TestCheckHandlerVersion(t *testing.T) {
 	t.Parallel()
 
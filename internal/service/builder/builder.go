@@ -34,6 +34,10 @@ type Builder interface {
 	WithValues(vals map[string]any) Builder
 	// WithTable устанавливает название таблицы.
 	WithTable(table string) Builder
+	// WithBatchValues устанавливает значения нескольких сообщений: из них собирается один запрос. Только для операции create.
+	WithBatchValues(rows []map[string]any) Builder
+	// WithCopyThreshold устанавливает, со скольких строк пачка записывается через COPY. 0 - COPY не используется.
+	WithCopyThreshold(threshold int) Builder
 
 	operations
 }
@@ -45,4 +49,6 @@ type operations interface {
 	WithUpdateOperation() (Builder, error)
 	// WithDeleteOperation устанавливает операцию удаления.
 	WithDeleteOperation() (Builder, error)
+	// WithUpsertOperation устанавливает операцию создания или обновления при конфликте.
+	WithUpsertOperation() (Builder, error)
 }
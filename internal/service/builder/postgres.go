@@ -5,8 +5,11 @@ import (
 	"db-worker/internal/storage"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/huandu/go-sqlbuilder"
+	"github.com/lib/pq"
 )
 
 // builder - интерфейс для создания запросов.
@@ -21,6 +24,9 @@ type postgresBuilder struct {
 	builder   builder
 	table     string
 	args      map[string]any
+
+	rows          []map[string]any // значения сообщений пачки
+	copyThreshold int              // со скольких строк пачка записывается через COPY
 }
 
 func (b *postgresBuilder) WithOperation(operation operation.Operation) Builder {
@@ -50,18 +56,47 @@ func (b *postgresBuilder) WithValues(vals map[string]any) Builder {
 	return b
 }
 
+// WithBatchValues устанавливает значения сообщений пачки для драйвера PostgreSQL.
+func (b *postgresBuilder) WithBatchValues(rows []map[string]any) Builder {
+	b.rows = rows
+
+	if create, ok := b.builder.(*createPostgresBuilder); ok {
+		create.rows = rows
+	}
+
+	return b
+}
+
+// WithCopyThreshold устанавливает, со скольких строк пачка записывается через COPY.
+func (b *postgresBuilder) WithCopyThreshold(threshold int) Builder {
+	b.copyThreshold = threshold
+
+	if create, ok := b.builder.(*createPostgresBuilder); ok {
+		create.copyThreshold = threshold
+	}
+
+	return b
+}
+
 func (b *postgresBuilder) WithCreateOperation() Builder {
 	b.builder = &createPostgresBuilder{
 		basePostgresBuilder: basePostgresBuilder{
 			table: b.table,
 			args:  b.args,
 		},
+		rows:          b.rows,
+		copyThreshold: b.copyThreshold,
+		columns:       declaredColumns(b.operation),
 	}
 
 	return b
 }
 
 func (b *postgresBuilder) WithUpdateOperation() (Builder, error) {
+	if b.rows != nil {
+		return nil, errors.New("batch is supported only for create operation")
+	}
+
 	b.builder = &updatePostgresBuilder{
 		basePostgresBuilder: basePostgresBuilder{
 			table: b.table,
@@ -77,6 +112,10 @@ func (b *postgresBuilder) WithUpdateOperation() (Builder, error) {
 }
 
 func (b *postgresBuilder) WithDeleteOperation() (Builder, error) {
+	if b.rows != nil {
+		return nil, errors.New("batch is supported only for create operation")
+	}
+
 	b.builder = &deletePostgresBuilder{
 		basePostgresBuilder: basePostgresBuilder{
 			table: b.table,
@@ -90,6 +129,26 @@ func (b *postgresBuilder) WithDeleteOperation() (Builder, error) {
 	return b, nil
 }
 
+// WithUpsertOperation устанавливает операцию создания или обновления при конфликте.
+func (b *postgresBuilder) WithUpsertOperation() (Builder, error) {
+	if b.rows != nil {
+		return nil, errors.New("batch is supported only for create operation")
+	}
+
+	b.builder = &upsertPostgresBuilder{
+		basePostgresBuilder: basePostgresBuilder{
+			table: b.table,
+			args:  b.args,
+		},
+
+		conflict:        b.operation.Conflict,
+		updateFieldsMap: b.operation.UpdateFieldsMap,
+		columns:         declaredColumns(b.operation),
+	}
+
+	return b, nil
+}
+
 func (b *postgresBuilder) Build() (*storage.Request, error) {
 	if b.builder == nil {
 		return nil, errors.New("builder is nil")
@@ -106,8 +165,14 @@ type basePostgresBuilder struct {
 }
 
 // createPostgresBuilder - строитель запросов для insert операций в PostgreSQL.
+// Если заданы строки пачки - строит один запрос на все строки: multi-row INSERT или COPY.
+// В запрос попадают только поля, объявленные в операции: остальные ключи сообщения отбрасываются.
 type createPostgresBuilder struct {
 	basePostgresBuilder
+
+	rows          []map[string]any
+	copyThreshold int
+	columns       map[string]string // поле операции -> колонка
 }
 
 func (b *createPostgresBuilder) withTable(table string) {
@@ -123,21 +188,255 @@ func (b *createPostgresBuilder) build() (*storage.Request, error) {
 		return nil, errors.New("table is nil")
 	}
 
+	if b.rows != nil {
+		return b.buildBatch()
+	}
+
 	if b.args == nil {
 		return nil, errors.New("args is nil")
 	}
 
+	cols, vals, err := collectColsAndVals(b.args, b.columns)
+	if err != nil {
+		return nil, err
+	}
+
 	sb := sqlbuilder.NewInsertBuilder() // In common scenarios, it is necessary to escape all user inputs. To achieve this, initialize a builder at the outset.
 
 	sb.SetFlavor(sqlbuilder.PostgreSQL)
 
-	sb.InsertInto(b.table)
+	sb.InsertInto(QuoteTable(b.table))
+
+	sb.Cols(quoteColumns(cols)...)
+	sb.Values(vals...)
+
+	sql, args := sb.Build()
+
+	return &storage.Request{
+		Val:  sql,
+		Args: args,
+		Raw:  b.args,
+	}, nil
+}
+
+// maxParams - сколько параметров PostgreSQL принимает в одном запросе.
+const maxParams = 65535
+
+// buildBatch строит запрос на запись всех строк пачки.
+// Если у строк одинаковые колонки и строк не меньше copyThreshold (или параметров больше, чем принимает PostgreSQL) -
+// строки записываются через COPY. Иначе - одним INSERT, колонки, которых нет в строке, получают значение по умолчанию.
+func (b *createPostgresBuilder) buildBatch() (*storage.Request, error) {
+	if len(b.rows) == 0 {
+		return nil, errors.New("rows are empty")
+	}
+
+	names, same := collectBatchCols(b.rows, b.columns)
+	if len(names) == 0 {
+		return nil, errors.New("no operation fields in rows")
+	}
+
+	cols := make([]string, 0, len(names))
+	for _, name := range names {
+		cols = append(cols, b.columns[name])
+	}
+
+	params := len(cols) * len(b.rows)
+
+	useCopy := (b.copyThreshold > 0 && len(b.rows) >= b.copyThreshold) || params > maxParams
+	if useCopy && same {
+		rows := make([][]any, 0, len(b.rows))
+		for _, row := range b.rows {
+			vals := make([]any, 0, len(names))
+			for _, name := range names {
+				vals = append(vals, row[name])
+			}
+
+			rows = append(rows, vals)
+		}
+
+		return &storage.Request{
+			Val:  storage.Copy{Table: b.table, Columns: cols, Rows: rows},
+			Args: []any{},
+			Raw:  map[string]any{"rows": b.rows},
+		}, nil
+	}
+
+	if params > maxParams {
+		return nil, fmt.Errorf("too many parameters for batch insert: %d, max %d", params, maxParams)
+	}
+
+	sb := sqlbuilder.NewInsertBuilder()
+
+	sb.SetFlavor(sqlbuilder.PostgreSQL)
+
+	sb.InsertInto(QuoteTable(b.table))
+	sb.Cols(quoteColumns(cols)...)
+
+	for _, row := range b.rows {
+		vals := make([]any, 0, len(names))
+		for _, name := range names {
+			value, ok := row[name]
+			if !ok {
+				vals = append(vals, sqlbuilder.Raw("DEFAULT"))
+				continue
+			}
+
+			vals = append(vals, value)
+		}
+
+		sb.Values(vals...)
+	}
+
+	sql, args := sb.Build()
+
+	return &storage.Request{
+		Val:  sql,
+		Args: args,
+		Raw:  map[string]any{"rows": b.rows},
+	}, nil
+}
+
+// collectBatchCols возвращает поля операции из всех строк пачки в алфавитном порядке их колонок и признак того,
+// что поля у строк одинаковые.
+func collectBatchCols(rows []map[string]any, columns map[string]string) ([]string, bool) {
+	set := make(map[string]struct{})
+
+	for _, row := range rows {
+		for name := range row {
+			if _, ok := columns[name]; ok {
+				set[name] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
 
-	cols, vals := collectColsAndVals(b.args)
+	sort.Slice(names, func(i, j int) bool { return columns[names[i]] < columns[names[j]] })
 
-	sb.Cols(cols...)
+	for _, row := range rows {
+		for _, name := range names {
+			if _, ok := row[name]; !ok {
+				return names, false
+			}
+		}
+	}
+
+	return names, true
+}
+
+// collectColsAndVals возвращает колонки полей операции из сообщения в алфавитном порядке и значения полей.
+func collectColsAndVals(args map[string]any, columns map[string]string) ([]string, []any, error) {
+	names := make([]string, 0, len(args))
+
+	for name := range args {
+		if _, ok := columns[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, nil, errors.New("no operation fields in message")
+	}
+
+	sort.Slice(names, func(i, j int) bool { return columns[names[i]] < columns[names[j]] })
+
+	cols := make([]string, 0, len(names))
+	vals := make([]any, 0, len(names))
+
+	for _, name := range names {
+		cols = append(cols, columns[name])
+		vals = append(vals, args[name])
+	}
+
+	return cols, vals, nil
+}
+
+// declaredColumns возвращает колонки полей, объявленных в операции: название поля -> колонка.
+func declaredColumns(op operation.Operation) map[string]string {
+	columns := make(map[string]string, len(op.Fields))
+	for _, field := range op.Fields {
+		columns[field.Name] = field.ColumnName()
+	}
+
+	return columns
+}
+
+// QuoteTable экранирует название таблицы. Схема и таблица экранируются по отдельности: notes.notes -> "notes"."notes".
+func QuoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteColumns экранирует названия колонок.
+func quoteColumns(cols []string) []string {
+	quoted := make([]string, 0, len(cols))
+	for _, col := range cols {
+		quoted = append(quoted, pq.QuoteIdentifier(col))
+	}
+
+	return quoted
+}
+
+// upsertPostgresBuilder - строитель запросов для upsert операций в PostgreSQL: INSERT ... ON CONFLICT.
+// При конфликте обновляются поля с update: true, которые есть в сообщении. Если таких полей нет
+// или задан do_nothing - существующая запись не меняется.
+type upsertPostgresBuilder struct {
+	basePostgresBuilder
+
+	conflict        *operation.Conflict
+	updateFieldsMap map[string]operation.Field
+	columns         map[string]string // поле операции -> колонка
+}
+
+func (b *upsertPostgresBuilder) withTable(table string) {
+	b.table = table
+}
+
+func (b *upsertPostgresBuilder) withValues(vals map[string]any) {
+	b.args = vals
+}
+
+func (b *upsertPostgresBuilder) build() (*storage.Request, error) {
+	if b.table == "" {
+		return nil, errors.New("table is nil")
+	}
+
+	if b.args == nil {
+		return nil, errors.New("args is nil")
+	}
+
+	if b.conflict == nil {
+		return nil, errors.New("conflict is nil")
+	}
+
+	target, err := b.conflictTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	cols, vals, err := collectColsAndVals(b.args, b.columns)
+	if err != nil {
+		return nil, err
+	}
+
+	sb := sqlbuilder.NewInsertBuilder()
+
+	sb.SetFlavor(sqlbuilder.PostgreSQL)
+
+	sb.InsertInto(QuoteTable(b.table))
+
+	sb.Cols(quoteColumns(cols)...)
 	sb.Values(vals...)
 
+	sb.SQL("ON CONFLICT " + target + " " + b.conflictAction(cols))
+
 	sql, args := sb.Build()
 
 	return &storage.Request{
@@ -147,16 +446,55 @@ func (b *createPostgresBuilder) build() (*storage.Request, error) {
 	}, nil
 }
 
-func collectColsAndVals(args map[string]any) ([]string, []any) {
-	cols := make([]string, 0, len(args))
-	vals := make([]any, 0, len(args))
+// conflictTarget возвращает ключ конфликта: список колонок или ограничение.
+func (b *upsertPostgresBuilder) conflictTarget() (string, error) {
+	if b.conflict.Constraint != "" {
+		return "ON CONSTRAINT " + pq.QuoteIdentifier(b.conflict.Constraint), nil
+	}
+
+	if len(b.conflict.Columns) == 0 {
+		return "", errors.New("conflict columns are empty")
+	}
+
+	// в conflict перечислены поля операции: берем их колонки
+	cols := make([]string, 0, len(b.conflict.Columns))
+	for _, name := range b.conflict.Columns {
+		col, ok := b.columns[name]
+		if !ok {
+			col = name
+		}
+
+		cols = append(cols, col)
+	}
+
+	return "(" + strings.Join(quoteColumns(cols), ", ") + ")", nil
+}
+
+// conflictAction возвращает действие при конфликте: обновление полей из вставляемой строки (EXCLUDED) или DO NOTHING.
+// cols - колонки вставляемой строки в алфавитном порядке.
+func (b *upsertPostgresBuilder) conflictAction(cols []string) string {
+	if b.conflict.DoNothing {
+		return "DO NOTHING"
+	}
 
-	for name, value := range args {
-		cols = append(cols, name)
-		vals = append(vals, value)
+	update := make(map[string]struct{}, len(b.updateFieldsMap))
+	for _, field := range b.updateFieldsMap {
+		update[field.ColumnName()] = struct{}{}
 	}
 
-	return cols, vals
+	assignments := make([]string, 0, len(update))
+	for _, col := range cols {
+		if _, ok := update[col]; ok {
+			quoted := pq.QuoteIdentifier(col)
+			assignments = append(assignments, quoted+" = EXCLUDED."+quoted)
+		}
+	}
+
+	if len(assignments) == 0 {
+		return "DO NOTHING"
+	}
+
+	return "DO UPDATE SET " + strings.Join(assignments, ", ")
 }
 
 // updatePostgresBuilder - строитель запросов для update операций в PostgreSQL.
@@ -254,20 +592,28 @@ func (b *whereUpdateBuilder) initUpdateBuilder() error {
 	ub := sqlbuilder.NewUpdateBuilder()
 	b.ub = ub
 	ub.SetFlavor(sqlbuilder.PostgreSQL)
-	ub.Update(b.table)
+	ub.Update(QuoteTable(b.table))
 
 	return nil
 }
 
 func (b *whereUpdateBuilder) applyAssignments() error {
-	assignments := make([]string, 0, len(b.args))
-	for name := range b.updateFieldsMap {
-		value, ok := b.args[name]
+	// колонки по алфавиту: один и тот же набор полей дает один и тот же запрос
+	fields := make([]operation.Field, 0, len(b.updateFieldsMap))
+	for _, field := range b.updateFieldsMap {
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].ColumnName() < fields[j].ColumnName() })
+
+	assignments := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value, ok := b.args[field.Name]
 		if !ok {
-			return fmt.Errorf("missing value for update field %s", name)
+			return fmt.Errorf("missing value for update field %s", field.Name)
 		}
 
-		assignments = append(assignments, b.ub.Assign(name, value))
+		assignments = append(assignments, b.ub.Assign(pq.QuoteIdentifier(field.ColumnName()), value))
 	}
 
 	if len(assignments) > 0 {
@@ -359,19 +705,21 @@ func (b *whereUpdateBuilder) buildComparator(field operation.WhereField) (string
 		value = v
 	}
 
+	column := pq.QuoteIdentifier(field.ColumnName())
+
 	switch field.Operator {
 	case operation.OperatorEqual:
-		return b.ub.Equal(field.Name, value), nil
+		return b.ub.Equal(column, value), nil
 	case operation.OperatorNotEqual:
-		return b.ub.NotEqual(field.Name, value), nil
+		return b.ub.NotEqual(column, value), nil
 	case operation.OperatorGreaterThan:
-		return b.ub.GreaterThan(field.Name, value), nil
+		return b.ub.GreaterThan(column, value), nil
 	case operation.OperatorGreaterThanOrEqual:
-		return b.ub.GreaterEqualThan(field.Name, value), nil
+		return b.ub.GreaterEqualThan(column, value), nil
 	case operation.OperatorLessThan:
-		return b.ub.LessThan(field.Name, value), nil
+		return b.ub.LessThan(column, value), nil
 	case operation.OperatorLessThanOrEqual:
-		return b.ub.LessEqualThan(field.Name, value), nil
+		return b.ub.LessEqualThan(column, value), nil
 	default:
 		return "", fmt.Errorf("unknown operator: %s", field.Operator)
 	}
@@ -464,7 +812,7 @@ func (b *whereDeleteBuilder) initDeleteBuilder() error {
 	ub := sqlbuilder.NewDeleteBuilder()
 	b.ub = ub
 	ub.SetFlavor(sqlbuilder.PostgreSQL)
-	ub.DeleteFrom(b.table)
+	ub.DeleteFrom(QuoteTable(b.table))
 
 	return nil
 }
@@ -566,19 +914,21 @@ func buildComparator(matcher matcher, field operation.WhereField, args map[strin
 		value = v
 	}
 
+	column := pq.QuoteIdentifier(field.ColumnName())
+
 	switch field.Operator {
 	case operation.OperatorEqual:
-		return matcher.Equal(field.Name, value), nil
+		return matcher.Equal(column, value), nil
 	case operation.OperatorNotEqual:
-		return matcher.NotEqual(field.Name, value), nil
+		return matcher.NotEqual(column, value), nil
 	case operation.OperatorGreaterThan:
-		return matcher.GreaterThan(field.Name, value), nil
+		return matcher.GreaterThan(column, value), nil
 	case operation.OperatorGreaterThanOrEqual:
-		return matcher.GreaterEqualThan(field.Name, value), nil
+		return matcher.GreaterEqualThan(column, value), nil
 	case operation.OperatorLessThan:
-		return matcher.LessThan(field.Name, value), nil
+		return matcher.LessThan(column, value), nil
 	case operation.OperatorLessThanOrEqual:
-		return matcher.LessEqualThan(field.Name, value), nil
+		return matcher.LessEqualThan(column, value), nil
 	default:
 		return "", fmt.Errorf("unknown operator: %s", field.Operator)
 	}
@@ -119,21 +119,26 @@ func TestWithValues(t *testing.T) {
 func TestWithCreateOperation(t *testing.T) {
 	t.Parallel()
 
+	op := operation.Operation{Fields: []operation.Field{{Name: "test"}}}
+
 	builder := &postgresBuilder{
-		args:  map[string]any{"test": "test"},
-		table: "test",
+		operation: op,
+		args:      map[string]any{"test": "test"},
+		table:     "test",
 	}
 	builder = builder.WithCreateOperation().(*postgresBuilder)
 	require.NotNil(t, builder)
 
 	assert.Equal(t, builder, &postgresBuilder{
-		args:  map[string]any{"test": "test"},
-		table: "test",
+		operation: op,
+		args:      map[string]any{"test": "test"},
+		table:     "test",
 		builder: &createPostgresBuilder{
 			basePostgresBuilder: basePostgresBuilder{
 				args:  map[string]any{"test": "test"},
 				table: "test",
 			},
+			columns: map[string]string{"test": "test"},
 		}})
 }
 
@@ -261,10 +266,11 @@ func TestBuild(t *testing.T) {
 						args:  map[string]any{"test": "test"},
 						table: "test",
 					},
+					columns: map[string]string{"test": "test"},
 				},
 			},
 			want: &storage.Request{
-				Val:  "INSERT INTO test (test) VALUES ($1)",
+				Val:  `INSERT INTO "test" ("test") VALUES ($1)`,
 				Args: []any{"test"},
 				Raw:  map[string]any{"test": "test"},
 			},
@@ -355,14 +361,167 @@ func TestCreatePostgresBuilder_Build(t *testing.T) {
 					table: "test",
 					args:  map[string]any{"test": "test"},
 				},
+				columns: map[string]string{"test": "test"},
 			},
 			want: &storage.Request{
-				Val:  "INSERT INTO test (test) VALUES ($1)",
+				Val:  `INSERT INTO "test" ("test") VALUES ($1)`,
 				Args: []any{"test"},
 				Raw:  map[string]any{"test": "test"},
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "positive case: undeclared keys are dropped",
+			builder: &createPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1, "text": "hello", "txet": "typo"},
+				},
+				columns: map[string]string{"user_id": "user_id", "text": "text"},
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("text", "user_id") VALUES ($1, $2)`,
+				Args: []any{"hello", 1},
+				Raw:  map[string]any{"user_id": 1, "text": "hello", "txet": "typo"},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: no declared fields in message",
+			builder: &createPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "test",
+					args:  map[string]any{"txet": "typo"},
+				},
+				columns: map[string]string{"text": "text"},
+			},
+			want:    nil,
+			wantErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := test.builder.build()
+			test.wantErr(t, err)
+			assert.Equal(t, test.want, req)
+		})
+	}
+}
+
+//nolint:funlen // много тест-кейсов
+func TestUpsertPostgresBuilder_Build(t *testing.T) {
+	t.Parallel()
+
+	updateFieldsMap := map[string]operation.Field{
+		"text":  {Name: "text", Update: true},
+		"title": {Name: "title", Update: true},
+	}
+
+	columns := map[string]string{"user_id": "user_id", "note_id": "note_id", "text": "text", "title": "title"}
+
+	tests := []struct {
+		name    string
+		builder *upsertPostgresBuilder
+		want    *storage.Request
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: update on conflict",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1, "note_id": 2, "text": "hello"},
+				},
+				conflict:        &operation.Conflict{Columns: []string{"user_id", "note_id"}},
+				updateFieldsMap: updateFieldsMap,
+				columns:         columns,
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("note_id", "text", "user_id") VALUES ($1, $2, $3) ON CONFLICT ("user_id", "note_id") DO UPDATE SET "text" = EXCLUDED."text"`,
+				Args: []any{2, "hello", 1},
+				Raw:  map[string]any{"user_id": 1, "note_id": 2, "text": "hello"},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: constraint and do nothing",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1},
+				},
+				conflict: &operation.Conflict{Constraint: "notes_pkey", DoNothing: true},
+				columns:  columns,
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("user_id") VALUES ($1) ON CONFLICT ON CONSTRAINT "notes_pkey" DO NOTHING`,
+				Args: []any{1},
+				Raw:  map[string]any{"user_id": 1},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: no update fields in message",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1},
+				},
+				conflict:        &operation.Conflict{Columns: []string{"user_id"}},
+				updateFieldsMap: updateFieldsMap,
+				columns:         columns,
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("user_id") VALUES ($1) ON CONFLICT ("user_id") DO NOTHING`,
+				Args: []any{1},
+				Raw:  map[string]any{"user_id": 1},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: nil conflict",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1},
+				},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: no conflict columns",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+					args:  map[string]any{"user_id": 1},
+				},
+				conflict: &operation.Conflict{},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: nil table",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					args: map[string]any{"user_id": 1},
+				},
+				conflict: &operation.Conflict{Columns: []string{"user_id"}},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: nil args",
+			builder: &upsertPostgresBuilder{
+				basePostgresBuilder: basePostgresBuilder{
+					table: "notes.notes",
+				},
+				conflict: &operation.Conflict{Columns: []string{"user_id"}},
+			},
+			wantErr: require.Error,
+		},
 	}
 
 	for _, test := range tests {
@@ -376,22 +535,89 @@ func TestCreatePostgresBuilder_Build(t *testing.T) {
 	}
 }
 
+func TestWithUpsertOperation(t *testing.T) {
+	t.Parallel()
+
+	conflict := &operation.Conflict{Columns: []string{"user_id"}}
+	updateFieldsMap := map[string]operation.Field{"text": {Name: "text", Update: true}}
+
+	b, err := ForPostgres().
+		WithOperation(operation.Operation{
+			Type:            operation.OperationTypeUpsert,
+			Fields:          []operation.Field{{Name: "user_id"}, {Name: "text", Update: true}},
+			Conflict:        conflict,
+			UpdateFieldsMap: updateFieldsMap,
+		}).
+		WithTable("notes.notes").
+		WithValues(map[string]any{"user_id": 1, "text": "hello"}).
+		WithUpsertOperation()
+	require.NoError(t, err)
+
+	req, err := b.Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, `INSERT INTO "notes"."notes" ("text", "user_id") VALUES ($1, $2) ON CONFLICT ("user_id") DO UPDATE SET "text" = EXCLUDED."text"`, req.Val)
+	assert.Equal(t, []any{"hello", 1}, req.Args)
+}
+
 func TestCollectColsAndVals(t *testing.T) {
 	t.Parallel()
 
-	args := map[string]any{"user_id": 1,
-		"name":      "ivan ivanov",
-		"email":     "ivan@ivanov.com",
-		"age":       20,
-		"is_active": true,
+	columns := map[string]string{"user_id": "user_id", "name": "name", "email": "email", "age": "age", "is_active": "is_active"}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantCols []string
+		wantVals []any
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			args: map[string]any{"user_id": 1,
+				"name":      "ivan ivanov",
+				"email":     "ivan@ivanov.com",
+				"age":       20,
+				"is_active": true,
+			},
+			wantCols: []string{"age", "email", "is_active", "name", "user_id"},
+			wantVals: []any{20, "ivan@ivanov.com", true, "ivan ivanov", 1},
+			wantErr:  require.NoError,
+		},
+		{
+			name:     "undeclared keys are dropped",
+			args:     map[string]any{"user_id": 1, "name": "ivan ivanov", "nmae": "typo", "admin": true},
+			wantCols: []string{"name", "user_id"},
+			wantVals: []any{"ivan ivanov", 1},
+			wantErr:  require.NoError,
+		},
+		{
+			name:    "negative case: no declared keys",
+			args:    map[string]any{"admin": true},
+			wantErr: require.Error,
+		},
 	}
 
-	wantCols := []string{"user_id", "name", "email", "age", "is_active"}
-	wantVals := []any{1, "ivan ivanov", "ivan@ivanov.com", 20, true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	cols, vals := collectColsAndVals(args)
-	assert.ElementsMatch(t, wantCols, cols)
-	assert.ElementsMatch(t, wantVals, vals)
+			cols, vals, err := collectColsAndVals(tt.args, columns)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantCols, cols)
+			assert.Equal(t, tt.wantVals, vals)
+		})
+	}
+}
+
+func TestQuoteTable(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `"notes"`, QuoteTable("notes"))
+	assert.Equal(t, `"notes"."notes"`, QuoteTable("notes.notes"))
+	assert.Equal(t, `"notes"."my ""notes"""`, QuoteTable(`notes.my "notes"`))
+	assert.Equal(t, `"Messages"."DeadLetters"`, QuoteTable("Messages.DeadLetters"))
+	assert.Equal(t, `"messages"."dead_letters; DROP TABLE notes"`, QuoteTable("messages.dead_letters; DROP TABLE notes"))
 }
 
 func TestUpdatePostgresBuilder_WithTable(t *testing.T) {
@@ -457,7 +683,7 @@ func TestUpdatePostgresBuilder_Build(t *testing.T) {
 			},
 			wantErr: require.NoError,
 			want: &storage.Request{
-				Val:  "UPDATE users.users SET age = $1 WHERE name = $2",
+				Val:  `UPDATE "users"."users" SET "age" = $1 WHERE "name" = $2`,
 				Args: []any{20, "ivan"},
 				Raw:  map[string]any{"age": 20},
 			},
@@ -804,11 +1030,43 @@ func TestWhereUpdateBuilder_Build(t *testing.T) {
 				},
 			},
 			want: &storage.Request{
-				Val:  "UPDATE users.users SET age = $1 WHERE name = $2",
+				Val:  `UPDATE "users"."users" SET "age" = $1 WHERE "name" = $2`,
 				Args: []any{20, "ivan"},
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "positive case: update fields in alphabetical order",
+			builder: &whereUpdateBuilder{
+				ub:    sqlbuilder.NewUpdateBuilder(),
+				table: "users.users",
+				args: map[string]any{
+					"name":  "ivan",
+					"email": "ivan@ivanov.com",
+					"age":   20,
+				},
+				where: []operation.Where{
+					{
+						Fields: []operation.WhereField{
+							{
+								Field:    operation.Field{Name: "name"},
+								Operator: operation.OperatorEqual,
+								Value:    "ivan",
+							},
+						},
+					},
+				},
+				updateFieldsMap: map[string]operation.Field{
+					"email": {Name: "email", Update: true},
+					"age":   {Name: "age", Update: true},
+				},
+			},
+			want: &storage.Request{
+				Val:  `UPDATE "users"."users" SET "age" = $1, "email" = $2 WHERE "name" = $3`,
+				Args: []any{20, "ivan@ivanov.com", "ivan"},
+			},
+			wantErr: require.NoError,
+		},
 		{
 			name: "positive case #2",
 			builder: &whereUpdateBuilder{
@@ -874,7 +1132,7 @@ func TestWhereUpdateBuilder_Build(t *testing.T) {
 				},
 			},
 			want: &storage.Request{
-				Val:  "UPDATE users.users SET email = $1 WHERE ((user_id = $2 AND name = $3) AND is_active = $4)",
+				Val:  `UPDATE "users"."users" SET "email" = $1 WHERE (("user_id" = $2 AND "name" = $3) AND "is_active" = $4)`,
 				Args: []any{"ivan@ivanov.com", 1, "ivan", true},
 			},
 			wantErr: require.NoError,
@@ -1353,7 +1611,7 @@ func TestDeletePostgresBuilder_Build(t *testing.T) {
 			},
 			wantErr: require.NoError,
 			want: &storage.Request{
-				Val:  "DELETE FROM users.users WHERE name = $1",
+				Val:  `DELETE FROM "users"."users" WHERE "name" = $1`,
 				Args: []any{"ivan"},
 				Raw:  map[string]any{"name": "ivan"},
 			},
@@ -1621,7 +1879,7 @@ func TestWhereDeleteBuilder_Build(t *testing.T) {
 				},
 			},
 			want: &storage.Request{
-				Val:  "DELETE FROM users.users WHERE name = $1",
+				Val:  `DELETE FROM "users"."users" WHERE "name" = $1`,
 				Args: []any{"ivan"},
 				Raw:  map[string]any{"name": "ivan"},
 			},
@@ -1681,7 +1939,7 @@ func TestWhereDeleteBuilder_Build(t *testing.T) {
 				},
 			},
 			want: &storage.Request{
-				Val:  "DELETE FROM users.users WHERE ((user_id = $1 AND name = $2) AND is_active = $3)",
+				Val:  `DELETE FROM "users"."users" WHERE (("user_id" = $1 AND "name" = $2) AND "is_active" = $3)`,
 				Args: []any{1, "ivan", true},
 			},
 			wantErr: require.NoError,
@@ -2408,3 +2666,255 @@ func TestBuildWhereExpr_UpdateBuilder(t *testing.T) {
 		})
 	}
 }
+
+//nolint:funlen // много тест-кейсов
+func TestCreatePostgresBuilder_BuildBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		rows          []map[string]any
+		copyThreshold int
+		want          *storage.Request
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: multi-row insert",
+			rows: []map[string]any{
+				{"user_id": 1, "text": "first"},
+				{"user_id": 2, "text": "second"},
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("text", "user_id") VALUES ($1, $2), ($3, $4)`,
+				Args: []any{"first", 1, "second", 2},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: missing column gets default",
+			rows: []map[string]any{
+				{"user_id": 1, "text": "first"},
+				{"user_id": 2},
+			},
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("text", "user_id") VALUES ($1, $2), (DEFAULT, $3)`,
+				Args: []any{"first", 1, 2},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: copy",
+			rows: []map[string]any{
+				{"user_id": 1, "text": "first"},
+				{"user_id": 2, "text": "second"},
+			},
+			copyThreshold: 2,
+			want: &storage.Request{
+				Val: storage.Copy{
+					Table:   "notes.notes",
+					Columns: []string{"text", "user_id"},
+					Rows:    [][]any{{"first", 1}, {"second", 2}},
+				},
+				Args: []any{},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: below copy threshold",
+			rows: []map[string]any{
+				{"user_id": 1},
+				{"user_id": 2},
+			},
+			copyThreshold: 3,
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("user_id") VALUES ($1), ($2)`,
+				Args: []any{1, 2},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: different columns are not copied",
+			rows: []map[string]any{
+				{"user_id": 1, "text": "first"},
+				{"user_id": 2},
+			},
+			copyThreshold: 1,
+			want: &storage.Request{
+				Val:  `INSERT INTO "notes"."notes" ("text", "user_id") VALUES ($1, $2), (DEFAULT, $3)`,
+				Args: []any{"first", 1, 2},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: undeclared keys are dropped",
+			rows: []map[string]any{
+				{"user_id": 1, "txet": "typo"},
+				{"user_id": 2},
+			},
+			copyThreshold: 2,
+			want: &storage.Request{
+				Val: storage.Copy{
+					Table:   "notes.notes",
+					Columns: []string{"user_id"},
+					Rows:    [][]any{{1}, {2}},
+				},
+				Args: []any{},
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: no declared fields in rows",
+			rows: []map[string]any{
+				{"txet": "typo"},
+			},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: rows are empty",
+			rows:    []map[string]any{},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := ForPostgres().
+				WithOperation(operation.Operation{Fields: []operation.Field{{Name: "user_id"}, {Name: "text"}}}).
+				WithBatchValues(tt.rows).
+				WithCopyThreshold(tt.copyThreshold).
+				WithTable("notes.notes").
+				WithCreateOperation().
+				Build()
+			tt.wantErr(t, err)
+
+			if tt.want == nil {
+				assert.Nil(t, req)
+				return
+			}
+
+			require.NotNil(t, req)
+			assert.Equal(t, tt.want.Val, req.Val)
+			assert.Equal(t, tt.want.Args, req.Args)
+			assert.Equal(t, map[string]any{"rows": tt.rows}, req.Raw)
+		})
+	}
+}
+
+func TestBatch_OnlyCreateOperation(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{{"id": 1}}
+
+	_, err := ForPostgres().WithBatchValues(rows).WithUpdateOperation()
+	require.Error(t, err)
+
+	_, err = ForPostgres().WithBatchValues(rows).WithDeleteOperation()
+	require.Error(t, err)
+
+	_, err = ForPostgres().WithBatchValues(rows).WithUpsertOperation()
+	require.Error(t, err)
+}
+
+func TestCollectBatchCols(t *testing.T) {
+	t.Parallel()
+
+	columns := map[string]string{"a": "a", "b": "b"}
+
+	cols, same := collectBatchCols([]map[string]any{{"b": 1, "a": 2}, {"a": 3, "b": 4}}, columns)
+	assert.Equal(t, []string{"a", "b"}, cols)
+	assert.True(t, same)
+
+	cols, same = collectBatchCols([]map[string]any{{"b": 1}, {"a": 3}}, columns)
+	assert.Equal(t, []string{"a", "b"}, cols)
+	assert.False(t, same)
+
+	// необъявленные ключи не влияют ни на колонки, ни на совпадение набора полей
+	cols, same = collectBatchCols([]map[string]any{{"a": 1, "typo": 2}, {"a": 3}}, columns)
+	assert.Equal(t, []string{"a"}, cols)
+	assert.True(t, same)
+}
+
+//nolint:funlen // тестовая функция
+func TestColumnAliases(t *testing.T) {
+	t.Parallel()
+
+	userID := operation.Field{Name: "user_id", Column: "owner_id"}
+	text := operation.Field{Name: "text", Column: "body", Update: true}
+
+	where := []operation.Where{
+		{Fields: []operation.WhereField{{Field: userID, Operator: operation.OperatorEqual}}},
+	}
+
+	op := operation.Operation{
+		Fields:          []operation.Field{userID, text},
+		Where:           where,
+		UpdateFieldsMap: map[string]operation.Field{"text": text},
+		Conflict:        &operation.Conflict{Columns: []string{"user_id"}},
+	}
+
+	args := map[string]any{"user_id": 1, "text": "hello"}
+
+	tests := []struct {
+		name  string
+		build func() (Builder, error)
+		want  string
+	}{
+		{
+			name: "create",
+			build: func() (Builder, error) {
+				return ForPostgres().WithOperation(op).WithTable("notes.notes").WithValues(args).WithCreateOperation(), nil
+			},
+			want: `INSERT INTO "notes"."notes" ("body", "owner_id") VALUES ($1, $2)`,
+		},
+		{
+			name: "batch",
+			build: func() (Builder, error) {
+				return ForPostgres().WithOperation(op).WithTable("notes.notes").
+					WithBatchValues([]map[string]any{args, {"user_id": 2}}).WithCreateOperation(), nil
+			},
+			want: `INSERT INTO "notes"."notes" ("body", "owner_id") VALUES ($1, $2), (DEFAULT, $3)`,
+		},
+		{
+			name: "upsert",
+			build: func() (Builder, error) {
+				return ForPostgres().WithOperation(op).WithTable("notes.notes").WithValues(args).WithUpsertOperation()
+			},
+			want: `INSERT INTO "notes"."notes" ("body", "owner_id") VALUES ($1, $2) ON CONFLICT ("owner_id") DO UPDATE SET "body" = EXCLUDED."body"`,
+		},
+		{
+			name: "update",
+			build: func() (Builder, error) {
+				return ForPostgres().WithOperation(op).WithTable("notes.notes").WithValues(args).WithUpdateOperation()
+			},
+			want: `UPDATE "notes"."notes" SET "body" = $1 WHERE "owner_id" = $2`,
+		},
+		{
+			name: "delete",
+			build: func() (Builder, error) {
+				return ForPostgres().WithOperation(op).WithTable("notes.notes").WithValues(args).WithDeleteOperation()
+			},
+			want: `DELETE FROM "notes"."notes" WHERE "owner_id" = $1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tt.build()
+			require.NoError(t, err)
+
+			req, err := b.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.Val)
+		})
+	}
+
+	// COPY получает колонки, а значения берутся по названиям полей
+	req, err := ForPostgres().WithOperation(op).WithTable("notes.notes").WithCopyThreshold(1).
+		WithBatchValues([]map[string]any{args}).WithCreateOperation().Build()
+	require.NoError(t, err)
+	assert.Equal(t, storage.Copy{Table: "notes.notes", Columns: []string{"body", "owner_id"}, Rows: [][]any{{"hello", 1}}}, req.Val)
+}
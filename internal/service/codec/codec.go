@@ -0,0 +1,189 @@
+package codec
+
+import (
+	"bytes"
+	"db-worker/internal/config/operation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// contentTypes - content-type, по которым отправитель выбирает формат тела сообщения.
+//
+//nolint:gochecknoglobals // используется только в этом модуле
+var contentTypes = map[string]operation.Codec{
+	"application/json":        operation.CodecJSON,
+	"application/msgpack":     operation.CodecMsgPack,
+	"application/x-msgpack":   operation.CodecMsgPack,
+	"application/vnd.msgpack": operation.CodecMsgPack,
+	"application/cbor":        operation.CodecCBOR,
+	"application/protobuf":    operation.CodecProtobuf,
+	"application/x-protobuf":  operation.CodecProtobuf,
+}
+
+// decodeFunc декодирует тело сообщения в поля.
+type decodeFunc func(data []byte) (map[string]any, error)
+
+// Registry декодирует тела сообщений соединения.
+// Формат выбирается по content-type сообщения, если он известен, иначе используется формат соединения.
+// Результат всегда имеет вид JSON-объекта: байты - строка base64, время - строка RFC3339,
+// поэтому поля операции одинаково работают с любым форматом. Числа JSON остаются json.Number,
+// числа остальных форматов приводятся к float64.
+type Registry struct {
+	codec operation.Codec // формат по умолчанию
+
+	protobuf struct {
+		descriptorSet string
+		message       string
+	}
+
+	codecs map[operation.Codec]decodeFunc
+}
+
+// Option определяет опции для Registry.
+type Option func(*Registry)
+
+// WithCodec устанавливает формат тела по умолчанию.
+func WithCodec(codec operation.Codec) Option {
+	return func(r *Registry) {
+		r.codec = codec
+	}
+}
+
+// WithProtobuf устанавливает схему protobuf-сообщений: путь к FileDescriptorSet и полное имя сообщения.
+func WithProtobuf(descriptorSet, message string) Option {
+	return func(r *Registry) {
+		r.protobuf.descriptorSet = descriptorSet
+		r.protobuf.message = message
+	}
+}
+
+// New создает новый экземпляр Registry.
+func New(opts ...Option) (*Registry, error) {
+	r := &Registry{}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.codec == "" {
+		r.codec = operation.CodecJSON
+	}
+
+	r.codecs = map[operation.Codec]decodeFunc{
+		operation.CodecJSON:    DecodeJSON,
+		operation.CodecMsgPack: decodeMsgPack,
+		operation.CodecCBOR:    decodeCBOR,
+	}
+
+	if r.protobuf.descriptorSet != "" {
+		decode, err := newProtobufDecoder(r.protobuf.descriptorSet, r.protobuf.message)
+		if err != nil {
+			return nil, err
+		}
+
+		r.codecs[operation.CodecProtobuf] = decode
+	}
+
+	if _, ok := r.codecs[r.codec]; !ok {
+		return nil, fmt.Errorf("codec: %s is not configured", r.codec)
+	}
+
+	return r, nil
+}
+
+// JSON возвращает Registry, который по умолчанию декодирует JSON.
+func JSON() *Registry {
+	r, _ := New() // без protobuf New не возвращает ошибку
+
+	return r
+}
+
+// Codec возвращает формат тела по умолчанию.
+func (r *Registry) Codec() operation.Codec {
+	return r.codec
+}
+
+// Decode декодирует тело сообщения. contentType может быть пустым.
+func (r *Registry) Decode(contentType string, data []byte) (map[string]any, error) {
+	codec := r.codecFor(contentType)
+
+	decode, ok := r.codecs[codec]
+	if !ok {
+		return nil, fmt.Errorf("codec: %s is not configured for connection", codec)
+	}
+
+	msg, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("codec: error decode %s: %w", codec, err)
+	}
+
+	return msg, nil
+}
+
+// codecFor возвращает формат по content-type. Неизвестный или пустой content-type - формат по умолчанию:
+// многие клиенты проставляют content-type, не задумываясь о нем (например, text/plain).
+func (r *Registry) codecFor(contentType string) operation.Codec {
+	if contentType == "" {
+		return r.codec
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return r.codec
+	}
+
+	if codec, ok := contentTypes[strings.ToLower(mediaType)]; ok {
+		return codec
+	}
+
+	return r.codec
+}
+
+var errNotObject = errors.New("payload is not an object")
+
+// DecodeJSON декодирует JSON-объект. Числа остаются json.Number с исходной записью: decimal и большие int64
+// не проходят через float64 и не теряют точность.
+func DecodeJSON(data []byte) (map[string]any, error) {
+	var msg map[string]any
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(&msg); err != nil {
+		return nil, err
+	}
+
+	// как json.Unmarshal: после объекта допустимы только пробелы
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid data after top-level value")
+	}
+
+	return msg, nil
+}
+
+func decodeMsgPack(data []byte) (map[string]any, error) {
+	var v any
+
+	if err := msgpack.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return normalizeObject(v)
+}
+
+func decodeCBOR(data []byte) (map[string]any, error) {
+	var v any
+
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return normalizeObject(v)
+}
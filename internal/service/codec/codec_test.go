@@ -0,0 +1,163 @@
+package codec
+
+import (
+	"db-worker/internal/config/operation"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestRegistry_Decode(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{
+		"id":    int64(42),
+		"name":  "note",
+		"price": 10.5,
+		"tags":  []any{"a", "b"},
+		"owner": map[string]any{"id": uint8(7)},
+		"raw":   []byte("hi"),
+	}
+
+	// так выглядит то же тело после encoding/json
+	expected := map[string]any{
+		"id":    float64(42),
+		"name":  "note",
+		"price": 10.5,
+		"tags":  []any{"a", "b"},
+		"owner": map[string]any{"id": float64(7)},
+		"raw":   "aGk=",
+	}
+
+	msgpackBody, err := msgpack.Marshal(payload)
+	require.NoError(t, err)
+
+	cborBody, err := cbor.Marshal(payload)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		codec       operation.Codec
+		contentType string
+		data        []byte
+		want        map[string]any
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: json by default",
+			data:    []byte(`{"id":42,"name":"note"}`),
+			want:    map[string]any{"id": json.Number("42"), "name": "note"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: json numbers keep precision",
+			data:    []byte(`{"amount":12345678901234567.89,"id":9007199254740993}`),
+			want:    map[string]any{"amount": json.Number("12345678901234567.89"), "id": json.Number("9007199254740993")},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: msgpack connection",
+			codec:   operation.CodecMsgPack,
+			data:    msgpackBody,
+			want:    expected,
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: cbor connection",
+			codec:   operation.CodecCBOR,
+			data:    cborBody,
+			want:    expected,
+			wantErr: require.NoError,
+		},
+		{
+			name:        "positive case: content type overrides connection codec",
+			contentType: "application/x-msgpack",
+			data:        msgpackBody,
+			want:        expected,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: content type with params",
+			codec:       operation.CodecCBOR,
+			contentType: "application/json; charset=utf-8",
+			data:        []byte(`{"id":1}`),
+			want:        map[string]any{"id": json.Number("1")},
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: unknown content type falls back to connection codec",
+			codec:       operation.CodecCBOR,
+			contentType: "text/plain",
+			data:        cborBody,
+			want:        expected,
+			wantErr:     require.NoError,
+		},
+		{
+			name:    "negative case: invalid json",
+			data:    []byte(`{"id":`),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: data after json object",
+			data:    []byte(`{"id":1} {"id":2}`),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: msgpack body is not an object",
+			codec:   operation.CodecMsgPack,
+			data:    mustMsgPack(t, []any{1, 2}),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: msgpack map with int keys",
+			codec:   operation.CodecMsgPack,
+			data:    mustMsgPack(t, map[int]string{1: "a"}),
+			wantErr: require.Error,
+		},
+		{
+			name:        "negative case: protobuf is not configured",
+			contentType: "application/protobuf",
+			data:        []byte{0x08, 0x01},
+			wantErr:     require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := New(WithCodec(tt.codec))
+			require.NoError(t, err)
+
+			got, err := r.Decode(tt.contentType, tt.data)
+			tt.wantErr(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(WithCodec(operation.CodecProtobuf))
+	require.Error(t, err)
+
+	_, err = New(WithProtobuf("not-exists.pb", "orders.v1.Order"))
+	require.Error(t, err)
+
+	r, err := New()
+	require.NoError(t, err)
+	require.Equal(t, operation.CodecJSON, r.Codec())
+}
+
+func mustMsgPack(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := msgpack.Marshal(v)
+	require.NoError(t, err)
+
+	return data
+}
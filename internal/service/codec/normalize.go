@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// normalizeObject приводит декодированное тело к виду JSON-объекта.
+func normalizeObject(v any) (map[string]any, error) {
+	normalized, err := normalize(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, ok := normalized.(map[string]any)
+	if !ok {
+		return nil, errNotObject
+	}
+
+	return msg, nil
+}
+
+// normalize приводит значение к типам, которые дает encoding/json: валидация и построение запросов
+// рассчитаны на них. Числа становятся float64, байты - строкой base64, время - строкой RFC3339.
+func normalize(v any) (any, error) {
+	switch val := v.(type) {
+	case nil, bool, string, float64:
+		return val, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val), nil
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case []any:
+		return normalizeList(val)
+	case map[string]any:
+		return normalizeMap(val)
+	case map[any]any:
+		obj := make(map[string]any, len(val))
+
+		for key, item := range val {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported key %v (%T): keys must be strings", key, key)
+			}
+
+			obj[name] = item
+		}
+
+		return normalizeMap(obj)
+	}
+
+	if f, ok := toFloat64(v); ok {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+func normalizeList(list []any) ([]any, error) {
+	items := make([]any, 0, len(list))
+
+	for i, item := range list {
+		n, err := normalize(item)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+
+		items = append(items, n)
+	}
+
+	return items, nil
+}
+
+func normalizeMap(obj map[string]any) (map[string]any, error) {
+	normalized := make(map[string]any, len(obj))
+
+	for key, item := range obj {
+		n, err := normalize(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		normalized[key] = n
+	}
+
+	return normalized, nil
+}
+
+// toFloat64 приводит число к float64, как это делает encoding/json.
+//
+//nolint:cyclop // один switch по типам чисел
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case big.Int:
+		f, _ := new(big.Float).SetInt(&n).Float64()
+		return f, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,127 @@
+package codec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newProtobufDecoder загружает схему сообщения из FileDescriptorSet
+// (protoc --descriptor_set_out=orders.pb --include_imports orders.proto).
+func newProtobufDecoder(descriptorSet, message string) (decodeFunc, error) {
+	data, err := os.ReadFile(descriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("codec: error read descriptor set: %w", err)
+	}
+
+	var set descriptorpb.FileDescriptorSet
+
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("codec: error unmarshal descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("codec: error build descriptors: %w", err)
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(message))
+	if err != nil {
+		return nil, fmt.Errorf("codec: message %q: %w", message, err)
+	}
+
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("codec: %q is not a message", message)
+	}
+
+	return func(data []byte) (map[string]any, error) {
+		msg := dynamicpb.NewMessage(md)
+
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return nil, err
+		}
+
+		return protoMessage(msg), nil
+	}, nil
+}
+
+// protoMessage переводит сообщение в поля с именами из .proto.
+// Скалярные поля без признака наличия попадают в результат всегда (со значением по умолчанию, как в proto3),
+// незаданные вложенные сообщения и поля oneof - пропускаются.
+func protoMessage(msg protoreflect.Message) map[string]any {
+	fields := msg.Descriptor().Fields()
+	obj := make(map[string]any, fields.Len())
+
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+
+		if fd.HasPresence() && !msg.Has(fd) {
+			continue
+		}
+
+		obj[string(fd.Name())] = protoField(fd, msg.Get(fd))
+	}
+
+	return obj
+}
+
+func protoField(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]any, 0, list.Len())
+
+		for i := range list.Len() {
+			items = append(items, protoValue(fd, list.Get(i)))
+		}
+
+		return items
+	case fd.IsMap():
+		obj := make(map[string]any, v.Map().Len())
+
+		v.Map().Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+			obj[key.String()] = protoValue(fd.MapValue(), val)
+			return true
+		})
+
+		return obj
+	default:
+		return protoValue(fd, v)
+	}
+}
+
+// protoValue переводит скалярное значение или вложенное сообщение. Перечисления становятся именем значения.
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+
+		return float64(v.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return protoMessage(v.Message())
+	default:
+		return nil
+	}
+}
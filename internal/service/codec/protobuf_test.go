@@ -0,0 +1,147 @@
+package codec
+
+import (
+	"db-worker/internal/config/operation"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// orderFile описывает:
+//
+//	syntax = "proto3";
+//	package orders.v1;
+//	enum Status { STATUS_UNKNOWN = 0; STATUS_PAID = 1; }
+//	message Item { string sku = 1; }
+//	message Order { int64 id = 1; string name = 2; Status status = 3; repeated string tags = 4; bytes raw = 5; Item item = 6; Item gift = 7; }
+func orderFile() *descriptorpb.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+
+		return f
+	}
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("orders.proto"),
+		Package: proto.String("orders.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("STATUS_UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("STATUS_PAID"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, "")},
+			},
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("status", 3, descriptorpb.FieldDescriptorProto_TYPE_ENUM, optional, ".orders.v1.Status"),
+					field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated, ""),
+					field("raw", 5, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+					field("item", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".orders.v1.Item"),
+					field("gift", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional, ".orders.v1.Item"),
+				},
+			},
+		},
+	}
+}
+
+// writeDescriptorSet сохраняет FileDescriptorSet со схемой заказа и возвращает путь к нему и дескриптор Order.
+func writeDescriptorSet(t *testing.T) (string, protoreflect.MessageDescriptor) {
+	t.Helper()
+
+	file := orderFile()
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "orders.pb")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+
+	return path, fd.Messages().ByName("Order")
+}
+
+func TestRegistry_DecodeProtobuf(t *testing.T) {
+	t.Parallel()
+
+	path, md := writeDescriptorSet(t)
+
+	order := dynamicpb.NewMessage(md)
+	order.Set(md.Fields().ByName("id"), protoreflect.ValueOfInt64(42))
+	order.Set(md.Fields().ByName("status"), protoreflect.ValueOfEnum(1))
+	order.Set(md.Fields().ByName("raw"), protoreflect.ValueOfBytes([]byte("hi")))
+
+	tags := order.Mutable(md.Fields().ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("new"))
+
+	item := order.Mutable(md.Fields().ByName("item")).Message()
+	item.Set(item.Descriptor().Fields().ByName("sku"), protoreflect.ValueOfString("A-1"))
+
+	data, err := proto.Marshal(order)
+	require.NoError(t, err)
+
+	r, err := New(WithCodec(operation.CodecProtobuf), WithProtobuf(path, "orders.v1.Order"))
+	require.NoError(t, err)
+
+	got, err := r.Decode("", data)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id":     float64(42),
+		"name":   "", // скалярные поля proto3 всегда имеют значение
+		"status": "STATUS_PAID",
+		"tags":   []any{"new"},
+		"raw":    "aGk=",
+		"item":   map[string]any{"sku": "A-1"},
+	}, got)
+
+	_, err = r.Decode("", []byte{0xff, 0xff})
+	require.Error(t, err)
+
+	// JSON по content-type продолжает работать для protobuf-соединения
+	got, err = r.Decode("application/json", []byte(`{"id":1}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": json.Number("1")}, got)
+}
+
+func TestNew_ProtobufUnknownMessage(t *testing.T) {
+	t.Parallel()
+
+	path, _ := writeDescriptorSet(t)
+
+	_, err := New(WithProtobuf(path, "orders.v1.Refund"))
+	require.Error(t, err)
+
+	_, err = New(WithProtobuf(path, "orders.v1.Status"))
+	require.Error(t, err)
+}
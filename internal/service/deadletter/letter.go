@@ -0,0 +1,64 @@
+package deadletter
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stage - этап обработки, на котором сообщение не удалось обработать.
+type Stage string
+
+const (
+	// StageValidation - сообщение не прошло валидацию.
+	StageValidation Stage = "validation"
+	// StageBuild - по сообщению не удалось построить запросы.
+	StageBuild Stage = "build"
+	// StageExec - не удалось выполнить транзакцию.
+	StageExec Stage = "exec"
+	// StageRoute - сообщение не подошло ни одной операции соединения.
+	StageRoute Stage = "route"
+	// StageDecode - тело сообщения не удалось декодировать.
+	StageDecode Stage = "decode"
+)
+
+// Letter - необработанное сообщение вместе с причиной ошибки.
+type Letter struct {
+	Operation string         // название операции
+	Stage     Stage          // этап, на котором произошла ошибка
+	Error     string         // текст ошибки
+	IDs       []uuid.UUID    // айди сообщений в messages.messages
+	Payload   map[string]any // исходное сообщение
+
+	// Raw и ContentType - исходное тело, которое не удалось декодировать (StageDecode), и его формат.
+	// Если Raw задан, Payload пустой.
+	Raw         []byte
+	ContentType string
+
+	FailedAt time.Time // время ошибки
+}
+
+// payload возвращает сообщение для сохранения в JSON. Тело, которое не удалось декодировать,
+// сохраняется строкой base64 вместе с форматом.
+func (l Letter) payload() any {
+	if l.Raw == nil {
+		return l.Payload
+	}
+
+	return map[string]any{
+		"raw":          base64.StdEncoding.EncodeToString(l.Raw),
+		"content_type": l.ContentType,
+	}
+}
+
+// messageIDs возвращает айди сообщений в виде строк.
+func (l Letter) messageIDs() []string {
+	ids := make([]string, 0, len(l.IDs))
+
+	for _, id := range l.IDs {
+		ids = append(ids, id.String())
+	}
+
+	return ids
+}
@@ -0,0 +1,118 @@
+package deadletter
+
+import (
+	"context"
+	"database/sql"
+	"db-worker/internal/service/builder"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Postgres сохраняет необработанные сообщения в таблицу PostgreSQL.
+type Postgres struct {
+	addr  string
+	table string
+	db    *sql.DB
+
+	insertTimeout int
+}
+
+// PostgresOption определяет опции для Postgres.
+type PostgresOption func(*Postgres)
+
+// WithAddr устанавливает адрес базы данных.
+func WithAddr(addr string) PostgresOption {
+	return func(p *Postgres) {
+		p.addr = addr
+	}
+}
+
+// WithTable устанавливает таблицу для необработанных сообщений.
+func WithTable(table string) PostgresOption {
+	return func(p *Postgres) {
+		p.table = table
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания вставки (мс).
+func WithInsertTimeout(insertTimeout int) PostgresOption {
+	return func(p *Postgres) {
+		p.insertTimeout = insertTimeout
+	}
+}
+
+// NewPostgres создает новый экземпляр Postgres.
+func NewPostgres(opts ...PostgresOption) (*Postgres, error) {
+	p := &Postgres{}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.addr == "" {
+		return nil, errors.New("dead letter: addr is required")
+	}
+
+	if p.table == "" {
+		return nil, errors.New("dead letter: table is required")
+	}
+
+	if p.insertTimeout == 0 {
+		return nil, errors.New("dead letter: insert timeout is required")
+	}
+
+	db, err := sql.Open("postgres", p.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dead letter: error opening db: %w", err)
+	}
+
+	p.db = db
+
+	return p, nil
+}
+
+// Run проверяет соединение с базой данных.
+func (p *Postgres) Run(ctx context.Context) error {
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("dead letter: error pinging db: %w", err)
+	}
+
+	logrus.WithField("table", p.table).Info("dead letter: successfully connected postgres")
+
+	return nil
+}
+
+// Publish сохраняет необработанное сообщение.
+func (p *Postgres) Publish(ctx context.Context, letter Letter) error {
+	payload, err := json.Marshal(letter.payload())
+	if err != nil {
+		return fmt.Errorf("dead letter: error marshaling payload: %w", err)
+	}
+
+	ids, err := json.Marshal(letter.messageIDs())
+	if err != nil {
+		return fmt.Errorf("dead letter: error marshaling message ids: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.insertTimeout)*time.Millisecond)
+	defer cancel()
+
+	query := "INSERT INTO " + builder.QuoteTable(p.table) + " (id, operation, stage, error, message_ids, payload, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+
+	_, err = p.db.ExecContext(ctx, query, uuid.New(), letter.Operation, string(letter.Stage), letter.Error, ids, payload, letter.FailedAt)
+	if err != nil {
+		return fmt.Errorf("dead letter: error inserting message: %w", err)
+	}
+
+	return nil
+}
+
+// Stop закрывает соединение с базой данных.
+func (p *Postgres) Stop(_ context.Context) error {
+	return p.db.Close()
+}
@@ -0,0 +1,125 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPostgres(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []PostgresOption
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []PostgresOption{WithAddr("postgres://localhost:5432/test"), WithTable("messages.dead_letters"), WithInsertTimeout(1)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: addr is empty",
+			opts:    []PostgresOption{WithTable("messages.dead_letters"), WithInsertTimeout(1)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: table is empty",
+			opts:    []PostgresOption{WithAddr("postgres://localhost:5432/test"), WithInsertTimeout(1)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    []PostgresOption{WithAddr("postgres://localhost:5432/test"), WithTable("messages.dead_letters")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewPostgres(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestPostgres_Publish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "messages"."dead_letters" \(id, operation, stage, error, message_ids, payload, created_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+					WithArgs(sqlmock.AnyArg(), "create_notes", "validation", "field \"text\" is required",
+						[]byte(`["8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60","0f9e8d7c-6b5a-4c3d-8e1f-a0b1c2d3e4f5"]`),
+						[]byte(`{"user_id":1}`), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: exec error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "messages"."dead_letters"`).WillReturnError(errors.New("exec error"))
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			p := &Postgres{
+				db:            db,
+				table:         "messages.dead_letters",
+				insertTimeout: 1000,
+			}
+
+			tt.wantErr(t, p.Publish(context.Background(), testLetter()))
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgres_Publish_Raw(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	// тело, которое не удалось декодировать, сохраняется строкой base64 вместе с форматом
+	mock.ExpectExec(`INSERT INTO "messages"."dead_letters"`).
+		WithArgs(sqlmock.AnyArg(), "", "decode", "codec: error decode msgpack: EOF", []byte(`[]`),
+			[]byte(`{"content_type":"application/msgpack","raw":"gaI="}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	p := &Postgres{
+		db:            db,
+		table:         "messages.dead_letters",
+		insertTimeout: 1000,
+	}
+
+	require.NoError(t, p.Publish(context.Background(), decodeLetter()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,127 @@
+package deadletter
+
+import (
+	"context"
+	"db-worker/internal/service/publisher"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Заголовки, с которыми публикуется необработанное сообщение.
+const (
+	HeaderOperation  = "x-operation"
+	HeaderStage      = "x-failure-stage"
+	HeaderError      = "x-error"
+	HeaderMessageIDs = "x-message-ids" // айди сообщений через запятую
+	HeaderFailedAt   = "x-failed-at"
+)
+
+// Rabbit публикует необработанные сообщения в exchange RabbitMQ.
+// Тело сообщения - исходное сообщение в JSON (или исходное тело, если его не удалось декодировать),
+// причина ошибки - в заголовках.
+type Rabbit struct {
+	address    string
+	exchange   string
+	routingKey string
+
+	publisher *publisher.Rabbit
+}
+
+// RabbitOption определяет опции для Rabbit.
+type RabbitOption func(*Rabbit)
+
+// WithAddress устанавливает адрес RabbitMQ.
+func WithAddress(address string) RabbitOption {
+	return func(r *Rabbit) {
+		r.address = address
+	}
+}
+
+// WithExchange устанавливает exchange, в который публикуются сообщения. Exchange должен существовать.
+func WithExchange(exchange string) RabbitOption {
+	return func(r *Rabbit) {
+		r.exchange = exchange
+	}
+}
+
+// WithRoutingKey устанавливает routing key, с которым публикуются сообщения.
+func WithRoutingKey(routingKey string) RabbitOption {
+	return func(r *Rabbit) {
+		r.routingKey = routingKey
+	}
+}
+
+// NewRabbit создает новый экземпляр Rabbit.
+func NewRabbit(opts ...RabbitOption) (*Rabbit, error) {
+	r := &Rabbit{}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	pub, err := publisher.NewRabbit(
+		publisher.WithName("dead letter"),
+		publisher.WithAddress(r.address),
+		publisher.WithExchange(r.exchange),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.publisher = pub
+
+	return r, nil
+}
+
+// Run соединяется с RabbitMQ.
+func (r *Rabbit) Run(ctx context.Context) error {
+	return r.publisher.Run(ctx)
+}
+
+// Publish публикует необработанное сообщение. Если соединение было потеряно - переподключается один раз.
+func (r *Rabbit) Publish(_ context.Context, letter Letter) error {
+	msg, err := r.publishing(letter)
+	if err != nil {
+		return err
+	}
+
+	return r.publisher.Publish(r.routingKey, msg)
+}
+
+// publishing формирует сообщение для публикации.
+// Тело, которое не удалось декодировать, публикуется как есть, с исходным content-type.
+func (r *Rabbit) publishing(letter Letter) (amqp.Publishing, error) {
+	body, contentType := letter.Raw, letter.ContentType
+
+	if body == nil {
+		payload, err := json.Marshal(letter.Payload)
+		if err != nil {
+			return amqp.Publishing{}, fmt.Errorf("dead letter: error marshaling payload: %w", err)
+		}
+
+		body, contentType = payload, "application/json"
+	}
+
+	return amqp.Publishing{
+		Headers: amqp.Table{
+			HeaderOperation:  letter.Operation,
+			HeaderStage:      string(letter.Stage),
+			HeaderError:      letter.Error,
+			HeaderMessageIDs: strings.Join(letter.messageIDs(), ","),
+			HeaderFailedAt:   letter.FailedAt.UTC().Format(time.RFC3339Nano),
+		},
+		ContentType:  contentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    letter.FailedAt,
+		Body:         body,
+	}, nil
+}
+
+// Stop закрывает соединение с RabbitMQ.
+func (r *Rabbit) Stop(ctx context.Context) error {
+	return r.publisher.Stop(ctx)
+}
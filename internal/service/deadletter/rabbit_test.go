@@ -0,0 +1,151 @@
+package deadletter
+
+import (
+	"context"
+	"db-worker/internal/service/publisher"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher запоминает опубликованные сообщения.
+type fakePublisher struct {
+	published []amqp.Publishing
+	keys      []string
+}
+
+func (p *fakePublisher) Publish(_, key string, _, _ bool, msg amqp.Publishing) error {
+	p.published = append(p.published, msg)
+	p.keys = append(p.keys, key)
+
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+// withChannel подменяет подключение к RabbitMQ: сообщения публикуются в ch.
+func withChannel(t *testing.T, r *Rabbit, ch publisher.Channel) {
+	t.Helper()
+
+	pub, err := publisher.NewRabbit(
+		publisher.WithAddress(r.address),
+		publisher.WithExchange(r.exchange),
+		publisher.WithDial(func(_ string) (publisher.Channel, io.Closer, error) {
+			return ch, nil, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	r.publisher = pub
+}
+
+func testLetter() Letter {
+	return Letter{
+		Operation: "create_notes",
+		Stage:     StageValidation,
+		Error:     "field \"text\" is required",
+		IDs:       []uuid.UUID{uuid.MustParse("8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60"), uuid.MustParse("0f9e8d7c-6b5a-4c3d-8e1f-a0b1c2d3e4f5")},
+		Payload:   map[string]any{"user_id": 1},
+		FailedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// decodeLetter - тело, которое не удалось декодировать.
+func decodeLetter() Letter {
+	return Letter{
+		Stage:       StageDecode,
+		Error:       "codec: error decode msgpack: EOF",
+		Raw:         []byte{0x81, 0xa2},
+		ContentType: "application/msgpack",
+		FailedAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNewRabbit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []RabbitOption
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []RabbitOption{WithAddress("amqp://localhost:5672"), WithExchange("notes.dlx"), WithRoutingKey("create")},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: address is empty",
+			opts:    []RabbitOption{WithExchange("notes.dlx")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: exchange is empty",
+			opts:    []RabbitOption{WithAddress("amqp://localhost:5672")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRabbit(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestRabbit_Publish(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRabbit(WithAddress("amqp://localhost:5672"), WithExchange("notes.dlx"), WithRoutingKey("create"))
+	require.NoError(t, err)
+
+	ch := &fakePublisher{}
+	withChannel(t, r, ch)
+
+	require.NoError(t, r.Publish(context.Background(), testLetter()))
+
+	require.Len(t, ch.published, 1)
+
+	msg := ch.published[0]
+	assert.Equal(t, "create", ch.keys[0])
+	assert.JSONEq(t, `{"user_id": 1}`, string(msg.Body))
+	assert.Equal(t, "application/json", msg.ContentType)
+	assert.Equal(t, amqp.Persistent, msg.DeliveryMode)
+	assert.Equal(t, amqp.Table{
+		HeaderOperation:  "create_notes",
+		HeaderStage:      "validation",
+		HeaderError:      "field \"text\" is required",
+		HeaderMessageIDs: "8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60,0f9e8d7c-6b5a-4c3d-8e1f-a0b1c2d3e4f5",
+		HeaderFailedAt:   "2025-01-02T03:04:05Z",
+	}, msg.Headers)
+}
+
+func TestRabbit_Publish_Raw(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRabbit(WithAddress("amqp://localhost:5672"), WithExchange("notes.dlx"))
+	require.NoError(t, err)
+
+	ch := &fakePublisher{}
+	withChannel(t, r, ch)
+
+	require.NoError(t, r.Publish(context.Background(), decodeLetter()))
+
+	require.Len(t, ch.published, 1)
+
+	// исходное тело публикуется как есть, с исходным content-type
+	msg := ch.published[0]
+	assert.Equal(t, []byte{0x81, 0xa2}, msg.Body)
+	assert.Equal(t, "application/msgpack", msg.ContentType)
+	assert.Equal(t, "decode", msg.Headers[HeaderStage])
+}
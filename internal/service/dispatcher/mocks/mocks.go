@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	deadletter "db-worker/internal/service/deadletter"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockdeadLetterPublisher is a mock of deadLetterPublisher interface.
+type MockdeadLetterPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockdeadLetterPublisherMockRecorder
+}
+
+// MockdeadLetterPublisherMockRecorder is the mock recorder for MockdeadLetterPublisher.
+type MockdeadLetterPublisherMockRecorder struct {
+	mock *MockdeadLetterPublisher
+}
+
+// NewMockdeadLetterPublisher creates a new mock instance.
+func NewMockdeadLetterPublisher(ctrl *gomock.Controller) *MockdeadLetterPublisher {
+	mock := &MockdeadLetterPublisher{ctrl: ctrl}
+	mock.recorder = &MockdeadLetterPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdeadLetterPublisher) EXPECT() *MockdeadLetterPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockdeadLetterPublisher) Publish(ctx context.Context, letter deadletter.Letter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, letter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockdeadLetterPublisherMockRecorder) Publish(ctx, letter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockdeadLetterPublisher)(nil).Publish), ctx, letter)
+}
+
+// MockdecodeMetrics is a mock of decodeMetrics interface.
+type MockdecodeMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockdecodeMetricsMockRecorder
+}
+
+// MockdecodeMetricsMockRecorder is the mock recorder for MockdecodeMetrics.
+type MockdecodeMetricsMockRecorder struct {
+	mock *MockdecodeMetrics
+}
+
+// NewMockdecodeMetrics creates a new mock instance.
+func NewMockdecodeMetrics(ctrl *gomock.Controller) *MockdecodeMetrics {
+	mock := &MockdecodeMetrics{ctrl: ctrl}
+	mock.recorder = &MockdecodeMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdecodeMetrics) EXPECT() *MockdecodeMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddDecodeErrors mocks base method.
+func (m *MockdecodeMetrics) AddDecodeErrors(name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddDecodeErrors", name)
+}
+
+// AddDecodeErrors indicates an expected call of AddDecodeErrors.
+func (mr *MockdecodeMetricsMockRecorder) AddDecodeErrors(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDecodeErrors", reflect.TypeOf((*MockdecodeMetrics)(nil).AddDecodeErrors), name)
+}
@@ -0,0 +1,89 @@
+package dispatcher
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"strings"
+)
+
+// route - операция и правило, по которому она получает сообщения соединения.
+type route struct {
+	operation string
+	match     *operation.Match // nil - все сообщения
+	field     operation.Path   // путь к полю match.field от корня сообщения
+	pattern   []string         // шаблон ключа маршрутизации по словам
+	msgChan   chan worker.Message
+}
+
+func newRoute(operationName string, match *operation.Match) *route {
+	r := &route{
+		operation: operationName,
+		match:     match,
+		msgChan:   make(chan worker.Message),
+	}
+
+	if match != nil && match.RoutingKey != "" {
+		r.pattern = splitKey(match.RoutingKey)
+	}
+
+	if match != nil && match.Field != "" {
+		// путь проверяется при загрузке конфигурации
+		r.field, _ = operation.ParsePath(match.Field)
+	}
+
+	return r
+}
+
+// matches проверяет, подходит ли сообщение операции. Поле match ищется от корня сообщения, а не от root операции:
+// признак операции может лежать вне тела модели, например {"type": "create", "data": {...}}.
+func (r *route) matches(msg worker.Message) bool {
+	if r.match == nil {
+		return true
+	}
+
+	if r.field != nil {
+		value, ok := r.field.Lookup(msg.Data)
+		if !ok || fmt.Sprint(value) != r.match.Value {
+			return false
+		}
+	}
+
+	if r.pattern != nil && !matchWords(r.pattern, splitKey(msg.Meta.RoutingKey)) {
+		return false
+	}
+
+	return true
+}
+
+// matchWords сравнивает ключ маршрутизации с шаблоном как topic exchange RabbitMQ:
+// * - ровно одно слово, # - любое количество слов (в том числе ни одного).
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+
+		return false
+	case "*":
+		return len(words) > 0 && matchWords(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchWords(pattern[1:], words[1:])
+	}
+}
+
+// splitKey разбивает ключ маршрутизации на слова. У пустого ключа нет слов.
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+
+	return strings.Split(key, ".")
+}
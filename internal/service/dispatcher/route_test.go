@@ -0,0 +1,128 @@
+package dispatcher
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_Matches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		match *operation.Match
+		msg   worker.Message
+		want  bool
+	}{
+		{
+			name: "no match rule",
+			msg:  worker.Message{Data: map[string]any{"id": 1}},
+			want: true,
+		},
+		{
+			name:  "field value",
+			match: &operation.Match{Field: "operation", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"operation": "create"}},
+			want:  true,
+		},
+		{
+			name:  "field value is not string",
+			match: &operation.Match{Field: "version", Value: "2"},
+			msg:   worker.Message{Data: map[string]any{"version": float64(2)}},
+			want:  true,
+		},
+		{
+			name:  "field value differs",
+			match: &operation.Match{Field: "operation", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"operation": "delete"}},
+			want:  false,
+		},
+		{
+			name:  "field is missing",
+			match: &operation.Match{Field: "operation", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"id": 1}},
+			want:  false,
+		},
+		{
+			name:  "field path",
+			match: &operation.Match{Field: "meta.type", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"meta": map[string]any{"type": "create"}}},
+			want:  true,
+		},
+		{
+			// root операции не учитывается: поле внутри конверта задается полным путем
+			name:  "field inside operation root",
+			match: &operation.Match{Field: "type", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"data": map[string]any{"type": "create"}}},
+			want:  false,
+		},
+		{
+			name:  "field path inside operation root",
+			match: &operation.Match{Field: "data.type", Value: "create"},
+			msg:   worker.Message{Data: map[string]any{"data": map[string]any{"type": "create"}}},
+			want:  true,
+		},
+		{
+			name:  "routing key",
+			match: &operation.Match{RoutingKey: "notes.*.create"},
+			msg:   worker.Message{Meta: worker.Metadata{RoutingKey: "notes.v1.create"}},
+			want:  true,
+		},
+		{
+			name:  "routing key differs",
+			match: &operation.Match{RoutingKey: "notes.*.create"},
+			msg:   worker.Message{Meta: worker.Metadata{RoutingKey: "notes.v1.delete"}},
+			want:  false,
+		},
+		{
+			name:  "field and routing key",
+			match: &operation.Match{Field: "operation", Value: "create", RoutingKey: "notes.#"},
+			msg:   worker.Message{Data: map[string]any{"operation": "create"}, Meta: worker.Metadata{RoutingKey: "users.create"}},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, newRoute("op", tt.match).matches(tt.msg))
+		})
+	}
+}
+
+func TestMatchWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{pattern: "notes.create", key: "notes.create", want: true},
+		{pattern: "notes.create", key: "notes.delete", want: false},
+		{pattern: "notes.create", key: "notes.create.v1", want: false},
+		{pattern: "notes.*", key: "notes.create", want: true},
+		{pattern: "notes.*", key: "notes", want: false},
+		{pattern: "notes.*", key: "notes.create.v1", want: false},
+		{pattern: "notes.#", key: "notes", want: true},
+		{pattern: "notes.#", key: "notes.create.v1", want: true},
+		{pattern: "#.create", key: "notes.v1.create", want: true},
+		{pattern: "#.create", key: "notes.v1.delete", want: false},
+		{pattern: "*.*.create", key: "notes.v1.create", want: true},
+		{pattern: "#", key: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			r := newRoute("op", &operation.Match{RoutingKey: tt.pattern})
+
+			assert.Equal(t, tt.want, matchWords(r.pattern, splitKey(tt.key)))
+		})
+	}
+}
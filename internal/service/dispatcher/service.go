@@ -0,0 +1,292 @@
+package dispatcher
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/deadletter"
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Service читает сообщения соединения и передает каждое в первую операцию, правило match которой подошло сообщению.
+// Так одно соединение (например, одна очередь) может нести create, update и delete одной модели.
+type Service struct {
+	name    string              // название соединения
+	msgChan chan worker.Message // сообщения соединения
+
+	routes []*route
+
+	unmatched  operation.UnmatchedAction // что делать с сообщениями, которые не подошли ни одной операции
+	deadLetter deadLetterPublisher       // куда отправлять такие сообщения (unmatched: dead_letter)
+
+	decodeDeadLetter deadLetterPublisher // куда отправлять сообщения, тело которых не удалось декодировать
+	metrics          decodeMetrics
+
+	quitChan chan struct{}
+}
+
+// deadLetterPublisher отправляет сообщения, которые не удалось обработать, во внешнее хранилище.
+//
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type deadLetterPublisher interface {
+	Publish(ctx context.Context, letter deadletter.Letter) error
+}
+
+// decodeMetrics считает сообщения соединения, тело которых не удалось декодировать.
+type decodeMetrics interface {
+	AddDecodeErrors(name string)
+}
+
+// Option определяет опции для сервиса.
+type Option func(*Service)
+
+// WithName устанавливает название соединения.
+func WithName(name string) Option {
+	return func(s *Service) {
+		s.name = name
+	}
+}
+
+// WithMsgChan устанавливает канал, из которого читаются сообщения соединения.
+func WithMsgChan(msgChan chan worker.Message) Option {
+	return func(s *Service) {
+		s.msgChan = msgChan
+	}
+}
+
+// WithRoute добавляет операцию. Правила проверяются в порядке добавления.
+// Операция без правила (match == nil) получает все сообщения, которые не подошли операциям перед ней.
+func WithRoute(operationName string, match *operation.Match) Option {
+	return func(s *Service) {
+		s.routes = append(s.routes, newRoute(operationName, match))
+	}
+}
+
+// WithUnmatched устанавливает, что делать с сообщениями, которые не подошли ни одной операции.
+func WithUnmatched(action operation.UnmatchedAction) Option {
+	return func(s *Service) {
+		s.unmatched = action
+	}
+}
+
+// WithDeadLetter устанавливает, куда отправлять сообщения, которые не подошли ни одной операции.
+func WithDeadLetter(deadLetter deadLetterPublisher) Option {
+	return func(s *Service) {
+		s.deadLetter = deadLetter
+	}
+}
+
+// WithDecodeDeadLetter устанавливает, куда отправлять сообщения, тело которых не удалось декодировать.
+// Если не задан - такие сообщения только считаются и подтверждаются как невалидные.
+func WithDecodeDeadLetter(deadLetter deadLetterPublisher) Option {
+	return func(s *Service) {
+		s.decodeDeadLetter = deadLetter
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для подсчета сообщений, тело которых не удалось декодировать.
+func WithMetrics(metrics decodeMetrics) Option {
+	return func(s *Service) {
+		s.metrics = metrics
+	}
+}
+
+// New создает новый экземпляр сервиса.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.name == "" {
+		return nil, errors.New("dispatcher: name is required")
+	}
+
+	if s.msgChan == nil {
+		return nil, errors.New("dispatcher: message channel is required")
+	}
+
+	if len(s.routes) == 0 {
+		return nil, errors.New("dispatcher: at least one route is required")
+	}
+
+	if s.unmatched == "" {
+		s.unmatched = operation.UnmatchedActionFail
+	}
+
+	if s.unmatched == operation.UnmatchedActionDeadLetter && s.deadLetter == nil {
+		return nil, errors.New("dispatcher: dead letter is required for unmatched action dead_letter")
+	}
+
+	s.quitChan = make(chan struct{})
+
+	return s, nil
+}
+
+// MsgChan возвращает канал сообщений операции. Если операции нет среди маршрутов - nil.
+func (s *Service) MsgChan(operationName string) chan worker.Message {
+	for _, r := range s.routes {
+		if r.operation == operationName {
+			return r.msgChan
+		}
+	}
+
+	return nil
+}
+
+// Run читает сообщения соединения и передает их в операции.
+func (s *Service) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name":      s.name,
+		"routes":    len(s.routes),
+		"unmatched": s.unmatched,
+	}).Info("dispatcher: start dispatch messages")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.quitChan:
+			return nil
+		case msg, ok := <-s.msgChan:
+			if !ok {
+				logrus.WithField("name", s.name).Info("dispatcher: message channel closed")
+
+				return nil
+			}
+
+			if !s.dispatch(ctx, msg) {
+				return nil
+			}
+		}
+	}
+}
+
+// Stop прекращает передачу сообщений.
+func (s *Service) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	return nil
+}
+
+// dispatch передает сообщение в первую подходящую операцию. Возвращает false, если работа завершена.
+func (s *Service) dispatch(ctx context.Context, msg worker.Message) bool {
+	if msg.DecodeErr != nil {
+		s.handleDecodeError(ctx, msg)
+		return true
+	}
+
+	for _, r := range s.routes {
+		if !r.matches(msg) {
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"name":        s.name,
+			"operation":   r.operation,
+			"routing_key": msg.Meta.RoutingKey,
+		}).Debug("dispatcher: message matched")
+
+		select {
+		case r.msgChan <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-s.quitChan:
+			return false
+		}
+	}
+
+	s.handleUnmatched(ctx, msg)
+
+	return true
+}
+
+// handleUnmatched завершает сообщение, которое не подошло ни одной операции.
+func (s *Service) handleUnmatched(ctx context.Context, msg worker.Message) {
+	log := logrus.WithFields(logrus.Fields{
+		"name":        s.name,
+		"routing_key": msg.Meta.RoutingKey,
+		"action":      s.unmatched,
+	})
+
+	cause := fmt.Errorf("connection %s: %w", s.name, worker.ErrUnmatched)
+
+	switch s.unmatched {
+	case operation.UnmatchedActionDrop:
+		log.Warn("dispatcher: drop unmatched message")
+	case operation.UnmatchedActionDeadLetter:
+		letter := deadletter.Letter{
+			Stage:    deadletter.StageRoute,
+			Error:    cause.Error(),
+			Payload:  msg.Data,
+			FailedAt: time.Now(),
+		}
+
+		if err := s.deadLetter.Publish(ctx, letter); err != nil {
+			log.WithError(err).Error("dispatcher: error publish dead letter")
+
+			// сообщение не сохранено: пусть источник доставит его повторно
+			msg.Done(worker.Result{Err: fmt.Errorf("%w: error publish dead letter: %w", cause, err)})
+
+			return
+		}
+
+		log.Info("dispatcher: unmatched message sent to dead letter")
+	default:
+		log.Error("dispatcher: unmatched message")
+
+		msg.Done(worker.Result{Err: cause})
+
+		return
+	}
+
+	// повторная доставка не поможет: сообщение подтверждается как невалидное
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, cause)})
+}
+
+// handleDecodeError завершает сообщение, тело которого не удалось декодировать: такое сообщение не подойдет
+// ни одной операции, и повторная доставка не поможет.
+func (s *Service) handleDecodeError(ctx context.Context, msg worker.Message) {
+	log := logrus.WithError(msg.DecodeErr).WithFields(logrus.Fields{
+		"name":         s.name,
+		"routing_key":  msg.Meta.RoutingKey,
+		"content_type": msg.Meta.ContentType,
+	})
+
+	if s.metrics != nil {
+		s.metrics.AddDecodeErrors(s.name)
+	}
+
+	cause := fmt.Errorf("connection %s: %w", s.name, msg.DecodeErr)
+
+	if s.decodeDeadLetter != nil {
+		letter := deadletter.Letter{
+			Stage:       deadletter.StageDecode,
+			Error:       cause.Error(),
+			Raw:         msg.Raw,
+			ContentType: msg.Meta.ContentType,
+			FailedAt:    time.Now(),
+		}
+
+		if err := s.decodeDeadLetter.Publish(ctx, letter); err != nil {
+			log.WithField("dead_letter_error", err).Error("dispatcher: error publish dead letter")
+
+			// тело не сохранено: пусть источник доставит его повторно
+			msg.Done(worker.Result{Err: fmt.Errorf("%w: error publish dead letter: %w", cause, err)})
+
+			return
+		}
+
+		log.Info("dispatcher: undecodable message sent to dead letter")
+	} else {
+		log.Error("dispatcher: drop undecodable message")
+	}
+
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, cause)})
+}
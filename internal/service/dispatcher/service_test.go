@@ -0,0 +1,359 @@
+package dispatcher
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/deadletter"
+	"db-worker/internal/service/dispatcher/mocks"
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resultNotifier запоминает итог обработки сообщения.
+type resultNotifier struct {
+	once sync.Once
+	done chan worker.Result
+}
+
+func newResultNotifier() *resultNotifier {
+	return &resultNotifier{done: make(chan worker.Result, 1)}
+}
+
+func (n *resultNotifier) Persisted(_ []uuid.UUID, _ error) {}
+
+func (n *resultNotifier) Done(res worker.Result) {
+	n.once.Do(func() {
+		n.done <- res
+	})
+}
+
+func (n *resultNotifier) result(t *testing.T) worker.Result {
+	t.Helper()
+
+	select {
+	case res := <-n.done:
+		return res
+	case <-time.After(time.Second):
+		require.FailNow(t, "message not done")
+	}
+
+	return worker.Result{}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantUnmatched operation.UnmatchedAction
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:          "positive case: default unmatched action",
+			opts:          []Option{WithName("notes"), WithMsgChan(make(chan worker.Message)), WithRoute("create", nil)},
+			wantUnmatched: operation.UnmatchedActionFail,
+			wantErr:       require.NoError,
+		},
+		{
+			name: "positive case: dead letter",
+			opts: []Option{
+				WithName("notes"),
+				WithMsgChan(make(chan worker.Message)),
+				WithRoute("create", &operation.Match{Field: "operation", Value: "create"}),
+				WithUnmatched(operation.UnmatchedActionDeadLetter),
+				WithDeadLetter(mocks.NewMockdeadLetterPublisher(ctrl)),
+			},
+			wantUnmatched: operation.UnmatchedActionDeadLetter,
+			wantErr:       require.NoError,
+		},
+		{
+			name:    "negative case: name is empty",
+			opts:    []Option{WithMsgChan(make(chan worker.Message)), WithRoute("create", nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: message channel is nil",
+			opts:    []Option{WithName("notes"), WithRoute("create", nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: no routes",
+			opts:    []Option{WithName("notes"), WithMsgChan(make(chan worker.Message))},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: dead letter without publisher",
+			opts: []Option{
+				WithName("notes"),
+				WithMsgChan(make(chan worker.Message)),
+				WithRoute("create", nil),
+				WithUnmatched(operation.UnmatchedActionDeadLetter),
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantUnmatched, got.unmatched)
+		})
+	}
+}
+
+func runDispatcher(t *testing.T, opts ...Option) (*Service, chan worker.Message) {
+	t.Helper()
+
+	in := make(chan worker.Message)
+
+	s, err := New(append([]Option{WithName("notes"), WithMsgChan(in)}, opts...)...)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, s.Run(context.Background()))
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, s.Stop(context.Background()))
+		<-done
+	})
+
+	return s, in
+}
+
+func receive(t *testing.T, ch chan worker.Message) worker.Message {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		require.FailNow(t, "message not received")
+	}
+
+	return worker.Message{}
+}
+
+func TestRun_Routes(t *testing.T) {
+	t.Parallel()
+
+	s, in := runDispatcher(t,
+		WithRoute("create", &operation.Match{Field: "operation", Value: "create"}),
+		WithRoute("delete", &operation.Match{RoutingKey: "notes.delete"}),
+		WithRoute("other", nil),
+	)
+
+	in <- worker.Message{Data: map[string]any{"operation": "create", "id": 1}}
+	assert.Equal(t, map[string]any{"operation": "create", "id": 1}, receive(t, s.MsgChan("create")).Data)
+
+	in <- worker.Message{Data: map[string]any{"id": 2}, Meta: worker.Metadata{RoutingKey: "notes.delete"}}
+	assert.Equal(t, map[string]any{"id": 2}, receive(t, s.MsgChan("delete")).Data)
+
+	// операция без правила получает сообщения, не подошедшие операциям перед ней
+	in <- worker.Message{Data: map[string]any{"operation": "update", "id": 3}, Meta: worker.Metadata{RoutingKey: "notes.update"}}
+	assert.Equal(t, map[string]any{"operation": "update", "id": 3}, receive(t, s.MsgChan("other")).Data)
+
+	assert.Nil(t, s.MsgChan("unknown"))
+}
+
+func TestRun_ClosedChan(t *testing.T) {
+	t.Parallel()
+
+	in := make(chan worker.Message)
+
+	s, err := New(WithName("notes"), WithMsgChan(in), WithRoute("create", nil))
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.Run(context.Background())
+	}()
+
+	// канал соединения закрыт при остановке воркера: диспетчер завершает работу
+	close(in)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "dispatcher not stopped")
+	}
+}
+
+func TestRun_Unmatched(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		action      operation.UnmatchedAction
+		setupMock   func(m *mocks.MockdeadLetterPublisher)
+		wantInvalid bool
+	}{
+		{
+			name:        "drop",
+			action:      operation.UnmatchedActionDrop,
+			wantInvalid: true,
+		},
+		{
+			name:   "dead letter",
+			action: operation.UnmatchedActionDeadLetter,
+			setupMock: func(m *mocks.MockdeadLetterPublisher) {
+				m.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter deadletter.Letter) error {
+					assert.Equal(t, deadletter.StageRoute, letter.Stage)
+					assert.Equal(t, map[string]any{"operation": "update"}, letter.Payload)
+					assert.Contains(t, letter.Error, worker.ErrUnmatched.Error())
+
+					return nil
+				})
+			},
+			wantInvalid: true,
+		},
+		{
+			name:   "dead letter publish error",
+			action: operation.UnmatchedActionDeadLetter,
+			setupMock: func(m *mocks.MockdeadLetterPublisher) {
+				m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(errors.New("publish error"))
+			},
+			wantInvalid: false,
+		},
+		{
+			name:        "fail",
+			action:      operation.UnmatchedActionFail,
+			wantInvalid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			deadLetter := mocks.NewMockdeadLetterPublisher(ctrl)
+
+			if tt.setupMock != nil {
+				tt.setupMock(deadLetter)
+			}
+
+			_, in := runDispatcher(t,
+				WithRoute("create", &operation.Match{Field: "operation", Value: "create"}),
+				WithUnmatched(tt.action),
+				WithDeadLetter(deadLetter),
+			)
+
+			notifier := newResultNotifier()
+			in <- worker.Message{Data: map[string]any{"operation": "update"}, Notifier: notifier}
+
+			res := notifier.result(t)
+
+			require.ErrorIs(t, res.Err, worker.ErrUnmatched)
+			assert.Equal(t, tt.wantInvalid, errors.Is(res.Err, worker.ErrInvalidMessage))
+		})
+	}
+}
+
+func TestRun_DecodeError(t *testing.T) {
+	t.Parallel()
+
+	decodeErr := errors.New("codec: error decode msgpack: EOF")
+
+	tests := []struct {
+		name        string
+		deadLetter  bool
+		setupMock   func(m *mocks.MockdeadLetterPublisher)
+		wantInvalid bool
+	}{
+		{
+			name:        "without dead letter",
+			wantInvalid: true,
+		},
+		{
+			name:       "dead letter",
+			deadLetter: true,
+			setupMock: func(m *mocks.MockdeadLetterPublisher) {
+				m.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter deadletter.Letter) error {
+					assert.Equal(t, deadletter.StageDecode, letter.Stage)
+					assert.Equal(t, []byte{0x81}, letter.Raw)
+					assert.Equal(t, "application/msgpack", letter.ContentType)
+					assert.Contains(t, letter.Error, decodeErr.Error())
+
+					return nil
+				})
+			},
+			wantInvalid: true,
+		},
+		{
+			name:       "dead letter publish error",
+			deadLetter: true,
+			setupMock: func(m *mocks.MockdeadLetterPublisher) {
+				m.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(errors.New("publish error"))
+			},
+			wantInvalid: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			metrics := mocks.NewMockdecodeMetrics(ctrl)
+			metrics.EXPECT().AddDecodeErrors("notes")
+
+			opts := []Option{WithRoute("create", nil), WithMetrics(metrics)}
+
+			if tt.deadLetter {
+				deadLetter := mocks.NewMockdeadLetterPublisher(ctrl)
+				tt.setupMock(deadLetter)
+
+				opts = append(opts, WithDecodeDeadLetter(deadLetter))
+			}
+
+			s, in := runDispatcher(t, opts...)
+
+			notifier := newResultNotifier()
+			in <- worker.Message{
+				Raw:       []byte{0x81},
+				DecodeErr: decodeErr,
+				Meta:      worker.Metadata{ContentType: "application/msgpack"},
+				Notifier:  notifier,
+			}
+
+			res := notifier.result(t)
+
+			require.ErrorIs(t, res.Err, decodeErr)
+			assert.Equal(t, tt.wantInvalid, errors.Is(res.Err, worker.ErrInvalidMessage))
+
+			// сообщение не попадает в операцию, даже если она принимает все сообщения соединения
+			select {
+			case msg := <-s.MsgChan("create"):
+				assert.Fail(t, "unexpected message", msg)
+			default:
+			}
+		})
+	}
+}
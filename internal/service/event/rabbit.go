@@ -0,0 +1,112 @@
+package event
+
+import (
+	"context"
+	"db-worker/internal/service/publisher"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Заголовки, с которыми публикуется итог транзакции.
+const (
+	HeaderOperation = "x-operation"
+	HeaderTxStatus  = "x-tx-status"
+)
+
+// Rabbit публикует итоги транзакций операции в exchange RabbitMQ.
+// Тело сообщения - worker.Event в JSON, операция и статус транзакции дублируются в заголовках,
+// чтобы по ним можно было строить привязки headers exchange.
+type Rabbit struct {
+	address    string
+	exchange   string
+	routingKey string
+
+	publisher *publisher.Rabbit
+}
+
+// Option определяет опции для Rabbit.
+type Option func(*Rabbit)
+
+// WithAddress устанавливает адрес RabbitMQ.
+func WithAddress(address string) Option {
+	return func(r *Rabbit) {
+		r.address = address
+	}
+}
+
+// WithExchange устанавливает exchange, в который публикуются события. Exchange должен существовать.
+func WithExchange(exchange string) Option {
+	return func(r *Rabbit) {
+		r.exchange = exchange
+	}
+}
+
+// WithRoutingKey устанавливает routing key, с которым публикуются события. По умолчанию - название операции.
+func WithRoutingKey(routingKey string) Option {
+	return func(r *Rabbit) {
+		r.routingKey = routingKey
+	}
+}
+
+// NewRabbit создает новый экземпляр Rabbit.
+func NewRabbit(opts ...Option) (*Rabbit, error) {
+	r := &Rabbit{}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	pub, err := publisher.NewRabbit(
+		publisher.WithName("events"),
+		publisher.WithAddress(r.address),
+		publisher.WithExchange(r.exchange),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.publisher = pub
+
+	return r, nil
+}
+
+// Run соединяется с RabbitMQ.
+func (r *Rabbit) Run(ctx context.Context) error {
+	return r.publisher.Run(ctx)
+}
+
+// Publish публикует итог транзакции. Если соединение было потеряно - переподключается один раз.
+func (r *Rabbit) Publish(_ context.Context, event worker.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("events: error marshaling event: %w", err)
+	}
+
+	key := r.routingKey
+	if key == "" {
+		key = event.Operation
+	}
+
+	msg := amqp.Publishing{
+		Headers: amqp.Table{
+			HeaderOperation: event.Operation,
+			HeaderTxStatus:  event.Status,
+		},
+		ContentType:   "application/json",
+		CorrelationId: event.CorrelationID,
+		DeliveryMode:  amqp.Persistent,
+		Timestamp:     time.Now(),
+		Body:          body,
+	}
+
+	return r.publisher.Publish(key, msg)
+}
+
+// Stop закрывает соединение с RabbitMQ.
+func (r *Rabbit) Stop(ctx context.Context) error {
+	return r.publisher.Stop(ctx)
+}
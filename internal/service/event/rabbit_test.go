@@ -0,0 +1,150 @@
+package event
+
+import (
+	"context"
+	"db-worker/internal/service/publisher"
+	"db-worker/internal/service/worker"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher запоминает опубликованные сообщения.
+type fakePublisher struct {
+	published []amqp.Publishing
+	exchanges []string
+	keys      []string
+}
+
+func (p *fakePublisher) Publish(exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	p.published = append(p.published, msg)
+	p.exchanges = append(p.exchanges, exchange)
+	p.keys = append(p.keys, key)
+
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+// withChannel подменяет подключение к RabbitMQ: сообщения публикуются в ch.
+func withChannel(t *testing.T, r *Rabbit, ch publisher.Channel) {
+	t.Helper()
+
+	pub, err := publisher.NewRabbit(
+		publisher.WithAddress(r.address),
+		publisher.WithExchange(r.exchange),
+		publisher.WithDial(func(_ string) (publisher.Channel, io.Closer, error) {
+			return ch, nil, nil
+		}),
+	)
+	require.NoError(t, err)
+
+	r.publisher = pub
+}
+
+func testEvent() worker.Event {
+	return worker.Event{
+		Operation:     "create_notes",
+		MessageID:     "msg-1",
+		CorrelationID: "corr-1",
+		TxID:          "tx-1",
+		Status:        "SUCCESS",
+		IDs:           []uuid.UUID{uuid.MustParse("8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60")},
+	}
+}
+
+func TestNewRabbit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithAddress("amqp://localhost:5672"), WithExchange("notes.events"), WithRoutingKey("created")},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: address is empty",
+			opts:    []Option{WithExchange("notes.events")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: exchange is empty",
+			opts:    []Option{WithAddress("amqp://localhost:5672")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRabbit(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestRabbit_Publish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		routingKey string
+		wantKey    string
+	}{
+		{
+			name:       "routing key from config",
+			routingKey: "created",
+			wantKey:    "created",
+		},
+		{
+			name:    "routing key is operation name",
+			wantKey: "create_notes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := NewRabbit(WithAddress("amqp://localhost:5672"), WithExchange("notes.events"), WithRoutingKey(tt.routingKey))
+			require.NoError(t, err)
+
+			ch := &fakePublisher{}
+			withChannel(t, r, ch)
+
+			require.NoError(t, r.Publish(context.Background(), testEvent()))
+
+			require.Len(t, ch.published, 1)
+
+			msg := ch.published[0]
+			assert.Equal(t, "notes.events", ch.exchanges[0])
+			assert.Equal(t, tt.wantKey, ch.keys[0])
+			assert.JSONEq(t, `{
+				"operation": "create_notes",
+				"message_id": "msg-1",
+				"correlation_id": "corr-1",
+				"transaction_id": "tx-1",
+				"status": "SUCCESS",
+				"message_ids": ["8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60"]
+			}`, string(msg.Body))
+			assert.Equal(t, "application/json", msg.ContentType)
+			assert.Equal(t, "corr-1", msg.CorrelationId)
+			assert.Equal(t, amqp.Persistent, msg.DeliveryMode)
+			assert.Equal(t, amqp.Table{
+				HeaderOperation: "create_notes",
+				HeaderTxStatus:  "SUCCESS",
+			}, msg.Headers)
+		})
+	}
+}
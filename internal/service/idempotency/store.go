@@ -0,0 +1,171 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "db-worker:idempotency"
+
+// Store хранит в Redis ключи сообщений, транзакции которых уже закоммичены, вместе с айди транзакции.
+// Ключи живут ttl: повтор сообщения в пределах ttl не обрабатывается.
+type Store struct {
+	client    redis.UniversalClient
+	operation string
+	cfg       *operation.Idempotency
+	ttl       time.Duration
+
+	insertTimeout int
+	readTimeout   int
+}
+
+// Option определяет опции для Store.
+type Option func(*Store)
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(s *Store) {
+		s.client = client
+	}
+}
+
+// WithOperation устанавливает название операции: ключи разных операций не пересекаются.
+func WithOperation(name string) Option {
+	return func(s *Store) {
+		s.operation = name
+	}
+}
+
+// WithCfg устанавливает, откуда брать ключ сообщения и сколько его хранить.
+func WithCfg(cfg *operation.Idempotency) Option {
+	return func(s *Store) {
+		s.cfg = cfg
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания записи ключа (мс).
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(s *Store) {
+		s.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает время ожидания чтения ключа (мс).
+func WithReadTimeout(readTimeout int) Option {
+	return func(s *Store) {
+		s.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Store.
+func New(opts ...Option) (*Store, error) {
+	s := &Store{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.client == nil {
+		return nil, errors.New("idempotency: redis client is required")
+	}
+
+	if s.operation == "" {
+		return nil, errors.New("idempotency: operation is required")
+	}
+
+	if s.cfg == nil {
+		return nil, errors.New("idempotency: cfg is required")
+	}
+
+	if s.cfg.TTL <= 0 {
+		return nil, errors.New("idempotency: ttl is required")
+	}
+
+	if s.insertTimeout == 0 {
+		return nil, errors.New("idempotency: insert timeout is required")
+	}
+
+	if s.readTimeout == 0 {
+		return nil, errors.New("idempotency: read timeout is required")
+	}
+
+	s.ttl = time.Duration(s.cfg.TTL) * time.Second
+
+	return s, nil
+}
+
+// Key возвращает ключ идемпотентности сообщения. false - у сообщения нет ключа
+// (нет поля или айди сообщения), такое сообщение обрабатывается без проверки.
+func (s *Store) Key(msg worker.Message) (string, bool) {
+	var key string
+
+	switch s.cfg.Key {
+	case operation.IdempotencyKeyField:
+		val, ok := msg.Data[s.cfg.Field]
+		if !ok || val == nil {
+			return "", false
+		}
+
+		key = fmt.Sprint(val)
+	case operation.IdempotencyKeyMessageID:
+		key = msg.Meta.MessageID
+	case operation.IdempotencyKeyHash:
+		values := make(map[string]any, len(s.cfg.Fields))
+		for _, field := range s.cfg.Fields {
+			values[field] = msg.Data[field]
+		}
+
+		// json сортирует ключи мапы: хеш не зависит от порядка полей в сообщении
+		data, err := json.Marshal(values)
+		if err != nil {
+			return "", false
+		}
+
+		sum := sha256.Sum256(data)
+		key = hex.EncodeToString(sum[:])
+	}
+
+	if key == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s:%s:%s", keyPrefix, s.operation, key), true
+}
+
+// Get возвращает айди транзакции, которой уже обработано сообщение с ключом. false - сообщение еще не обработано.
+func (s *Store) Get(ctx context.Context, key string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.readTimeout)*time.Millisecond)
+	defer cancel()
+
+	txID, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("idempotency: error get key: %w", err)
+	}
+
+	return txID, true, nil
+}
+
+// Save запоминает, что сообщение с ключом обработано транзакцией txID.
+func (s *Store) Save(ctx context.Context, key, txID string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.insertTimeout)*time.Millisecond)
+	defer cancel()
+
+	if err := s.client.Set(ctx, key, txID, s.ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency: error save key: %w", err)
+	}
+
+	return nil
+}
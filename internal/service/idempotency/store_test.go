@@ -0,0 +1,202 @@
+package idempotency
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return srv, client
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+	cfg := &operation.Idempotency{Key: operation.IdempotencyKeyMessageID, TTL: 60}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithClient(client), WithOperation("create_notes"), WithCfg(cfg), WithInsertTimeout(100), WithReadTimeout(100)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: no client",
+			opts:    []Option{WithOperation("create_notes"), WithCfg(cfg), WithInsertTimeout(100), WithReadTimeout(100)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: no operation",
+			opts:    []Option{WithClient(client), WithCfg(cfg), WithInsertTimeout(100), WithReadTimeout(100)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: no cfg",
+			opts:    []Option{WithClient(client), WithOperation("create_notes"), WithInsertTimeout(100), WithReadTimeout(100)},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: no ttl",
+			opts: []Option{
+				WithClient(client), WithOperation("create_notes"), WithCfg(&operation.Idempotency{Key: operation.IdempotencyKeyMessageID}),
+				WithInsertTimeout(100), WithReadTimeout(100),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: no insert timeout",
+			opts:    []Option{WithClient(client), WithOperation("create_notes"), WithCfg(cfg), WithReadTimeout(100)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: no read timeout",
+			opts:    []Option{WithClient(client), WithOperation("create_notes"), WithCfg(cfg), WithInsertTimeout(100)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+//nolint:funlen // много тест-кейсов
+func TestStore_Key(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+
+	hashCfg := &operation.Idempotency{Key: operation.IdempotencyKeyHash, Fields: []string{"user_id", "text"}, TTL: 60}
+
+	tests := []struct {
+		name    string
+		cfg     *operation.Idempotency
+		msg     worker.Message
+		want    string
+		wantKey bool
+	}{
+		{
+			name:    "positive case: field",
+			cfg:     &operation.Idempotency{Key: operation.IdempotencyKeyField, Field: "request_id", TTL: 60},
+			msg:     worker.Message{Data: map[string]any{"request_id": float64(42)}},
+			want:    "db-worker:idempotency:create_notes:42",
+			wantKey: true,
+		},
+		{
+			name:    "positive case: message id",
+			cfg:     &operation.Idempotency{Key: operation.IdempotencyKeyMessageID, TTL: 60},
+			msg:     worker.Message{Meta: worker.Metadata{MessageID: "msg-1"}},
+			want:    "db-worker:idempotency:create_notes:msg-1",
+			wantKey: true,
+		},
+		{
+			name:    "positive case: hash",
+			cfg:     hashCfg,
+			msg:     worker.Message{Data: map[string]any{"user_id": float64(1), "text": "note", "other": "ignored"}},
+			want:    "db-worker:idempotency:create_notes:" + mustKey(t, client, hashCfg, map[string]any{"text": "note", "user_id": float64(1)}),
+			wantKey: true,
+		},
+		{
+			name:    "negative case: no field",
+			cfg:     &operation.Idempotency{Key: operation.IdempotencyKeyField, Field: "request_id", TTL: 60},
+			msg:     worker.Message{Data: map[string]any{"text": "note"}},
+			wantKey: false,
+		},
+		{
+			name:    "negative case: no message id",
+			cfg:     &operation.Idempotency{Key: operation.IdempotencyKeyMessageID, TTL: 60},
+			msg:     worker.Message{},
+			wantKey: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := New(WithClient(client), WithOperation("create_notes"), WithCfg(tt.cfg), WithInsertTimeout(100), WithReadTimeout(100))
+			require.NoError(t, err)
+
+			got, ok := store.Key(tt.msg)
+			assert.Equal(t, tt.wantKey, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// mustKey возвращает хеш-часть ключа для сообщения с другим порядком полей.
+func mustKey(t *testing.T, client *redis.Client, cfg *operation.Idempotency, data map[string]any) string {
+	t.Helper()
+
+	store, err := New(WithClient(client), WithOperation("other"), WithCfg(cfg), WithInsertTimeout(100), WithReadTimeout(100))
+	require.NoError(t, err)
+
+	key, ok := store.Key(worker.Message{Data: data})
+	require.True(t, ok)
+
+	return key[len("db-worker:idempotency:other:"):]
+}
+
+func TestStore_GetSave(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestClient(t)
+
+	store, err := New(
+		WithClient(client),
+		WithOperation("create_notes"),
+		WithCfg(&operation.Idempotency{Key: operation.IdempotencyKeyMessageID, TTL: 60}),
+		WithInsertTimeout(100),
+		WithReadTimeout(100),
+	)
+	require.NoError(t, err)
+
+	const key = "db-worker:idempotency:create_notes:msg-1"
+
+	_, found, err := store.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Save(t.Context(), key, "tx-1"))
+
+	txID, found, err := store.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "tx-1", txID)
+	assert.Equal(t, time.Minute, srv.TTL(key))
+
+	srv.FastForward(time.Minute)
+
+	_, found, err = store.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	srv.Close()
+
+	_, _, err = store.Get(t.Context(), key)
+	require.Error(t, err)
+}
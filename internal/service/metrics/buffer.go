@@ -0,0 +1,15 @@
+package metrics
+
+import "github.com/sirupsen/logrus"
+
+// ObserveBufferFlush учитывает обработку буфера операции: причину и количество сообщений в буфере.
+func (s *Service) ObserveBufferFlush(operation, reason string, size int) {
+	s.bufferFlushes.WithLabelValues(operation, reason).Inc()
+	s.bufferBatchSize.WithLabelValues(operation).Observe(float64(size))
+
+	logrus.WithFields(logrus.Fields{
+		"operation": operation,
+		"reason":    reason,
+		"size":      size,
+	}).Debug("metrics: observe buffer flush")
+}
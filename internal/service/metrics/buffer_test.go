@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveBufferFlush(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	metricsService := New(WithRegisterer(registry))
+
+	metricsService.ObserveBufferFlush("create_notes", "size", 10)
+	metricsService.ObserveBufferFlush("create_notes", "timeout", 3)
+	metricsService.ObserveBufferFlush("create_notes", "timeout", 1)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricsService.bufferFlushes.WithLabelValues("create_notes", "size")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsService.bufferFlushes.WithLabelValues("create_notes", "timeout")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metricsService.bufferFlushes.WithLabelValues("create_notes", "bytes")))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(metricsService.bufferBatchSize))
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import "github.com/sirupsen/logrus"
+
+// SetConnectionStatus устанавливает состояние соединения: 1 - подключено, 0 - нет.
+func (s *Service) SetConnectionStatus(name string, connected bool) {
+	value := 0.0
+	if connected {
+		value = 1
+	}
+
+	s.connectionStatus.WithLabelValues(name).Set(value)
+
+	logrus.WithFields(logrus.Fields{
+		"connection": name,
+		"connected":  connected,
+	}).Debug("metrics: set connection status")
+}
+
+// AddConnectionReconnects увеличивает количество попыток переподключения соединения.
+func (s *Service) AddConnectionReconnects(name string) {
+	s.connectionReconnects.WithLabelValues(name).Inc()
+
+	logrus.WithFields(logrus.Fields{
+		"connection": name,
+	}).Debug("metrics: add connection reconnects")
+}
+
+// AddDecodeErrors увеличивает количество сообщений соединения, тело которых не удалось декодировать.
+func (s *Service) AddDecodeErrors(name string) {
+	s.decodeErrors.WithLabelValues(name).Inc()
+
+	logrus.WithFields(logrus.Fields{
+		"connection": name,
+	}).Debug("metrics: add decode errors")
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetConnectionStatus(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	metricsService := New(WithRegisterer(registry))
+
+	metricsService.SetConnectionStatus("rabbit", true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricsService.connectionStatus.WithLabelValues("rabbit")))
+
+	metricsService.SetConnectionStatus("rabbit", false)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metricsService.connectionStatus.WithLabelValues("rabbit")))
+}
+
+func TestAddConnectionReconnects(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	metricsService := New(WithRegisterer(registry))
+
+	metricsService.AddConnectionReconnects("rabbit")
+	metricsService.AddConnectionReconnects("rabbit")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricsService.connectionReconnects.WithLabelValues("rabbit")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metricsService.connectionReconnects.WithLabelValues("kafka")))
+}
+
+func TestAddDecodeErrors(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	metricsService := New(WithRegisterer(registry))
+
+	metricsService.AddDecodeErrors("kafka")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricsService.decodeErrors.WithLabelValues("kafka")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metricsService.decodeErrors.WithLabelValues("rabbit")))
+}
@@ -24,6 +24,15 @@ type Service struct {
 	failedTransactions     prometheus.Gauge // количество транзакций в статусе failed
 	canceledTransactions   prometheus.Gauge // количество транзакций в статусе canceled
 	successTransactions    prometheus.Gauge // количество транзакций в статусе success
+
+	// Метрики для соединений
+	connectionStatus     *prometheus.GaugeVec   // состояние соединения: 1 - подключено, 0 - нет
+	connectionReconnects *prometheus.CounterVec // количество попыток переподключения
+	decodeErrors         *prometheus.CounterVec // количество сообщений, тело которых не удалось декодировать
+
+	// Метрики для буфера операций
+	bufferFlushes   *prometheus.CounterVec   // количество обработок буфера по причине
+	bufferBatchSize *prometheus.HistogramVec // количество сообщений в обработанном буфере
 }
 
 // Option описывает опции инициализации сервиса метрик.
@@ -86,6 +95,8 @@ func New(opts ...Option) *Service {
 func (s *Service) registerMetrics() {
 	s.registerMessageMetrics()
 	s.registerTransactionMetrics()
+	s.registerConnectionMetrics()
+	s.registerBufferMetrics()
 }
 
 //nolint:dupl // похожая реализация, с разницей в устанавливаемых метриках
@@ -193,3 +204,63 @@ func (s *Service) registerTransactionMetrics() {
 	)
 	s.registry.MustRegister(s.successTransactions)
 }
+
+func (s *Service) registerConnectionMetrics() {
+	s.connectionStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: s.namespace,
+			Subsystem: s.subsystem,
+			Name:      "connection_up",
+			Help:      "Connection status: 1 - connected, 0 - disconnected",
+		},
+		[]string{"connection"},
+	)
+	s.registry.MustRegister(s.connectionStatus)
+
+	s.connectionReconnects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: s.namespace,
+			Subsystem: s.subsystem,
+			Name:      "connection_reconnects_total",
+			Help:      "Total number of reconnect attempts",
+		},
+		[]string{"connection"},
+	)
+	s.registry.MustRegister(s.connectionReconnects)
+
+	s.decodeErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: s.namespace,
+			Subsystem: s.subsystem,
+			Name:      "decode_errors_total",
+			Help:      "Total number of messages with undecodable payload",
+		},
+		[]string{"connection"},
+	)
+	s.registry.MustRegister(s.decodeErrors)
+}
+
+func (s *Service) registerBufferMetrics() {
+	s.bufferFlushes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: s.namespace,
+			Subsystem: s.subsystem,
+			Name:      "buffer_flushes_total",
+			Help:      "Total number of operation buffer flushes by reason: size, bytes or timeout",
+		},
+		[]string{"operation", "reason"},
+	)
+	s.registry.MustRegister(s.bufferFlushes)
+
+	s.bufferBatchSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: s.namespace,
+			Subsystem: s.subsystem,
+			Name:      "buffer_batch_size",
+			Help:      "Number of messages in flushed operation buffer",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12), // от 1 до 2048 сообщений
+		},
+		[]string{"operation"},
+	)
+	s.registry.MustRegister(s.bufferBatchSize)
+}
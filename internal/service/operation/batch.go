@@ -0,0 +1,218 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/service/deadletter"
+	"db-worker/internal/service/operation/message"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"db-worker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// processBatch записывает сообщения буфера create операции одной транзакцией: по одному запросу на хранилище.
+// Сообщения, не прошедшие валидацию, завершаются сразу и в пачку не попадают.
+// Если пачку записать не удалось - сообщения записываются заново одной транзакцией, каждое под своей точкой
+// сохранения: ошибку получает только сообщение, из-за которого упала пачка, остальные коммитятся.
+func (s *Service) processBatch(ctx context.Context, items []item) error {
+	errs := make([]error, 0, len(items))
+
+	batch := make([]item, 0, len(items))
+	rows := make([]map[string]any, 0, len(items))
+
+	// у пачки одна транзакция: айди общий для всех сообщений
+	txID := s.newTxID()
+
+	for _, item := range items {
+		msg, err := s.prepareMessage(ctx, item.msg.Data, item.msg.Meta, item.ids, txID)
+		if err != nil {
+			s.releaseMessages(item.ids)
+			s.finishItem(ctx, item, uow.Result{}, err)
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		batch = append(batch, item)
+		rows = append(rows, msg)
+	}
+
+	if len(batch) == 0 {
+		return errors.Join(errs...)
+	}
+
+	res, err := s.execBatch(ctx, batch, rows)
+
+	// транзакция пачки закоммичена: ошибка могла возникнуть только после коммита, повторять запись нельзя
+	if err == nil || res.Status == string(storage.TxStatusSuccess) {
+		if err != nil {
+			s.logCommittedError(res, len(batch), err)
+		}
+
+		s.setMessagesStatus(ctx, batchIDs(batch), message.StatusValidated, nil)
+
+		for _, item := range batch {
+			s.releaseMessages(item.ids)
+			s.finishItem(ctx, item, res, nil)
+		}
+
+		return errors.Join(errs...)
+	}
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"connection":     s.cfg.Request.From,
+		"count":          len(batch),
+		"transaction_id": res.TxID,
+	}).Warn("operation: error exec batch, retrying messages with savepoints")
+
+	return errors.Join(append(errs, s.processRows(ctx, batch, rows))...)
+}
+
+// execBatch строит запросы на все сообщения пачки и выполняет их одной транзакцией.
+func (s *Service) execBatch(ctx context.Context, batch []item, rows []map[string]any) (uow.Result, error) {
+	requests, err := s.uow.BuildBatchRequests(rows, s.uow.StoragesMap(), *s.cfg)
+	if err != nil {
+		return uow.Result{}, fmt.Errorf("error build batch requests: %w", err)
+	}
+
+	// у пачки нет метаданных одного сообщения: сообщения хранятся в данных транзакции
+	res, err := s.uow.ExecRequests(ctx, requests, uow.BatchRaw(rows), worker.Metadata{})
+	if err != nil {
+		return res, fmt.Errorf("error exec batch requests: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"connection":     s.cfg.Request.From,
+		"requests_count": len(requests),
+		"count":          len(batch),
+		"transaction_id": res.TxID,
+	}).Info("operation: batch executed")
+
+	return res, nil
+}
+
+// processRows записывает сообщения пачки одной транзакцией, каждое под своей точкой сохранения.
+// Статус каждого сообщения в БД и итог, переданный источнику, отражают результат записи именно этого сообщения.
+//
+//nolint:funlen // цельная логика функции, много строк из-за логов
+func (s *Service) processRows(ctx context.Context, batch []item, rows []map[string]any) error {
+	errs := make([]error, 0, len(batch))
+
+	built := make([]item, 0, len(batch))
+	builtRows := make([]map[string]any, 0, len(batch))
+	requests := make([]map[storage.Driver]*storage.Request, 0, len(batch))
+
+	// айди транзакции упавшей пачки уже занят: сообщения записываются новой транзакцией
+	s.setTxID(rows, s.newTxID())
+
+	for i, item := range batch {
+		reqs, err := s.uow.BuildRequests(rows[i], s.uow.StoragesMap(), *s.cfg)
+		if err != nil {
+			err = fmt.Errorf("%w: error build requests: %w", worker.ErrInvalidMessage, err)
+
+			s.publishDeadLetter(ctx, deadletter.StageBuild, item.msg.Data, item.ids, err)
+			s.setMessagesStatus(ctx, item.ids, message.StatusFailed, err)
+			s.releaseMessages(item.ids)
+			s.finishItem(ctx, item, uow.Result{}, err)
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		built = append(built, item)
+		builtRows = append(builtRows, rows[i])
+		requests = append(requests, reqs)
+	}
+
+	if len(built) == 0 {
+		return errors.Join(errs...)
+	}
+
+	res, rowErrs, err := s.uow.ExecRowRequests(ctx, requests, uow.RowsRaw(builtRows), worker.Metadata{})
+
+	committed := res.Status == string(storage.TxStatusSuccess)
+	if committed && err != nil {
+		s.logCommittedError(res, len(built), err)
+	}
+
+	for i, item := range built {
+		var rowErr error
+		if i < len(rowErrs) {
+			rowErr = rowErrs[i]
+		}
+
+		// транзакция не закоммичена: сообщение не записано, даже если его запросы выполнились
+		if rowErr == nil && !committed {
+			rowErr = err
+		}
+
+		if rowErr == nil {
+			s.setMessagesStatus(ctx, item.ids, message.StatusValidated, nil)
+			s.releaseMessages(item.ids)
+			s.finishItem(ctx, item, res, nil)
+
+			continue
+		}
+
+		rowErr = fmt.Errorf("error exec requests: %w", rowErr)
+
+		logrus.WithError(rowErr).WithFields(logrus.Fields{
+			"name":           s.cfg.Name,
+			"connection":     s.cfg.Request.From,
+			"ids":            item.ids,
+			"transaction_id": res.TxID,
+			"correlation_id": item.msg.Meta.CorrelationID,
+		}).Error("operation: message not written")
+
+		s.publishDeadLetter(ctx, deadletter.StageExec, item.msg.Data, item.ids, rowErr)
+		s.setMessagesStatus(ctx, item.ids, message.StatusFailed, rowErr)
+		s.releaseMessages(item.ids)
+		s.finishItem(ctx, item, uow.Result{TxID: res.TxID, Status: string(storage.TxStatusFailed)}, rowErr)
+
+		errs = append(errs, rowErr)
+	}
+
+	return errors.Join(errs...)
+}
+
+// logCommittedError логирует ошибку, которая возникла после коммита транзакции пачки (например, не удалось сохранить
+// итог транзакции). Сообщения записаны: источник получает успешный итог и не доставляет их повторно.
+func (s *Service) logCommittedError(res uow.Result, count int, err error) {
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"connection":     s.cfg.Request.From,
+		"count":          count,
+		"transaction_id": res.TxID,
+	}).Error("operation: error after batch transaction commit")
+}
+
+// setMessagesStatus обновляет статус сообщений в БД. Ошибка только логируется: итог записи уже известен,
+// и сообщение завершается им независимо от статуса в БД.
+func (s *Service) setMessagesStatus(ctx context.Context, ids []uuid.UUID, status message.Status, errMsg error) {
+	if err := s.updateMessagesStatus(ctx, status, ids, errMsg); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":       s.cfg.Name,
+			"connection": s.cfg.Request.From,
+			"ids":        ids,
+			"status":     status,
+		}).Error("operation: error update messages status")
+	}
+}
+
+// batchIDs возвращает айди сообщений всех элементов пачки.
+func batchIDs(batch []item) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(batch))
+	for _, item := range batch {
+		ids = append(ids, item.ids...)
+	}
+
+	return ids
+}
@@ -0,0 +1,256 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/operation/message"
+	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchService создает сервис create операции и буфер из сообщений с переданными значениями field1.
+// Возвращает уведомления и сообщения в БД каждого элемента буфера.
+func batchService(t *testing.T, ctrl *gomock.Controller, values ...any) (*Service, *mocks.MockunitOfWork, []*recordNotifier, []*message.Message) {
+	t.Helper()
+
+	mockUow := mocks.NewMockunitOfWork(ctrl)
+	messageRepo := mocks.NewMockmessageRepo(ctrl)
+	metricsService := mocks.NewMockmessageCounter(ctrl)
+
+	messageRepo.EXPECT().UpdateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	metricsService.EXPECT().AddFailedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddValidatedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
+
+	buffer, err := newBuffer(len(values), 0, 0)
+	require.NoError(t, err)
+
+	svc := &Service{
+		cfg: &operation.Operation{
+			Name: "test",
+			Type: operation.OperationTypeCreate,
+			Fields: []operation.Field{
+				{Name: "field1", Type: "string", Required: true},
+			},
+		},
+		messageRepo:    messageRepo,
+		uow:            mockUow,
+		metricsService: metricsService,
+		messages:       make(map[uuid.UUID]*message.Message),
+		buffer:         buffer,
+	}
+
+	notifiers := make([]*recordNotifier, 0, len(values))
+	messages := make([]*message.Message, 0, len(values))
+
+	for _, value := range values {
+		id := uuid.New()
+		msg := &message.Message{ID: id, Status: message.StatusInProgress}
+		svc.messages[id] = msg
+		messages = append(messages, msg)
+
+		n := &recordNotifier{}
+		notifiers = append(notifiers, n)
+
+		require.NoError(t, svc.buffer.add([]uuid.UUID{id}, worker.Message{Data: map[string]any{"field1": value}, Notifier: n}))
+	}
+
+	return svc, mockUow, notifiers, messages
+}
+
+func TestProcessBatch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// второе сообщение не проходит валидацию и в пачку не попадает
+	svc, mockUow, notifiers, messages := batchService(t, ctrl, "first", 2, "third")
+
+	rows := []map[string]any{{"field1": "first"}, {"field1": "third"}}
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).Times(1)
+	mockUow.EXPECT().BuildBatchRequests(rows, gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), uow.BatchRaw(rows), worker.Metadata{}).
+		Return(uow.Result{TxID: "tx-1", Status: "SUCCESS"}, nil).Times(1)
+
+	err := svc.processMessages(t.Context())
+	require.ErrorIs(t, err, worker.ErrInvalidMessage)
+
+	require.NotNil(t, notifiers[0].done)
+	assert.Equal(t, "tx-1", notifiers[0].done.TxID)
+	require.NoError(t, notifiers[0].done.Err)
+
+	require.NotNil(t, notifiers[1].done)
+	require.ErrorIs(t, notifiers[1].done.Err, worker.ErrInvalidMessage)
+	assert.Empty(t, notifiers[1].done.TxID)
+
+	require.NotNil(t, notifiers[2].done)
+	assert.Equal(t, "tx-1", notifiers[2].done.TxID)
+
+	assert.Equal(t, message.StatusValidated, messages[0].Status)
+	assert.Equal(t, message.StatusFailed, messages[1].Status)
+	assert.Equal(t, message.StatusValidated, messages[2].Status)
+
+	assert.Empty(t, svc.messages)
+}
+
+func TestProcessBatch_CommittedWithError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, mockUow, notifiers, messages := batchService(t, ctrl, "first", "second")
+
+	store := mocks.NewMockidempotencyStore(ctrl)
+	svc.idempotency = store
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).Times(1)
+	mockUow.EXPECT().BuildBatchRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), worker.Metadata{}).
+		Return(uow.Result{TxID: "tx-1", Status: "SUCCESS"}, errors.New("error finishing transaction")).Times(1)
+
+	// пачка записана: ключи сохраняются, и сообщения не доставляются повторно
+	store.EXPECT().Key(gomock.Any()).Return("key", true).Times(2)
+	store.EXPECT().Save(gomock.Any(), "key", "tx-1").Return(nil).Times(2)
+
+	require.NoError(t, svc.processMessages(t.Context()))
+
+	for i := range notifiers {
+		require.NotNil(t, notifiers[i].done)
+		require.NoError(t, notifiers[i].done.Err)
+		assert.Equal(t, "tx-1", notifiers[i].done.TxID)
+		assert.Equal(t, "SUCCESS", notifiers[i].done.Status)
+		assert.Equal(t, message.StatusValidated, messages[i].Status)
+	}
+}
+
+func TestProcessBatch_Savepoints(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, mockUow, notifiers, messages := batchService(t, ctrl, "first", "bad", "third")
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	mockUow.EXPECT().BuildBatchRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), worker.Metadata{}).
+		Return(uow.Result{TxID: "tx-batch", Status: "FAILED"}, errors.New("invalid input syntax")).Times(1)
+
+	// пачка упала: сообщения записываются заново под точками сохранения, откатывается только плохое сообщение
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(3)
+	mockUow.EXPECT().ExecRowRequests(gomock.Any(), gomock.Len(3), uow.RowsRaw([]map[string]any{{"field1": "first"}, {"field1": "bad"}, {"field1": "third"}}), worker.Metadata{}).
+		Return(uow.Result{TxID: "tx-1", Status: "SUCCESS"}, []error{nil, errors.New("invalid input syntax"), nil}, nil).Times(1)
+
+	err := svc.processMessages(t.Context())
+	require.Error(t, err)
+
+	require.NotNil(t, notifiers[0].done)
+	assert.Equal(t, "tx-1", notifiers[0].done.TxID)
+	assert.Equal(t, "SUCCESS", notifiers[0].done.Status)
+	require.NoError(t, notifiers[0].done.Err)
+
+	require.NotNil(t, notifiers[1].done)
+	assert.Equal(t, "tx-1", notifiers[1].done.TxID)
+	assert.Equal(t, "FAILED", notifiers[1].done.Status)
+	require.ErrorContains(t, notifiers[1].done.Err, "invalid input syntax")
+
+	require.NotNil(t, notifiers[2].done)
+	require.NoError(t, notifiers[2].done.Err)
+
+	assert.Equal(t, message.StatusValidated, messages[0].Status)
+	assert.Equal(t, message.StatusFailed, messages[1].Status)
+	assert.Equal(t, "error exec requests: invalid input syntax", messages[1].Error)
+	assert.Equal(t, message.StatusValidated, messages[2].Status)
+
+	assert.Empty(t, svc.messages)
+}
+
+func TestProcessBatch_SavepointsTxFailed(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, mockUow, notifiers, messages := batchService(t, ctrl, "first", "second")
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	mockUow.EXPECT().BuildBatchRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("too many parameters")).Times(1)
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	mockUow.EXPECT().ExecRowRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(uow.Result{TxID: "tx-1", Status: "FAILED"}, []error{nil, nil}, errors.New("error commit transaction")).Times(1)
+
+	require.Error(t, svc.processMessages(t.Context()))
+
+	// транзакция не закоммичена: ни одно сообщение не записано
+	for i := range notifiers {
+		require.NotNil(t, notifiers[i].done)
+		require.ErrorContains(t, notifiers[i].done.Err, "error commit transaction")
+		assert.Equal(t, message.StatusFailed, messages[i].Status)
+	}
+}
+
+func TestProcessBatch_SavepointsCommittedWithError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, mockUow, notifiers, messages := batchService(t, ctrl, "first", "bad")
+
+	store := mocks.NewMockidempotencyStore(ctrl)
+	svc.idempotency = store
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	mockUow.EXPECT().BuildBatchRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("too many parameters")).Times(1)
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	mockUow.EXPECT().ExecRowRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(uow.Result{TxID: "tx-1", Status: "SUCCESS"}, []error{nil, errors.New("invalid input syntax")}, errors.New("error finishing transaction")).Times(1)
+
+	// ключ сохраняется только у записанного сообщения
+	store.EXPECT().Key(gomock.Any()).Return("key", true).Times(1)
+	store.EXPECT().Save(gomock.Any(), "key", "tx-1").Return(nil).Times(1)
+
+	err := svc.processMessages(t.Context())
+	require.ErrorContains(t, err, "invalid input syntax")
+	require.NotContains(t, err.Error(), "error finishing transaction")
+
+	require.NotNil(t, notifiers[0].done)
+	require.NoError(t, notifiers[0].done.Err)
+	assert.Equal(t, "SUCCESS", notifiers[0].done.Status)
+	assert.Equal(t, message.StatusValidated, messages[0].Status)
+
+	require.NotNil(t, notifiers[1].done)
+	require.ErrorContains(t, notifiers[1].done.Err, "invalid input syntax")
+	assert.Equal(t, message.StatusFailed, messages[1].Status)
+}
+
+func TestProcessMessages_SingleCreateNotBatched(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc, mockUow, notifiers, _ := batchService(t, ctrl, "first")
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).Times(1)
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), map[string]any{"field1": "first"}, gomock.Any()).
+		Return(uow.Result{TxID: "tx-1", Status: "SUCCESS"}, nil).Times(1)
+
+	require.NoError(t, svc.processMessages(t.Context()))
+
+	require.NotNil(t, notifiers[0].done)
+	assert.Equal(t, "tx-1", notifiers[0].done.TxID)
+}
@@ -1,36 +1,67 @@
 package operation
 
 import (
+	"db-worker/internal/service/worker"
+	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// flushReason - почему буфер передан на обработку.
+type flushReason string
+
+const (
+	// flushReasonSize - в буфере buffer сообщений.
+	flushReasonSize flushReason = "size"
+	// flushReasonBytes - суммарный размер сообщений достиг buffer_bytes.
+	flushReasonBytes flushReason = "bytes"
+	// flushReasonTimeout - первое сообщение ждет в буфере дольше timeout.
+	flushReasonTimeout flushReason = "timeout"
+)
+
+// buffer копит сообщения операции, пока не сработает одно из условий обработки: размер, суммарный объем сообщений
+// или время ожидания первого сообщения.
 type buffer struct {
-	size int
-	mu   sync.Mutex
+	size     int           // сколько сообщений обрабатывать за раз
+	maxBytes int           // суммарный размер сообщений в JSON, после которого буфер обрабатывается. 0 - без ограничения
+	maxWait  time.Duration // сколько первое сообщение ждет в буфере. 0 - без ограничения
+	mu       sync.Mutex
 
 	items []item
+	bytes int       // суммарный размер сообщений в буфере
+	first time.Time // когда в пустой буфер добавлено сообщение
 }
 
 type item struct {
-	ids  []uuid.UUID
-	data map[string]any
+	ids []uuid.UUID
+	msg worker.Message
 }
 
-func newBuffer(size int) (*buffer, error) {
+func newBuffer(size, maxBytes int, maxWait time.Duration) (*buffer, error) {
 	if size <= 0 {
 		return nil, fmt.Errorf("size must be greater than 0")
 	}
 
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("max bytes must not be negative")
+	}
+
+	if maxWait < 0 {
+		return nil, fmt.Errorf("max wait must not be negative")
+	}
+
 	return &buffer{
-		size:  size,
-		items: make([]item, 0, size),
+		size:     size,
+		maxBytes: maxBytes,
+		maxWait:  maxWait,
+		items:    make([]item, 0, size),
 	}, nil
 }
 
-func (b *buffer) add(ids []uuid.UUID, data map[string]any) error {
+func (b *buffer) add(ids []uuid.UUID, msg worker.Message) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -38,14 +69,37 @@ func (b *buffer) add(ids []uuid.UUID, data map[string]any) error {
 		return fmt.Errorf("buffer is full")
 	}
 
+	if b.maxBytes > 0 {
+		size, err := payloadSize(msg)
+		if err != nil {
+			return err
+		}
+
+		b.bytes += size
+	}
+
+	if len(b.items) == 0 {
+		b.first = time.Now()
+	}
+
 	b.items = append(b.items, item{
-		ids:  ids,
-		data: data,
+		ids: ids,
+		msg: msg,
 	})
 
 	return nil
 }
 
+// payloadSize возвращает размер сообщения в JSON.
+func payloadSize(msg worker.Message) (int, error) {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return 0, fmt.Errorf("error marshal message: %w", err)
+	}
+
+	return len(data), nil
+}
+
 func (b *buffer) count() int {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -70,9 +124,39 @@ func (b *buffer) isFull() bool {
 	return len(b.items) >= b.size
 }
 
+// ready возвращает причину, по которой буфер пора обработать. false - буфер пуст или ни одно условие не сработало.
+func (b *buffer) ready(now time.Time) (flushReason, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case len(b.items) == 0:
+		return "", false
+	case len(b.items) >= b.size:
+		return flushReasonSize, true
+	case b.maxBytes > 0 && b.bytes >= b.maxBytes:
+		return flushReasonBytes, true
+	case b.maxWait > 0 && now.Sub(b.first) >= b.maxWait:
+		return flushReasonTimeout, true
+	default:
+		return "", false
+	}
+}
+
+// tick возвращает, как часто проверять время ожидания сообщений. 0 - время ожидания не ограничено.
+func (b *buffer) tick() time.Duration {
+	if b.maxWait == 0 {
+		return 0
+	}
+
+	return max(b.maxWait/4, time.Millisecond)
+}
+
 func (b *buffer) clear() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	b.items = make([]item, 0, b.size)
+	b.bytes = 0
+	b.first = time.Time{}
 }
@@ -1,7 +1,9 @@
 package operation
 
 import (
+	"db-worker/internal/service/worker"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -12,10 +14,12 @@ func TestNewBuffer(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name    string
-		size    int
-		want    *buffer
-		wantErr require.ErrorAssertionFunc
+		name     string
+		size     int
+		maxBytes int
+		maxWait  time.Duration
+		want     *buffer
+		wantErr  require.ErrorAssertionFunc
 	}{
 		{
 			name:    "positive case",
@@ -26,19 +30,46 @@ func TestNewBuffer(t *testing.T) {
 				items: make([]item, 0, 10),
 			},
 		},
+		{
+			name:     "positive case: with max bytes and max wait",
+			size:     10,
+			maxBytes: 1024,
+			maxWait:  time.Second,
+			wantErr:  require.NoError,
+			want: &buffer{
+				size:     10,
+				maxBytes: 1024,
+				maxWait:  time.Second,
+				items:    make([]item, 0, 10),
+			},
+		},
 		{
 			name:    "negative case: size is 0",
 			size:    0,
 			wantErr: require.Error,
 			want:    nil,
 		},
+		{
+			name:     "negative case: max bytes is negative",
+			size:     10,
+			maxBytes: -1,
+			wantErr:  require.Error,
+			want:     nil,
+		},
+		{
+			name:    "negative case: max wait is negative",
+			size:    10,
+			maxWait: -time.Second,
+			wantErr: require.Error,
+			want:    nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			buffer, err := newBuffer(tt.size)
+			buffer, err := newBuffer(tt.size, tt.maxBytes, tt.maxWait)
 			tt.wantErr(t, err)
 
 			assert.Equal(t, tt.want, buffer)
@@ -71,8 +102,8 @@ func TestBuffer_Add(t *testing.T) {
 				size: 10,
 				items: []item{
 					{
-						ids:  []uuid.UUID{uuid.New(), uuid.New()},
-						data: map[string]any{"test": "test"},
+						ids: []uuid.UUID{uuid.New(), uuid.New()},
+						msg: worker.Message{Data: map[string]any{"test": "test"}},
 					},
 				},
 			},
@@ -85,12 +116,12 @@ func TestBuffer_Add(t *testing.T) {
 				size: 2,
 				items: []item{
 					{
-						ids:  []uuid.UUID{uuid.New(), uuid.New()},
-						data: map[string]any{"test": "test"},
+						ids: []uuid.UUID{uuid.New(), uuid.New()},
+						msg: worker.Message{Data: map[string]any{"test": "test"}},
 					},
 					{
-						ids:  []uuid.UUID{uuid.New(), uuid.New()},
-						data: map[string]any{"test": "test"},
+						ids: []uuid.UUID{uuid.New(), uuid.New()},
+						msg: worker.Message{Data: map[string]any{"test": "test"}},
 					},
 				},
 			},
@@ -103,7 +134,7 @@ func TestBuffer_Add(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := tt.buffer.add(tt.ids, tt.data)
+			err := tt.buffer.add(tt.ids, worker.Message{Data: tt.data})
 			tt.wantErr(t, err)
 
 			if tt.want != nil {
@@ -124,7 +155,7 @@ func TestBuffer_Count(t *testing.T) {
 	ids := []uuid.UUID{uuid.New(), uuid.New()}
 	data := map[string]any{"test": "test"}
 
-	err := buffer.add(ids, data)
+	err := buffer.add(ids, worker.Message{Data: data})
 	require.NoError(t, err)
 
 	assert.Equal(t, buffer.count(), 1)
@@ -141,13 +172,13 @@ func TestBuffer_GetAll(t *testing.T) {
 	ids := []uuid.UUID{uuid.New(), uuid.New()}
 	data := map[string]any{"test": "test"}
 
-	err := buffer.add(ids, data)
+	err := buffer.add(ids, worker.Message{Data: data})
 	require.NoError(t, err)
 
 	expected := []item{
 		{
-			ids:  ids,
-			data: data,
+			ids: ids,
+			msg: worker.Message{Data: data},
 		},
 	}
 
@@ -170,16 +201,16 @@ func TestBuffer_IsFull(t *testing.T) {
 			size: 3,
 			items: []item{
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 			},
 			want: true,
@@ -189,16 +220,16 @@ func TestBuffer_IsFull(t *testing.T) {
 			size: 10,
 			items: []item{
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 				{
-					ids:  []uuid.UUID{uuid.New(), uuid.New()},
-					data: map[string]any{"test": "test"},
+					ids: []uuid.UUID{uuid.New(), uuid.New()},
+					msg: worker.Message{Data: map[string]any{"test": "test"}},
 				},
 			},
 			want: false,
@@ -229,12 +260,12 @@ func TestBuffer_Clear(t *testing.T) {
 
 	buffer.items = []item{
 		{
-			ids:  []uuid.UUID{uuid.New(), uuid.New()},
-			data: map[string]any{"test": "test"},
+			ids: []uuid.UUID{uuid.New(), uuid.New()},
+			msg: worker.Message{Data: map[string]any{"test": "test"}},
 		},
 		{
-			ids:  []uuid.UUID{uuid.New(), uuid.New()},
-			data: map[string]any{"test": "test"},
+			ids: []uuid.UUID{uuid.New(), uuid.New()},
+			msg: worker.Message{Data: map[string]any{"test": "test"}},
 		},
 	}
 
@@ -242,3 +273,115 @@ func TestBuffer_Clear(t *testing.T) {
 
 	assert.Len(t, buffer.items, 0)
 }
+
+func TestBuffer_Add_Bytes(t *testing.T) {
+	t.Parallel()
+
+	buffer, err := newBuffer(10, 100, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, buffer.add([]uuid.UUID{uuid.New()}, worker.Message{Data: map[string]any{"text": "note"}}))
+	assert.Equal(t, len(`{"text":"note"}`), buffer.bytes)
+	assert.False(t, buffer.first.IsZero())
+
+	buffer.clear()
+
+	assert.Zero(t, buffer.bytes)
+	assert.True(t, buffer.first.IsZero())
+}
+
+//nolint:funlen // тестовая функция
+func TestBuffer_Ready(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	msg := item{ids: []uuid.UUID{uuid.New()}, msg: worker.Message{Data: map[string]any{"test": "test"}}}
+
+	tests := []struct {
+		name       string
+		buffer     *buffer
+		wantReason flushReason
+		wantOk     bool
+	}{
+		{
+			name:   "empty buffer",
+			buffer: &buffer{size: 1, maxWait: time.Second, first: now.Add(-time.Hour)},
+		},
+		{
+			name:       "size reached",
+			buffer:     &buffer{size: 2, items: []item{msg, msg}, first: now},
+			wantReason: flushReasonSize,
+			wantOk:     true,
+		},
+		{
+			name:       "bytes reached",
+			buffer:     &buffer{size: 10, maxBytes: 10, bytes: 15, items: []item{msg}, first: now},
+			wantReason: flushReasonBytes,
+			wantOk:     true,
+		},
+		{
+			name:   "bytes not limited",
+			buffer: &buffer{size: 10, bytes: 15, items: []item{msg}, first: now},
+		},
+		{
+			name:       "max wait reached",
+			buffer:     &buffer{size: 10, maxWait: time.Second, items: []item{msg}, first: now.Add(-time.Second)},
+			wantReason: flushReasonTimeout,
+			wantOk:     true,
+		},
+		{
+			name:   "max wait not reached",
+			buffer: &buffer{size: 10, maxWait: time.Second, items: []item{msg}, first: now.Add(-time.Millisecond)},
+		},
+		{
+			name:       "size checked before timeout",
+			buffer:     &buffer{size: 1, maxWait: time.Second, items: []item{msg}, first: now.Add(-time.Hour)},
+			wantReason: flushReasonSize,
+			wantOk:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reason, ok := tt.buffer.ready(now)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestBuffer_Tick(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		maxWait time.Duration
+		want    time.Duration
+	}{
+		{
+			name: "max wait not set",
+			want: 0,
+		},
+		{
+			name:    "quarter of max wait",
+			maxWait: time.Second,
+			want:    250 * time.Millisecond,
+		},
+		{
+			name:    "not less than millisecond",
+			maxWait: time.Millisecond,
+			want:    time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := &buffer{size: 1, maxWait: tt.maxWait}
+			assert.Equal(t, tt.want, buffer.tick())
+		})
+	}
+}
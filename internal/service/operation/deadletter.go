@@ -0,0 +1,43 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/service/deadletter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// publishDeadLetter отправляет необработанное сообщение, если для операции настроен dead_letter.
+// Ошибка отправки только логируется: статус сообщения уже сохранен в messages.messages.
+func (s *Service) publishDeadLetter(ctx context.Context, stage deadletter.Stage, msg map[string]any, ids []uuid.UUID, cause error) {
+	if s.deadLetter == nil {
+		return
+	}
+
+	letter := deadletter.Letter{
+		Operation: s.cfg.Name,
+		Stage:     stage,
+		Error:     cause.Error(),
+		IDs:       ids,
+		Payload:   msg,
+		FailedAt:  time.Now(),
+	}
+
+	if err := s.deadLetter.Publish(ctx, letter); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":  s.cfg.Name,
+			"stage": stage,
+			"ids":   ids,
+		}).Error("operation: error publish dead letter")
+
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":  s.cfg.Name,
+		"stage": stage,
+		"ids":   ids,
+	}).Info("operation: dead letter published")
+}
@@ -0,0 +1,251 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/deadletter"
+	"db-worker/internal/service/operation/message"
+	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	msg := map[string]any{"user_id": 1}
+	cause := errors.New("field user_id is required")
+
+	tests := []struct {
+		name       string
+		withPub    bool
+		publishErr error
+	}{
+		{
+			name: "dead letter not configured",
+		},
+		{
+			name:    "positive case",
+			withPub: true,
+		},
+		{
+			name:       "error publish",
+			withPub:    true,
+			publishErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := &Service{cfg: &operation.Operation{Name: "create_notes"}}
+
+			if tt.withPub {
+				publisher := mocks.NewMockdeadLetterPublisher(ctrl)
+				publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, letter deadletter.Letter) error {
+						assert.Equal(t, "create_notes", letter.Operation)
+						assert.Equal(t, deadletter.StageBuild, letter.Stage)
+						assert.Equal(t, cause.Error(), letter.Error)
+						assert.Equal(t, ids, letter.IDs)
+						assert.Equal(t, msg, letter.Payload)
+						assert.False(t, letter.FailedAt.IsZero())
+
+						return tt.publishErr
+					})
+
+				svc.deadLetter = publisher
+			}
+
+			svc.publishDeadLetter(t.Context(), deadletter.StageBuild, msg, ids, cause)
+		})
+	}
+}
+
+// deadLetterOperation - операция, которая разворачивает конверт, достает поле по пути и преобразует значения:
+// подготовленное сообщение не похоже на исходное тело.
+func deadLetterOperation() *operation.Operation {
+	return &operation.Operation{
+		Name: "create_notes",
+		Type: operation.OperationTypeCreate,
+		Root: "data",
+		Fields: []operation.Field{
+			{Name: "user_id", Type: operation.FieldTypeInt64, Path: "user.id", Transforms: []operation.Transform{
+				{Type: operation.TransformTrim},
+				{Type: operation.TransformToInt64},
+			}},
+			{Name: "email", Type: operation.FieldTypeString, Transforms: []operation.Transform{
+				{Type: operation.TransformLower},
+			}},
+		},
+	}
+}
+
+//nolint:funlen // много тест-кейсов
+func TestProcessMessage_DeadLetterPayload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		msg       map[string]any
+		setupUow  func(mockUow *mocks.MockunitOfWork)
+		wantStage deadletter.Stage
+	}{
+		{
+			name: "validation",
+			msg: map[string]any{
+				"data": map[string]any{"user": map[string]any{"id": "seven"}, "email": "Bob@Example.com"},
+			},
+			setupUow:  func(_ *mocks.MockunitOfWork) {},
+			wantStage: deadletter.StageValidation,
+		},
+		{
+			name: "build",
+			msg: map[string]any{
+				"data": map[string]any{"user": map[string]any{"id": " 7 "}, "email": "Bob@Example.com"},
+			},
+			setupUow: func(mockUow *mocks.MockunitOfWork) {
+				mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("build error"))
+			},
+			wantStage: deadletter.StageBuild,
+		},
+		{
+			name: "exec",
+			msg: map[string]any{
+				"data": map[string]any{"user": map[string]any{"id": " 7 "}, "email": "Bob@Example.com"},
+			},
+			setupUow: func(mockUow *mocks.MockunitOfWork) {
+				mockUow.EXPECT().BuildRequests(map[string]any{"user_id": int64(7), "email": "bob@example.com"}, gomock.Any(), gomock.Any()).
+					Return(nil, nil)
+				mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(uow.Result{}, errors.New("exec error"))
+			},
+			wantStage: deadletter.StageExec,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUow := mocks.NewMockunitOfWork(ctrl)
+			messageRepo := mocks.NewMockmessageRepo(ctrl)
+			metricsService := mocks.NewMockmessageCounter(ctrl)
+			publisher := mocks.NewMockdeadLetterPublisher(ctrl)
+
+			mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+			messageRepo.EXPECT().UpdateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			metricsService.EXPECT().AddFailedMessages(gomock.Any()).Return().AnyTimes()
+			metricsService.EXPECT().AddValidatedMessages(gomock.Any()).Return().AnyTimes()
+			metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
+			metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
+
+			tt.setupUow(mockUow)
+
+			// в dead letter уходит исходное тело, а не извлеченные и преобразованные поля
+			publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, letter deadletter.Letter) error {
+					assert.Equal(t, tt.wantStage, letter.Stage)
+					assert.Equal(t, tt.msg, letter.Payload)
+
+					return nil
+				})
+
+			svc := &Service{
+				cfg:            deadLetterOperation(),
+				messageRepo:    messageRepo,
+				uow:            mockUow,
+				metricsService: metricsService,
+				messages:       make(map[uuid.UUID]*message.Message),
+				deadLetter:     publisher,
+			}
+
+			_, err := svc.processMessage(t.Context(), tt.msg, worker.Metadata{}, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestProcessRows_DeadLetterPayload(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUow := mocks.NewMockunitOfWork(ctrl)
+	messageRepo := mocks.NewMockmessageRepo(ctrl)
+	metricsService := mocks.NewMockmessageCounter(ctrl)
+	publisher := mocks.NewMockdeadLetterPublisher(ctrl)
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	messageRepo.EXPECT().UpdateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	metricsService.EXPECT().AddFailedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddValidatedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
+
+	svc := &Service{
+		cfg:            deadLetterOperation(),
+		messageRepo:    messageRepo,
+		uow:            mockUow,
+		metricsService: metricsService,
+		messages:       make(map[uuid.UUID]*message.Message),
+		deadLetter:     publisher,
+	}
+
+	bodies := []map[string]any{
+		{"data": map[string]any{"user": map[string]any{"id": "1"}, "email": "Ann@Example.com"}},
+		{"data": map[string]any{"user": map[string]any{"id": "2"}, "email": "Bob@Example.com"}},
+	}
+
+	batch := make([]item, 0, len(bodies))
+	rows := make([]map[string]any, 0, len(bodies))
+
+	for _, body := range bodies {
+		row, err := svc.prepareMessage(t.Context(), body, worker.Metadata{}, nil, "")
+		require.NoError(t, err)
+
+		batch = append(batch, item{msg: worker.Message{Data: body, Notifier: &recordNotifier{}}})
+		rows = append(rows, row)
+	}
+
+	// второе сообщение не собралось в запросы, первое не записалось
+	gomock.InOrder(
+		mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil),
+		mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("build error")),
+	)
+	mockUow.EXPECT().ExecRowRequests(gomock.Any(), gomock.Len(1), gomock.Any(), gomock.Any()).
+		Return(uow.Result{TxID: "tx-1", Status: "FAILED"}, []error{errors.New("invalid input syntax")}, errors.New("no rows written"))
+
+	gomock.InOrder(
+		publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter deadletter.Letter) error {
+			assert.Equal(t, deadletter.StageBuild, letter.Stage)
+			assert.Equal(t, bodies[1], letter.Payload)
+
+			return nil
+		}),
+		publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, letter deadletter.Letter) error {
+			assert.Equal(t, deadletter.StageExec, letter.Stage)
+			assert.Equal(t, bodies[0], letter.Payload)
+
+			return nil
+		}),
+	)
+
+	require.Error(t, svc.processRows(t.Context(), batch, rows))
+}
@@ -0,0 +1,33 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+
+	"github.com/sirupsen/logrus"
+)
+
+// publishEvent публикует итог транзакции, если для операции настроены events.
+// Сообщения, которые не дошли до транзакции, не публикуются. Ошибка отправки только логируется:
+// транзакция уже завершена.
+func (s *Service) publishEvent(ctx context.Context, meta worker.Metadata, res worker.Result) {
+	if s.events == nil || res.TxID == "" {
+		return
+	}
+
+	if err := s.events.Publish(ctx, res.Event(meta)); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":           s.cfg.Name,
+			"transaction_id": res.TxID,
+			"status":         res.Status,
+		}).Error("operation: error publish event")
+
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"transaction_id": res.TxID,
+		"status":         res.Status,
+	}).Debug("operation: event published")
+}
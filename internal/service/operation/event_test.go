@@ -0,0 +1,85 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/worker"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishEvent(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.New()}
+	meta := worker.Metadata{MessageID: "msg-1", CorrelationID: "corr-1"}
+
+	tests := []struct {
+		name        string
+		withPub     bool
+		res         worker.Result
+		wantPublish bool
+		publishErr  error
+	}{
+		{
+			name: "events not configured",
+			res:  worker.Result{Operation: "create_notes", TxID: "tx-1", Status: "SUCCESS"},
+		},
+		{
+			name:    "transaction not executed",
+			withPub: true,
+			res:     worker.Result{Operation: "create_notes", IDs: ids, Err: worker.ErrInvalidMessage},
+		},
+		{
+			name:        "positive case: success",
+			withPub:     true,
+			res:         worker.Result{Operation: "create_notes", IDs: ids, TxID: "tx-1", Status: "SUCCESS"},
+			wantPublish: true,
+		},
+		{
+			name:        "positive case: failed transaction",
+			withPub:     true,
+			res:         worker.Result{Operation: "create_notes", IDs: ids, TxID: "tx-1", Status: "FAILED", FailedDriver: "notes", Err: errors.New("duplicate key")},
+			wantPublish: true,
+		},
+		{
+			name:        "error publish",
+			withPub:     true,
+			res:         worker.Result{Operation: "create_notes", IDs: ids, TxID: "tx-1", Status: "SUCCESS"},
+			wantPublish: true,
+			publishErr:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := &Service{cfg: &operation.Operation{Name: "create_notes"}}
+
+			if tt.withPub {
+				publisher := mocks.NewMockeventPublisher(ctrl)
+
+				if tt.wantPublish {
+					publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, event worker.Event) error {
+							assert.Equal(t, tt.res.Event(meta), event)
+							return tt.publishErr
+						})
+				}
+
+				svc.events = publisher
+			}
+
+			svc.publishEvent(t.Context(), meta, tt.res)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"fmt"
+)
+
+// extractFields достает поля из тела сообщения: тело разворачивается по root операции, поле с path берет значение
+// по пути, остальные поля - по своему названию. Ключи тела, которые не объявлены в fields и не начинают путь
+// ни одного поля, переносятся как есть: их отбросит строитель запросов или отклонит unknown_fields: reject.
+// Возвращает плоское сообщение с ключами - названиями полей. Если root и path не заданы, возвращает исходное сообщение.
+func (s *Service) extractFields(msg map[string]any) (map[string]any, error) {
+	root, err := operation.ParsePath(s.cfg.Root)
+	if err != nil {
+		return msg, fmt.Errorf("root: %w", err)
+	}
+
+	if len(root) > 0 {
+		val, ok := root.Lookup(msg)
+		if !ok {
+			return msg, fmt.Errorf("root %q is not found", s.cfg.Root)
+		}
+
+		body, ok := val.(map[string]any)
+		if !ok {
+			return msg, fmt.Errorf("root %q is not an object", s.cfg.Root)
+		}
+
+		msg = body
+	}
+
+	paths := make(map[string]operation.Path)
+	heads := make(map[string]struct{})
+
+	for _, field := range s.cfg.Fields {
+		if field.Path == "" {
+			continue
+		}
+
+		path, err := operation.ParsePath(field.Path)
+		if err != nil {
+			return msg, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+
+		paths[field.Name] = path
+		heads[path[0]] = struct{}{}
+	}
+
+	if len(paths) == 0 {
+		return msg, nil
+	}
+
+	// поле без path с тем же названием, что и начало пути, берется из тела как есть
+	for _, field := range s.cfg.Fields {
+		if field.Path == "" {
+			delete(heads, field.Name)
+		}
+	}
+
+	out := make(map[string]any, len(msg))
+
+	for key, val := range msg {
+		if _, ok := heads[key]; !ok {
+			out[key] = val
+		}
+	}
+
+	for _, field := range s.cfg.Fields {
+		path, ok := paths[field.Name]
+		if !ok {
+			continue
+		}
+
+		// поля нет в сообщении: его отсутствие проверит валидация
+		delete(out, field.Name)
+
+		if val, ok := path.Lookup(msg); ok {
+			out[field.Name] = val
+		}
+	}
+
+	return out, nil
+}
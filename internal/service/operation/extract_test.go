@@ -0,0 +1,144 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestExtractFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		root    string
+		fields  []operation.Field
+		msg     map[string]any
+		want    map[string]any
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: flat message",
+			fields:  []operation.Field{{Name: "text"}},
+			msg:     map[string]any{"text": "hello", "extra": 1},
+			want:    map[string]any{"text": "hello", "extra": 1},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: paths",
+			fields: []operation.Field{
+				{Name: "text", Path: "note.text"},
+				{Name: "user_id", Path: "$.meta.user.id"},
+				{Name: "tag", Path: "tags[1]"},
+				{Name: "source"},
+			},
+			msg: map[string]any{
+				"note":   map[string]any{"text": "hello"},
+				"meta":   map[string]any{"user": map[string]any{"id": float64(42)}},
+				"tags":   []any{"first", "second"},
+				"source": "api",
+			},
+			want:    map[string]any{"text": "hello", "user_id": float64(42), "tag": "second", "source": "api"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: value not found",
+			fields:  []operation.Field{{Name: "text", Path: "note.text"}},
+			msg:     map[string]any{"note": map[string]any{}},
+			want:    map[string]any{},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: path wins over flat key",
+			fields:  []operation.Field{{Name: "text", Path: "note.text"}},
+			msg:     map[string]any{"text": "flat", "note": map[string]any{"text": "nested"}},
+			want:    map[string]any{"text": "nested"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: unknown keys are kept",
+			fields:  []operation.Field{{Name: "text", Path: "note.text"}},
+			msg:     map[string]any{"note": map[string]any{"text": "hello"}, "txet": "typo"},
+			want:    map[string]any{"text": "hello", "txet": "typo"},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: field without path named as path head",
+			fields: []operation.Field{
+				{Name: "note"},
+				{Name: "text", Path: "note.text"},
+			},
+			msg:     map[string]any{"note": map[string]any{"text": "hello"}},
+			want:    map[string]any{"note": map[string]any{"text": "hello"}, "text": "hello"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: root",
+			root:    "data",
+			fields:  []operation.Field{{Name: "text"}},
+			msg:     map[string]any{"data": map[string]any{"text": "hello"}, "version": 2},
+			want:    map[string]any{"text": "hello"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: root and path",
+			root:    "$.data",
+			fields:  []operation.Field{{Name: "text", Path: "note.text"}},
+			msg:     map[string]any{"data": map[string]any{"note": map[string]any{"text": "hello"}}},
+			want:    map[string]any{"text": "hello"},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: root not found",
+			root:    "data",
+			fields:  []operation.Field{{Name: "text"}},
+			msg:     map[string]any{"text": "hello"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: root is not an object",
+			root:    "data",
+			fields:  []operation.Field{{Name: "text"}},
+			msg:     map[string]any{"data": []any{"hello"}},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Service{cfg: &operation.Operation{Root: tt.root, Fields: tt.fields}}
+
+			got, err := s.extractFields(tt.msg)
+			tt.wantErr(t, err)
+
+			if tt.want != nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestService_WithFields(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{
+		Root:         "data",
+		Fields:       []operation.Field{{Name: "user_id", Path: "user.id"}},
+		PartitionKey: "user_id",
+	}}
+
+	msg := worker.Message{Data: map[string]any{"data": map[string]any{"user": map[string]any{"id": "u-1"}}}}
+
+	assert.Equal(t, map[string]any{"user_id": "u-1"}, s.withFields(msg).Data)
+	assert.Equal(t, "u-1", s.partitionKey(item{msg: msg}))
+
+	// тело не разворачивается: ключ берется из исходного сообщения
+	broken := worker.Message{Data: map[string]any{"user_id": "u-2"}}
+	assert.Equal(t, broken, s.withFields(broken))
+}
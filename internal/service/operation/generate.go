@@ -0,0 +1,124 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/pkg/random"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// txIDLength - длина айди транзакции, как у айди, который генерирует хранилище.
+const txIDLength = 20
+
+// newTxID генерирует айди транзакции для полей с generator: tx_id.
+// Пустая строка - в операции нет таких полей, айди сгенерирует хранилище.
+func (s *Service) newTxID() string {
+	for _, field := range s.cfg.Fields {
+		if field.Generator == operation.GeneratorTxID {
+			return random.String(txIDLength)
+		}
+	}
+
+	return ""
+}
+
+// applyGenerated подставляет значения по умолчанию полям, которых нет в сообщении, и вычисляет значения генераторов.
+// payload - исходное тело сообщения, txID - айди транзакции, в которой будет записано сообщение.
+// Значения попадают в данные транзакции, поэтому при повторе транзакции после рестарта не вычисляются заново.
+// Возвращает копию сообщения, при ошибке - исходное сообщение.
+func (s *Service) applyGenerated(msg, payload map[string]any, txID string) (map[string]any, error) {
+	var out map[string]any
+
+	for _, field := range s.cfg.Fields {
+		var (
+			val any
+			err error
+		)
+
+		switch {
+		case field.Generator != "":
+			val, err = s.generate(field, payload, txID)
+		case field.Default != nil:
+			if _, ok := msg[field.Name]; ok {
+				continue
+			}
+
+			val, err = convertFieldValue(field, field.Default)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return msg, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(msg)+1)
+			for k, v := range msg {
+				out[k] = v
+			}
+		}
+
+		out[field.Name] = val
+	}
+
+	if out == nil {
+		return msg, nil
+	}
+
+	return out, nil
+}
+
+// generate вычисляет значение генератора поля и приводит его к типу поля.
+func (s *Service) generate(field operation.Field, payload map[string]any, txID string) (any, error) {
+	var val any
+
+	switch field.Generator {
+	case operation.GeneratorNow:
+		val = time.Now()
+	case operation.GeneratorUUIDv4:
+		val = uuid.New()
+	case operation.GeneratorUUIDv7:
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("error generate uuid v7: %w", err)
+		}
+
+		val = id
+	case operation.GeneratorInstanceID:
+		val = int64(s.instanceID)
+	case operation.GeneratorOperationHash:
+		val = hex.EncodeToString(s.cfg.Hash)
+	case operation.GeneratorTxID:
+		val = txID
+	case operation.GeneratorPayload:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("error marshal payload: %w", err)
+		}
+
+		val = string(data)
+	default:
+		return nil, fmt.Errorf("unknown generator %q", field.Generator)
+	}
+
+	return convertFieldValue(field, val)
+}
+
+// setTxID записывает новый айди транзакции в поля с generator: tx_id. Нужен, когда сообщения пачки
+// записываются заново другой транзакцией.
+func (s *Service) setTxID(rows []map[string]any, txID string) {
+	for _, field := range s.cfg.Fields {
+		if field.Generator != operation.GeneratorTxID {
+			continue
+		}
+
+		for _, row := range rows {
+			row[field.Name] = txID
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyGenerated(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{
+		instanceID: 3,
+		cfg: &operation.Operation{
+			Hash: []byte{0xab, 0xcd},
+			Fields: []operation.Field{
+				{Name: "text", Type: operation.FieldTypeString},
+				{Name: "status", Type: operation.FieldTypeString, Default: "new"},
+				{Name: "priority", Type: operation.FieldTypeInt64, Default: 1},
+				{Name: "id", Type: operation.FieldTypeUUID, Generator: operation.GeneratorUUIDv7},
+				{Name: "request_id", Type: operation.FieldTypeString, Generator: operation.GeneratorUUIDv4},
+				{Name: "created_at", Type: operation.FieldTypeInt64, Generator: operation.GeneratorNow},
+				{Name: "instance_id", Type: operation.FieldTypeInt64, Generator: operation.GeneratorInstanceID},
+				{Name: "operation_hash", Type: operation.FieldTypeString, Generator: operation.GeneratorOperationHash},
+				{Name: "tx_id", Type: operation.FieldTypeString, Generator: operation.GeneratorTxID},
+				{Name: "raw", Type: operation.FieldTypeString, Generator: operation.GeneratorPayload},
+			},
+		},
+	}
+
+	payload := map[string]any{"note": map[string]any{"text": "hello"}, "priority": float64(5)}
+	msg := map[string]any{"text": "hello", "priority": float64(5)}
+
+	before := time.Now().UnixMilli()
+
+	got, err := s.applyGenerated(msg, payload, "tx-1")
+	require.NoError(t, err)
+
+	// исходное сообщение не меняется
+	assert.Equal(t, map[string]any{"text": "hello", "priority": float64(5)}, msg)
+
+	assert.Equal(t, "hello", got["text"])
+	assert.Equal(t, "new", got["status"])
+	// значение из сообщения важнее значения по умолчанию
+	assert.Equal(t, float64(5), got["priority"])
+
+	id, ok := got["id"].(uuid.UUID)
+	require.True(t, ok)
+	assert.Equal(t, uuid.Version(7), id.Version())
+
+	_, err = uuid.Parse(got["request_id"].(string))
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, got["created_at"], before)
+	assert.Equal(t, int64(3), got["instance_id"])
+	assert.Equal(t, "abcd", got["operation_hash"])
+	assert.Equal(t, "tx-1", got["tx_id"])
+	assert.JSONEq(t, `{"note": {"text": "hello"}, "priority": 5}`, got["raw"].(string))
+}
+
+func TestApplyGenerated_NoFields(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{{Name: "text", Type: operation.FieldTypeString}}}}
+
+	msg := map[string]any{"text": "hello"}
+
+	got, err := s.applyGenerated(msg, msg, "")
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+	assert.Empty(t, s.newTxID())
+}
+
+func TestSetTxID(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{
+		{Name: "text", Type: operation.FieldTypeString},
+		{Name: "tx_id", Type: operation.FieldTypeString, Generator: operation.GeneratorTxID},
+	}}}
+
+	txID := s.newTxID()
+	assert.Len(t, txID, txIDLength)
+
+	rows := []map[string]any{{"text": "a", "tx_id": "old"}, {"text": "b", "tx_id": "old"}}
+	s.setTxID(rows, txID)
+
+	assert.Equal(t, []map[string]any{{"text": "a", "tx_id": txID}, {"text": "b", "tx_id": txID}}, rows)
+}
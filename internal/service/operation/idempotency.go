@@ -0,0 +1,101 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"db-worker/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// skipDuplicate проверяет, не обработано ли сообщение раньше. Повторное сообщение сразу завершается
+// итогом исходной транзакции. Возвращает true, если сообщение обрабатывать не нужно.
+func (s *Service) skipDuplicate(ctx context.Context, msg worker.Message) bool {
+	if s.idempotency == nil {
+		return false
+	}
+
+	key, ok := s.idempotency.Key(s.withFields(msg))
+	if !ok {
+		logrus.WithFields(logrus.Fields{
+			"name":       s.cfg.Name,
+			"connection": s.cfg.Request.From,
+			"message":    msg.Data,
+		}).Warn("operation: message has no idempotency key, processing without check")
+
+		return false
+	}
+
+	txID, found, err := s.idempotency.Get(ctx, key)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":       s.cfg.Name,
+			"connection": s.cfg.Request.From,
+			"key":        key,
+		}).Error("operation: error check idempotency key")
+
+		// без проверки сообщение может записаться повторно: источник доставит его еще раз
+		msg.Persisted(nil, err)
+		msg.Done(worker.Result{Operation: s.cfg.Name, Err: err})
+
+		return true
+	}
+
+	if !found {
+		return false
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"connection":     s.cfg.Request.From,
+		"key":            key,
+		"transaction_id": txID,
+		"correlation_id": msg.Meta.CorrelationID,
+	}).Info("operation: duplicate message skipped")
+
+	msg.Persisted(nil, nil)
+	msg.Done(worker.Result{
+		Operation: s.cfg.Name,
+		TxID:      txID,
+		Status:    string(storage.TxStatusSuccess),
+		Duplicate: true,
+	})
+
+	return true
+}
+
+// saveIdempotencyKey запоминает ключ сообщения, транзакция которого закоммичена.
+func (s *Service) saveIdempotencyKey(ctx context.Context, msg worker.Message, res uow.Result) {
+	if s.idempotency == nil || res.Status != string(storage.TxStatusSuccess) {
+		return
+	}
+
+	key, ok := s.idempotency.Key(s.withFields(msg))
+	if !ok {
+		return
+	}
+
+	if err := s.idempotency.Save(ctx, key, res.TxID); err != nil {
+		// транзакция уже закоммичена: повтор этого сообщения будет записан еще раз
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":           s.cfg.Name,
+			"connection":     s.cfg.Request.From,
+			"key":            key,
+			"transaction_id": res.TxID,
+		}).Error("operation: error save idempotency key")
+	}
+}
+
+// withFields возвращает копию сообщения с полями, извлеченными из тела (root и path): ключ идемпотентности
+// берется из значений полей операции. Если поля извлечь не удалось - тело остается исходным.
+func (s *Service) withFields(msg worker.Message) worker.Message {
+	data, err := s.extractFields(msg.Data)
+	if err != nil {
+		return msg
+	}
+
+	msg.Data = data
+
+	return msg
+}
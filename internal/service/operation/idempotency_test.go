@@ -0,0 +1,123 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordNotifier запоминает уведомления операции о сообщении.
+type recordNotifier struct {
+	persisted  bool
+	persistErr error
+	done       *worker.Result
+}
+
+func (n *recordNotifier) Persisted(_ []uuid.UUID, err error) {
+	n.persisted = true
+	n.persistErr = err
+}
+
+func (n *recordNotifier) Done(res worker.Result) {
+	n.done = &res
+}
+
+//nolint:funlen // много тест-кейсов
+func TestSkipDuplicate(t *testing.T) {
+	t.Parallel()
+
+	const key = "db-worker:idempotency:test:msg-1"
+
+	tests := []struct {
+		name       string
+		setupMocks func(store *mocks.MockidempotencyStore)
+		want       bool
+		wantDone   *worker.Result
+	}{
+		{
+			name: "positive case: new message",
+			setupMocks: func(store *mocks.MockidempotencyStore) {
+				store.EXPECT().Key(gomock.Any()).Return(key, true)
+				store.EXPECT().Get(gomock.Any(), key).Return("", false, nil)
+			},
+			want: false,
+		},
+		{
+			name: "positive case: duplicate",
+			setupMocks: func(store *mocks.MockidempotencyStore) {
+				store.EXPECT().Key(gomock.Any()).Return(key, true)
+				store.EXPECT().Get(gomock.Any(), key).Return("tx-1", true, nil)
+			},
+			want:     true,
+			wantDone: &worker.Result{Operation: "test", TxID: "tx-1", Status: "SUCCESS", Duplicate: true},
+		},
+		{
+			name: "positive case: no key",
+			setupMocks: func(store *mocks.MockidempotencyStore) {
+				store.EXPECT().Key(gomock.Any()).Return("", false)
+			},
+			want: false,
+		},
+		{
+			name: "negative case: redis error",
+			setupMocks: func(store *mocks.MockidempotencyStore) {
+				store.EXPECT().Key(gomock.Any()).Return(key, true)
+				store.EXPECT().Get(gomock.Any(), key).Return("", false, errors.New("redis error"))
+			},
+			want:     true,
+			wantDone: &worker.Result{Operation: "test", Err: errors.New("redis error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			store := mocks.NewMockidempotencyStore(ctrl)
+
+			tt.setupMocks(store)
+
+			svc := &Service{cfg: &operation.Operation{Name: "test"}, idempotency: store}
+			n := &recordNotifier{}
+
+			got := svc.skipDuplicate(t.Context(), worker.Message{Meta: worker.Metadata{MessageID: "msg-1"}, Notifier: n})
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, n.persisted)
+			assert.Equal(t, tt.wantDone, n.done)
+		})
+	}
+}
+
+func TestSkipDuplicate_NotConfigured(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{cfg: &operation.Operation{Name: "test"}}
+
+	assert.False(t, svc.skipDuplicate(t.Context(), worker.Message{}))
+}
+
+func TestSaveIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockidempotencyStore(ctrl)
+
+	svc := &Service{cfg: &operation.Operation{Name: "test"}, idempotency: store}
+	msg := worker.Message{Meta: worker.Metadata{MessageID: "msg-1"}}
+
+	store.EXPECT().Key(msg).Return("key", true)
+	store.EXPECT().Save(gomock.Any(), "key", "tx-1").Return(nil)
+
+	svc.saveIdempotencyKey(t.Context(), msg, uow.Result{TxID: "tx-1", Status: "SUCCESS"})
+
+	// неуспешная транзакция не запоминается
+	svc.saveIdempotencyKey(t.Context(), msg, uow.Result{TxID: "tx-2", Status: "FAILED"})
+}
@@ -2,14 +2,23 @@ package operation
 
 import (
 	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/deadletter"
 	"db-worker/internal/service/operation/message"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// readMessages читает сообщения и копит их в буфере. Буфер обрабатывается, когда в нем buffer сообщений,
+// суммарный размер сообщений достиг buffer_bytes или первое сообщение ждет дольше timeout - что наступит раньше.
+// Время ожидания проверяется по тикеру, поэтому частично заполненный буфер обрабатывается и без новых сообщений.
+//
 //nolint:gocognit,funlen // цельная логика функции, много строк из-за логов
 func (s *Service) readMessages(ctx context.Context) {
 	logrus.WithFields(logrus.Fields{
@@ -17,6 +26,15 @@ func (s *Service) readMessages(ctx context.Context) {
 		"connection": s.cfg.Request.From,
 	}).Info("operation: start read messages")
 
+	var tick <-chan time.Time // nil-канал никогда не срабатывает: время ожидания не ограничено
+
+	if interval := s.buffer.tick(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -33,6 +51,10 @@ func (s *Service) readMessages(ctx context.Context) {
 			}).Info("operation: quit channel received")
 
 			return
+		case now := <-tick:
+			if reason, ok := s.buffer.ready(now); ok {
+				s.flush(ctx, reason)
+			}
 		case msg, ok := <-s.msgChan:
 			if !ok {
 				logrus.WithFields(logrus.Fields{
@@ -43,8 +65,13 @@ func (s *Service) readMessages(ctx context.Context) {
 				return
 			}
 
+			// повторно доставленное сообщение уже записано: не создаем для него сообщения и транзакцию
+			if s.skipDuplicate(ctx, msg) {
+				continue
+			}
+
 			// сохраняем в БД сообщения для каждого драйвера
-			ids, err := s.createMessages(ctx, msg)
+			ids, err := s.createMessages(ctx, msg.Data)
 			if err != nil {
 				logrus.WithError(err).WithFields(logrus.Fields{
 					"name":       s.cfg.Name,
@@ -53,9 +80,14 @@ func (s *Service) readMessages(ctx context.Context) {
 
 				s.deleteMessagesFromMap(ids) // удаляем сообщения из карты, т.к. они не были созданы
 
+				msg.Persisted(nil, err)
+				msg.Done(worker.Result{Operation: s.cfg.Name, Err: err})
+
 				continue
 			}
 
+			msg.Persisted(ids, nil)
+
 			s.addTotalMessages(len(ids))
 
 			if err := s.buffer.add(ids, msg); err != nil {
@@ -64,51 +96,94 @@ func (s *Service) readMessages(ctx context.Context) {
 					"connection": s.cfg.Request.From,
 				}).Error("operation: error add message to buffer")
 
+				msg.Done(worker.Result{Operation: s.cfg.Name, IDs: ids, Err: err})
+
 				continue
 			}
 
-			// не начинаем обработку, если буфер не заполнен и канал не пуст
-			if !s.buffer.isFull() && len(s.msgChan) > 0 {
+			reason, ok := s.buffer.ready(time.Now())
+			if !ok {
 				logrus.WithFields(logrus.Fields{
 					"name":       s.cfg.Name,
 					"connection": s.cfg.Request.From,
-				}).Debug("operation: buffer is not full and message channel is not empty")
+					"count":      s.buffer.count(),
+				}).Debug("operation: message added to buffer")
 
 				continue
 			}
 
-			logrus.WithFields(logrus.Fields{
-				"name":       s.cfg.Name,
-				"connection": s.cfg.Request.From,
-				"count":      s.buffer.count(),
-				"ids":        ids,
-			}).Info("operation: buffer is full or message channel is empty. Processing messages...")
+			s.flush(ctx, reason)
+		}
+	}
+}
 
-			if err := s.processMessages(ctx); err != nil {
-				logrus.WithError(err).WithFields(logrus.Fields{
-					"name":       s.cfg.Name,
-					"connection": s.cfg.Request.From,
-				}).Error("operation: error process messages")
+// flush обрабатывает сообщения буфера и очищает его.
+func (s *Service) flush(ctx context.Context, reason flushReason) {
+	count := s.buffer.count()
 
-				continue
-			}
+	logrus.WithFields(logrus.Fields{
+		"name":       s.cfg.Name,
+		"connection": s.cfg.Request.From,
+		"count":      count,
+		"reason":     reason,
+	}).Info("operation: flush buffer. Processing messages...")
+
+	if s.bufferMetrics != nil {
+		s.bufferMetrics.ObserveBufferFlush(s.cfg.Name, string(reason), count)
+	}
+
+	// сообщения обрабатывают воркеры: буфер сразу готов к новым сообщениям
+	if s.pool != nil {
+		items := s.buffer.getAll()
+		s.buffer.clear()
 
+		if !s.dispatch(ctx, items) {
 			logrus.WithFields(logrus.Fields{
 				"name":       s.cfg.Name,
-				"message":    msg,
 				"connection": s.cfg.Request.From,
-			}).Info("operation: message processed")
-
-			s.buffer.clear()
+				"count":      count,
+			}).Warn("operation: stopped before messages were passed to workers")
 		}
+
+		return
+	}
+
+	err := s.processMessages(ctx)
+
+	// очищаем буфер в любом случае: каждое сообщение уже получило свой итоговый статус
+	s.buffer.clear()
+
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":       s.cfg.Name,
+			"connection": s.cfg.Request.From,
+		}).Error("operation: error process messages")
+
+		return
 	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":       s.cfg.Name,
+		"connection": s.cfg.Request.From,
+		"count":      count,
+	}).Info("operation: messages processed")
 }
 
 func (s *Service) processMessages(ctx context.Context) error {
-	errs := make([]error, 0, s.buffer.count())
+	return s.processItems(ctx, s.buffer.getAll())
+}
 
-	for _, item := range s.buffer.getAll() {
-		if err := s.processMessage(ctx, item.data, item.ids); err != nil {
+// processItems обрабатывает сообщения буфера и передает итог каждого сообщения источнику.
+func (s *Service) processItems(ctx context.Context, items []item) error {
+	// сообщения create операции записываются одной транзакцией на весь буфер
+	if s.cfg.Type == operation.OperationTypeCreate && len(items) > 1 {
+		return s.processBatch(ctx, items)
+	}
+
+	errs := make([]error, 0, len(items))
+
+	for _, item := range items {
+		if err := s.processItem(ctx, item); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -116,76 +191,84 @@ func (s *Service) processMessages(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
+// processItem обрабатывает сообщение буфера отдельной транзакцией и передает итог источнику.
+func (s *Service) processItem(ctx context.Context, item item) error {
+	res, err := s.processMessage(ctx, item.msg.Data, item.msg.Meta, item.ids)
+
+	s.finishItem(ctx, item, res, err)
+
+	return err
+}
+
+// finishItem сохраняет ключ идемпотентности успешного сообщения, публикует итог транзакции и передает его источнику.
+func (s *Service) finishItem(ctx context.Context, item item, res uow.Result, err error) {
+	if err == nil {
+		s.saveIdempotencyKey(ctx, item.msg, res)
+	}
+
+	result := worker.Result{
+		Operation:    s.cfg.Name,
+		IDs:          item.ids,
+		TxID:         res.TxID,
+		Status:       res.Status,
+		FailedDriver: res.FailedDriver,
+		Err:          err,
+	}
+
+	s.publishEvent(ctx, item.msg.Meta, result)
+
+	item.msg.Done(result)
+}
+
 // processMessage обрабатывает сообщение - валидирует, строит запросы и передает на выполнение в UOW.
-// Принимает сообщение и список айдишников созданных сообщений.
+// Принимает сообщение, его метаданные и список айдишников созданных сообщений.
+// Возвращает итог выполнения транзакции (пустой, если до транзакции дело не дошло).
 //
 //nolint:funlen // цельная логика функции, много строк из-за логов
-func (s *Service) processMessage(ctx context.Context, msg map[string]any, ids []uuid.UUID) error {
-	logrus.WithFields(logrus.Fields{
-		"name":       s.cfg.Name,
-		"message":    msg,
-		"connection": s.cfg.Request.From,
-		"ids":        ids,
-	}).Info("operation: received message")
+func (s *Service) processMessage(ctx context.Context, msg map[string]any, meta worker.Metadata, ids []uuid.UUID) (uow.Result, error) {
+	defer s.releaseMessages(ids)
 
-	defer func() {
-		s.deleteMessagesFromMap(ids) // удаляем сообщения из мапы, т.к. они обработаны
-		s.addProcessedMessages(len(ids))
-	}()
+	// в dead letter уходит исходное тело сообщения, а не поля, подготовленные для запросов
+	payload := msg
 
-	err := s.validateMessage(msg)
+	msg, err := s.prepareMessage(ctx, msg, meta, ids, s.newTxID())
 	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"name":       s.cfg.Name,
-			"message":    msg,
-			"connection": s.cfg.Request.From,
-			"ids":        ids,
-		}).Error("operation: error validate message")
-
-		// обновляем статус сообщений в БД: failed
-		if err := s.updateMessagesStatus(ctx, message.StatusFailed, ids, err); err != nil {
-			s.addFailedMessages(len(ids))
-			return fmt.Errorf("error update messages: %w", err)
-		}
-
-		return fmt.Errorf("error validate message: %w", err)
+		return uow.Result{}, err
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"name":       s.cfg.Name,
-		"message":    msg,
-		"connection": s.cfg.Request.From,
-		"ids":        ids,
-	}).Info("operation: message validated")
-
 	requests, err := s.uow.BuildRequests(msg, s.uow.StoragesMap(), *s.cfg)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
-			"name":       s.cfg.Name,
-			"message":    msg,
-			"connection": s.cfg.Request.From,
-			"ids":        ids,
+			"name":           s.cfg.Name,
+			"message":        msg,
+			"connection":     s.cfg.Request.From,
+			"ids":            ids,
+			"correlation_id": meta.CorrelationID,
 		}).Error("operation: error build requests")
 
+		s.publishDeadLetter(ctx, deadletter.StageBuild, payload, ids, err)
+
 		// обновляем статус сообщений в БД: failed
 		if err := s.updateMessagesStatus(ctx, message.StatusFailed, ids, err); err != nil {
 			s.addFailedMessages(len(ids))
-			return fmt.Errorf("error update messages: %w", err)
+			return uow.Result{}, fmt.Errorf("error update messages: %w", err)
 		}
 
-		return fmt.Errorf("error build requests: %w", err)
+		return uow.Result{}, fmt.Errorf("%w: error build requests: %w", worker.ErrInvalidMessage, err)
 	}
 
 	// обновляем статус сообщений в БД: validated
 	if err := s.updateMessagesStatus(ctx, message.StatusValidated, ids, nil); err != nil {
 		s.addFailedMessages(len(ids))
-		return fmt.Errorf("error update messages: %w", err)
+		return uow.Result{}, fmt.Errorf("error update messages: %w", err)
 	}
 
-	err = s.uow.ExecRequests(ctx, requests, msg)
+	res, err := s.uow.ExecRequests(ctx, requests, msg, meta)
 	if err != nil {
+		s.publishDeadLetter(ctx, deadletter.StageExec, payload, ids, err)
+
 		// не обновляем статус сообщений, т.к. валидация прошла успешно, а дальше это работа UOW
-		return fmt.Errorf("error exec requests: %w", err)
+		return res, fmt.Errorf("error exec requests: %w", err)
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -194,9 +277,85 @@ func (s *Service) processMessage(ctx context.Context, msg map[string]any, ids []
 		"connection":     s.cfg.Request.From,
 		"requests_count": len(requests),
 		"ids":            ids,
+		"correlation_id": meta.CorrelationID,
+		"transaction_id": res.TxID,
 	}).Info("operation: requests executed")
 
-	return nil
+	return res, nil
+}
+
+// prepareMessage достает поля из тела сообщения, подставляет значения из источников, значения по умолчанию
+// и значения генераторов (txID - айди транзакции для generator: tx_id), применяет преобразования полей,
+// разбирает значения timestamp, date и decimal и валидирует сообщение.
+// Если сообщение не прошло валидацию - отправляет исходное тело в dead letter и переводит сообщения в статус failed.
+// Возвращает сообщение, готовое для построения запросов.
+func (s *Service) prepareMessage(ctx context.Context, msg map[string]any, meta worker.Metadata, ids []uuid.UUID, txID string) (map[string]any, error) {
+	logrus.WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"message":        msg,
+		"connection":     s.cfg.Request.From,
+		"ids":            ids,
+		"correlation_id": meta.CorrelationID,
+	}).Info("operation: received message")
+
+	payload := msg
+
+	msg, err := s.extractFields(msg)
+	if err == nil {
+		msg, err = s.applySources(msg, meta)
+	}
+
+	if err == nil {
+		msg, err = s.applyGenerated(msg, payload, txID)
+	}
+
+	if err == nil {
+		msg, err = s.applyTransforms(msg)
+	}
+
+	if err == nil {
+		msg, err = s.parseFieldValues(msg)
+	}
+
+	if err == nil {
+		err = s.validateMessage(msg)
+	}
+
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":           s.cfg.Name,
+			"message":        msg,
+			"connection":     s.cfg.Request.From,
+			"ids":            ids,
+			"correlation_id": meta.CorrelationID,
+		}).Error("operation: error validate message")
+
+		s.publishDeadLetter(ctx, deadletter.StageValidation, payload, ids, err)
+
+		// обновляем статус сообщений в БД: failed
+		if err := s.updateMessagesStatus(ctx, message.StatusFailed, ids, err); err != nil {
+			s.addFailedMessages(len(ids))
+			return nil, fmt.Errorf("error update messages: %w", err)
+		}
+
+		return nil, fmt.Errorf("%w: error validate message: %w", worker.ErrInvalidMessage, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":           s.cfg.Name,
+		"message":        msg,
+		"connection":     s.cfg.Request.From,
+		"ids":            ids,
+		"correlation_id": meta.CorrelationID,
+	}).Info("operation: message validated")
+
+	return msg, nil
+}
+
+// releaseMessages удаляет обработанные сообщения из мапы и учитывает их в метриках.
+func (s *Service) releaseMessages(ids []uuid.UUID) {
+	s.deleteMessagesFromMap(ids)
+	s.addProcessedMessages(len(ids))
 }
 
 // createMessages создает экземпляры сообщений для каждого драйвера и возвращает список айдишников созданных сообщений.
@@ -6,6 +6,7 @@ import (
 	"db-worker/internal/service/operation/message"
 	"db-worker/internal/service/operation/mocks"
 	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
 	storagemocks "db-worker/internal/storage/mocks"
 	"db-worker/internal/storage/model"
 	"errors"
@@ -56,7 +57,7 @@ func TestReadMessages(t *testing.T) {
 				"test-storage-2": configurator2,
 			}
 
-			buffer, err := newBuffer(10)
+			buffer, err := newBuffer(10, 0, 0)
 			require.NoError(t, err)
 
 			svc := &Service{
@@ -69,7 +70,7 @@ func TestReadMessages(t *testing.T) {
 				instanceID:     1,
 				metricsService: metricsService,
 				messages:       make(map[uuid.UUID]*message.Message),
-				msgChan:        make(chan map[string]interface{}),
+				msgChan:        make(chan worker.Message),
 				quitChan:       make(chan struct{}),
 				buffer:         buffer,
 			}
@@ -77,7 +78,7 @@ func TestReadMessages(t *testing.T) {
 			// AnyTimes - потому что мы не знаем, в какой момент будет закрыть канал
 			mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
 			mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
-			mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uow.Result{}, nil).AnyTimes()
 
 			configurator1.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
 			configurator1.EXPECT().Name().Return("test-storage").AnyTimes()
@@ -115,7 +116,7 @@ func TestReadMessages(t *testing.T) {
 				svc.readMessages(t.Context())
 			}()
 
-			svc.msgChan <- tt.msg
+			svc.msgChan <- worker.Message{Data: tt.msg}
 
 			// Даем время на обработку сообщения перед закрытием канала
 			time.Sleep(10 * time.Millisecond)
@@ -128,6 +129,85 @@ func TestReadMessages(t *testing.T) {
 	}
 }
 
+func TestReadMessages_FlushByTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUow := mocks.NewMockunitOfWork(ctrl)
+	messageRepo := mocks.NewMockmessageRepo(ctrl)
+	metricsService := mocks.NewMockmessageCounter(ctrl)
+	bufferMetrics := mocks.NewMockbufferMetrics(ctrl)
+
+	configurator := storagemocks.NewMockConfigurator(ctrl)
+
+	// буфер на 10 сообщений не заполнится: обработать его должен тикер
+	buffer, err := newBuffer(10, 0, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	svc := &Service{
+		cfg: &operation.Operation{
+			Name: "test",
+		},
+		messageRepo:    messageRepo,
+		uow:            mockUow,
+		driversMap:     map[string]model.Configurator{"test-storage": configurator},
+		instanceID:     1,
+		metricsService: metricsService,
+		bufferMetrics:  bufferMetrics,
+		messages:       make(map[uuid.UUID]*message.Message),
+		msgChan:        make(chan worker.Message),
+		quitChan:       make(chan struct{}),
+		buffer:         buffer,
+	}
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uow.Result{}, nil).AnyTimes()
+
+	configurator.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+	configurator.EXPECT().Name().Return("test-storage").AnyTimes()
+
+	messageRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	messageRepo.EXPECT().UpdateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	metricsService.EXPECT().AddTotalMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddProcessingMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddValidatedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
+
+	flushed := make(chan struct{})
+
+	bufferMetrics.EXPECT().ObserveBufferFlush("test", string(flushReasonTimeout), 2).Do(func(_, _ string, _ int) {
+		close(flushed)
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		svc.readMessages(t.Context())
+	}()
+
+	svc.msgChan <- worker.Message{Data: map[string]any{"field1": "first"}}
+	svc.msgChan <- worker.Message{Data: map[string]any{"field1": "second"}}
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("buffer was not flushed by timeout")
+	}
+
+	close(svc.quitChan)
+	wg.Wait()
+
+	assert.Zero(t, svc.buffer.count())
+}
+
 //nolint:funlen,gocognit,cyclop // много тест-кейсов, сложный тест - ок
 func TestProcessMessage(t *testing.T) {
 	t.Parallel()
@@ -164,7 +244,7 @@ func TestProcessMessage(t *testing.T) {
 
 				mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).Times(1)
 				mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
-				mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uow.Result{}, nil).Times(1)
 
 				messageRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Return(nil).Times(1).Do(func(ctx context.Context, messages []message.Message) error {
 					for _, msg := range messages {
@@ -255,7 +335,9 @@ func TestProcessMessage(t *testing.T) {
 			msg: map[string]interface{}{
 				"field1": "test",
 			},
-			wantErr: require.Error,
+			wantErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, worker.ErrInvalidMessage)
+			},
 		},
 		{
 			name: "negative case: error build requests",
@@ -310,7 +392,9 @@ func TestProcessMessage(t *testing.T) {
 				metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
 				metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
 			},
-			wantErr: require.Error,
+			wantErr: func(t require.TestingT, err error, _ ...any) {
+				require.ErrorIs(t, err, worker.ErrInvalidMessage)
+			},
 		},
 		{
 			name: "negative case: error exec requests",
@@ -337,7 +421,7 @@ func TestProcessMessage(t *testing.T) {
 
 				mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).Times(1)
 				mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
-				mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error")).Times(1)
+				mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(uow.Result{}, errors.New("error")).Times(1)
 
 				messageRepo.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Return(nil).Times(1).Do(func(ctx context.Context, messages []message.Message) error {
 					for _, msg := range messages {
@@ -397,7 +481,7 @@ func TestProcessMessage(t *testing.T) {
 			ids, err := svc.createMessages(t.Context(), tt.msg)
 			require.NoError(t, err)
 
-			err = svc.processMessage(t.Context(), tt.msg, ids)
+			_, err = svc.processMessage(t.Context(), tt.msg, worker.Metadata{}, ids)
 			tt.wantErr(t, err)
 
 			assert.Len(t, svc.messages, 0)
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	operation "db-worker/internal/config/operation"
+	deadletter "db-worker/internal/service/deadletter"
 	message "db-worker/internal/service/operation/message"
 	uow "db-worker/internal/service/uow"
+	worker "db-worker/internal/service/worker"
 	storage "db-worker/internal/storage"
 	reflect "reflect"
 
@@ -38,6 +40,21 @@ func (m *MockunitOfWork) EXPECT() *MockunitOfWorkMockRecorder {
 	return m.recorder
 }
 
+// BuildBatchRequests mocks base method.
+func (m *MockunitOfWork) BuildBatchRequests(msgs []map[string]any, driversMap map[string]uow.DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildBatchRequests", msgs, driversMap, operation)
+	ret0, _ := ret[0].(map[storage.Driver]*storage.Request)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BuildBatchRequests indicates an expected call of BuildBatchRequests.
+func (mr *MockunitOfWorkMockRecorder) BuildBatchRequests(msgs, driversMap, operation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildBatchRequests", reflect.TypeOf((*MockunitOfWork)(nil).BuildBatchRequests), msgs, driversMap, operation)
+}
+
 // BuildRequests mocks base method.
 func (m *MockunitOfWork) BuildRequests(msg map[string]interface{}, driversMap map[string]uow.DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error) {
 	m.ctrl.T.Helper()
@@ -54,17 +71,34 @@ func (mr *MockunitOfWorkMockRecorder) BuildRequests(msg, driversMap, operation i
 }
 
 // ExecRequests mocks base method.
-func (m *MockunitOfWork) ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any) error {
+func (m *MockunitOfWork) ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (uow.Result, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecRequests", ctx, requests, raw)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ExecRequests", ctx, requests, raw, meta)
+	ret0, _ := ret[0].(uow.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ExecRequests indicates an expected call of ExecRequests.
-func (mr *MockunitOfWorkMockRecorder) ExecRequests(ctx, requests, raw interface{}) *gomock.Call {
+func (mr *MockunitOfWorkMockRecorder) ExecRequests(ctx, requests, raw, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecRequests", reflect.TypeOf((*MockunitOfWork)(nil).ExecRequests), ctx, requests, raw, meta)
+}
+
+// ExecRowRequests mocks base method.
+func (m *MockunitOfWork) ExecRowRequests(ctx context.Context, rows []map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (uow.Result, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecRowRequests", ctx, rows, raw, meta)
+	ret0, _ := ret[0].(uow.Result)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ExecRowRequests indicates an expected call of ExecRowRequests.
+func (mr *MockunitOfWorkMockRecorder) ExecRowRequests(ctx, rows, raw, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecRequests", reflect.TypeOf((*MockunitOfWork)(nil).ExecRequests), ctx, requests, raw)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecRowRequests", reflect.TypeOf((*MockunitOfWork)(nil).ExecRowRequests), ctx, rows, raw, meta)
 }
 
 // StoragesMap mocks base method.
@@ -289,6 +323,183 @@ func (mr *MockmessageGetterMockRecorder) GetAll(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockmessageGetter)(nil).GetAll), ctx)
 }
 
+// MockdeadLetterPublisher is a mock of deadLetterPublisher interface.
+type MockdeadLetterPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockdeadLetterPublisherMockRecorder
+}
+
+// MockdeadLetterPublisherMockRecorder is the mock recorder for MockdeadLetterPublisher.
+type MockdeadLetterPublisherMockRecorder struct {
+	mock *MockdeadLetterPublisher
+}
+
+// NewMockdeadLetterPublisher creates a new mock instance.
+func NewMockdeadLetterPublisher(ctrl *gomock.Controller) *MockdeadLetterPublisher {
+	mock := &MockdeadLetterPublisher{ctrl: ctrl}
+	mock.recorder = &MockdeadLetterPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockdeadLetterPublisher) EXPECT() *MockdeadLetterPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockdeadLetterPublisher) Publish(ctx context.Context, letter deadletter.Letter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, letter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockdeadLetterPublisherMockRecorder) Publish(ctx, letter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockdeadLetterPublisher)(nil).Publish), ctx, letter)
+}
+
+// MockeventPublisher is a mock of eventPublisher interface.
+type MockeventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockeventPublisherMockRecorder
+}
+
+// MockeventPublisherMockRecorder is the mock recorder for MockeventPublisher.
+type MockeventPublisherMockRecorder struct {
+	mock *MockeventPublisher
+}
+
+// NewMockeventPublisher creates a new mock instance.
+func NewMockeventPublisher(ctrl *gomock.Controller) *MockeventPublisher {
+	mock := &MockeventPublisher{ctrl: ctrl}
+	mock.recorder = &MockeventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockeventPublisher) EXPECT() *MockeventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockeventPublisher) Publish(ctx context.Context, event worker.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockeventPublisherMockRecorder) Publish(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockeventPublisher)(nil).Publish), ctx, event)
+}
+
+// MockidempotencyStore is a mock of idempotencyStore interface.
+type MockidempotencyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockidempotencyStoreMockRecorder
+}
+
+// MockidempotencyStoreMockRecorder is the mock recorder for MockidempotencyStore.
+type MockidempotencyStoreMockRecorder struct {
+	mock *MockidempotencyStore
+}
+
+// NewMockidempotencyStore creates a new mock instance.
+func NewMockidempotencyStore(ctrl *gomock.Controller) *MockidempotencyStore {
+	mock := &MockidempotencyStore{ctrl: ctrl}
+	mock.recorder = &MockidempotencyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockidempotencyStore) EXPECT() *MockidempotencyStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockidempotencyStore) Get(ctx context.Context, key string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockidempotencyStoreMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockidempotencyStore)(nil).Get), ctx, key)
+}
+
+// Key mocks base method.
+func (m *MockidempotencyStore) Key(msg worker.Message) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key", msg)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Key indicates an expected call of Key.
+func (mr *MockidempotencyStoreMockRecorder) Key(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockidempotencyStore)(nil).Key), msg)
+}
+
+// Save mocks base method.
+func (m *MockidempotencyStore) Save(ctx context.Context, key, txID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, key, txID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockidempotencyStoreMockRecorder) Save(ctx, key, txID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockidempotencyStore)(nil).Save), ctx, key, txID)
+}
+
+// MockbufferMetrics is a mock of bufferMetrics interface.
+type MockbufferMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockbufferMetricsMockRecorder
+}
+
+// MockbufferMetricsMockRecorder is the mock recorder for MockbufferMetrics.
+type MockbufferMetricsMockRecorder struct {
+	mock *MockbufferMetrics
+}
+
+// NewMockbufferMetrics creates a new mock instance.
+func NewMockbufferMetrics(ctrl *gomock.Controller) *MockbufferMetrics {
+	mock := &MockbufferMetrics{ctrl: ctrl}
+	mock.recorder = &MockbufferMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockbufferMetrics) EXPECT() *MockbufferMetricsMockRecorder {
+	return m.recorder
+}
+
+// ObserveBufferFlush mocks base method.
+func (m *MockbufferMetrics) ObserveBufferFlush(operation, reason string, size int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveBufferFlush", operation, reason, size)
+}
+
+// ObserveBufferFlush indicates an expected call of ObserveBufferFlush.
+func (mr *MockbufferMetricsMockRecorder) ObserveBufferFlush(operation, reason, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveBufferFlush", reflect.TypeOf((*MockbufferMetrics)(nil).ObserveBufferFlush), operation, reason, size)
+}
+
 // MockmessageCounter is a mock of messageCounter interface.
 type MockmessageCounter struct {
 	ctrl     *gomock.Controller
@@ -3,13 +3,16 @@ package operation
 import (
 	"context"
 	"db-worker/internal/config/operation"
+	"db-worker/internal/service/deadletter"
 	"db-worker/internal/service/operation/message"
 	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
 	"db-worker/internal/storage"
 	"db-worker/internal/storage/model"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -23,14 +26,21 @@ type Service struct {
 	buffer     *buffer
 	bufferSize int
 
+	pool *pool // воркеры, обрабатывающие сообщения параллельно. nil - сообщения обрабатываются последовательно
+
 	mu sync.Mutex
 
 	uow unitOfWork
 
 	messageRepo    messageRepo    // репозиторий для работы с сообщениями
 	metricsService messageCounter // сервис для работы с метриками
+	bufferMetrics  bufferMetrics  // метрики обработки буфера. Может быть nil
+
+	deadLetter  deadLetterPublisher // куда отправлять необработанные сообщения. Может быть nil
+	idempotency idempotencyStore    // ключи уже обработанных сообщений. Может быть nil
+	events      eventPublisher      // куда публиковать итоги транзакций. Может быть nil
 
-	msgChan  chan map[string]interface{}
+	msgChan  chan worker.Message
 	quitChan chan struct{}
 
 	messages map[uuid.UUID]*message.Message
@@ -43,7 +53,9 @@ type Service struct {
 //go:generate mockgen -source=operation.go -destination=mocks/mocks.go -package=mocks
 type unitOfWork interface {
 	BuildRequests(msg map[string]interface{}, driversMap map[string]uow.DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error)
-	ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any) error
+	BuildBatchRequests(msgs []map[string]any, driversMap map[string]uow.DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error)
+	ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (uow.Result, error)
+	ExecRowRequests(ctx context.Context, rows []map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (uow.Result, []error, error)
 	StoragesMap() map[string]uow.DriversMap
 }
 
@@ -66,6 +78,31 @@ type messageGetter interface {
 	GetAll(ctx context.Context) ([]message.Message, error)
 }
 
+// deadLetterPublisher отправляет сообщения, которые не удалось обработать, во внешнее хранилище.
+type deadLetterPublisher interface {
+	Publish(ctx context.Context, letter deadletter.Letter) error
+}
+
+// eventPublisher публикует итоги выполненных транзакций операции.
+type eventPublisher interface {
+	Publish(ctx context.Context, event worker.Event) error
+}
+
+// idempotencyStore хранит ключи сообщений, транзакции которых уже закоммичены.
+type idempotencyStore interface {
+	// Key возвращает ключ идемпотентности сообщения. false - у сообщения нет ключа.
+	Key(msg worker.Message) (string, bool)
+	// Get возвращает айди транзакции, которой обработано сообщение с ключом. false - сообщение еще не обработано.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Save запоминает, что сообщение с ключом обработано транзакцией txID.
+	Save(ctx context.Context, key, txID string) error
+}
+
+// bufferMetrics учитывает обработку буфера: причину и количество сообщений.
+type bufferMetrics interface {
+	ObserveBufferFlush(operation, reason string, size int)
+}
+
 type messageCounter interface {
 	messageAdder
 	messageDecrementer
@@ -91,67 +128,95 @@ type messageDecrementer interface {
 	DecrementFailedMessagesBy(count int)
 }
 
-// option определяет опции для сервиса.
-type option func(*Service)
+// Option определяет опции для сервиса.
+type Option func(*Service)
 
 // WithCfg устанавливает конфигурацию операции.
-func WithCfg(cfg *operation.Operation) option {
+func WithCfg(cfg *operation.Operation) Option {
 	return func(s *Service) {
 		s.cfg = cfg
 	}
 }
 
 // WithMsgChan устанавливает канал для получения сообщений.
-func WithMsgChan(msgChan chan map[string]interface{}) option {
+func WithMsgChan(msgChan chan worker.Message) Option {
 	return func(s *Service) {
 		s.msgChan = msgChan
 	}
 }
 
 // WithUow устанавливает сервис для работы с хранилищами.
-func WithUow(uow unitOfWork) option {
+func WithUow(uow unitOfWork) Option {
 	return func(s *Service) {
 		s.uow = uow
 	}
 }
 
 // WithMessageRepo устанавливает репозиторий для работы с сообщениями.
-func WithMessageRepo(messageRepo messageRepo) option {
+func WithMessageRepo(messageRepo messageRepo) Option {
 	return func(s *Service) {
 		s.messageRepo = messageRepo
 	}
 }
 
 // WithInstanceID устанавливает id экземпляра приложения.
-func WithInstanceID(instanceID int) option {
+func WithInstanceID(instanceID int) Option {
 	return func(s *Service) {
 		s.instanceID = instanceID
 	}
 }
 
 // WithDriversMap устанавливает мапу с драйверами.
-func WithDriversMap(driversMap map[string]model.Configurator) option {
+func WithDriversMap(driversMap map[string]model.Configurator) Option {
 	return func(s *Service) {
 		s.driversMap = driversMap
 	}
 }
 
 // WithMetricsService устанавливает сервис для работы с метриками.
-func WithMetricsService(metricsService messageCounter) option {
+func WithMetricsService(metricsService messageCounter) Option {
 	return func(s *Service) {
 		s.metricsService = metricsService
 	}
 }
 
+// WithBufferMetrics устанавливает метрики обработки буфера.
+func WithBufferMetrics(metrics bufferMetrics) Option {
+	return func(s *Service) {
+		s.bufferMetrics = metrics
+	}
+}
+
+// WithDeadLetter устанавливает, куда отправлять необработанные сообщения.
+func WithDeadLetter(deadLetter deadLetterPublisher) Option {
+	return func(s *Service) {
+		s.deadLetter = deadLetter
+	}
+}
+
+// WithIdempotency устанавливает хранилище ключей обработанных сообщений.
+func WithIdempotency(store idempotencyStore) Option {
+	return func(s *Service) {
+		s.idempotency = store
+	}
+}
+
+// WithEvents устанавливает, куда публиковать итоги выполненных транзакций.
+func WithEvents(events eventPublisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
 // WithBuffer устанавливает размер буфера.
-func WithBuffer(bufferSize int) option {
+func WithBuffer(bufferSize int) Option {
 	return func(s *Service) {
 		s.bufferSize = bufferSize
 	}
 }
 
 // New создает новый экземпляр сервиса.
-func New(opts ...option) (*Service, error) {
+func New(opts ...Option) (*Service, error) {
 	s := &Service{
 		messages: make(map[uuid.UUID]*message.Message),
 	}
@@ -192,11 +257,15 @@ func New(opts ...option) (*Service, error) {
 
 	var err error
 
-	s.buffer, err = newBuffer(s.bufferSize)
+	s.buffer, err = newBuffer(s.bufferSize, s.cfg.BufferBytes, time.Duration(s.cfg.Timeout)*time.Millisecond)
 	if err != nil {
 		return nil, fmt.Errorf("error creating buffer: %w", err)
 	}
 
+	if s.cfg.Concurrency > 1 {
+		s.pool = newPool(s.cfg.Concurrency, s.bufferSize)
+	}
+
 	s.quitChan = make(chan struct{})
 
 	return s, nil
@@ -204,6 +273,10 @@ func New(opts ...option) (*Service, error) {
 
 // Run запускает сервис.
 func (s *Service) Run(ctx context.Context) error {
+	if s.pool != nil {
+		s.pool.run(ctx, s.quitChan, s.processJob)
+	}
+
 	go s.readMessages(ctx)
 
 	return nil
@@ -217,5 +290,10 @@ func (s *Service) Stop(_ context.Context) error {
 
 	close(s.quitChan)
 
+	// дожидаемся транзакций, которые воркеры уже начали
+	if s.pool != nil {
+		s.pool.wait()
+	}
+
 	return nil
 }
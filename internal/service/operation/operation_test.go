@@ -4,6 +4,7 @@ import (
 	"context"
 	"db-worker/internal/config/operation"
 	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/worker"
 	storagemocks "db-worker/internal/storage/mocks"
 	"db-worker/internal/storage/model"
 	"testing"
@@ -17,20 +18,20 @@ import (
 func TestNew(t *testing.T) {
 	t.Parallel()
 
-	msgChan := make(chan map[string]interface{})
+	msgChan := make(chan worker.Message)
 
 	tests := []struct {
 		name       string
-		createOpts func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option
+		createOpts func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option
 		wantErr    require.ErrorAssertionFunc
 		createWant func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) *Service
 	}{
 		{
 			name: "positive case",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithUow(uow),
@@ -44,7 +45,7 @@ func TestNew(t *testing.T) {
 			createWant: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) *Service {
 				t.Helper()
 
-				buffer, err := newBuffer(10)
+				buffer, err := newBuffer(10, 0, 0)
 				require.NoError(t, err)
 
 				return &Service{
@@ -63,10 +64,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: cfg is nil",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithMsgChan(msgChan),
 					WithUow(uow),
 					WithMessageRepo(messageRepo),
@@ -80,10 +81,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: message channel is nil",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithUow(uow),
 					WithMessageRepo(messageRepo),
@@ -97,10 +98,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: uow is nil",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithMessageRepo(messageRepo),
@@ -114,10 +115,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: message repo is nil",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithUow(uow),
@@ -131,10 +132,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: drivers map is empty",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithUow(uow),
@@ -148,10 +149,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: metrics service is nil",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithUow(uow),
@@ -165,10 +166,10 @@ func TestNew(t *testing.T) {
 		},
 		{
 			name: "negative case: buffer size is 0",
-			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []option {
+			createOpts: func(t *testing.T, uow *mocks.MockunitOfWork, messageRepo *mocks.MockmessageRepo, driversMap map[string]model.Configurator, metricsService *mocks.MockmessageCounter) []Option {
 				t.Helper()
 
-				return []option{
+				return []Option{
 					WithCfg(&operation.Operation{}),
 					WithMsgChan(msgChan),
 					WithUow(uow),
@@ -0,0 +1,173 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// pool обрабатывает сообщения буфера в нескольких воркерах: медленная транзакция одного воркера не останавливает
+// остальные. Задача воркера - пачка сообщений create операции или одно сообщение.
+//
+// Задачи с ключом партиции всегда попадают к воркеру своей партиции и обрабатываются им по порядку,
+// задачи без ключа берет любой свободный воркер.
+type pool struct {
+	shared     chan []item   // задачи без ключа партиции
+	partitions []chan []item // задачи с ключом партиции: по очереди на воркер
+
+	wg sync.WaitGroup
+}
+
+// job - сообщения, которые воркер обрабатывает за раз, и очередь, в которую они передаются.
+type job struct {
+	items []item
+	queue chan<- []item
+}
+
+func newPool(concurrency, queueSize int) *pool {
+	p := &pool{
+		shared:     make(chan []item, queueSize),
+		partitions: make([]chan []item, concurrency),
+	}
+
+	for i := range p.partitions {
+		p.partitions[i] = make(chan []item, queueSize)
+	}
+
+	return p
+}
+
+// run запускает воркеры. process вызывается для каждой задачи, воркеры завершаются вместе с работой.
+func (p *pool) run(ctx context.Context, quit <-chan struct{}, process func(ctx context.Context, items []item)) {
+	for _, partition := range p.partitions {
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+
+			for {
+				// сначала задачи своей партиции: их не возьмет другой воркер
+				select {
+				case items := <-partition:
+					process(ctx, items)
+					continue
+				default:
+				}
+
+				select {
+				case items := <-partition:
+					process(ctx, items)
+				case items := <-p.shared:
+					process(ctx, items)
+				case <-ctx.Done():
+					return
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// wait ждет завершения воркеров.
+func (p *pool) wait() {
+	p.wg.Wait()
+}
+
+// queue возвращает очередь задачи с ключом партиции key. Пустой ключ - общая очередь.
+func (p *pool) queue(key string) chan<- []item {
+	if key == "" {
+		return p.shared
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key)) // запись в хеш не возвращает ошибку
+
+	return p.partitions[h.Sum32()%uint32(len(p.partitions))]
+}
+
+// dispatch раскладывает сообщения буфера по задачам и передает их воркерам. Блокируется, пока очереди воркеров заполнены.
+// Возвращает false, если работа завершена раньше, чем все задачи удалось передать.
+func (s *Service) dispatch(ctx context.Context, items []item) bool {
+	for _, job := range s.jobs(items) {
+		select {
+		case job.queue <- job.items:
+		case <-ctx.Done():
+			return false
+		case <-s.quitChan:
+			return false
+		}
+	}
+
+	return true
+}
+
+// jobs раскладывает сообщения буфера по задачам. Сообщения create операции с одной очередью записываются одной пачкой,
+// сообщения остальных операций - каждое отдельной задачей. Порядок сообщений внутри очереди сохраняется.
+func (s *Service) jobs(items []item) []job {
+	if s.cfg.Type != operation.OperationTypeCreate {
+		jobs := make([]job, 0, len(items))
+
+		for i := range items {
+			jobs = append(jobs, job{items: items[i : i+1], queue: s.pool.queue(s.partitionKey(items[i]))})
+		}
+
+		return jobs
+	}
+
+	jobs := make([]job, 0)
+	batches := make(map[chan<- []item]int) // очередь -> индекс ее пачки в jobs
+
+	for _, item := range items {
+		queue := s.pool.queue(s.partitionKey(item))
+
+		i, ok := batches[queue]
+		if !ok {
+			i = len(jobs)
+			batches[queue] = i
+			jobs = append(jobs, job{queue: queue})
+		}
+
+		jobs[i].items = append(jobs[i].items, item)
+	}
+
+	return jobs
+}
+
+// partitionKey возвращает значение поля partition_key сообщения. Пустое, если поле не задано или его нет в сообщении.
+func (s *Service) partitionKey(item item) string {
+	if s.cfg.PartitionKey == "" {
+		return ""
+	}
+
+	value, ok := s.withFields(item.msg).Data[s.cfg.PartitionKey]
+	if !ok || value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// processJob обрабатывает задачу воркера: пачку create операции одной транзакцией, остальные сообщения - по одному.
+func (s *Service) processJob(ctx context.Context, items []item) {
+	err := s.processItems(ctx, items)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":       s.cfg.Name,
+			"connection": s.cfg.Request.From,
+			"count":      len(items),
+		}).Error("operation: error process messages")
+
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":       s.cfg.Name,
+		"connection": s.cfg.Request.From,
+		"count":      len(items),
+	}).Debug("operation: messages processed by worker")
+}
@@ -0,0 +1,243 @@
+package operation
+
+import (
+	"context"
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/operation/message"
+	"db-worker/internal/service/operation/mocks"
+	"db-worker/internal/service/uow"
+	"db-worker/internal/service/worker"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chanNotifier передает итоги сообщений в канал.
+type chanNotifier struct {
+	done chan<- worker.Result
+}
+
+func (n chanNotifier) Persisted(_ []uuid.UUID, _ error) {}
+
+func (n chanNotifier) Done(res worker.Result) {
+	n.done <- res
+}
+
+func poolItem(data map[string]any) item {
+	return item{ids: []uuid.UUID{uuid.New()}, msg: worker.Message{Data: data}}
+}
+
+func TestPool_Queue(t *testing.T) {
+	t.Parallel()
+
+	p := newPool(4, 1)
+
+	assert.Equal(t, chan<- []item(p.shared), p.queue(""))
+	assert.Equal(t, p.queue("user-1"), p.queue("user-1"))
+
+	// ключи распределяются по партициям, а не попадают в общую очередь
+	for _, key := range []string{"user-1", "user-2", "user-3"} {
+		assert.NotEqual(t, chan<- []item(p.shared), p.queue(key))
+	}
+}
+
+func TestService_Jobs(t *testing.T) {
+	t.Parallel()
+
+	items := []item{
+		poolItem(map[string]any{"user_id": "u-1", "n": 0}),
+		poolItem(map[string]any{"user_id": "u-2", "n": 1}),
+		poolItem(map[string]any{"n": 2}),
+		poolItem(map[string]any{"user_id": "u-1", "n": 3}),
+	}
+
+	tests := []struct {
+		name     string
+		opType   operation.Type
+		wantJobs [][]int // номера сообщений в задачах
+	}{
+		{
+			name:     "create operation: batch per queue",
+			opType:   operation.OperationTypeCreate,
+			wantJobs: [][]int{{0, 3}, {1}, {2}},
+		},
+		{
+			name:     "update operation: job per message",
+			opType:   operation.OperationTypeUpdate,
+			wantJobs: [][]int{{0}, {1}, {2}, {3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &Service{
+				cfg:  &operation.Operation{Type: tt.opType, PartitionKey: "user_id"},
+				pool: newPool(64, 1),
+			}
+
+			// при 64 партициях u-1 и u-2 попадают в разные очереди
+			require.NotEqual(t, s.pool.queue("u-1"), s.pool.queue("u-2"))
+
+			jobs := s.jobs(items)
+			require.Len(t, jobs, len(tt.wantJobs))
+
+			for i, want := range tt.wantJobs {
+				got := make([]int, 0, len(jobs[i].items))
+				for _, item := range jobs[i].items {
+					got = append(got, item.msg.Data["n"].(int)) //nolint:forcetypeassert // тип задан в тесте
+				}
+
+				assert.Equal(t, want, got)
+				assert.Equal(t, s.pool.queue(s.partitionKey(jobs[i].items[0])), jobs[i].queue)
+			}
+		})
+	}
+}
+
+func TestPool_PartitionOrder(t *testing.T) {
+	t.Parallel()
+
+	p := newPool(2, 10)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		processed []string
+	)
+
+	unblock := make(chan struct{})
+
+	p.run(ctx, make(chan struct{}), func(_ context.Context, items []item) {
+		name := items[0].msg.Data["name"].(string) //nolint:forcetypeassert // тип задан в тесте
+
+		// первое сообщение ключа ждет: следующее сообщение с тем же ключом не должно обогнать его
+		if name == "slow-1" {
+			<-unblock
+		}
+
+		mu.Lock()
+		processed = append(processed, name)
+		mu.Unlock()
+	})
+
+	key := "user-1"
+	p.queue(key) <- []item{poolItem(map[string]any{"name": "slow-1"})}
+	p.queue(key) <- []item{poolItem(map[string]any{"name": "slow-2"})}
+
+	// сообщения без ключа обрабатывает свободный воркер, пока первый занят
+	p.queue("") <- []item{poolItem(map[string]any{"name": "free"})}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(processed) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	close(unblock)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(processed) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, []string{"free", "slow-1", "slow-2"}, processed)
+
+	cancel()
+	p.wait()
+}
+
+//nolint:funlen // много моков
+func TestService_Concurrency(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUow := mocks.NewMockunitOfWork(ctrl)
+	messageRepo := mocks.NewMockmessageRepo(ctrl)
+	metricsService := mocks.NewMockmessageCounter(ctrl)
+
+	buffer, err := newBuffer(1, 0, 0)
+	require.NoError(t, err)
+
+	svc := &Service{
+		cfg: &operation.Operation{
+			Name:        "test",
+			Type:        operation.OperationTypeUpdate,
+			Concurrency: 2,
+			Fields: []operation.Field{
+				{Name: "field1", Type: "string", Required: true},
+			},
+		},
+		messageRepo:    messageRepo,
+		uow:            mockUow,
+		metricsService: metricsService,
+		messages:       make(map[uuid.UUID]*message.Message),
+		buffer:         buffer,
+		pool:           newPool(2, 1),
+		quitChan:       make(chan struct{}),
+	}
+
+	messageRepo.EXPECT().UpdateMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	metricsService.EXPECT().AddValidatedMessages(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().DecrementProcessingMessagesBy(gomock.Any()).Return().AnyTimes()
+	metricsService.EXPECT().AddProcessedMessages(gomock.Any()).Return().AnyTimes()
+
+	unblock := make(chan struct{})
+
+	mockUow.EXPECT().StoragesMap().Return(map[string]uow.DriversMap{}).AnyTimes()
+	mockUow.EXPECT().BuildRequests(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	mockUow.EXPECT().ExecRequests(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, raw map[string]any, _ worker.Metadata) (uow.Result, error) {
+			// транзакция первого сообщения медленная
+			if raw["field1"] == "slow" {
+				<-unblock
+			}
+
+			return uow.Result{TxID: raw["field1"].(string), Status: "SUCCESS"}, nil //nolint:forcetypeassert // тип задан в тесте
+		}).Times(2)
+
+	require.NoError(t, svc.Run(t.Context()))
+
+	done := make(chan worker.Result, 2)
+
+	for _, value := range []string{"slow", "fast"} {
+		id := uuid.New()
+		svc.messages[id] = &message.Message{ID: id, Status: message.StatusInProgress}
+
+		require.NoError(t, svc.buffer.add([]uuid.UUID{id}, worker.Message{Data: map[string]any{"field1": value}, Notifier: chanNotifier{done: done}}))
+		svc.flush(t.Context(), flushReasonSize)
+	}
+
+	// второе сообщение не ждет медленную транзакцию первого
+	select {
+	case res := <-done:
+		assert.Equal(t, "fast", res.TxID)
+	case <-time.After(time.Second):
+		t.Fatal("message was not processed in parallel")
+	}
+
+	close(unblock)
+
+	res := <-done
+	assert.Equal(t, "slow", res.TxID)
+
+	require.NoError(t, svc.Stop(t.Context()))
+
+	svc.mu.Lock()
+	assert.Empty(t, svc.messages)
+	svc.mu.Unlock()
+}
@@ -0,0 +1,203 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// applySources подставляет в сообщение значения полей, которые берутся из заголовков и метаданных (source).
+// Возвращает копию сообщения: исходное тело сохраняется в messages.messages без изменений.
+// При ошибке возвращает исходное сообщение.
+// Если у сообщения нет нужного заголовка, значение остается из тела: отсутствие поля проверит валидация.
+func (s *Service) applySources(msg map[string]any, meta worker.Metadata) (map[string]any, error) {
+	var out map[string]any
+
+	for _, field := range s.cfg.Fields {
+		if field.Source == "" {
+			continue
+		}
+
+		src, err := operation.ParseSource(field.Source)
+		if err != nil {
+			return msg, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+
+		raw, ok := sourceValue(src, meta)
+		if !ok {
+			continue
+		}
+
+		val, err := convertFieldValue(field, raw)
+		if err != nil {
+			return msg, fmt.Errorf("field %q: source %q: %w", field.Name, field.Source, err)
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(msg)+1)
+			for k, v := range msg {
+				out[k] = v
+			}
+		}
+
+		out[field.Name] = val
+	}
+
+	if out == nil {
+		return msg, nil
+	}
+
+	return out, nil
+}
+
+// sourceValue возвращает значение заголовка или метаданных. false - значения нет.
+// Имя заголовка сравнивается без учета регистра: HTTP и NATS приводят имена заголовков к каноническому виду.
+func sourceValue(src operation.Source, meta worker.Metadata) (any, bool) {
+	switch src.Meta {
+	case operation.MetaMessageID:
+		return meta.MessageID, meta.MessageID != ""
+	case operation.MetaCorrelationID:
+		return meta.CorrelationID, meta.CorrelationID != ""
+	case operation.MetaRoutingKey:
+		return meta.RoutingKey, meta.RoutingKey != ""
+	case operation.MetaTimestamp:
+		return meta.Timestamp, !meta.Timestamp.IsZero()
+	}
+
+	val, ok := meta.Headers[src.Header]
+	if !ok {
+		for key, v := range meta.Headers {
+			if strings.EqualFold(key, src.Header) {
+				val, ok = v, true
+				break
+			}
+		}
+	}
+
+	// у заголовка несколько значений - берем первое
+	if list, isList := val.([]any); isList {
+		if len(list) == 0 {
+			return nil, false
+		}
+
+		val = list[0]
+	}
+
+	return val, ok && val != nil
+}
+
+// convertSourceValue приводит значение заголовка к типу поля: заголовки обычно приходят строками.
+//
+//nolint:cyclop // один switch по типам поля
+func convertSourceValue(fieldType operation.FieldType, val any) (any, error) {
+	if b, ok := val.([]byte); ok {
+		val = string(b)
+	}
+
+	str, isString := val.(string)
+
+	switch fieldType {
+	case operation.FieldTypeString:
+		if t, ok := val.(time.Time); ok {
+			return t.UTC().Format(time.RFC3339Nano), nil
+		}
+
+		return fmt.Sprint(val), nil
+	case operation.FieldTypeInt64:
+		if isString {
+			return strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+		}
+
+		if t, ok := val.(time.Time); ok {
+			return t.UnixMilli(), nil
+		}
+
+		return toInt64(val)
+	case operation.FieldTypeFloat64:
+		if isString {
+			return strconv.ParseFloat(strings.TrimSpace(str), 64)
+		}
+
+		return toFloat64(val)
+	case operation.FieldTypeBool:
+		if isString {
+			return strconv.ParseBool(strings.TrimSpace(str))
+		}
+
+		if b, ok := val.(bool); ok {
+			return b, nil
+		}
+	case operation.FieldTypeUUID:
+		if isString {
+			return uuid.Parse(strings.TrimSpace(str))
+		}
+
+		if id, ok := val.(uuid.UUID); ok {
+			return id, nil
+		}
+	}
+
+	return nil, fmt.Errorf("can't convert %T to %s", val, fieldType)
+}
+
+func toInt64(val any) (int64, error) {
+	// число из JSON: целое разбирается без float64, чтобы не потерять точность больших значений
+	if n, ok := val.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+
+		f, err := n.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("can't convert %q to int64", n)
+		}
+
+		val = f
+	}
+
+	v := reflect.ValueOf(val)
+
+	switch v.Kind() { //nolint:exhaustive // остальные типы не приводятся к числу
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows int64", v.Uint())
+		}
+
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == math.Trunc(f) {
+			return int64(f), nil
+		}
+	}
+
+	return 0, fmt.Errorf("can't convert %T to int64", val)
+}
+
+func toFloat64(val any) (float64, error) {
+	if n, ok := val.(json.Number); ok {
+		return n.Float64()
+	}
+
+	v := reflect.ValueOf(val)
+
+	switch v.Kind() { //nolint:exhaustive // остальные типы не приводятся к числу
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+
+	return 0, fmt.Errorf("can't convert %T to float64", val)
+}
@@ -0,0 +1,121 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestApplySources(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	id := uuid.New()
+
+	meta := worker.Metadata{
+		MessageID:     "msg-1",
+		CorrelationID: "corr-1",
+		RoutingKey:    "notes.create",
+		Timestamp:     ts,
+		Headers: map[string]any{
+			"X-User-Id": "42",
+			"score":     int32(7),
+			"active":    "true",
+			"tenant":    id.String(),
+			"tags":      []any{"a", "b"},
+			"broken":    "abc",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		fields  []operation.Field
+		msg     map[string]any
+		want    map[string]any
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case: no sources",
+			fields:  []operation.Field{{Name: "name", Type: operation.FieldTypeString}},
+			msg:     map[string]any{"name": "note"},
+			want:    map[string]any{"name": "note"},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: headers converted to field type",
+			fields: []operation.Field{
+				{Name: "user_id", Type: operation.FieldTypeInt64, Source: "header.x-user-id"},
+				{Name: "score", Type: operation.FieldTypeFloat64, Source: "header.score"},
+				{Name: "active", Type: operation.FieldTypeBool, Source: "header.active"},
+				{Name: "tenant_id", Type: operation.FieldTypeUUID, Source: "header.tenant"},
+				{Name: "tag", Type: operation.FieldTypeString, Source: "header.tags"},
+			},
+			msg: map[string]any{"name": "note"},
+			want: map[string]any{
+				"name":      "note",
+				"user_id":   int64(42),
+				"score":     float64(7),
+				"active":    true,
+				"tenant_id": id,
+				"tag":       "a",
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: metadata",
+			fields: []operation.Field{
+				{Name: "message_id", Type: operation.FieldTypeString, Source: "meta.message_id"},
+				{Name: "correlation_id", Type: operation.FieldTypeString, Source: "meta.correlation_id"},
+				{Name: "routing_key", Type: operation.FieldTypeString, Source: "meta.routing_key"},
+				{Name: "sent_at", Type: operation.FieldTypeInt64, Source: "meta.timestamp"},
+			},
+			msg: map[string]any{},
+			want: map[string]any{
+				"message_id":     "msg-1",
+				"correlation_id": "corr-1",
+				"routing_key":    "notes.create",
+				"sent_at":        ts.UnixMilli(),
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "positive case: missing header keeps body value",
+			fields:  []operation.Field{{Name: "user_id", Type: operation.FieldTypeInt64, Source: "header.missing"}},
+			msg:     map[string]any{"user_id": float64(1)},
+			want:    map[string]any{"user_id": float64(1)},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: header can't be converted",
+			fields:  []operation.Field{{Name: "user_id", Type: operation.FieldTypeInt64, Source: "header.broken"}},
+			msg:     map[string]any{"name": "note"},
+			want:    map[string]any{"name": "note"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{cfg: &operation.Operation{Name: "test", Fields: tt.fields}}
+
+			orig := make(map[string]any, len(tt.msg))
+			for k, v := range tt.msg {
+				orig[k] = v
+			}
+
+			got, err := svc.applySources(tt.msg, meta)
+			tt.wantErr(t, err)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, orig, tt.msg, "original message must not be changed")
+		})
+	}
+}
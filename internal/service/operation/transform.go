@@ -0,0 +1,124 @@
+package operation
+
+import (
+	"crypto/sha256"
+	"db-worker/internal/config/operation"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// applyTransforms применяет к значениям полей преобразования из transform по порядку.
+// Поля, которых нет в сообщении, не преобразуются. Возвращает копию сообщения, при ошибке - исходное сообщение.
+func (s *Service) applyTransforms(msg map[string]any) (map[string]any, error) {
+	var out map[string]any
+
+	for _, field := range s.cfg.Fields {
+		if len(field.Transforms) == 0 {
+			continue
+		}
+
+		val, ok := msg[field.Name]
+		if !ok {
+			continue
+		}
+
+		for _, transform := range field.Transforms {
+			var err error
+
+			val, err = applyTransform(transform, val)
+			if err != nil {
+				return msg, fmt.Errorf("field %q: transform %q: %w", field.Name, transform.Type, err)
+			}
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(msg))
+			for k, v := range msg {
+				out[k] = v
+			}
+		}
+
+		out[field.Name] = val
+	}
+
+	if out == nil {
+		return msg, nil
+	}
+
+	return out, nil
+}
+
+// applyTransform применяет одно преобразование к значению. null остается null, кроме default_if_empty.
+//
+//nolint:cyclop // один switch по типам преобразований
+func applyTransform(t operation.Transform, val any) (any, error) {
+	if t.Type == operation.TransformDefaultIfEmpty {
+		if val == nil || val == "" {
+			return t.Value, nil
+		}
+
+		return val, nil
+	}
+
+	if val == nil {
+		return nil, nil
+	}
+
+	switch t.Type {
+	case operation.TransformToInt64:
+		return convertSourceValue(operation.FieldTypeInt64, val)
+	case operation.TransformToUUID:
+		return convertSourceValue(operation.FieldTypeUUID, val)
+	case operation.TransformUnixToTimestamp:
+		val, err := convertSourceValue(operation.FieldTypeInt64, val)
+		if err != nil {
+			return nil, err
+		}
+
+		sec, _ := val.(int64)
+
+		return time.Unix(sec, 0).UTC().Format(time.RFC3339), nil
+	}
+
+	str, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("value %v is not a string", val)
+	}
+
+	// остальные преобразования работают со строкой
+	switch t.Type {
+	case operation.TransformTrim:
+		return strings.TrimSpace(str), nil
+	case operation.TransformLower:
+		return strings.ToLower(str), nil
+	case operation.TransformUpper:
+		return strings.ToUpper(str), nil
+	case operation.TransformTruncate:
+		n, _ := t.Value.(int)
+		if runes := []rune(str); len(runes) > n {
+			return string(runes[:n]), nil
+		}
+
+		return str, nil
+	case operation.TransformSHA256:
+		sum := sha256.Sum256([]byte(str))
+		return hex.EncodeToString(sum[:]), nil
+	case operation.TransformRegexReplace:
+		re := t.Regexp
+		if re == nil {
+			var err error
+
+			re, err = regexp.Compile(t.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("error compile pattern: %w", err)
+			}
+		}
+
+		return re.ReplaceAllString(str, t.Replacement), nil
+	}
+
+	return nil, fmt.Errorf("unknown transform %q", t.Type)
+}
@@ -0,0 +1,213 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // много тест-кейсов
+func TestApplyTransform(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.MustParse("0191f1a2-8a2c-7cc3-9b7e-0d2f5a6b7c8d")
+
+	tests := []struct {
+		name      string
+		transform operation.Transform
+		val       any
+		want      any
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "trim",
+			transform: operation.Transform{Type: operation.TransformTrim},
+			val:       "  hello \n",
+			want:      "hello",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "lower",
+			transform: operation.Transform{Type: operation.TransformLower},
+			val:       "Hello@Example.COM",
+			want:      "hello@example.com",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "upper",
+			transform: operation.Transform{Type: operation.TransformUpper},
+			val:       "ru",
+			want:      "RU",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "truncate by runes",
+			transform: operation.Transform{Type: operation.TransformTruncate, Value: 3},
+			val:       "привет",
+			want:      "при",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "truncate short string",
+			transform: operation.Transform{Type: operation.TransformTruncate, Value: 10},
+			val:       "hi",
+			want:      "hi",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "sha256",
+			transform: operation.Transform{Type: operation.TransformSHA256},
+			val:       "abc",
+			want:      "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			wantErr:   require.NoError,
+		},
+		{
+			name: "regex_replace compiled",
+			transform: operation.Transform{
+				Type: operation.TransformRegexReplace, Regexp: regexp.MustCompile(`(\d{3})\d+`), Replacement: "${1}***",
+			},
+			val:     "phone 9991234567",
+			want:    "phone 999***",
+			wantErr: require.NoError,
+		},
+		{
+			name:      "regex_replace not compiled",
+			transform: operation.Transform{Type: operation.TransformRegexReplace, Pattern: `\s+`, Replacement: " "},
+			val:       "a   b",
+			want:      "a b",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "default_if_empty: empty string",
+			transform: operation.Transform{Type: operation.TransformDefaultIfEmpty, Value: "n/a"},
+			val:       "",
+			want:      "n/a",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "default_if_empty: null",
+			transform: operation.Transform{Type: operation.TransformDefaultIfEmpty, Value: "n/a"},
+			val:       nil,
+			want:      "n/a",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "default_if_empty: value",
+			transform: operation.Transform{Type: operation.TransformDefaultIfEmpty, Value: "n/a"},
+			val:       "text",
+			want:      "text",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "to_int64",
+			transform: operation.Transform{Type: operation.TransformToInt64},
+			val:       " 42 ",
+			want:      int64(42),
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "to_int64: float number",
+			transform: operation.Transform{Type: operation.TransformToInt64},
+			val:       float64(42),
+			want:      int64(42),
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "to_int64: json number",
+			transform: operation.Transform{Type: operation.TransformToInt64},
+			val:       json.Number("9007199254740993"),
+			want:      int64(9007199254740993),
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "to_uuid",
+			transform: operation.Transform{Type: operation.TransformToUUID},
+			val:       id.String(),
+			want:      id,
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "unix_to_timestamp",
+			transform: operation.Transform{Type: operation.TransformUnixToTimestamp},
+			val:       float64(1700000000),
+			want:      "2023-11-14T22:13:20Z",
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "null is kept",
+			transform: operation.Transform{Type: operation.TransformTrim},
+			val:       nil,
+			want:      nil,
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "negative case: not a string",
+			transform: operation.Transform{Type: operation.TransformTrim},
+			val:       float64(1),
+			wantErr:   require.Error,
+		},
+		{
+			name:      "negative case: invalid int64",
+			transform: operation.Transform{Type: operation.TransformToInt64},
+			val:       "forty two",
+			wantErr:   require.Error,
+		},
+		{
+			name:      "negative case: invalid uuid",
+			transform: operation.Transform{Type: operation.TransformToUUID},
+			val:       "not-uuid",
+			wantErr:   require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := applyTransform(tt.transform, tt.val)
+			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyTransforms(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{
+		{Name: "email", Type: operation.FieldTypeString, Transforms: []operation.Transform{
+			{Type: operation.TransformTrim},
+			{Type: operation.TransformLower},
+		}},
+		{Name: "user_id", Type: operation.FieldTypeInt64, Transforms: []operation.Transform{
+			{Type: operation.TransformTrim},
+			{Type: operation.TransformToInt64},
+		}},
+		{Name: "text", Type: operation.FieldTypeString, Transforms: []operation.Transform{
+			{Type: operation.TransformTruncate, Value: 5},
+		}},
+	}}}
+
+	msg := map[string]any{"email": " Bob@Example.com ", "user_id": " 7 "}
+
+	got, err := s.applyTransforms(msg)
+	require.NoError(t, err)
+	// поля text нет в сообщении - оно не появляется
+	assert.Equal(t, map[string]any{"email": "bob@example.com", "user_id": int64(7)}, got)
+	// исходное сообщение не меняется
+	assert.Equal(t, " Bob@Example.com ", msg["email"])
+
+	msg = map[string]any{"email": "bob@example.com", "user_id": "seven"}
+
+	got, err = s.applyTransforms(msg)
+	require.ErrorContains(t, err, `field "user_id": transform "to_int64"`)
+	assert.Equal(t, msg, got)
+}
@@ -0,0 +1,138 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsedFieldTypes - типы полей, значения которых разбирает parseFieldValues.
+var parsedFieldTypes = map[operation.FieldType]bool{
+	operation.FieldTypeInt64:     true,
+	operation.FieldTypeFloat64:   true,
+	operation.FieldTypeTimestamp: true,
+	operation.FieldTypeDate:      true,
+	operation.FieldTypeDecimal:   true,
+}
+
+// parseFieldValues разбирает значения полей по настройкам поля: числа JSON в полях int64 и float64 становятся
+// int64 и float64, timestamp - time.Time, date - строкой 2006-01-02, decimal - строкой с десятичной записью
+// без потери точности. Возвращает копию сообщения, при ошибке - исходное сообщение.
+func (s *Service) parseFieldValues(msg map[string]any) (map[string]any, error) {
+	var out map[string]any
+
+	for _, field := range s.cfg.Fields {
+		if !parsedFieldTypes[field.Type] {
+			continue
+		}
+
+		// отсутствие и null проверит валидация
+		raw, ok := msg[field.Name]
+		if !ok || raw == nil {
+			continue
+		}
+
+		val, err := parseFieldValue(field, raw)
+		if err != nil {
+			return msg, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(msg))
+			for k, v := range msg {
+				out[k] = v
+			}
+		}
+
+		out[field.Name] = val
+	}
+
+	if out == nil {
+		return msg, nil
+	}
+
+	return out, nil
+}
+
+// convertFieldValue приводит значение заголовка, значение по умолчанию или значение генератора к типу поля.
+// Значения timestamp, date и decimal остаются как есть: их разбирает parseFieldValues после преобразований.
+func convertFieldValue(field operation.Field, val any) (any, error) {
+	switch field.Type {
+	case operation.FieldTypeTimestamp, operation.FieldTypeDate, operation.FieldTypeDecimal:
+		return val, nil
+	case operation.FieldTypeEnum:
+		return convertSourceValue(operation.FieldTypeString, val)
+	default:
+		return convertSourceValue(field.Type, val)
+	}
+}
+
+// parseFieldValue разбирает значение поля: число JSON, timestamp и date по format и timezone поля, decimal.
+func parseFieldValue(field operation.Field, val any) (any, error) {
+	switch field.Type {
+	case operation.FieldTypeInt64, operation.FieldTypeFloat64:
+		return numberValue(field.Type, val)
+	case operation.FieldTypeTimestamp:
+		return field.ParseTime(val)
+	case operation.FieldTypeDate:
+		t, err := field.ParseTime(val)
+		if err != nil {
+			return nil, err
+		}
+
+		return t.Format(operation.DateLayout), nil
+	case operation.FieldTypeDecimal:
+		return decimalString(val)
+	default:
+		return val, nil
+	}
+}
+
+// numberValue приводит число JSON к int64 или float64 по типу поля. Остальные значения проверит валидация.
+// Дробное число в поле int64 становится float64: валидация int64 принимает и его.
+func numberValue(fieldType operation.FieldType, val any) (any, error) {
+	n, ok := val.(json.Number)
+	if !ok {
+		return val, nil
+	}
+
+	if fieldType == operation.FieldTypeInt64 {
+		if i, err := toInt64(n); err == nil {
+			return i, nil
+		}
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("can't convert %q to %s: %w", n, fieldType, err)
+	}
+
+	return f, nil
+}
+
+// decimalString возвращает десятичную запись числа. Строки и числа JSON (json.Number) сохраняются как есть,
+// поэтому точность не ограничена. float64 приходит из остальных форматов сообщений.
+func decimalString(val any) (string, error) {
+	switch v := val.(type) {
+	case string:
+		str := strings.TrimSpace(v)
+		if _, err := operation.ParseDecimal(str); err != nil {
+			return "", err
+		}
+
+		return str, nil
+	case json.Number:
+		return decimalString(v.String())
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+
+	n, err := toInt64(val)
+	if err != nil {
+		return "", fmt.Errorf("can't convert %T to decimal", val)
+	}
+
+	return strconv.FormatInt(n, 10), nil
+}
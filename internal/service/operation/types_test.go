@@ -0,0 +1,119 @@
+package operation
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/service/codec"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldValues(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{
+		{Name: "text", Type: operation.FieldTypeString},
+		{Name: "due_at", Type: operation.FieldTypeTimestamp, Format: operation.FormatUnixMs},
+		{Name: "due_date", Type: operation.FieldTypeDate, Format: operation.FormatUnix, Timezone: "Europe/Moscow"},
+		{Name: "amount", Type: operation.FieldTypeDecimal},
+		{Name: "status", Type: operation.FieldTypeEnum, Enum: []string{"new"}},
+		{Name: "remind_at", Type: operation.FieldTypeTimestamp},
+	}}}
+
+	// 2024-04-30T22:00:00Z - уже 1 мая по Москве
+	msg := map[string]any{
+		"text":      "hello",
+		"due_at":    float64(1714546800000),
+		"due_date":  float64(1714514400),
+		"amount":    "12345678901234567890.10",
+		"status":    "new",
+		"remind_at": nil,
+	}
+
+	got, err := s.parseFieldValues(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), got["due_at"])
+	assert.Equal(t, "2024-05-01", got["due_date"])
+	assert.Equal(t, "12345678901234567890.10", got["amount"])
+	assert.Equal(t, "new", got["status"])
+	assert.Nil(t, got["remind_at"])
+	// исходное сообщение не меняется
+	assert.InDelta(t, float64(1714546800000), msg["due_at"], 0)
+
+	msg = map[string]any{"amount": "12,5"}
+
+	got, err = s.parseFieldValues(msg)
+	require.ErrorContains(t, err, `field "amount"`)
+	assert.Equal(t, msg, got)
+}
+
+func TestParseFieldValues_JSONNumbers(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{
+		{Name: "amount", Type: operation.FieldTypeDecimal},
+		{Name: "user_id", Type: operation.FieldTypeInt64},
+		{Name: "score", Type: operation.FieldTypeInt64},
+		{Name: "rating", Type: operation.FieldTypeFloat64},
+	}}}
+
+	// числа JSON не проходят через float64: decimal и большие int64 не теряют точность
+	msg, err := codec.DecodeJSON([]byte(`{"amount":12345678901234567.89,"user_id":9007199254740993,"score":2.5,"rating":4.5}`))
+	require.NoError(t, err)
+
+	got, err := s.parseFieldValues(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "12345678901234567.89", got["amount"])
+	assert.Equal(t, int64(9007199254740993), got["user_id"])
+	assert.InDelta(t, float64(2.5), got["score"], 0)
+	assert.InDelta(t, float64(4.5), got["rating"], 0)
+}
+
+func TestDecimalString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		val     any
+		want    string
+		wantErr require.ErrorAssertionFunc
+	}{
+		{val: " 10.50 ", want: "10.50", wantErr: require.NoError},
+		{val: json.Number("0.1"), want: "0.1", wantErr: require.NoError},
+		{val: float64(19.99), want: "19.99", wantErr: require.NoError},
+		{val: int64(42), want: "42", wantErr: require.NoError},
+		{val: "ten", wantErr: require.Error},
+		{val: true, wantErr: require.Error},
+	}
+
+	for _, tt := range tests {
+		got, err := decimalString(tt.val)
+		tt.wantErr(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestConvertFieldValue(t *testing.T) {
+	t.Parallel()
+
+	// значения timestamp, date и decimal разбираются позже, после преобразований
+	for _, fieldType := range []operation.FieldType{
+		operation.FieldTypeTimestamp, operation.FieldTypeDate, operation.FieldTypeDecimal,
+	} {
+		got, err := convertFieldValue(operation.Field{Type: fieldType}, "1714546800")
+		require.NoError(t, err)
+		assert.Equal(t, "1714546800", got)
+	}
+
+	got, err := convertFieldValue(operation.Field{Type: operation.FieldTypeEnum}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "1", got)
+
+	got, err = convertFieldValue(operation.Field{Type: operation.FieldTypeInt64}, "7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got)
+}
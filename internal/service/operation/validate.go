@@ -4,10 +4,17 @@ import (
 	"db-worker/internal/config/operation"
 	"db-worker/internal/service/validator"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 func (s *Service) validateMessage(msg map[string]interface{}) error {
-	err := s.validateFieldsCount(msg)
+	err := s.validateUnknownFields(msg)
+	if err != nil {
+		return fmt.Errorf("operation: error validate fields: %w", err)
+	}
+
+	err = s.validateFieldsCount(msg)
 	if err != nil {
 		return fmt.Errorf("operation: error validate fields: %w", err)
 	}
@@ -33,6 +40,34 @@ func (s *Service) validateFieldsCount(msg map[string]interface{}) error {
 	return nil
 }
 
+// validateUnknownFields проверяет, что в сообщении нет ключей, которые не объявлены в fields операции.
+// Проверяется только при unknown_fields: reject, иначе такие ключи отбрасывает строитель запросов.
+func (s *Service) validateUnknownFields(msg map[string]interface{}) error {
+	if s.cfg.UnknownFields != operation.UnknownFieldsReject {
+		return nil
+	}
+
+	declared := make(map[string]struct{}, len(s.cfg.Fields))
+	for _, field := range s.cfg.Fields {
+		declared[field.Name] = struct{}{}
+	}
+
+	var unknown []string
+
+	for name := range msg {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
 func (s *Service) validateFieldVals(msg map[string]interface{}) error {
 	for _, field := range s.cfg.Fields {
 		val, ok := msg[field.Name]
@@ -141,6 +141,64 @@ func TestValidateMessage(t *testing.T) {
 	}
 }
 
+func TestValidateUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	fields := []operation.Field{
+		{Name: "field1", Type: "string"},
+	}
+
+	tests := []struct {
+		name          string
+		unknownFields operation.UnknownFields
+		msg           map[string]interface{}
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:          "positive case: only declared fields",
+			unknownFields: operation.UnknownFieldsReject,
+			msg:           map[string]interface{}{"field1": "test"},
+			wantErr:       require.NoError,
+		},
+		{
+			name:          "positive case: unknown fields are ignored",
+			unknownFields: operation.UnknownFieldsIgnore,
+			msg:           map[string]interface{}{"field1": "test", "txet": "typo"},
+			wantErr:       require.NoError,
+		},
+		{
+			name:    "positive case: policy not set",
+			msg:     map[string]interface{}{"field1": "test", "txet": "typo"},
+			wantErr: require.NoError,
+		},
+		{
+			name:          "negative case: unknown fields are rejected",
+			unknownFields: operation.UnknownFieldsReject,
+			msg:           map[string]interface{}{"field1": "test", "txet": "typo", "extra": 1},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "unknown fields: extra, txet")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &Service{
+				cfg: &operation.Operation{
+					Name:          "test",
+					Fields:        fields,
+					UnknownFields: tt.unknownFields,
+				},
+			}
+
+			tt.wantErr(t, svc.validateUnknownFields(tt.msg))
+			tt.wantErr(t, svc.validateMessage(tt.msg))
+		})
+	}
+}
+
 //nolint:funlen // это тест
 func TestValidateFieldsCount(t *testing.T) {
 	t.Parallel()
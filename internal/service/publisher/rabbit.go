@@ -0,0 +1,181 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// Rabbit публикует сообщения в exchange RabbitMQ. Соединение открывается в Run или при первой публикации.
+// Если канал был закрыт, Publish переподключается один раз.
+// Сообщения и заголовки формируют пакеты, которые его используют (dead letter, события операций).
+type Rabbit struct {
+	name     string // кто публикует: префикс логов и ошибок
+	address  string
+	exchange string
+
+	mu      sync.Mutex // защищает conn и channel
+	conn    io.Closer
+	channel Channel
+
+	dial DialFunc
+}
+
+// Channel - канал RabbitMQ, в который публикуются сообщения (реализует *amqp.Channel).
+type Channel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Close() error
+}
+
+// DialFunc открывает соединение с RabbitMQ и канал в нем.
+type DialFunc func(address string) (Channel, io.Closer, error)
+
+// Option определяет опции для Rabbit.
+type Option func(*Rabbit)
+
+// WithName устанавливает название публикующего: оно добавляется к логам и ошибкам.
+func WithName(name string) Option {
+	return func(r *Rabbit) {
+		r.name = name
+	}
+}
+
+// WithAddress устанавливает адрес RabbitMQ.
+func WithAddress(address string) Option {
+	return func(r *Rabbit) {
+		r.address = address
+	}
+}
+
+// WithExchange устанавливает exchange, в который публикуются сообщения. Exchange должен существовать.
+func WithExchange(exchange string) Option {
+	return func(r *Rabbit) {
+		r.exchange = exchange
+	}
+}
+
+// WithDial устанавливает функцию подключения к RabbitMQ. По умолчанию - amqp.Dial.
+func WithDial(dial DialFunc) Option {
+	return func(r *Rabbit) {
+		r.dial = dial
+	}
+}
+
+// NewRabbit создает новый экземпляр Rabbit.
+func NewRabbit(opts ...Option) (*Rabbit, error) {
+	r := &Rabbit{
+		name: "publisher",
+		dial: dialAMQP,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.address == "" {
+		return nil, fmt.Errorf("%s: address is required", r.name)
+	}
+
+	if r.exchange == "" {
+		return nil, fmt.Errorf("%s: exchange is required", r.name)
+	}
+
+	return r, nil
+}
+
+// Run соединяется с RabbitMQ.
+func (r *Rabbit) Run(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.connect()
+}
+
+// dialAMQP открывает соединение и канал через amqp.Dial.
+func dialAMQP(address string) (Channel, io.Closer, error) {
+	conn, err := amqp.Dial(address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating connection: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("error creating channel: %w", err)
+	}
+
+	return ch, conn, nil
+}
+
+func (r *Rabbit) connect() error {
+	ch, conn, err := r.dial(r.address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.name, err)
+	}
+
+	r.conn = conn
+	r.channel = ch
+
+	logrus.WithField("exchange", r.exchange).Infof("%s: successfully connected rabbit", r.name)
+
+	return nil
+}
+
+// Publish публикует сообщение с ключом маршрутизации key. Если соединение было потеряно - переподключается один раз.
+func (r *Rabbit) Publish(key string, msg amqp.Publishing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.channel == nil {
+		if err := r.connect(); err != nil {
+			return err
+		}
+	}
+
+	err := r.channel.Publish(r.exchange, key, false, false, msg)
+	if errors.Is(err, amqp.ErrClosed) {
+		logrus.WithError(err).Warnf("%s: channel closed, reconnecting", r.name)
+
+		r.close()
+
+		if err := r.connect(); err != nil {
+			return err
+		}
+
+		err = r.channel.Publish(r.exchange, key, false, false, msg)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: error publishing message: %w", r.name, err)
+	}
+
+	return nil
+}
+
+// Stop закрывает соединение с RabbitMQ.
+func (r *Rabbit) Stop(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.close()
+
+	return nil
+}
+
+// close закрывает канал и соединение. Ошибки не важны: соединение больше не используется.
+func (r *Rabbit) close() {
+	if r.channel != nil {
+		_ = r.channel.Close()
+		r.channel = nil
+	}
+
+	if r.conn != nil {
+		_ = r.conn.Close()
+		r.conn = nil
+	}
+}
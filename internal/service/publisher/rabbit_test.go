@@ -0,0 +1,160 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannel запоминает опубликованные сообщения.
+type fakeChannel struct {
+	err       error // ошибка, которую вернет Publish
+	published []amqp.Publishing
+	exchanges []string
+	keys      []string
+	closed    bool
+}
+
+func (c *fakeChannel) Publish(exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	c.published = append(c.published, msg)
+	c.exchanges = append(c.exchanges, exchange)
+	c.keys = append(c.keys, key)
+
+	return nil
+}
+
+func (c *fakeChannel) Close() error {
+	c.closed = true
+	return nil
+}
+
+// dialChannels возвращает функцию подключения, которая по очереди отдает каналы channels.
+func dialChannels(channels ...Channel) DialFunc {
+	return func(_ string) (Channel, io.Closer, error) {
+		if len(channels) == 0 {
+			return nil, nil, errors.New("connection refused")
+		}
+
+		ch := channels[0]
+		channels = channels[1:]
+
+		return ch, nil, nil
+	}
+}
+
+func TestNewRabbit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "positive case",
+			opts:    []Option{WithName("events"), WithAddress("amqp://localhost:5672"), WithExchange("notes.events")},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "negative case: address is empty",
+			opts:    []Option{WithExchange("notes.events")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: exchange is empty",
+			opts:    []Option{WithAddress("amqp://localhost:5672")},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRabbit(tt.opts...)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestRabbit_Publish(t *testing.T) {
+	t.Parallel()
+
+	ch := &fakeChannel{}
+
+	r, err := NewRabbit(WithAddress("amqp://localhost:5672"), WithExchange("notes.events"), WithDial(dialChannels(ch)))
+	require.NoError(t, err)
+
+	// соединение открывается при первой публикации
+	require.NoError(t, r.Publish("created", amqp.Publishing{Body: []byte("body")}))
+
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, "notes.events", ch.exchanges[0])
+	assert.Equal(t, "created", ch.keys[0])
+	assert.Equal(t, []byte("body"), ch.published[0].Body)
+
+	require.NoError(t, r.Stop(context.Background()))
+	assert.True(t, ch.closed)
+}
+
+func TestRabbit_Publish_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	closed := &fakeChannel{err: amqp.ErrClosed}
+	ch := &fakeChannel{}
+
+	r, err := NewRabbit(WithAddress("amqp://localhost:5672"), WithExchange("notes.events"), WithDial(dialChannels(closed, ch)))
+	require.NoError(t, err)
+
+	require.NoError(t, r.Run(context.Background()))
+	require.NoError(t, r.Publish("created", amqp.Publishing{}))
+
+	assert.True(t, closed.closed)
+	assert.Len(t, ch.published, 1)
+}
+
+func TestRabbit_Publish_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		channels []Channel
+		wantErr  string
+	}{
+		{
+			name:    "negative case: connection refused",
+			wantErr: "events: connection refused",
+		},
+		{
+			name:     "negative case: publish error",
+			channels: []Channel{&fakeChannel{err: errors.New("publish error")}},
+			wantErr:  "events: error publishing message: publish error",
+		},
+		{
+			name:     "negative case: reconnect failed",
+			channels: []Channel{&fakeChannel{err: amqp.ErrClosed}},
+			wantErr:  "events: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := NewRabbit(WithName("events"), WithAddress("amqp://localhost:5672"), WithExchange("notes.events"),
+				WithDial(dialChannels(tt.channels...)))
+			require.NoError(t, err)
+
+			require.EqualError(t, r.Publish("created", amqp.Publishing{}), tt.wantErr)
+		})
+	}
+}
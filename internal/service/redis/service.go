@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"sync"
 
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,6 +24,7 @@ type Service struct {
 type redisClient interface {
 	Connect(ctx context.Context) error
 	Close(ctx context.Context) error
+	Client() goredis.UniversalClient
 }
 
 // Option определяет опции для Service.
@@ -118,3 +120,15 @@ func (s *Service) Stop(ctx context.Context) error {
 
 	return s.client.Close(ctx)
 }
+
+// Client возвращает клиент для выполнения команд. До вызова Connect возвращает ошибку.
+func (s *Service) Client() (goredis.UniversalClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil, fmt.Errorf("redis is not connected")
+	}
+
+	return s.client.Client(), nil
+}
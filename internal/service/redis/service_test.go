@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,6 +31,10 @@ func (m *mockRedisClient) Close(_ context.Context) error {
 	return m.closeError
 }
 
+func (m *mockRedisClient) Client() goredis.UniversalClient {
+	return nil
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 
@@ -132,3 +137,18 @@ func TestStop(t *testing.T) {
 		})
 	}
 }
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	svc, err := New(WithCfg(&config.Redis{Type: config.RedisTypeSingle}))
+	require.NoError(t, err)
+
+	_, err = svc.Client()
+	require.Error(t, err)
+
+	svc.client = &mockRedisClient{}
+
+	_, err = svc.Client()
+	require.NoError(t, err)
+}
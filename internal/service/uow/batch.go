@@ -0,0 +1,55 @@
+package uow
+
+// Ключи, под которыми в данных транзакции хранятся сообщения пачки.
+// Поля сообщения - колонки таблицы, а колонки PostgreSQL не начинаются с $: ключи не пересекаются с полями.
+const (
+	batchKey = "$batch" // пачка записывается одним запросом на хранилище
+	rowsKey  = "$rows"  // каждое сообщение пачки записывается под своей точкой сохранения
+)
+
+// BatchRaw возвращает данные транзакции для пачки сообщений: по ним транзакция пачки восстанавливается при запуске.
+func BatchRaw(msgs []map[string]any) map[string]any {
+	return map[string]any{batchKey: msgs}
+}
+
+// RowsRaw возвращает данные транзакции для сообщений, которые записываются под точками сохранения (ExecRowRequests).
+// При запуске такая транзакция восстанавливается так же: сообщение, запрос которого не выполнился, откатывается
+// до своей точки сохранения и не мешает записать остальные.
+func RowsRaw(msgs []map[string]any) map[string]any {
+	return map[string]any{rowsKey: msgs}
+}
+
+// batchRows возвращает сообщения пачки из данных транзакции. false - транзакция создана из одного сообщения.
+func batchRows(data map[string]any) ([]map[string]any, bool) {
+	return rawRows(data, batchKey)
+}
+
+// savepointRows возвращает сообщения, записываемые под точками сохранения, из данных транзакции.
+// false - транзакция создана не ExecRowRequests.
+func savepointRows(data map[string]any) ([]map[string]any, bool) {
+	return rawRows(data, rowsKey)
+}
+
+// rawRows возвращает сообщения из данных транзакции по ключу key.
+// Данные, загруженные из БД, приходят как []any из map[string]any.
+func rawRows(data map[string]any, key string) ([]map[string]any, bool) {
+	switch rows := data[key].(type) {
+	case []map[string]any:
+		return rows, true
+	case []any:
+		res := make([]map[string]any, 0, len(rows))
+
+		for _, row := range rows {
+			msg, ok := row.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+
+			res = append(res, msg)
+		}
+
+		return res, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,94 @@
+package uow
+
+import (
+	"db-worker/internal/config/operation"
+	"db-worker/internal/storage"
+	"db-worker/internal/storage/mocks"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBatchRequests(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	driver := mocks.NewMockDriver(ctrl)
+	driver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+
+	driversMap := map[string]DriversMap{
+		"test-storage": {
+			driver: driver,
+			cfg:    operation.StorageCfg{Name: "test-storage", Table: "users.users"},
+		},
+	}
+
+	rows := []map[string]any{{"user_id": "1"}, {"user_id": "2"}}
+
+	svc := &Service{}
+	fields := []operation.Field{{Name: "user_id"}}
+
+	reqs, err := svc.BuildBatchRequests(rows, driversMap, operation.Operation{Type: operation.OperationTypeCreate, Fields: fields})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[storage.Driver]*storage.Request{
+		driver: {
+			Val:  `INSERT INTO "users"."users" ("user_id") VALUES ($1), ($2)`,
+			Args: []any{"1", "2"},
+			Raw:  map[string]any{"rows": rows},
+		},
+	}, reqs)
+
+	reqs, err = svc.BuildBatchRequests(rows, driversMap, operation.Operation{Type: operation.OperationTypeCreate, Fields: fields, CopyThreshold: 2})
+	require.NoError(t, err)
+	assert.IsType(t, storage.Copy{}, reqs[driver].Val)
+
+	_, err = svc.BuildBatchRequests(rows, driversMap, operation.Operation{Type: operation.OperationTypeUpdate})
+	require.Error(t, err)
+}
+
+func TestBatchRows(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{{"user_id": "1"}, {"user_id": "2"}}
+
+	got, ok := batchRows(BatchRaw(rows))
+	require.True(t, ok)
+	assert.Equal(t, rows, got)
+
+	// данные транзакции, загруженные из БД
+	data, err := json.Marshal(BatchRaw(rows))
+	require.NoError(t, err)
+
+	var loaded map[string]any
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	got, ok = batchRows(loaded)
+	require.True(t, ok)
+	assert.Equal(t, rows, got)
+
+	_, ok = batchRows(map[string]any{"user_id": "1"})
+	assert.False(t, ok)
+}
+
+func TestSavepointRows(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{{"user_id": "1"}, {"user_id": "2"}}
+
+	got, ok := savepointRows(RowsRaw(rows))
+	require.True(t, ok)
+	assert.Equal(t, rows, got)
+
+	// пачка одним запросом и сообщения под точками сохранения восстанавливаются по-разному
+	_, ok = savepointRows(BatchRaw(rows))
+	assert.False(t, ok)
+
+	_, ok = batchRows(RowsRaw(rows))
+	assert.False(t, ok)
+}
@@ -2,26 +2,56 @@ package uow
 
 import (
 	"context"
+	"db-worker/internal/service/worker"
 	"db-worker/internal/storage"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 )
 
-// ExecRequests выполняет запросы к хранилищам.
+// Result - итог выполнения транзакции.
+type Result struct {
+	TxID         string // айди транзакции
+	Status       string // статус транзакции
+	FailedDriver string // название драйвера, на котором упала транзакция
+}
+
+// ExecRequests выполняет запросы к хранилищам. Метаданные сообщения сохраняются вместе с транзакцией.
 // В случае неудачи - откатывает коммит, в случае успеха - коммитит транзакцию.
+// Возвращает итог выполнения транзакции. Если транзакцию не удалось создать - итог пустой.
+func (s *Service) ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (Result, error) {
+	return s.execTransaction(ctx, []map[storage.Driver]*storage.Request{requests}, raw, meta, s.execTx)
+}
+
+// execTransaction создает транзакцию из запросов сообщений rows, выполняет ее через run и фиксирует итоговое
+// состояние и метрики.
 //
 //nolint:funlen // цельная логика работы, разбивать проблематично. +многострочные логи
-func (s *Service) ExecRequests(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any) (err error) {
-	tx, err := s.newTx(ctx, requests, raw)
+func (s *Service) execTransaction(
+	ctx context.Context,
+	rows []map[storage.Driver]*storage.Request,
+	raw map[string]any,
+	meta worker.Metadata,
+	run func(ctx context.Context, tx storage.TransactionEditor) error,
+) (res Result, err error) {
+	tx, err := s.newTx(ctx, rows, raw, meta)
 	if err != nil {
-		return fmt.Errorf("error creating transaction: %w", err)
+		return Result{}, fmt.Errorf("error creating transaction: %w", err)
 	}
 
 	if tx == nil {
-		return fmt.Errorf("transaction is nil")
+		return Result{}, fmt.Errorf("transaction is nil")
 	}
 
+	// выполняется последним, чтобы зафиксировать итоговое состояние транзакции
+	defer func() {
+		res = Result{
+			TxID:         tx.ID(),
+			Status:       tx.Status(),
+			FailedDriver: tx.FailedDriverName(),
+		}
+	}()
+
 	s.addTotalTransactions(1)
 	s.addInProgressTransactions(1)
 
@@ -65,7 +95,7 @@ func (s *Service) ExecRequests(ctx context.Context, requests map[storage.Driver]
 	}).Info("executing requests")
 
 	// здесь будут изменены метрики транзакций
-	if err = s.execTx(ctx, tx); err != nil {
+	if err = run(ctx, tx); err != nil {
 		err = fmt.Errorf("error executing transaction: %w", err)
 		return
 	}
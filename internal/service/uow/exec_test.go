@@ -3,6 +3,7 @@ package uow
 import (
 	"db-worker/internal/config/operation"
 	uowmocks "db-worker/internal/service/uow/mocks"
+	"db-worker/internal/service/worker"
 	"db-worker/internal/storage"
 	"db-worker/internal/storage/mocks"
 	"db-worker/internal/storage/testtransaction"
@@ -298,8 +299,14 @@ func TestExecRequests(t *testing.T) {
 			userDriver := mocks.NewMockDriver(ctrl)
 			metricsService := uowmocks.NewMocktxCounter(ctrl)
 
-			err := tt.setupSvc(t, systemDriver, userDriver, metricsService).ExecRequests(t.Context(), tt.requests(t, userDriver), tt.rawReq)
+			res, err := tt.setupSvc(t, systemDriver, userDriver, metricsService).ExecRequests(t.Context(), tt.requests(t, userDriver), tt.rawReq, worker.Metadata{})
 			tt.wantErr(t, err)
+
+			if err == nil {
+				assert.NotEmpty(t, res.TxID)
+				assert.Equal(t, string(storage.TxStatusSuccess), res.Status)
+				assert.Empty(t, res.FailedDriver)
+			}
 		})
 	}
 }
@@ -198,15 +198,32 @@ func (s *Service) processTxModel(ctx context.Context, txModel storage.Transactio
 		}
 	}()
 
+	tx := storage.NewTransactionFromModel(&txModel)
+
+	// сообщения, которые записывались под точками сохранения, повторяются так же: сообщение с ошибкой
+	// откатывается до своей точки сохранения, остальные коммитятся
+	if rows, ok := savepointRows(txModel.Data); ok {
+		if err = s.execRowsModel(ctx, tx, rows); err != nil {
+			err = fmt.Errorf("error exec tx with savepoints: %w", err)
+			return
+		}
+
+		return nil
+	}
+
 	var reqs map[storage.Driver]*storage.Request
 
-	reqs, err = s.BuildRequests(txModel.Data, s.userDriversMap, *s.cfg)
+	if rows, ok := batchRows(txModel.Data); ok {
+		reqs, err = s.BuildBatchRequests(rows, s.userDriversMap, *s.cfg)
+	} else {
+		reqs, err = s.BuildRequests(txModel.Data, s.userDriversMap, *s.cfg)
+	}
+
 	if err != nil {
 		err = fmt.Errorf("error build requests: %w", err)
 		return
 	}
 
-	tx := storage.NewTransactionFromModel(&txModel)
 	tx.SaveRequests(reqs)
 
 	if err = s.execTx(ctx, tx); err != nil {
@@ -217,6 +234,24 @@ func (s *Service) processTxModel(ctx context.Context, txModel storage.Transactio
 	return nil
 }
 
+// execRowsModel строит запросы каждого сообщения транзакции с точками сохранения и выполняет их заново.
+func (s *Service) execRowsModel(ctx context.Context, tx *storage.Transaction, msgs []map[string]any) error {
+	rows := make([]map[storage.Driver]*storage.Request, 0, len(msgs))
+
+	for i, msg := range msgs {
+		reqs, err := s.BuildRequests(msg, s.userDriversMap, *s.cfg)
+		if err != nil {
+			return fmt.Errorf("error build requests for row %d: %w", i, err)
+		}
+
+		rows = append(rows, reqs)
+	}
+
+	tx.SaveRequests(rowsDrivers(rows))
+
+	return s.execRowsTx(ctx, tx, rows, make([]error, len(rows)))
+}
+
 func compareOperationHash(operationHash []byte, cfgHash []byte) bool {
 	return bytes.Equal(operationHash, cfgHash)
 }
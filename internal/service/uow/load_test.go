@@ -37,9 +37,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -132,9 +133,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -228,9 +230,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -286,9 +289,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -383,9 +387,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -471,9 +476,10 @@ func TestLoadOnStartup(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -594,9 +600,10 @@ func TestProcessTxModel(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -637,9 +644,10 @@ func TestProcessTxModel(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -675,9 +683,10 @@ func TestProcessTxModel(t *testing.T) {
 					metricsService: metricsService,
 					instanceID:     1,
 					cfg: &operation.Operation{
-						Name: "test-operation",
-						Hash: []byte{0x1, 0x2, 0x3},
-						Type: operation.OperationTypeCreate,
+						Name:   "test-operation",
+						Hash:   []byte{0x1, 0x2, 0x3},
+						Type:   operation.OperationTypeCreate,
+						Fields: []operation.Field{{Name: "id"}},
 					},
 					userDriversMap: map[string]DriversMap{
 						"test-storage": {
@@ -1135,3 +1144,72 @@ func TestSetupCanceledTransactionMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessTxModel_Savepoints(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requestsRepo := uowmocks.NewMockrequestsRepo(ctrl)
+	userDriver := storagemocks.NewMockDriver(ctrl)
+	metricsService := uowmocks.NewMocktxCounter(ctrl)
+
+	userDriver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+	userDriver.EXPECT().Name().Return("test-storage").AnyTimes()
+
+	// сообщение, которое не записалось до рестарта, снова откатывается до своей точки сохранения,
+	// остальные коммитятся
+	gomock.InOrder(
+		userDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil),
+		userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_0").Return(nil),
+		userDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		userDriver.EXPECT().ReleaseSavepoint(gomock.Any(), gomock.Any(), "row_0").Return(nil),
+		userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_1").Return(nil),
+		userDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("invalid input syntax")),
+		userDriver.EXPECT().RollbackToSavepoint(gomock.Any(), gomock.Any(), "row_1").Return(nil),
+		userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_2").Return(nil),
+		userDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		userDriver.EXPECT().ReleaseSavepoint(gomock.Any(), gomock.Any(), "row_2").Return(nil),
+		userDriver.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	metricsService.EXPECT().AddSuccessTransactions(1).Times(1)
+	metricsService.EXPECT().DecrementInProgressTransactions(1).Times(1)
+
+	svc := &Service{
+		requestsRepo:   requestsRepo,
+		metricsService: metricsService,
+		instanceID:     1,
+		cfg: &operation.Operation{
+			Name:   "test-operation",
+			Hash:   []byte{0x1, 0x2, 0x3},
+			Type:   operation.OperationTypeCreate,
+			Fields: []operation.Field{{Name: "id"}},
+		},
+		userDriversMap: map[string]DriversMap{
+			"test-storage": {
+				driver: userDriver,
+				cfg:    operation.StorageCfg{Name: "test-storage", Table: "users.users"},
+			},
+		},
+		userStoragesMap: map[string]storage.Driver{
+			"test-storage": userDriver,
+		},
+	}
+
+	// данные транзакции, загруженные из БД
+	txModel := storage.TransactionModel{
+		ID:            random.String(10),
+		Status:        storage.TxStatusInProgress,
+		InstanceID:    1,
+		OperationType: string(operation.OperationTypeCreate),
+		OperationHash: []byte{0x1, 0x2, 0x3},
+		Data: map[string]any{
+			rowsKey: []any{map[string]any{"id": "1"}, map[string]any{"id": "bad"}, map[string]any{"id": "3"}},
+		},
+		CreatedAt: time.Now(),
+	}
+
+	require.NoError(t, svc.processTxModel(t.Context(), txModel))
+}
@@ -8,24 +8,26 @@ import (
 )
 
 // saveRequests сохраняет запросы, принадлежащие транзакции.
-// requests - запросы оригинальной транзакции.
-func (s *Service) saveRequests(ctx context.Context, utilityTx storage.TransactionEditor, requests map[storage.Driver]*storage.Request) error {
+// rows - запросы оригинальной транзакции по сообщениям: сохраняется каждый запрос каждого сообщения.
+func (s *Service) saveRequests(ctx context.Context, utilityTx storage.TransactionEditor, rows []map[storage.Driver]*storage.Request) error {
 	op := s.operationForSavingRequests(utilityTx)
 
 	// составляем запросы для сохранения пользовательских запросов, принадлежащих транзакции
-	for driver := range requests {
-		msg := fieldsForReq(utilityTx.ID(), string(driver.Type()), driver.Name())
-		// создаем запросы для сохранения транзакции
-		reqs, err := s.BuildRequests(msg, s.requestsDriversMap, op)
-		if err != nil {
-			return fmt.Errorf("error building requests for saving requests: %w", err)
-		}
-
-		utilityTx.SaveRequests(reqs)
-
-		err = s.execRequests(ctx, utilityTx)
-		if err != nil {
-			return fmt.Errorf("error while saving requests: %w", err)
+	for _, requests := range rows {
+		for driver := range requests {
+			msg := fieldsForReq(utilityTx.ID(), string(driver.Type()), driver.Name())
+			// создаем запросы для сохранения транзакции
+			reqs, err := s.BuildRequests(msg, s.requestsDriversMap, op)
+			if err != nil {
+				return fmt.Errorf("error building requests for saving requests: %w", err)
+			}
+
+			utilityTx.SaveRequests(reqs)
+
+			err = s.execRequests(ctx, utilityTx)
+			if err != nil {
+				return fmt.Errorf("error while saving requests: %w", err)
+			}
 		}
 	}
 
@@ -38,6 +40,7 @@ func (s *Service) operationForSavingRequests(tx storage.TransactionEditor) opera
 		Name:    fmt.Sprintf("system operation for saving requests for tx %s", tx.ID()),
 		Type:    operation.OperationTypeCreate,
 		Timeout: s.cfg.Timeout,
+		Fields:  systemFields("tx_id", "driver_type", "driver_name"),
 	}
 }
 
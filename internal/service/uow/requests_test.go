@@ -195,7 +195,7 @@ func TestSaveRequests(t *testing.T) {
 
 			tx := tt.createTx(t, userDriver, systemDriver)
 
-			err := svc.saveRequests(t.Context(), tx, tx.Requests())
+			err := svc.saveRequests(t.Context(), tx, []map[storage.Driver]*storage.Request{tx.Requests()})
 			tt.wantErr(t, err)
 
 			tt.checkTx(t, tx, userDriver)
@@ -203,6 +203,36 @@ func TestSaveRequests(t *testing.T) {
 	}
 }
 
+func TestSaveRequests_Rows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	systemDriver := mocks.NewMockDriver(ctrl)
+	userDriver := mocks.NewMockDriver(ctrl)
+
+	svc := newTestService(t, systemDriver, userDriver, uowmocks.NewMocktxCounter(ctrl))
+
+	systemDriver.EXPECT().Name().Return("system-storage").AnyTimes()
+	systemDriver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+	systemDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userDriver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+	userDriver.EXPECT().Name().Return("test-storage").AnyTimes()
+
+	// у каждого сообщения свой запрос в хранилище: в transactions.requests сохраняется каждый
+	systemDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	tx := testtransaction.NewTestTransaction(
+		testtransaction.WithStatus(string(storage.TxStatusInProgress)),
+		testtransaction.WithOriginalTx(testtransaction.NewTestTransaction()),
+	)
+
+	err := svc.saveRequests(t.Context(), tx, savepointRequests(userDriver))
+	require.NoError(t, err)
+}
+
 func TestFieldsForReq(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,145 @@
+package uow
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"db-worker/internal/storage"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExecRowRequests выполняет запросы сообщений пачки одной транзакцией. Запросы каждого сообщения выполняются
+// под точкой сохранения: если запрос сообщения не выполнился, откатываются только запросы этого сообщения,
+// остальные коммитятся. Возвращает итог транзакции и ошибку каждого сообщения: nil - сообщение записано.
+// Если не записалось ни одно сообщение - транзакция откатывается и завершается ошибкой.
+// raw - данные транзакции из RowsRaw: при запуске транзакция восстанавливается так же, под точками сохранения.
+func (s *Service) ExecRowRequests(ctx context.Context, rows []map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (Result, []error, error) {
+	if len(rows) == 0 {
+		return Result{}, nil, errors.New("rows are empty")
+	}
+
+	rowErrs := make([]error, len(rows))
+
+	res, err := s.execTransaction(ctx, rows, raw, meta, func(ctx context.Context, tx storage.TransactionEditor) error {
+		return s.execRowsTx(ctx, tx, rows, rowErrs)
+	})
+
+	return res, rowErrs, err
+}
+
+// execRowsTx начинает транзакцию в пользовательских хранилищах, выполняет запросы сообщений под точками сохранения
+// и коммитит записанные сообщения. Ошибки сообщений записываются в rowErrs.
+func (s *Service) execRowsTx(ctx context.Context, tx storage.TransactionEditor, rows []map[storage.Driver]*storage.Request, rowErrs []error) error {
+	logrus.WithFields(logrus.Fields{
+		"transaction_id":           tx.ID(),
+		"operation":                s.cfg.Name,
+		"service":                  "uow",
+		"transaction_requests_num": len(tx.Requests()),
+		"rows":                     len(rows),
+	}).Info("executing transaction with savepoints")
+
+	if !tx.IsInProgress() {
+		return fmt.Errorf("transaction status not equal to: %q. Real status: %q", storage.TxStatusInProgress, tx.Status())
+	}
+
+	for driver := range tx.Requests() {
+		if err := s.beginInDriver(ctx, tx, driver); err != nil {
+			return fmt.Errorf("error beginning transaction: %+v", err)
+		}
+	}
+
+	var (
+		written      int
+		failedDriver storage.Driver
+	)
+
+	for i, row := range rows {
+		driver, rowErr, err := s.execRow(ctx, tx, row, fmt.Sprintf("row_%d", i))
+		if err != nil {
+			return s.failTx(ctx, tx, driver, fmt.Errorf("error exec row %d: %w", i, err))
+		}
+
+		if rowErr != nil {
+			logrus.WithFields(logrus.Fields{
+				"transaction_id": tx.ID(),
+				"operation":      s.cfg.Name,
+				"service":        "uow",
+				"driver":         driver.Name(),
+				"row":            i,
+			}).WithError(rowErr).Warn("row rolled back to savepoint")
+
+			rowErrs[i] = rowErr
+			failedDriver = driver
+
+			continue
+		}
+
+		written++
+	}
+
+	if written == 0 {
+		return s.failTx(ctx, tx, failedDriver, fmt.Errorf("no rows written: %w", errors.Join(rowErrs...)))
+	}
+
+	if err := s.Commit(ctx, tx); err != nil {
+		return fmt.Errorf("error commit transaction: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"transaction_id": tx.ID(),
+		"operation":      s.cfg.Name,
+		"service":        "uow",
+		"rows":           len(rows),
+		"written":        written,
+	}).Info("transaction with savepoints executed successfully")
+
+	return nil
+}
+
+// execRow выполняет запросы сообщения во всех хранилищах под точкой сохранения name.
+// rowErr - запрос сообщения не выполнился, его запросы откачены до точки сохранения, транзакцию можно продолжать.
+// err - не удалось создать или откатить точку сохранения, транзакцию продолжать нельзя.
+// driver - хранилище, в котором произошла ошибка.
+func (s *Service) execRow(ctx context.Context, tx storage.TransactionEditor, row map[storage.Driver]*storage.Request, name string) (driver storage.Driver, rowErr, err error) {
+	for driver := range row {
+		if err := driver.Savepoint(ctx, tx.ID(), name); err != nil {
+			return driver, nil, fmt.Errorf("error create savepoint in driver %q: %w", driver.Name(), err)
+		}
+	}
+
+	var failed storage.Driver
+
+	for driver, req := range row {
+		if err := driver.Exec(ctx, req, tx.ID()); err != nil {
+			failed, rowErr = driver, fmt.Errorf("error exec request in driver %q: %w", driver.Name(), err)
+			break
+		}
+	}
+
+	if rowErr != nil {
+		for driver := range row {
+			if err := driver.RollbackToSavepoint(ctx, tx.ID(), name); err != nil {
+				return driver, nil, fmt.Errorf("error rollback to savepoint in driver %q: %w", driver.Name(), err)
+			}
+		}
+
+		return failed, rowErr, nil
+	}
+
+	for driver := range row {
+		if err := driver.ReleaseSavepoint(ctx, tx.ID(), name); err != nil {
+			return driver, nil, fmt.Errorf("error release savepoint in driver %q: %w", driver.Name(), err)
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// failTx помечает транзакцию сломанной на драйвере driver и откатывает ее.
+func (s *Service) failTx(ctx context.Context, tx storage.TransactionEditor, driver storage.Driver, err error) error {
+	return s.execWithRollback(ctx, tx, driver, func() error {
+		return err
+	})
+}
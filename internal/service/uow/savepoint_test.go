@@ -0,0 +1,147 @@
+package uow
+
+import (
+	"db-worker/internal/config/operation"
+	uowmocks "db-worker/internal/service/uow/mocks"
+	"db-worker/internal/service/worker"
+	"db-worker/internal/storage"
+	"db-worker/internal/storage/mocks"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savepointRequests возвращает запросы двух сообщений пачки в пользовательское хранилище.
+func savepointRequests(userDriver *mocks.MockDriver) []map[storage.Driver]*storage.Request {
+	return []map[storage.Driver]*storage.Request{
+		{userDriver: {Val: "INSERT INTO users.users (user_id) VALUES ($1)", Args: []any{"1"}}},
+		{userDriver: {Val: "INSERT INTO users.users (user_id) VALUES ($1)", Args: []any{"bad"}}},
+	}
+}
+
+func expectSystemDriver(systemDriver *mocks.MockDriver) {
+	systemDriver.EXPECT().Name().Return(StorageNameForTransactionsTable).AnyTimes()
+	systemDriver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+	systemDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	systemDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	systemDriver.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	systemDriver.EXPECT().Rollback(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	systemDriver.EXPECT().FinishTx(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+func expectMetrics(metricsService *uowmocks.MocktxCounter) {
+	metricsService.EXPECT().AddTotalTransactions(gomock.Any()).AnyTimes()
+	metricsService.EXPECT().AddInProgressTransactions(gomock.Any()).AnyTimes()
+	metricsService.EXPECT().AddSuccessTransactions(gomock.Any()).AnyTimes()
+	metricsService.EXPECT().AddFailedTransactions(gomock.Any()).AnyTimes()
+	metricsService.EXPECT().DecrementInProgressTransactions(gomock.Any()).AnyTimes()
+}
+
+//nolint:funlen // много тест-кейсов
+func TestExecRowRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		setup       func(userDriver *mocks.MockDriver, rows []map[storage.Driver]*storage.Request)
+		wantStatus  string
+		wantRowErrs []bool
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case: failed row rolled back to savepoint",
+			setup: func(userDriver *mocks.MockDriver, rows []map[storage.Driver]*storage.Request) {
+				gomock.InOrder(
+					userDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil),
+					userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_0").Return(nil),
+					userDriver.EXPECT().Exec(gomock.Any(), rows[0][userDriver], gomock.Any()).Return(nil),
+					userDriver.EXPECT().ReleaseSavepoint(gomock.Any(), gomock.Any(), "row_0").Return(nil),
+					userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_1").Return(nil),
+					userDriver.EXPECT().Exec(gomock.Any(), rows[1][userDriver], gomock.Any()).Return(errors.New("invalid input syntax")),
+					userDriver.EXPECT().RollbackToSavepoint(gomock.Any(), gomock.Any(), "row_1").Return(nil),
+					userDriver.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil),
+					userDriver.EXPECT().FinishTx(gomock.Any(), gomock.Any()).Return(nil),
+				)
+			},
+			wantStatus:  string(storage.TxStatusSuccess),
+			wantRowErrs: []bool{false, true},
+			wantErr:     require.NoError,
+		},
+		{
+			name: "error case: no rows written",
+			setup: func(userDriver *mocks.MockDriver, _ []map[storage.Driver]*storage.Request) {
+				userDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil)
+				userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				userDriver.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("invalid input syntax")).Times(2)
+				userDriver.EXPECT().RollbackToSavepoint(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				userDriver.EXPECT().Rollback(gomock.Any(), gomock.Any()).Return(nil)
+				userDriver.EXPECT().FinishTx(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantStatus:  string(storage.TxStatusFailed),
+			wantRowErrs: []bool{true, true},
+			wantErr: require.ErrorAssertionFunc(func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "no rows written")
+			}),
+		},
+		{
+			name: "error case: savepoint error",
+			setup: func(userDriver *mocks.MockDriver, _ []map[storage.Driver]*storage.Request) {
+				userDriver.EXPECT().Begin(gomock.Any(), gomock.Any()).Return(nil)
+				userDriver.EXPECT().Savepoint(gomock.Any(), gomock.Any(), "row_0").Return(errors.New("connection lost"))
+				userDriver.EXPECT().Rollback(gomock.Any(), gomock.Any()).Return(nil)
+				userDriver.EXPECT().FinishTx(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantStatus:  string(storage.TxStatusFailed),
+			wantRowErrs: []bool{false, false},
+			wantErr: require.ErrorAssertionFunc(func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "error create savepoint")
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			systemDriver := mocks.NewMockDriver(ctrl)
+			userDriver := mocks.NewMockDriver(ctrl)
+			metricsService := uowmocks.NewMocktxCounter(ctrl)
+
+			expectSystemDriver(systemDriver)
+			expectMetrics(metricsService)
+
+			userDriver.EXPECT().Name().Return("test-storage").AnyTimes()
+			userDriver.EXPECT().Type().Return(operation.StorageTypePostgres).AnyTimes()
+
+			rows := savepointRequests(userDriver)
+			tt.setup(userDriver, rows)
+
+			svc := newTestService(t, systemDriver, userDriver, metricsService)
+
+			res, rowErrs, err := svc.ExecRowRequests(t.Context(), rows, BatchRaw([]map[string]any{{"user_id": "1"}, {"user_id": "bad"}}), worker.Metadata{})
+			tt.wantErr(t, err)
+
+			assert.Equal(t, tt.wantStatus, res.Status)
+			require.Len(t, rowErrs, len(tt.wantRowErrs))
+
+			for i, wantErr := range tt.wantRowErrs {
+				assert.Equal(t, wantErr, rowErrs[i] != nil, "row %d", i)
+			}
+		})
+	}
+}
+
+func TestExecRowRequests_Empty(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{}
+
+	_, _, err := svc.ExecRowRequests(t.Context(), nil, nil, worker.Metadata{})
+	require.Error(t, err)
+}
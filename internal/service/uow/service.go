@@ -207,6 +207,21 @@ func (s *Service) StoragesMap() map[string]DriversMap {
 
 // BuildRequests принимает на вход сообщение в виде мапы. Возвращает мапу с запросами для каждого драйвера.
 func (s *Service) BuildRequests(msg map[string]interface{}, driversMap map[string]DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error) {
+	return buildRequests(driversMap, operation, func(builder builder_pkg.Builder) builder_pkg.Builder {
+		return builder.WithValues(msg)
+	})
+}
+
+// BuildBatchRequests принимает на вход сообщения create операции. Возвращает мапу с одним запросом на все сообщения
+// для каждого драйвера: multi-row INSERT или COPY, если сообщений не меньше copy_threshold операции.
+func (s *Service) BuildBatchRequests(msgs []map[string]any, driversMap map[string]DriversMap, operation operation.Operation) (map[storage.Driver]*storage.Request, error) {
+	return buildRequests(driversMap, operation, func(builder builder_pkg.Builder) builder_pkg.Builder {
+		return builder.WithBatchValues(msgs).WithCopyThreshold(operation.CopyThreshold)
+	})
+}
+
+// buildRequests строит запрос для каждого драйвера. withValues передает в строитель значения запроса.
+func buildRequests(driversMap map[string]DriversMap, operation operation.Operation, withValues func(builder_pkg.Builder) builder_pkg.Builder) (map[storage.Driver]*storage.Request, error) {
 	res := make(map[storage.Driver]*storage.Request)
 
 	for _, storage := range driversMap {
@@ -220,7 +235,7 @@ func (s *Service) BuildRequests(msg map[string]interface{}, driversMap map[strin
 			return nil, fmt.Errorf("error get builder by storage type %q: %w", storage.driver.Type(), err)
 		}
 
-		builder = builder.WithOperation(operation).WithValues(msg).WithTable(storage.cfg.Table)
+		builder = withValues(builder.WithOperation(operation)).WithTable(storage.cfg.Table)
 
 		builder, err = setOperationType(builder, operation.Type)
 		if err != nil {
@@ -255,6 +270,8 @@ func setOperationType(builder builder_pkg.Builder, operationType operation.Type)
 		return builder.WithUpdateOperation()
 	case operation.OperationTypeDelete:
 		return builder.WithDeleteOperation()
+	case operation.OperationTypeUpsert:
+		return builder.WithUpsertOperation()
 	default:
 		return nil, fmt.Errorf("unknown operation type: %s", operationType)
 	}
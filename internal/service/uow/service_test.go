@@ -228,6 +228,18 @@ func (m *mockStorage) FinishTx(ctx context.Context, _ string) error {
 	return m.finishTxError
 }
 
+func (m *mockStorage) Savepoint(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *mockStorage) RollbackToSavepoint(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *mockStorage) ReleaseSavepoint(_ context.Context, _, _ string) error {
+	return nil
+}
+
 //nolint:funlen,dupl // много тест-кейсов, схожие тест-кейсы
 func TestNew(t *testing.T) {
 	t.Parallel()
@@ -729,7 +741,8 @@ func TestBuildRequests(t *testing.T) {
 				}
 			},
 			operation: &operation.Operation{
-				Type: operation.OperationTypeCreate,
+				Type:   operation.OperationTypeCreate,
+				Fields: []operation.Field{{Name: "user_id"}},
 				Storages: []operation.StorageCfg{
 					{
 						Name:  "test-storage",
@@ -767,7 +780,7 @@ func TestBuildRequests(t *testing.T) {
 
 				return map[storage.Driver]*storage.Request{
 					driver1: {
-						Val:  "INSERT INTO users.users (user_id) VALUES ($1)",
+						Val:  `INSERT INTO "users"."users" ("user_id") VALUES ($1)`,
 						Args: []any{"1"},
 						Raw:  map[string]any{"user_id": "1"},
 					},
@@ -806,7 +819,8 @@ func TestBuildRequests(t *testing.T) {
 				}
 			},
 			operation: &operation.Operation{
-				Type: operation.OperationTypeCreate,
+				Type:   operation.OperationTypeCreate,
+				Fields: []operation.Field{{Name: "user_id"}},
 				Storages: []operation.StorageCfg{
 					{Name: "test-storage",
 						Type: operation.StorageTypePostgres,
@@ -857,12 +871,12 @@ func TestBuildRequests(t *testing.T) {
 
 				return map[storage.Driver]*storage.Request{
 					driver1: {
-						Val:  "INSERT INTO users.users (user_id) VALUES ($1)",
+						Val:  `INSERT INTO "users"."users" ("user_id") VALUES ($1)`,
 						Args: []any{"1"},
 						Raw:  map[string]any{"user_id": "1"},
 					},
 					driver2: {
-						Val:  "INSERT INTO users.users (user_id) VALUES ($1)",
+						Val:  `INSERT INTO "users"."users" ("user_id") VALUES ($1)`,
 						Args: []any{"1"},
 						Raw:  map[string]any{"user_id": "1"},
 					},
@@ -880,7 +894,8 @@ func TestBuildRequests(t *testing.T) {
 				"user_id": "1",
 			},
 			operation: &operation.Operation{
-				Type: operation.OperationTypeCreate,
+				Type:   operation.OperationTypeCreate,
+				Fields: []operation.Field{{Name: "user_id"}},
 				Storages: []operation.StorageCfg{
 					{Name: "test-storage", Type: operation.StorageTypePostgres},
 					{Name: "test-storage-2", Type: operation.StorageTypePostgres},
@@ -1043,6 +1058,9 @@ func TestSetOperationType(t *testing.T) {
 	deletedBuilder, err := builder_pkg.ForPostgres().WithDeleteOperation()
 	require.NoError(t, err)
 
+	upsertBuilder, err := builder_pkg.ForPostgres().WithUpsertOperation()
+	require.NoError(t, err)
+
 	tests := []struct {
 		name          string
 		operationType operation.Type
@@ -1071,6 +1089,13 @@ func TestSetOperationType(t *testing.T) {
 			want:          deletedBuilder,
 			wantErr:       require.NoError,
 		},
+		{
+			name:          "positive case: upsert",
+			operationType: operation.OperationTypeUpsert,
+			builder:       builder_pkg.ForPostgres(),
+			want:          upsertBuilder,
+			wantErr:       require.NoError,
+		},
 		{
 			name:          "negative case: unknown operation type",
 			operationType: "unknown",
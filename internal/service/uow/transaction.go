@@ -3,6 +3,7 @@ package uow
 import (
 	"context"
 	"db-worker/internal/config/operation"
+	"db-worker/internal/service/worker"
 	"db-worker/internal/storage"
 	"encoding/json"
 	"errors"
@@ -11,18 +12,58 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// generatedTxID возвращает айди транзакции, который сервис операции записал в поле с generator: tx_id.
+// У пачки айди общий для всех сообщений - берется из первого. Пустая строка - айди генерирует хранилище.
+func (s *Service) generatedTxID(raw map[string]any) string {
+	for _, field := range s.cfg.Fields {
+		if field.Generator != operation.GeneratorTxID {
+			continue
+		}
+
+		rows, ok := batchRows(raw)
+		if !ok {
+			rows, ok = savepointRows(raw)
+		}
+
+		if ok {
+			if len(rows) == 0 {
+				return ""
+			}
+
+			raw = rows[0]
+		}
+
+		id, _ := raw[field.Name].(string)
+
+		return id
+	}
+
+	return ""
+}
+
 // newTx создает новую транзакцию и сохраняет в системное хранилище.
-func (s *Service) newTx(ctx context.Context, requests map[storage.Driver]*storage.Request, raw map[string]any) (*storage.Transaction, error) {
+// rows - запросы транзакции по сообщениям: один набор, если транзакция выполняет запросы разом, и набор на каждое
+// сообщение, если сообщения записываются под точками сохранения. Все запросы сохраняются в transactions.requests.
+func (s *Service) newTx(ctx context.Context, rows []map[storage.Driver]*storage.Request, raw map[string]any, meta worker.Metadata) (*storage.Transaction, error) {
+	requests := rowsDrivers(rows)
+
 	tx, err := storage.NewTransaction(requests, s.instanceID, s.cfg.Hash, raw)
 	if err != nil {
 		return nil, fmt.Errorf("error creating transaction: %w", err)
 	}
 
+	if id := s.generatedTxID(raw); id != "" {
+		tx.SetID(id)
+	}
+
+	tx.SetMetadata(meta.CorrelationID, meta.Map())
+
 	logrus.WithFields(logrus.Fields{
 		"transaction_id":           tx.ID(),
 		"operation":                s.cfg.Name,
 		"service":                  "uow",
 		"transaction_requests_num": len(requests),
+		"correlation_id":           meta.CorrelationID,
 	}).Info("creating new transaction")
 
 	logrus.WithFields(logrus.Fields{
@@ -32,7 +73,7 @@ func (s *Service) newTx(ctx context.Context, requests map[storage.Driver]*storag
 		"transaction_requests_num": len(requests),
 	}).Info("saving new transaction")
 
-	err = s.saveTx(ctx, tx)
+	err = s.saveTx(ctx, tx, rows)
 	if err != nil {
 		return nil, fmt.Errorf("error saving transaction: %w", err)
 	}
@@ -40,6 +81,22 @@ func (s *Service) newTx(ctx context.Context, requests map[storage.Driver]*storag
 	return tx, nil
 }
 
+// rowsDrivers возвращает запросы транзакции по хранилищам. Транзакции они нужны для списка хранилищ:
+// в них транзакция начинается и коммитится. Если у хранилища несколько запросов - берется первый.
+func rowsDrivers(rows []map[storage.Driver]*storage.Request) map[storage.Driver]*storage.Request {
+	requests := make(map[storage.Driver]*storage.Request)
+
+	for _, row := range rows {
+		for driver, req := range row {
+			if _, ok := requests[driver]; !ok {
+				requests[driver] = req
+			}
+		}
+	}
+
+	return requests
+}
+
 func (s *Service) beginInDriver(ctx context.Context, tx storage.TransactionEditor, driver storage.Driver) error {
 	logrus.WithFields(logrus.Fields{
 		"transaction_id": tx.ID(),
@@ -136,11 +193,11 @@ func (s *Service) finishTx(ctx context.Context, tx storage.TransactionEditor) er
 	return nil
 }
 
-// saveTx сохраняет новую транзакцию в кэш и хранилище.
+// saveTx сохраняет новую транзакцию в кэш и хранилище вместе с запросами rows.
 // Для сохранения уже имеющейся транзакции необходимо использовать метод updateTx.
 //
 //nolint:cyclop // заведена задача BZ-95 на рефакторинг этого метода
-func (s *Service) saveTx(ctx context.Context, tx storage.TransactionEditor) error {
+func (s *Service) saveTx(ctx context.Context, tx storage.TransactionEditor, rows []map[storage.Driver]*storage.Request) error {
 	// создаем вспомогательную транзакцию для сохранения основной.
 	// вспомогательная транзакция нужна только как прослойка для сохранения основной, и не будет сохранена в БД.
 	if tx == nil {
@@ -188,7 +245,7 @@ func (s *Service) saveTx(ctx context.Context, tx storage.TransactionEditor) erro
 		return fmt.Errorf("error while executing requests: %w", err)
 	}
 
-	err = s.saveRequests(ctx, utilityTx, tx.Requests())
+	err = s.saveRequests(ctx, utilityTx, rows)
 	if err != nil {
 		return fmt.Errorf("error while saving requests: %w", err)
 	}
@@ -271,6 +328,11 @@ func (s *Service) fieldsForTx(tx storage.TransactionEditor) (map[string]any, err
 		return nil, fmt.Errorf("error marshaling request raw: %w", err)
 	}
 
+	jsonMetadata, err := json.Marshal(tx.Metadata())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling metadata: %w", err)
+	}
+
 	return map[string]interface{}{
 		"id":             tx.ID(),
 		"status":         tx.Status(),
@@ -280,6 +342,8 @@ func (s *Service) fieldsForTx(tx storage.TransactionEditor) (map[string]any, err
 		"data":           jsonData,
 		"operation_hash": s.cfg.Hash,
 		"operation_type": s.cfg.Type,
+		"correlation_id": tx.CorrelationID(),
+		"metadata":       jsonMetadata,
 	}, nil
 }
 
@@ -289,9 +353,21 @@ func (s *Service) operationForSavingTx(tx storage.TransactionEditor) operation.O
 		Name:    fmt.Sprintf("system operation for saving tx %s", tx.ID()),
 		Type:    operation.OperationTypeCreate,
 		Timeout: s.cfg.Timeout,
+		Fields: systemFields("id", "status", "error", "instance_id", "failed_driver", "data",
+			"operation_hash", "operation_type", "correlation_id", "metadata"),
 	}
 }
 
+// systemFields объявляет поля системной операции: в запрос попадают только объявленные поля.
+func systemFields(names ...string) []operation.Field {
+	fields := make([]operation.Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, operation.Field{Name: name})
+	}
+
+	return fields
+}
+
 // operationForUpdatingTx составляет операцию для обновления транзакции.
 func (s *Service) operationForUpdatingTx() operation.Operation {
 	return operation.Operation{
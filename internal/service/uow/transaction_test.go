@@ -3,6 +3,7 @@ package uow
 import (
 	"db-worker/internal/config/operation"
 	uowmocks "db-worker/internal/service/uow/mocks"
+	"db-worker/internal/service/worker"
 	"db-worker/internal/storage"
 	"db-worker/internal/storage/mocks"
 	"db-worker/internal/storage/testtransaction"
@@ -188,7 +189,7 @@ func TestNewTx(t *testing.T) {
 
 			tt.setupMocks(t, systemDriver, userDriver)
 
-			actualTx, err := svc.newTx(t.Context(), tt.createRequests(userDriver), tt.rawReq)
+			actualTx, err := svc.newTx(t.Context(), []map[storage.Driver]*storage.Request{tt.createRequests(userDriver)}, tt.rawReq, worker.Metadata{})
 			tt.wantErr(t, err)
 
 			tt.checkTx(t, tt.createExpectedTx(userDriver), actualTx)
@@ -196,6 +197,24 @@ func TestNewTx(t *testing.T) {
 	}
 }
 
+func TestGeneratedTxID(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{cfg: &operation.Operation{Fields: []operation.Field{
+		{Name: "text", Type: operation.FieldTypeString},
+		{Name: "tx_id", Type: operation.FieldTypeString, Generator: operation.GeneratorTxID},
+	}}}
+
+	assert.Equal(t, "tx-1", s.generatedTxID(map[string]any{"text": "a", "tx_id": "tx-1"}))
+	assert.Equal(t, "tx-2", s.generatedTxID(BatchRaw([]map[string]any{{"tx_id": "tx-2"}, {"tx_id": "tx-2"}})))
+	// данные загружены из БД
+	assert.Equal(t, "tx-3", s.generatedTxID(map[string]any{batchKey: []any{map[string]any{"tx_id": "tx-3"}}}))
+	assert.Empty(t, s.generatedTxID(BatchRaw(nil)))
+
+	s.cfg.Fields = s.cfg.Fields[:1]
+	assert.Empty(t, s.generatedTxID(map[string]any{"text": "a", "tx_id": "tx-1"}))
+}
+
 //nolint:funlen // много тест-кейсов
 func TestBeginInDriver(t *testing.T) {
 	t.Parallel()
@@ -1093,7 +1112,12 @@ func TestSaveTx(t *testing.T) {
 
 			tx := tt.createTx(t, driver)
 
-			err := svc.saveTx(t.Context(), tx)
+			var rows []map[storage.Driver]*storage.Request
+			if tx != nil {
+				rows = []map[storage.Driver]*storage.Request{tx.Requests()}
+			}
+
+			err := svc.saveTx(t.Context(), tx, rows)
 			tt.wantErr(t, err)
 
 			tt.checkTx(t, tx, driver)
@@ -1460,6 +1484,10 @@ func TestFieldsForTx(t *testing.T) {
 		testtransaction.WithRawReq(map[string]any{
 			"id": 1,
 		}),
+		testtransaction.WithMetadata("corr-1", map[string]any{
+			"correlation_id": "corr-1",
+			"headers":        map[string]any{"user_id": "42"},
+		}),
 	)
 
 	svc := &Service{
@@ -1472,6 +1500,9 @@ func TestFieldsForTx(t *testing.T) {
 	jsonData, err := json.Marshal(tx.RawReq())
 	require.NoError(t, err)
 
+	jsonMetadata, err := json.Marshal(tx.Metadata())
+	require.NoError(t, err)
+
 	expectedFields := map[string]any{
 		"id":             txID,
 		"status":         string(storage.TxStatusFailed),
@@ -1481,6 +1512,8 @@ func TestFieldsForTx(t *testing.T) {
 		"operation_hash": svc.cfg.Hash,
 		"operation_type": svc.cfg.Type,
 		"data":           jsonData,
+		"correlation_id": "corr-1",
+		"metadata":       jsonMetadata,
 	}
 
 	userDriver.EXPECT().Name().Return("test-storage").AnyTimes()
@@ -3,8 +3,15 @@ package validator
 import (
 	"db-worker/internal/config/operation"
 	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
 )
 
+// decimalPrecision - сколько знаков после точки выводить в ошибках валидации decimal с бесконечной дробью.
+const decimalPrecision = 6
+
 func validateInt64Value(field operation.Field, val int64) error {
 	validation := field.Validation
 
@@ -113,3 +120,61 @@ func validateBoolValue(field operation.Field, val bool) error {
 
 	return nil
 }
+
+// validateTimeValue проверяет границы timestamp и date. Дата сравнивается с границами как полночь UTC.
+func validateTimeValue(field operation.Field, val time.Time) error {
+	if field.Validation.MaxTime != nil {
+		if val.After(*field.Validation.MaxTime) {
+			return fmt.Errorf("field %q must be before %s, but got %s", field.Name, field.Validation.MaxTime.Format(time.RFC3339), val.Format(time.RFC3339))
+		}
+	}
+
+	if field.Validation.MinTime != nil {
+		if val.Before(*field.Validation.MinTime) {
+			return fmt.Errorf("field %q must be after %s, but got %s", field.Name, field.Validation.MinTime.Format(time.RFC3339), val.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func validateDecimalValue(field operation.Field, val *big.Rat) error {
+	if field.Validation.MaxDecimal != nil {
+		if val.Cmp(field.Validation.MaxDecimal) > 0 {
+			return fmt.Errorf("field %q must be less than %s, but got %s", field.Name, decimalString(field.Validation.MaxDecimal), decimalString(val))
+		}
+	}
+
+	if field.Validation.MinDecimal != nil {
+		if val.Cmp(field.Validation.MinDecimal) < 0 {
+			return fmt.Errorf("field %q must be greater than %s, but got %s", field.Name, decimalString(field.Validation.MinDecimal), decimalString(val))
+		}
+	}
+
+	return nil
+}
+
+// decimalString возвращает десятичную запись числа для ошибок валидации.
+func decimalString(val *big.Rat) string {
+	prec, exact := val.FloatPrec()
+	if !exact {
+		prec = decimalPrecision
+	}
+
+	return val.FloatString(prec)
+}
+
+func validateEnumValue(field operation.Field, val string) error {
+	if !slices.Contains(field.Enum, val) {
+		return fmt.Errorf("field %q must be one of %s, but got %s", field.Name, strings.Join(field.Enum, ", "), val)
+	}
+
+	if field.Validation.ExpectedValue != nil {
+		expectedValue, _ := field.Validation.ExpectedValue.(string)
+		if expectedValue != val {
+			return fmt.Errorf("field %q must be %s, but got %s", field.Name, expectedValue, val)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package validator
 import (
 	"db-worker/internal/config/operation"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,6 +17,11 @@ var validatorsMap = map[operation.FieldType]validatorFunc{
 	operation.FieldTypeBool:    validateBool,
 	operation.FieldTypeUUID:    validateUUID,
 	operation.FieldTypeString:  validateString,
+
+	operation.FieldTypeTimestamp: validateTimestamp,
+	operation.FieldTypeDate:      validateDate,
+	operation.FieldTypeDecimal:   validateDecimal,
+	operation.FieldTypeEnum:      validateEnum,
 }
 
 type validator struct {
@@ -120,3 +126,51 @@ func validateString(field operation.Field, val any) error {
 
 	return validateStringValue(field, v)
 }
+
+func validateTimestamp(field operation.Field, val any) error {
+	v, ok := val.(time.Time)
+	if !ok {
+		return fmt.Errorf("field %q is not a timestamp", field.Name)
+	}
+
+	return validateTimeValue(field, v)
+}
+
+// validateDate валидирует дату: после разбора она хранится строкой 2006-01-02.
+func validateDate(field operation.Field, val any) error {
+	v, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("field %q is not a date", field.Name)
+	}
+
+	date, err := time.Parse(operation.DateLayout, v)
+	if err != nil {
+		return fmt.Errorf("field %q must be a date in format %s", field.Name, operation.DateLayout)
+	}
+
+	return validateTimeValue(field, date)
+}
+
+// validateDecimal валидирует десятичное число: оно хранится строкой, чтобы не терять точность во float64.
+func validateDecimal(field operation.Field, val any) error {
+	v, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("field %q is not a decimal", field.Name)
+	}
+
+	d, err := operation.ParseDecimal(v)
+	if err != nil {
+		return fmt.Errorf("field %q must be a valid decimal", field.Name)
+	}
+
+	return validateDecimalValue(field, d)
+}
+
+func validateEnum(field operation.Field, val any) error {
+	v, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("field %q is not a string", field.Name)
+	}
+
+	return validateEnumValue(field, v)
+}
@@ -2,7 +2,9 @@ package validator
 
 import (
 	"db-worker/internal/config/operation"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -740,6 +742,283 @@ func TestValidateUUID(t *testing.T) {
 	}
 }
 
+//nolint:funlen // тестовая функция
+func TestValidateTimestamp(t *testing.T) {
+	t.Parallel()
+
+	minTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	field := operation.Field{
+		Name:       "test",
+		Type:       operation.FieldTypeTimestamp,
+		Validation: operation.AggregatedValidation{MinTime: &minTime, MaxTime: &maxTime},
+	}
+
+	testCases := []struct {
+		name        string
+		field       operation.Field
+		value       any
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "valid_timestamp",
+			field:       field,
+			value:       time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			expectError: false,
+		},
+		{
+			name:        "valid_timestamp_on_bound_other_zone",
+			field:       field,
+			value:       time.Date(2024, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			expectError: false,
+		},
+		{
+			name:        "timestamp_before_min",
+			field:       field,
+			value:       time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC),
+			expectError: true,
+			errorMsg:    "field \"test\" must be after 2024-01-01T00:00:00Z",
+		},
+		{
+			name:        "timestamp_after_max",
+			field:       field,
+			value:       time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC),
+			expectError: true,
+			errorMsg:    "field \"test\" must be before 2025-01-01T00:00:00Z",
+		},
+		{
+			name:        "invalid_type_string",
+			field:       field,
+			value:       "2024-06-01T12:00:00Z",
+			expectError: true,
+			errorMsg:    "field \"test\" is not a timestamp",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTimestamp(tc.field, tc.value)
+
+			if tc.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+//nolint:funlen // тестовая функция
+func TestValidateDate(t *testing.T) {
+	t.Parallel()
+
+	minDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	field := operation.Field{
+		Name:       "test",
+		Type:       operation.FieldTypeDate,
+		Validation: operation.AggregatedValidation{MinTime: &minDate},
+	}
+
+	testCases := []struct {
+		name        string
+		field       operation.Field
+		value       any
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "valid_date",
+			field:       field,
+			value:       "2024-01-01",
+			expectError: false,
+		},
+		{
+			name:        "date_before_min",
+			field:       field,
+			value:       "2023-12-31",
+			expectError: true,
+			errorMsg:    "field \"test\" must be after 2024-01-01T00:00:00Z",
+		},
+		{
+			name:        "invalid_date_format",
+			field:       field,
+			value:       "01.01.2024",
+			expectError: true,
+			errorMsg:    "field \"test\" must be a date in format 2006-01-02",
+		},
+		{
+			name:        "invalid_type_time",
+			field:       field,
+			value:       minDate,
+			expectError: true,
+			errorMsg:    "field \"test\" is not a date",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDate(tc.field, tc.value)
+
+			if tc.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+//nolint:funlen // тестовая функция
+func TestValidateDecimal(t *testing.T) {
+	t.Parallel()
+
+	field := operation.Field{
+		Name: "test",
+		Type: operation.FieldTypeDecimal,
+		Validation: operation.AggregatedValidation{
+			MinDecimal: big.NewRat(1, 100),
+			MaxDecimal: big.NewRat(1000, 1),
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		field       operation.Field
+		value       any
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "valid_decimal",
+			field:       field,
+			value:       "999.99",
+			expectError: false,
+		},
+		{
+			name:        "valid_decimal_min_bound",
+			field:       field,
+			value:       "0.0100",
+			expectError: false,
+		},
+		{
+			name:        "valid_decimal_precision",
+			field:       operation.Field{Name: "test", Type: operation.FieldTypeDecimal},
+			value:       "12345678901234567890.123456789012345678",
+			expectError: false,
+		},
+		{
+			name:        "decimal_less_than_min",
+			field:       field,
+			value:       "0.009",
+			expectError: true,
+			errorMsg:    "field \"test\" must be greater than 0.01, but got 0.009",
+		},
+		{
+			name:        "decimal_greater_than_max",
+			field:       field,
+			value:       "1000.000000000000000001",
+			expectError: true,
+			errorMsg:    "field \"test\" must be less than 1000, but got 1000.000000000000000001",
+		},
+		{
+			name:        "invalid_decimal",
+			field:       field,
+			value:       "1/3",
+			expectError: true,
+			errorMsg:    "field \"test\" must be a valid decimal",
+		},
+		{
+			name:        "invalid_type_float",
+			field:       field,
+			value:       float64(10),
+			expectError: true,
+			errorMsg:    "field \"test\" is not a decimal",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDecimal(tc.field, tc.value)
+
+			if tc.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateEnum(t *testing.T) {
+	t.Parallel()
+
+	field := operation.Field{Name: "test", Type: operation.FieldTypeEnum, Enum: []string{"new", "done"}}
+
+	testCases := []struct {
+		name        string
+		field       operation.Field
+		value       any
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "valid_enum",
+			field:       field,
+			value:       "done",
+			expectError: false,
+		},
+		{
+			name:        "value_not_in_enum",
+			field:       field,
+			value:       "archived",
+			expectError: true,
+			errorMsg:    "field \"test\" must be one of new, done, but got archived",
+		},
+		{
+			name: "expected_value_mismatch",
+			field: operation.Field{
+				Name: "test", Type: operation.FieldTypeEnum, Enum: []string{"new", "done"},
+				Validation: operation.AggregatedValidation{ExpectedValue: "new"},
+			},
+			value:       "done",
+			expectError: true,
+			errorMsg:    "field \"test\" must be new, but got done",
+		},
+		{
+			name:        "invalid_type_int",
+			field:       field,
+			value:       1,
+			expectError: true,
+			errorMsg:    "field \"test\" is not a string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateEnum(tc.field, tc.value)
+
+			if tc.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidatorsMap_Completeness(t *testing.T) {
 	t.Parallel()
 
@@ -750,6 +1029,10 @@ func TestValidatorsMap_Completeness(t *testing.T) {
 		operation.FieldTypeFloat64,
 		operation.FieldTypeBool,
 		operation.FieldTypeUUID,
+		operation.FieldTypeTimestamp,
+		operation.FieldTypeDate,
+		operation.FieldTypeDecimal,
+		operation.FieldTypeEnum,
 	}
 
 	for _, fieldType := range expectedTypes {
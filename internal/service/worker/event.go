@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"github.com/google/uuid"
+)
+
+// Event - итог обработки сообщения в том виде, в котором он публикуется наружу:
+// ответ отправителю (reply_to) и событие операции (events).
+type Event struct {
+	Operation     string      `json:"operation,omitempty"`      // название операции, обработавшей сообщение
+	MessageID     string      `json:"message_id,omitempty"`     // айди сообщения у источника
+	CorrelationID string      `json:"correlation_id,omitempty"` // correlation id отправителя
+	TxID          string      `json:"transaction_id,omitempty"` // айди транзакции (пустой, если до транзакции дело не дошло)
+	Status        string      `json:"status,omitempty"`         // итоговый статус транзакции
+	FailedDriver  string      `json:"failed_driver,omitempty"`  // название драйвера, на котором упала транзакция
+	Error         string      `json:"error,omitempty"`          // ошибка обработки
+	Duplicate     bool        `json:"duplicate,omitempty"`      // сообщение уже обработано раньше
+	IDs           []uuid.UUID `json:"message_ids,omitempty"`    // айди сообщений в messages.messages
+}
+
+// Event возвращает итог обработки сообщения с метаданными meta для публикации.
+func (r Result) Event(meta Metadata) Event {
+	event := Event{
+		Operation:     r.Operation,
+		MessageID:     meta.MessageID,
+		CorrelationID: meta.CorrelationID,
+		TxID:          r.TxID,
+		Status:        r.Status,
+		FailedDriver:  r.FailedDriver,
+		Duplicate:     r.Duplicate,
+		IDs:           r.IDs,
+	}
+
+	if r.Err != nil {
+		event.Error = r.Err.Error()
+	}
+
+	return event
+}
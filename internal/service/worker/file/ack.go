@@ -0,0 +1,85 @@
+package file
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// lineNotifier отмечает строку обработанной после успешного завершения транзакции.
+// Если обработка не удалась, строка отправляется в операцию повторно через retryInterval.
+type lineNotifier struct {
+	worker  *Worker
+	file    string
+	line    int64
+	payload payload
+
+	once sync.Once
+}
+
+// Persisted реализует worker.Notifier. Если строку не удалось сохранить - она отправляется повторно.
+func (n *lineNotifier) Persisted(_ []uuid.UUID, err error) {
+	if err != nil {
+		n.retry(err)
+	}
+}
+
+// Done реализует worker.Notifier.
+// Строки, не прошедшие валидацию, считаются обработанными: повторная обработка не поможет.
+func (n *lineNotifier) Done(res worker.Result) {
+	switch {
+	case res.Err == nil:
+		n.done()
+	case errors.Is(res.Err, worker.ErrInvalidMessage):
+		logrus.WithError(res.Err).WithFields(logrus.Fields{
+			"name": n.worker.config.name,
+			"file": n.file,
+			"line": n.line,
+		}).Warn("file: skip invalid line")
+
+		n.done()
+	default:
+		n.retry(res.Err)
+	}
+}
+
+func (n *lineNotifier) done() {
+	n.once.Do(func() {
+		n.worker.lines.done(n.file, n.line)
+	})
+}
+
+func (n *lineNotifier) retry(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithFields(logrus.Fields{
+			"name":  n.worker.config.name,
+			"file":  n.file,
+			"line":  n.line,
+			"delay": n.worker.config.retryInterval,
+		}).Warn("file: retry line")
+
+		go n.worker.resend(n.file, n.line, n.payload)
+	})
+}
+
+// resend повторно отправляет строку в операцию после retryInterval.
+// Если работа завершится раньше, строка будет прочитана из файла после перезапуска.
+func (s *Worker) resend(file string, line int64, p payload) {
+	timer := time.NewTimer(s.config.retryInterval)
+	defer timer.Stop()
+
+	select {
+	case <-s.quitChan:
+		return
+	case <-timer.C:
+	}
+
+	select {
+	case s.msgChan <- s.message(file, line, p):
+	case <-s.quitChan:
+	}
+}
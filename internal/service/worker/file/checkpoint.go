@@ -0,0 +1,138 @@
+package file
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lineTracker следит за строками файлов, переданными в операцию, и определяет позицию чтения:
+// сохраняется только непрерывный префикс обработанных строк, поэтому необработанная строка
+// никогда не окажется перед сохраненной позицией.
+type lineTracker struct {
+	mu    sync.Mutex
+	files map[string]*fileLines
+}
+
+// fileLines - обработанные строки файла.
+type fileLines struct {
+	processed int64              // сколько строк с начала файла обработано без пропусков
+	saved     int64              // сколько строк сохранено в базе
+	done      map[int64]struct{} // обработанные строки после первой необработанной
+}
+
+func newLineTracker() *lineTracker {
+	return &lineTracker{
+		files: make(map[string]*fileLines),
+	}
+}
+
+// start начинает отслеживать файл, первые processed строк которого уже обработаны.
+func (t *lineTracker) start(file string, processed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.files[file] = &fileLines{
+		processed: processed,
+		saved:     processed,
+		done:      make(map[int64]struct{}),
+	}
+}
+
+// done отмечает строку (нумерация с 1) обработанной.
+func (t *lineTracker) done(file string, line int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.files[file]
+	if !ok || line <= f.processed {
+		return
+	}
+
+	f.done[line] = struct{}{}
+
+	for {
+		if _, ok := f.done[f.processed+1]; !ok {
+			break
+		}
+
+		delete(f.done, f.processed+1)
+		f.processed++
+	}
+}
+
+// unsaved возвращает позиции файлов, которые изменились с последнего сохранения.
+func (t *lineTracker) unsaved() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	positions := make(map[string]int64)
+
+	for file, f := range t.files {
+		if f.processed != f.saved {
+			positions[file] = f.processed
+		}
+	}
+
+	return positions
+}
+
+// saved отмечает позицию файла сохраненной.
+func (t *lineTracker) saved(file string, processed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if f, ok := t.files[file]; ok {
+		f.saved = processed
+	}
+}
+
+// runCheckpoints периодически сохраняет позиции чтения.
+func (s *Worker) runCheckpoints(ctx context.Context) {
+	ticker := time.NewTicker(s.config.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.quitChan:
+			return
+		case <-ticker.C:
+			s.saveCheckpoints()
+		}
+	}
+}
+
+// saveCheckpoints сохраняет позиции чтения, которые изменились с последнего сохранения.
+func (s *Worker) saveCheckpoints() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	for file, processed := range s.lines.unsaved() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.insertTimeout)*time.Millisecond)
+		err := s.checkpoints.Save(ctx, s.config.name, file, processed)
+
+		cancel()
+
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"name": s.config.name,
+				"file": file,
+				"line": processed,
+			}).Error("file: error save checkpoint")
+
+			continue
+		}
+
+		s.lines.saved(file, processed)
+
+		logrus.WithFields(logrus.Fields{
+			"name": s.config.name,
+			"file": file,
+			"line": processed,
+		}).Debug("file: checkpoint saved")
+	}
+}
@@ -0,0 +1,66 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineTracker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		processed int64
+		done      []int64
+		want      map[string]int64
+	}{
+		{
+			name: "lines in order",
+			done: []int64{1, 2, 3},
+			want: map[string]int64{"a.jsonl": 3},
+		},
+		{
+			name: "gap stops position",
+			done: []int64{1, 3, 4},
+			want: map[string]int64{"a.jsonl": 1},
+		},
+		{
+			name: "gap filled",
+			done: []int64{3, 2, 1},
+			want: map[string]int64{"a.jsonl": 3},
+		},
+		{
+			name:      "resume from checkpoint",
+			processed: 10,
+			done:      []int64{9, 11, 12},
+			want:      map[string]int64{"a.jsonl": 12},
+		},
+		{
+			name: "nothing processed",
+			done: []int64{2},
+			want: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := newLineTracker()
+			tracker.start("a.jsonl", tt.processed)
+
+			for _, line := range tt.done {
+				tracker.done("a.jsonl", line)
+			}
+
+			assert.Equal(t, tt.want, tracker.unsaved())
+
+			for file, processed := range tt.want {
+				tracker.saved(file, processed)
+			}
+
+			assert.Empty(t, tracker.unsaved())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Run читает файлы по порядку и по одной передает строки в операцию.
+// Когда все файлы прочитаны, Run продолжает сохранять позиции чтения до завершения работы.
+func (s *Worker) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name":   s.config.name,
+		"files":  s.files,
+		"follow": s.config.follow,
+	}).Info("file: start read messages")
+
+	go s.runCheckpoints(ctx)
+
+	for i, file := range s.files {
+		follow := s.config.follow && i == len(s.files)-1
+
+		ok, err := s.readFile(ctx, file, follow)
+		if err != nil {
+			s.setConnected(false)
+			return err
+		}
+
+		if !ok {
+			logrus.WithField("name", s.config.name).Info("file: stop read messages")
+			return nil
+		}
+	}
+
+	logrus.WithField("name", s.config.name).Info("file: all files read")
+
+	select {
+	case <-ctx.Done():
+	case <-s.quitChan:
+	}
+
+	return nil
+}
+
+// readFile передает в операцию необработанные строки файла. Возвращает false, если работа завершена.
+//
+//nolint:funlen // цельная логика чтения файла
+func (s *Worker) readFile(ctx context.Context, file string, follow bool) (bool, error) {
+	processed, err := s.checkpoint(ctx, file)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return false, fmt.Errorf("file: error open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+
+	if isGzip(file) {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return false, fmt.Errorf("file: error open gzip %s: %w", file, err)
+		}
+		defer gz.Close()
+
+		r = gz
+	}
+
+	s.lines.start(file, processed)
+
+	logrus.WithFields(logrus.Fields{
+		"name":   s.config.name,
+		"file":   file,
+		"skip":   processed,
+		"follow": follow,
+	}).Info("file: start read file")
+
+	reader := bufio.NewReader(r)
+
+	var (
+		line    int64
+		partial []byte // начало строки, которую еще не дописали (follow)
+	)
+
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("file: error read %s: %w", file, err)
+		}
+
+		if errors.Is(err, io.EOF) && follow {
+			partial = append(partial, chunk...)
+
+			if !s.wait(ctx, s.config.pollInterval) {
+				return false, nil
+			}
+
+			continue
+		}
+
+		if len(partial) > 0 {
+			chunk = append(partial, chunk...)
+			partial = nil
+		}
+
+		if len(chunk) > 0 {
+			line++
+
+			if line > processed && !s.handle(ctx, file, line, chunk) {
+				return false, nil
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			logrus.WithFields(logrus.Fields{
+				"name":  s.config.name,
+				"file":  file,
+				"lines": line,
+			}).Info("file: file read")
+
+			return true, nil
+		}
+	}
+}
+
+// checkpoint возвращает количество уже обработанных строк файла.
+func (s *Worker) checkpoint(ctx context.Context, file string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.readTimeout)*time.Millisecond)
+	defer cancel()
+
+	processed, err := s.checkpoints.Get(ctx, s.config.name, file)
+	if err != nil {
+		return 0, fmt.Errorf("file: error get checkpoint of %s: %w", file, err)
+	}
+
+	return processed, nil
+}
+
+// handle передает строку в операцию. Пустые строки пропускаются.
+// Строка с некорректным JSON тоже передается: ее завершит диспетчер соединения.
+// Возвращает false, если работа завершена.
+func (s *Worker) handle(ctx context.Context, file string, line int64, raw []byte) bool {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		s.lines.done(file, line)
+		return true
+	}
+
+	var (
+		p   payload
+		err error
+	)
+
+	p.data, err = codec.DecodeJSON(raw)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name": s.config.name,
+			"file": file,
+			"line": line,
+		}).Error("file: error unmarshal message")
+
+		// строку не удастся обработать и при повторном чтении
+		p = payload{raw: bytes.Clone(raw), err: fmt.Errorf("file: error unmarshal line: %w", err)}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":    s.config.name,
+		"file":    file,
+		"line":    line,
+		"message": p.data,
+	}).Debug("file: received message")
+
+	select {
+	case s.msgChan <- s.message(file, line, p):
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	}
+}
+
+// payload - содержимое строки: сообщение или, если строку не удалось разобрать, исходная строка и ошибка.
+type payload struct {
+	data map[string]any
+	raw  []byte
+	err  error
+}
+
+func (s *Worker) message(file string, line int64, p payload) worker.Message {
+	return worker.Message{
+		Data:      p.data,
+		Raw:       p.raw,
+		DecodeErr: p.err,
+		Meta:      worker.Metadata{MessageID: fmt.Sprintf("%s:%d", file, line)},
+		Notifier:  &lineNotifier{worker: s, file: file, line: line, payload: p},
+	}
+}
+
+// wait ждет delay. Возвращает false, если работа завершена раньше.
+func (s *Worker) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,266 @@
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore хранит позиции чтения в памяти.
+type fakeStore struct {
+	mu    sync.Mutex
+	lines map[string]int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{lines: make(map[string]int64)}
+}
+
+func (s *fakeStore) Get(_ context.Context, connection, file string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lines[connection+":"+file], nil
+}
+
+func (s *fakeStore) Save(_ context.Context, connection, file string, line int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lines[connection+":"+file] = line
+
+	return nil
+}
+
+func (s *fakeStore) get(file string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lines["replay:"+file]
+}
+
+func newTestWorker(t *testing.T, path string, store *fakeStore, opts ...Option) *Worker {
+	t.Helper()
+
+	w, err := New(append([]Option{
+		WithName("replay"),
+		WithPath(path),
+		WithCheckpoints(store),
+		WithPollInterval(10 * time.Millisecond),
+		WithCheckpointInterval(10 * time.Millisecond),
+		WithRetryInterval(10 * time.Millisecond),
+		WithInsertTimeout(1000),
+		WithReadTimeout(1000),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Connect())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, w.Run(t.Context()))
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, w.Stop(context.Background()))
+		<-done
+	})
+
+	return w
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func writeGzip(t *testing.T, path, data string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	gz := gzip.NewWriter(f)
+
+	_, err = gz.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+}
+
+func receive(t *testing.T, w *Worker) worker.Message {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		return msg
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "message not received")
+	}
+
+	return worker.Message{}
+}
+
+func noMessages(t *testing.T, w *Worker) {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		assert.Fail(t, "unexpected message", "%v", msg.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRun_Files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "01.jsonl")
+	second := filepath.Join(dir, "02.jsonl.gz")
+
+	// пустые строки пропускаются, последняя строка без перевода строки тоже читается
+	writeFile(t, first, "{\"id\": 1}\n\nnot json\n{\"id\": 2}")
+	writeGzip(t, second, "{\"id\": 3}\n")
+
+	store := newFakeStore()
+	w := newTestWorker(t, filepath.Join(dir, "*"), store)
+
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	// битый JSON передается с исходной строкой, диспетчер завершает его как невалидный
+	msg = receive(t, w)
+	require.Error(t, msg.DecodeErr)
+	assert.Equal(t, []byte("not json"), msg.Raw)
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, msg.DecodeErr)})
+
+	for _, id := range []json.Number{"2", "3"} {
+		msg := receive(t, w)
+		assert.Equal(t, map[string]any{"id": id}, msg.Data)
+		msg.Done(worker.Result{})
+	}
+
+	noMessages(t, w)
+
+	assert.Eventually(t, func() bool {
+		return store.get(first) == 4 && store.get(second) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_ResumeFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.jsonl")
+
+	writeFile(t, path, "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n")
+
+	store := newFakeStore()
+	require.NoError(t, store.Save(t.Context(), "replay", path, 2))
+
+	w := newTestWorker(t, path, store)
+
+	// первые две строки обработаны до перезапуска
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("3")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	noMessages(t, w)
+
+	assert.Eventually(t, func() bool {
+		return store.get(path) == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_Retry(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.jsonl")
+
+	writeFile(t, path, "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n")
+
+	store := newFakeStore()
+	w := newTestWorker(t, path, store)
+
+	failed := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, failed.Data)
+	failed.Done(worker.Result{Err: errors.New("error exec requests")})
+
+	// невалидная строка больше не отправляется, но и не задерживает позицию
+	invalid := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, invalid.Data)
+	invalid.Done(worker.Result{Err: worker.ErrInvalidMessage})
+
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("3")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	// пока первая строка не обработана, позиция не сдвигается
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(0), store.get(path))
+
+	retried := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, retried.Data)
+	retried.Done(worker.Result{})
+
+	noMessages(t, w)
+
+	assert.Eventually(t, func() bool {
+		return store.get(path) == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_Follow(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.jsonl")
+
+	writeFile(t, path, "{\"id\": 1}\n")
+
+	store := newFakeStore()
+	w := newTestWorker(t, path, store, WithFollow(true))
+
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	// недописанная строка не отправляется, пока не появится перевод строки
+	_, err = f.WriteString("{\"id\":")
+	require.NoError(t, err)
+
+	noMessages(t, w)
+
+	_, err = f.WriteString(" 2}\n")
+	require.NoError(t, err)
+
+	msg = receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	assert.Eventually(t, func() bool {
+		return store.get(path) == 2
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockconnectionMetrics is a mock of connectionMetrics interface.
+type MockconnectionMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockconnectionMetricsMockRecorder
+}
+
+// MockconnectionMetricsMockRecorder is the mock recorder for MockconnectionMetrics.
+type MockconnectionMetricsMockRecorder struct {
+	mock *MockconnectionMetrics
+}
+
+// NewMockconnectionMetrics creates a new mock instance.
+func NewMockconnectionMetrics(ctrl *gomock.Controller) *MockconnectionMetrics {
+	mock := &MockconnectionMetrics{ctrl: ctrl}
+	mock.recorder = &MockconnectionMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockconnectionMetrics) EXPECT() *MockconnectionMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddConnectionReconnects mocks base method.
+func (m *MockconnectionMetrics) AddConnectionReconnects(name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddConnectionReconnects", name)
+}
+
+// AddConnectionReconnects indicates an expected call of AddConnectionReconnects.
+func (mr *MockconnectionMetricsMockRecorder) AddConnectionReconnects(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConnectionReconnects", reflect.TypeOf((*MockconnectionMetrics)(nil).AddConnectionReconnects), name)
+}
+
+// SetConnectionStatus mocks base method.
+func (m *MockconnectionMetrics) SetConnectionStatus(name string, connected bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConnectionStatus", name, connected)
+}
+
+// SetConnectionStatus indicates an expected call of SetConnectionStatus.
+func (mr *MockconnectionMetricsMockRecorder) SetConnectionStatus(name, connected interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConnectionStatus", reflect.TypeOf((*MockconnectionMetrics)(nil).SetConnectionStatus), name, connected)
+}
+
+// MockcheckpointStore is a mock of checkpointStore interface.
+type MockcheckpointStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockcheckpointStoreMockRecorder
+}
+
+// MockcheckpointStoreMockRecorder is the mock recorder for MockcheckpointStore.
+type MockcheckpointStoreMockRecorder struct {
+	mock *MockcheckpointStore
+}
+
+// NewMockcheckpointStore creates a new mock instance.
+func NewMockcheckpointStore(ctrl *gomock.Controller) *MockcheckpointStore {
+	mock := &MockcheckpointStore{ctrl: ctrl}
+	mock.recorder = &MockcheckpointStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcheckpointStore) EXPECT() *MockcheckpointStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockcheckpointStore) Get(ctx context.Context, connection, file string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, connection, file)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockcheckpointStoreMockRecorder) Get(ctx, connection, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockcheckpointStore)(nil).Get), ctx, connection, file)
+}
+
+// Save mocks base method.
+func (m *MockcheckpointStore) Save(ctx context.Context, connection, file string, line int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, connection, file, line)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockcheckpointStoreMockRecorder) Save(ctx, connection, file, line interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockcheckpointStore)(nil).Save), ctx, connection, file, line)
+}
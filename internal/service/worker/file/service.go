@@ -0,0 +1,276 @@
+package file
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Worker читает сообщения из JSONL-файлов: по одному JSON-объекту в строке. Файлы с расширением .gz читаются через gzip.
+// Количество обработанных строк каждого файла сохраняется в базе сервиса, поэтому после перезапуска чтение продолжается
+// с первой необработанной строки. В режиме follow последний файл читается дальше по мере дописывания (как tail -f).
+type Worker struct {
+	config struct {
+		name   string
+		path   string // путь к файлу или glob
+		follow bool   // читать последний файл дальше по мере дописывания
+
+		pollInterval       time.Duration // как часто проверять, дописан ли файл (follow)
+		checkpointInterval time.Duration // как часто сохранять количество обработанных строк
+		retryInterval      time.Duration // пауза перед повторной отправкой строки после ошибки обработки
+	}
+
+	files []string // файлы в порядке чтения
+
+	checkpoints checkpointStore
+	lines       *lineTracker
+	saveMu      sync.Mutex // позиции сохраняются по таймеру и при остановке: не даем сохранениям перемешаться
+
+	msgChan  chan worker.Message
+	quitChan chan struct{}
+
+	connected atomic.Bool
+	metrics   connectionMetrics
+
+	insertTimeout int
+	readTimeout   int
+}
+
+const (
+	defaultPollInterval       = time.Second
+	defaultCheckpointInterval = time.Second
+	defaultRetryInterval      = time.Second
+)
+
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type connectionMetrics interface {
+	SetConnectionStatus(name string, connected bool)
+	AddConnectionReconnects(name string)
+}
+
+// checkpointStore хранит количество обработанных строк файлов.
+type checkpointStore interface {
+	Get(ctx context.Context, connection, file string) (int64, error)
+	Save(ctx context.Context, connection, file string, line int64) error
+}
+
+// Option определяет опции для Worker.
+type Option func(*Worker)
+
+// WithName устанавливает имя соединения. Позиции чтения сохраняются по имени соединения и пути к файлу.
+func WithName(name string) Option {
+	return func(w *Worker) {
+		w.config.name = name
+	}
+}
+
+// WithPath устанавливает путь к файлу или glob. Файлы читаются по порядку имен.
+func WithPath(path string) Option {
+	return func(w *Worker) {
+		w.config.path = path
+	}
+}
+
+// WithFollow включает чтение последнего файла по мере дописывания.
+func WithFollow(follow bool) Option {
+	return func(w *Worker) {
+		w.config.follow = follow
+	}
+}
+
+// WithPollInterval устанавливает, как часто проверять, дописан ли файл.
+func WithPollInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.pollInterval = interval
+	}
+}
+
+// WithCheckpointInterval устанавливает, как часто сохранять количество обработанных строк.
+func WithCheckpointInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.checkpointInterval = interval
+	}
+}
+
+// WithRetryInterval устанавливает паузу перед повторной отправкой строки после ошибки обработки.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.retryInterval = interval
+	}
+}
+
+// WithCheckpoints устанавливает хранилище позиций чтения.
+func WithCheckpoints(checkpoints checkpointStore) Option {
+	return func(w *Worker) {
+		w.checkpoints = checkpoints
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для отчета о состоянии соединения.
+func WithMetrics(metrics connectionMetrics) Option {
+	return func(w *Worker) {
+		w.metrics = metrics
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания сохранения позиции чтения (мс).
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(w *Worker) {
+		w.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает время ожидания чтения позиции (мс).
+func WithReadTimeout(readTimeout int) Option {
+	return func(w *Worker) {
+		w.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Worker.
+func New(opts ...Option) (*Worker, error) {
+	w := &Worker{}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.config.name == "" {
+		return nil, fmt.Errorf("file: name is required")
+	}
+
+	if w.config.path == "" {
+		return nil, fmt.Errorf("file: path is required")
+	}
+
+	if w.checkpoints == nil {
+		return nil, fmt.Errorf("file: checkpoints are required")
+	}
+
+	if w.insertTimeout == 0 {
+		return nil, fmt.Errorf("file: insert timeout is required")
+	}
+
+	if w.readTimeout == 0 {
+		return nil, fmt.Errorf("file: read timeout is required")
+	}
+
+	if w.config.pollInterval == 0 {
+		w.config.pollInterval = defaultPollInterval
+	}
+
+	if w.config.checkpointInterval == 0 {
+		w.config.checkpointInterval = defaultCheckpointInterval
+	}
+
+	if w.config.retryInterval == 0 {
+		w.config.retryInterval = defaultRetryInterval
+	}
+
+	w.lines = newLineTracker()
+	w.msgChan = make(chan worker.Message)
+	w.quitChan = make(chan struct{})
+
+	return w, nil
+}
+
+// Connect находит файлы по пути. Файлы, появившиеся после подключения, не читаются.
+func (s *Worker) Connect() error {
+	files, err := filepath.Glob(s.config.path)
+	if err != nil {
+		return fmt.Errorf("file: error match path: %w", err)
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("file: no files match %s", s.config.path)
+	}
+
+	sort.Strings(files)
+
+	if s.config.follow && isGzip(files[len(files)-1]) {
+		return fmt.Errorf("file: follow is not supported for gzip file %s", files[len(files)-1])
+	}
+
+	s.files = files
+	s.setConnected(true)
+
+	logrus.WithFields(logrus.Fields{
+		"name":   s.config.name,
+		"path":   s.config.path,
+		"files":  files,
+		"follow": s.config.follow,
+	}).Info("file: connected")
+
+	return nil
+}
+
+// Stop прекращает чтение и сохраняет позиции чтения.
+// Строки, которые сейчас обрабатываются, будут прочитаны повторно после перезапуска.
+func (s *Worker) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	s.saveCheckpoints()
+	s.setConnected(false)
+
+	return nil
+}
+
+func (s *Worker) setConnected(connected bool) {
+	s.connected.Store(connected)
+
+	if s.metrics != nil {
+		s.metrics.SetConnectionStatus(s.config.name, connected)
+	}
+}
+
+// Connected возвращает true, если файлы найдены и читаются.
+func (s *Worker) Connected() bool {
+	return s.connected.Load()
+}
+
+func isGzip(file string) bool {
+	return strings.HasSuffix(file, ".gz")
+}
+
+// Name возвращает имя соединения.
+func (s *Worker) Name() string {
+	return s.config.name
+}
+
+// MsgChan возвращает канал для получения сообщений.
+func (s *Worker) MsgChan() chan worker.Message {
+	return s.msgChan
+}
+
+// Address возвращает путь к файлам.
+func (s *Worker) Address() string {
+	return s.config.path
+}
+
+// Queue для соответствия интерфейсу Worker.
+func (s *Worker) Queue() string {
+	return ""
+}
+
+// RoutingKey для соответствия интерфейсу Worker.
+func (s *Worker) RoutingKey() string {
+	return ""
+}
+
+// InsertTimeout для соответствия интерфейсу Worker.
+func (s *Worker) InsertTimeout() int {
+	return s.insertTimeout
+}
+
+// ReadTimeout для соответствия интерфейсу Worker.
+func (s *Worker) ReadTimeout() int {
+	return s.readTimeout
+}
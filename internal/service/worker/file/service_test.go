@@ -0,0 +1,148 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	validOpts := func(opts ...Option) []Option {
+		return append([]Option{
+			WithName("replay"),
+			WithPath("testdata/*.jsonl"),
+			WithCheckpoints(newFakeStore()),
+			WithInsertTimeout(1),
+			WithReadTimeout(1),
+		}, opts...)
+	}
+
+	tests := []struct {
+		name             string
+		opts             []Option
+		wantPollInterval time.Duration
+		wantErr          require.ErrorAssertionFunc
+	}{
+		{
+			name:             "positive case: defaults",
+			opts:             validOpts(),
+			wantPollInterval: defaultPollInterval,
+			wantErr:          require.NoError,
+		},
+		{
+			name:             "positive case: custom poll interval",
+			opts:             validOpts(WithFollow(true), WithPollInterval(time.Minute)),
+			wantPollInterval: time.Minute,
+			wantErr:          require.NoError,
+		},
+		{
+			name:    "negative case: name is empty",
+			opts:    validOpts(WithName("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: path is empty",
+			opts:    validOpts(WithPath("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: checkpoints are nil",
+			opts:    validOpts(WithCheckpoints(nil)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    validOpts(WithInsertTimeout(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: read timeout is 0",
+			opts:    validOpts(WithReadTimeout(0)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantPollInterval, got.config.pollInterval)
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"b.jsonl", "a.jsonl", "c.jsonl.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		follow    bool
+		wantFiles []string
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "positive case: glob sorted by name",
+			path:      filepath.Join(dir, "*.jsonl"),
+			wantFiles: []string{filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")},
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "positive case: single file",
+			path:      filepath.Join(dir, "c.jsonl.gz"),
+			wantFiles: []string{filepath.Join(dir, "c.jsonl.gz")},
+			wantErr:   require.NoError,
+		},
+		{
+			name:    "negative case: no files",
+			path:    filepath.Join(dir, "*.csv"),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: follow gzip",
+			path:    filepath.Join(dir, "*"),
+			follow:  true,
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := New(
+				WithName("replay"),
+				WithPath(tt.path),
+				WithFollow(tt.follow),
+				WithCheckpoints(newFakeStore()),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			)
+			require.NoError(t, err)
+
+			tt.wantErr(t, w.Connect())
+
+			assert.Equal(t, tt.wantFiles, w.files)
+			assert.Equal(t, tt.wantFiles != nil, w.Connected())
+		})
+	}
+}
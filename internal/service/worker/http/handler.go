@@ -0,0 +1,260 @@
+package http
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Response - ответ на запрос с сообщением.
+type Response struct {
+	IDs    []uuid.UUID `json:"ids,omitempty"`    // айди сообщений в messages.messages
+	TxID   string      `json:"tx_id,omitempty"`  // айди транзакции (только sync)
+	Status string      `json:"status,omitempty"` // статус транзакции (только sync)
+	// Duplicate - сообщение уже обработано раньше, TxID и Status - итог исходной транзакции (только sync)
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// notifier получает уведомления от операции и передает их в обработчик запроса.
+type notifier struct {
+	persisted chan persistedEvent
+	done      chan worker.Result
+}
+
+type persistedEvent struct {
+	ids []uuid.UUID
+	err error
+	res *worker.Result // итог обработки, если она завершилась без сохранения сообщения
+}
+
+func newNotifier() *notifier {
+	// буферизованные каналы, чтобы операция не блокировалась, если клиент уже ушел
+	return &notifier{
+		persisted: make(chan persistedEvent, 1),
+		done:      make(chan worker.Result, 1),
+	}
+}
+
+// Persisted реализует worker.Notifier.
+func (n *notifier) Persisted(ids []uuid.UUID, err error) {
+	select {
+	case n.persisted <- persistedEvent{ids: ids, err: err}:
+	default:
+	}
+}
+
+// Done реализует worker.Notifier.
+// Persisted всегда вызывается раньше Done. Если его не было, обработчик все еще ждет сохранения: итог передается
+// и ему. Если Persisted уже был, событие не попадет в занятый буфер или останется непрочитанным.
+func (n *notifier) Done(res worker.Result) {
+	select {
+	case n.persisted <- persistedEvent{res: &res}:
+	default:
+	}
+
+	select {
+	case n.done <- res:
+	default:
+	}
+}
+
+// Handle принимает сообщение в теле запроса и передает его в операцию. Формат тела выбирается по Content-Type,
+// неизвестный Content-Type - формат соединения (по умолчанию JSON).
+// В режиме async отвечает 202 с айди сохраненных сообщений, в режиме sync - итогом транзакции.
+// Тело больше max_body_size не читается до конца: ответ 413.
+func (s *Worker) Handle(c echo.Context) error {
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, s.config.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, Response{
+				Error: fmt.Sprintf("body is larger than %d bytes", maxBytesErr.Limit),
+			})
+		}
+
+		return c.JSON(http.StatusBadRequest, Response{Error: "error read body: " + err.Error()})
+	}
+
+	meta := metadata(c.Request())
+
+	body, err := s.codec.Decode(meta.ContentType, raw)
+	if err != nil {
+		return s.handleDecodeError(c, raw, meta, err)
+	}
+
+	if len(body) == 0 {
+		return c.JSON(http.StatusBadRequest, Response{Error: "empty message"})
+	}
+
+	n := newNotifier()
+
+	logrus.WithFields(logrus.Fields{
+		"name":    s.config.name,
+		"message": body,
+	}).Debug("http: received message")
+
+	if status, err := s.send(c, worker.Message{Data: body, Meta: meta, Notifier: n}); err != nil {
+		return c.JSON(status, Response{Error: err.Error()})
+	}
+
+	timer := time.NewTimer(time.Duration(s.readTimeout) * time.Millisecond)
+	defer timer.Stop()
+
+	var ids []uuid.UUID
+
+	select {
+	case ev := <-n.persisted:
+		if ev.res != nil {
+			// обработка завершилась без сохранения: например, сообщение не подошло ни одной операции
+			return c.JSON(resultStatus(*ev.res), Response{Error: errorString(ev.res.Err)})
+		}
+
+		if ev.err != nil {
+			return c.JSON(http.StatusInternalServerError, Response{Error: ev.err.Error()})
+		}
+
+		ids = ev.ids
+	case <-timer.C:
+		return c.JSON(http.StatusGatewayTimeout, Response{Error: "timeout waiting for message to be saved"})
+	case <-c.Request().Context().Done():
+		return c.Request().Context().Err()
+	}
+
+	if s.config.mode == ModeAsync {
+		return c.JSON(http.StatusAccepted, Response{IDs: ids})
+	}
+
+	select {
+	case res := <-n.done:
+		return c.JSON(resultStatus(res), Response{
+			IDs:       ids,
+			TxID:      res.TxID,
+			Status:    res.Status,
+			Duplicate: res.Duplicate,
+			Error:     errorString(res.Err),
+		})
+	case <-timer.C:
+		return c.JSON(http.StatusGatewayTimeout, Response{IDs: ids, Error: "timeout waiting for transaction result"})
+	case <-c.Request().Context().Done():
+		return c.Request().Context().Err()
+	}
+}
+
+// handleDecodeError передает тело, которое не удалось декодировать, диспетчеру соединения
+// (он считает такие сообщения и отправляет их в dead_letter) и отвечает 400.
+// Если сохранить тело в dead_letter не удалось - отвечает 500, чтобы клиент повторил запрос.
+func (s *Worker) handleDecodeError(c echo.Context, raw []byte, meta worker.Metadata, decodeErr error) error {
+	logrus.WithError(decodeErr).WithFields(logrus.Fields{
+		"name":         s.config.name,
+		"content_type": meta.ContentType,
+	}).Warn("http: error decode message")
+
+	n := newNotifier()
+
+	if status, err := s.send(c, worker.Message{Meta: meta, Raw: raw, DecodeErr: decodeErr, Notifier: n}); err != nil {
+		return c.JSON(status, Response{Error: err.Error()})
+	}
+
+	timer := time.NewTimer(time.Duration(s.readTimeout) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case res := <-n.done:
+		if res.Err != nil && !errors.Is(res.Err, worker.ErrInvalidMessage) {
+			return c.JSON(http.StatusInternalServerError, Response{Error: res.Err.Error()})
+		}
+	case <-timer.C:
+		// тело все равно невалидно: ответ клиенту не зависит от dead_letter
+	case <-c.Request().Context().Done():
+		return c.Request().Context().Err()
+	}
+
+	return c.JSON(http.StatusBadRequest, Response{Error: "invalid body: " + decodeErr.Error()})
+}
+
+// send передает сообщение в операцию. Возвращает http-статус и ошибку, если передать не удалось.
+func (s *Worker) send(c echo.Context, msg worker.Message) (int, error) {
+	timer := time.NewTimer(time.Duration(s.insertTimeout) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case s.msgChan <- msg:
+		return http.StatusOK, nil
+	case <-timer.C:
+		return http.StatusServiceUnavailable, errors.New("operation is busy")
+	case <-s.quitChan:
+		return http.StatusServiceUnavailable, errors.New("connection is stopped")
+	case <-c.Request().Context().Done():
+		return http.StatusServiceUnavailable, c.Request().Context().Err()
+	}
+}
+
+// resultStatus выбирает http-статус по итогу обработки сообщения:
+//   - транзакция успешна - 200;
+//   - сообщение не прошло валидацию или не подошло ни одной операции - 422;
+//   - остальные ошибки - 500.
+func resultStatus(res worker.Result) int {
+	if res.Err == nil {
+		return http.StatusOK
+	}
+
+	if errors.Is(res.Err, worker.ErrInvalidMessage) || errors.Is(res.Err, worker.ErrUnmatched) {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusInternalServerError
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// заголовки с учетными данными не сохраняются в метаданных транзакции
+var secretHeaders = map[string]struct{}{
+	echo.HeaderAuthorization: {},
+	echo.HeaderCookie:        {},
+	"Proxy-Authorization":    {},
+}
+
+// metadata собирает метаданные запроса: айди запроса из X-Request-Id, correlation id из X-Correlation-Id
+// и заголовки без учетных данных.
+func metadata(r *http.Request) worker.Metadata {
+	meta := worker.Metadata{
+		MessageID:     r.Header.Get(echo.HeaderXRequestID),
+		CorrelationID: r.Header.Get(correlationIDHeader),
+		ContentType:   r.Header.Get(echo.HeaderContentType),
+		Headers:       make(map[string]any, len(r.Header)),
+	}
+
+	for key, values := range r.Header {
+		if _, ok := secretHeaders[key]; ok {
+			continue
+		}
+
+		if len(values) == 1 {
+			meta.Headers[key] = values[0]
+			continue
+		}
+
+		list := make([]any, 0, len(values))
+		for _, v := range values {
+			list = append(list, v)
+		}
+
+		meta.Headers[key] = list
+	}
+
+	return meta
+}
@@ -0,0 +1,286 @@
+package http
+
+import (
+	"bytes"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+//nolint:funlen // это тест
+func TestHandle(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.New()}
+
+	tests := []struct {
+		name string
+		mode Mode
+		body string
+		// consume - имитация операции: читает сообщение и уведомляет обработчик. nil - никто не читает канал.
+		consume    func(msg worker.Message)
+		wantStatus int
+		want       Response
+	}{
+		{
+			name: "positive case: async",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(ids, nil)
+			},
+			wantStatus: http.StatusAccepted,
+			want:       Response{IDs: ids},
+		},
+		{
+			name: "positive case: sync",
+			mode: ModeSync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(ids, nil)
+				msg.Done(worker.Result{IDs: ids, TxID: "tx", Status: "success"})
+			},
+			wantStatus: http.StatusOK,
+			want:       Response{IDs: ids, TxID: "tx", Status: "success"},
+		},
+		{
+			name: "negative case: sync, validation error",
+			mode: ModeSync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(ids, nil)
+				msg.Done(worker.Result{IDs: ids, Err: fmt.Errorf("%w: validation error", worker.ErrInvalidMessage)})
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       Response{IDs: ids, Error: "invalid message: validation error"},
+		},
+		{
+			name: "negative case: sync, transaction failed",
+			mode: ModeSync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(ids, nil)
+				msg.Done(worker.Result{IDs: ids, TxID: "tx", Status: "failed", Err: errors.New("exec error")})
+			},
+			wantStatus: http.StatusInternalServerError,
+			want:       Response{IDs: ids, TxID: "tx", Status: "failed", Error: "exec error"},
+		},
+		{
+			name: "negative case: message not saved",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(nil, errors.New("save error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			want:       Response{Error: "save error"},
+		},
+		{
+			name: "negative case: no persisted notification",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(_ worker.Message) {
+			},
+			wantStatus: http.StatusGatewayTimeout,
+			want:       Response{Error: "timeout waiting for message to be saved"},
+		},
+		{
+			name: "positive case: async, duplicate",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Persisted(nil, nil)
+				msg.Done(worker.Result{TxID: "tx", Status: "success", Duplicate: true})
+			},
+			wantStatus: http.StatusAccepted,
+			want:       Response{},
+		},
+		{
+			name: "negative case: async, unmatched message",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Done(worker.Result{Err: fmt.Errorf("connection test: %w", worker.ErrUnmatched)})
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       Response{Error: "connection test: message does not match any operation"},
+		},
+		{
+			name: "negative case: async, unmatched message sent to dead letter",
+			mode: ModeAsync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Done(worker.Result{Err: fmt.Errorf("%w: connection test: %w", worker.ErrInvalidMessage, worker.ErrUnmatched)})
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       Response{Error: "invalid message: connection test: message does not match any operation"},
+		},
+		{
+			name: "negative case: sync, unmatched message, dead letter failed",
+			mode: ModeSync,
+			body: `{"user_id": 1}`,
+			consume: func(msg worker.Message) {
+				msg.Done(worker.Result{Err: errors.New("error publish dead letter")})
+			},
+			wantStatus: http.StatusInternalServerError,
+			want:       Response{Error: "error publish dead letter"},
+		},
+		{
+			name:       "negative case: operation is busy",
+			mode:       ModeAsync,
+			body:       `{"user_id": 1}`,
+			wantStatus: http.StatusServiceUnavailable,
+			want:       Response{Error: "operation is busy"},
+		},
+		{
+			name: "negative case: invalid json",
+			mode: ModeAsync,
+			body: `{"user_id":`,
+			consume: func(msg worker.Message) {
+				msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, msg.DecodeErr)})
+			},
+			wantStatus: http.StatusBadRequest,
+			want:       Response{Error: "invalid body: codec: error decode json: unexpected EOF"},
+		},
+		{
+			name: "negative case: invalid json, dead letter failed",
+			mode: ModeAsync,
+			body: `{"user_id":`,
+			consume: func(msg worker.Message) {
+				msg.Done(worker.Result{Err: errors.New("error publish dead letter")})
+			},
+			wantStatus: http.StatusInternalServerError,
+			want:       Response{Error: "error publish dead letter"},
+		},
+		{
+			name:       "negative case: empty message",
+			mode:       ModeAsync,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			want:       Response{Error: "empty message"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := New(
+				WithName("test"),
+				WithPath("notes"),
+				WithMode(tt.mode),
+				WithInsertTimeout(50),
+				WithReadTimeout(50),
+			)
+			require.NoError(t, err)
+
+			if tt.consume != nil {
+				go func() {
+					tt.consume(<-w.MsgChan())
+				}()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v0/notes", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			require.NoError(t, w.Handle(echo.New().NewContext(req, rec)))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var got Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandle_Stopped(t *testing.T) {
+	t.Parallel()
+
+	w, err := New(WithName("test"), WithPath("notes"), WithInsertTimeout(1000), WithReadTimeout(1000))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Stop(t.Context()))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/notes", strings.NewReader(`{"user_id": 1}`))
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, w.Handle(echo.New().NewContext(req, rec)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestHandle_BodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	w, err := New(WithName("test"), WithPath("notes"), WithMaxBodySize(8), WithInsertTimeout(1000), WithReadTimeout(1000))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/notes", strings.NewReader(`{"user_id": 1}`))
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, w.Handle(echo.New().NewContext(req, rec)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.JSONEq(t, `{"error": "body is larger than 8 bytes"}`, rec.Body.String())
+	assert.Empty(t, w.MsgChan())
+}
+
+func TestHandle_ContentType(t *testing.T) {
+	t.Parallel()
+
+	w, err := New(WithName("test"), WithPath("notes"), WithInsertTimeout(1000), WithReadTimeout(1000))
+	require.NoError(t, err)
+
+	body, err := msgpack.Marshal(map[string]any{"user_id": 1})
+	require.NoError(t, err)
+
+	received := make(chan worker.Message, 1)
+
+	go func() {
+		msg := <-w.MsgChan()
+		msg.Persisted(nil, nil)
+		received <- msg
+	}()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/notes", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, "application/msgpack")
+
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, w.Handle(echo.New().NewContext(req, rec)))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	msg := <-received
+	assert.Equal(t, map[string]any{"user_id": float64(1)}, msg.Data)
+	assert.Equal(t, "application/msgpack", msg.Meta.ContentType)
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v0/notes", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	req.Header.Set("X-Correlation-Id", "corr-1")
+	req.Header.Set("X-User-Id", "42")
+	req.Header.Add("X-Tag", "a")
+	req.Header.Add("X-Tag", "b")
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+
+	meta := metadata(req)
+
+	assert.Equal(t, "req-1", meta.MessageID)
+	assert.Equal(t, "corr-1", meta.CorrelationID)
+	assert.Equal(t, "42", meta.Headers["X-User-Id"])
+	assert.Equal(t, []any{"a", "b"}, meta.Headers["X-Tag"])
+	assert.NotContains(t, meta.Headers, echo.HeaderAuthorization)
+}
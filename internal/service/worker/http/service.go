@@ -0,0 +1,229 @@
+package http
+
+import (
+	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Mode - режим ответа на запрос.
+type Mode string
+
+const (
+	// ModeAsync - ответ 202 с айди сообщений сразу после их сохранения.
+	ModeAsync Mode = "async"
+	// ModeSync - ответ после завершения транзакции: айди транзакции и её статус.
+	ModeSync Mode = "sync"
+)
+
+const correlationIDHeader = "X-Correlation-Id"
+
+// defaultMaxBodySize - максимальный размер тела запроса по умолчанию (1 МБ).
+const defaultMaxBodySize = 1 << 20
+
+// Worker принимает сообщения через POST-запросы к серверу приложения.
+// Маршрут регистрирует сервер (см. server.WithHTTPWorkers), воркер только обрабатывает запросы.
+type Worker struct {
+	config struct {
+		name string
+		path string
+		mode Mode
+
+		maxBodySize int64 // максимальный размер тела запроса в байтах
+	}
+
+	msgChan  chan worker.Message
+	quitChan chan struct{}
+
+	codec *codec.Registry // декодирует тела запросов
+
+	insertTimeout int // сколько ждать, пока операция заберет сообщение (мс)
+	readTimeout   int // сколько ждать ответа операции (мс)
+}
+
+// Option определяет опции для Worker.
+type Option func(*Worker)
+
+// WithName устанавливает имя соединения.
+func WithName(name string) Option {
+	return func(w *Worker) {
+		w.config.name = name
+	}
+}
+
+// WithPath устанавливает путь, на который принимаются запросы.
+func WithPath(path string) Option {
+	return func(w *Worker) {
+		w.config.path = path
+	}
+}
+
+// WithMode устанавливает режим ответа.
+func WithMode(mode Mode) Option {
+	return func(w *Worker) {
+		w.config.mode = mode
+	}
+}
+
+// WithMaxBodySize устанавливает максимальный размер тела запроса в байтах. По умолчанию 1 МБ.
+func WithMaxBodySize(size int64) Option {
+	return func(w *Worker) {
+		w.config.maxBodySize = size
+	}
+}
+
+// WithCodec устанавливает форматы тела запросов. По умолчанию JSON.
+func WithCodec(registry *codec.Registry) Option {
+	return func(w *Worker) {
+		w.codec = registry
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания передачи сообщения в операцию.
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(w *Worker) {
+		w.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает время ожидания ответа операции.
+func WithReadTimeout(readTimeout int) Option {
+	return func(w *Worker) {
+		w.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Worker. Если режим не задан - используется ModeAsync.
+func New(opts ...Option) (*Worker, error) {
+	w := &Worker{}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.insertTimeout == 0 {
+		return nil, fmt.Errorf("http: insert timeout is required")
+	}
+
+	if w.readTimeout == 0 {
+		return nil, fmt.Errorf("http: read timeout is required")
+	}
+
+	if w.config.name == "" {
+		return nil, fmt.Errorf("http: name is required")
+	}
+
+	w.config.path = strings.Trim(w.config.path, "/")
+	if w.config.path == "" {
+		return nil, fmt.Errorf("http: path is required")
+	}
+
+	if w.config.mode == "" {
+		w.config.mode = ModeAsync
+	}
+
+	if w.config.mode != ModeAsync && w.config.mode != ModeSync {
+		return nil, fmt.Errorf("http: unknown mode: %s", w.config.mode)
+	}
+
+	if w.config.maxBodySize < 0 {
+		return nil, fmt.Errorf("http: max body size must not be negative")
+	}
+
+	if w.config.maxBodySize == 0 {
+		w.config.maxBodySize = defaultMaxBodySize
+	}
+
+	if w.codec == nil {
+		w.codec = codec.JSON()
+	}
+
+	w.msgChan = make(chan worker.Message)
+	w.quitChan = make(chan struct{})
+
+	return w, nil
+}
+
+// Connect для соответствия интерфейсу Worker. Соединение не требуется: запросы принимает сервер приложения.
+func (s *Worker) Connect() error {
+	return nil
+}
+
+// Run ожидает завершения работы. Сообщения поступают через Handle.
+func (s *Worker) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name": s.config.name,
+		"path": s.config.path,
+		"mode": s.config.mode,
+	}).Info("http: start accepting messages")
+
+	select {
+	case <-ctx.Done():
+		logrus.WithField("name", s.config.name).Info("http: ctx done: stop accepting messages")
+	case <-s.quitChan:
+		logrus.WithField("name", s.config.name).Info("http: quit chan: stop accepting messages")
+	}
+
+	return nil
+}
+
+// Stop прекращает прием сообщений.
+func (s *Worker) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	return nil
+}
+
+// Connected возвращает true, пока соединение принимает сообщения.
+func (s *Worker) Connected() bool {
+	select {
+	case <-s.quitChan:
+		return false
+	default:
+		return true
+	}
+}
+
+// Name возвращает имя соединения.
+func (s *Worker) Name() string {
+	return s.config.name
+}
+
+// Path возвращает путь, на который принимаются запросы.
+func (s *Worker) Path() string {
+	return s.config.path
+}
+
+// MsgChan возвращает канал для получения сообщений.
+func (s *Worker) MsgChan() chan worker.Message {
+	return s.msgChan
+}
+
+// Address для соответствия интерфейсу Worker. Возвращает путь, на который принимаются запросы.
+func (s *Worker) Address() string {
+	return s.config.path
+}
+
+// Queue для соответствия интерфейсу Worker. Не используется, возвращает пустую строку.
+func (s *Worker) Queue() string {
+	return ""
+}
+
+// RoutingKey для соответствия интерфейсу Worker. Не используется, возвращает пустую строку.
+func (s *Worker) RoutingKey() string {
+	return ""
+}
+
+// InsertTimeout для соответствия интерфейсу Worker.
+func (s *Worker) InsertTimeout() int {
+	return s.insertTimeout
+}
+
+// ReadTimeout для соответствия интерфейсу Worker.
+func (s *Worker) ReadTimeout() int {
+	return s.readTimeout
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // это тест
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantPath string
+		wantMode Mode
+		wantSize int64
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			opts: []Option{
+				WithName("test"),
+				WithPath("/notes/create/"),
+				WithMode(ModeSync),
+				WithMaxBodySize(1024),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantPath: "notes/create",
+			wantMode: ModeSync,
+			wantSize: 1024,
+			wantErr:  require.NoError,
+		},
+		{
+			name: "positive case: default mode",
+			opts: []Option{
+				WithName("test"),
+				WithPath("notes/create"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantPath: "notes/create",
+			wantMode: ModeAsync,
+			wantSize: defaultMaxBodySize,
+			wantErr:  require.NoError,
+		},
+		{
+			name: "negative case: insert timeout is 0",
+			opts: []Option{
+				WithName("test"),
+				WithPath("notes/create"),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: read timeout is 0",
+			opts: []Option{
+				WithName("test"),
+				WithPath("notes/create"),
+				WithInsertTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: name is empty",
+			opts: []Option{
+				WithPath("notes/create"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: path is empty",
+			opts: []Option{
+				WithName("test"),
+				WithPath("/"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: unknown mode",
+			opts: []Option{
+				WithName("test"),
+				WithPath("notes/create"),
+				WithMode("unknown"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: negative max body size",
+			opts: []Option{
+				WithName("test"),
+				WithPath("notes/create"),
+				WithMaxBodySize(-1),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "test", w.Name())
+			assert.Equal(t, tt.wantPath, w.Path())
+			assert.Equal(t, tt.wantPath, w.Address())
+			assert.Equal(t, tt.wantMode, w.config.mode)
+			assert.Equal(t, tt.wantSize, w.config.maxBodySize)
+			assert.NotNil(t, w.MsgChan())
+			assert.Equal(t, 1, w.InsertTimeout())
+			assert.Equal(t, 1, w.ReadTimeout())
+		})
+	}
+}
+
+func TestRunStop(t *testing.T) {
+	t.Parallel()
+
+	w, err := New(WithName("test"), WithPath("notes"), WithInsertTimeout(1), WithReadTimeout(1))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Connect())
+
+	done := make(chan error)
+
+	go func() {
+		done <- w.Run(context.Background())
+	}()
+
+	assert.True(t, w.Connected())
+
+	require.NoError(t, w.Stop(context.Background()))
+
+	assert.False(t, w.Connected())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run did not stop")
+	}
+}
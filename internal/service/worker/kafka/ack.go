@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// commitNotifier отмечает запись для коммита offset после успешного завершения транзакции.
+// Если обработка не удалась, партиция перематывается на эту запись и читается повторно.
+type commitNotifier struct {
+	worker *Worker
+	token  recordToken
+
+	once sync.Once
+}
+
+// Persisted реализует worker.Notifier. Если сообщение не удалось сохранить - запись читается повторно.
+func (n *commitNotifier) Persisted(_ []uuid.UUID, err error) {
+	if err != nil {
+		n.retry(err)
+	}
+}
+
+// Done реализует worker.Notifier.
+// Сообщения, не прошедшие валидацию, тоже коммитятся: повторная обработка не поможет.
+func (n *commitNotifier) Done(res worker.Result) {
+	switch {
+	case res.Err == nil:
+		n.once.Do(func() { n.worker.markDone(n.token) })
+	case errors.Is(res.Err, worker.ErrInvalidMessage):
+		n.once.Do(func() {
+			logrus.WithError(res.Err).WithFields(logrus.Fields{
+				"name":      n.worker.config.name,
+				"partition": n.token.record.Partition,
+				"offset":    n.token.record.Offset,
+			}).Warn("kafka: skip invalid message")
+
+			n.worker.markDone(n.token)
+		})
+	default:
+		n.retry(res.Err)
+	}
+}
+
+func (n *commitNotifier) retry(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithFields(logrus.Fields{
+			"name":      n.worker.config.name,
+			"partition": n.token.record.Partition,
+			"offset":    n.token.record.Offset,
+		}).Warn("kafka: message will be read again")
+
+		n.worker.rewind(n.token)
+	})
+}
@@ -0,0 +1,279 @@
+package kafka
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Run читает записи топика и по одной передает их в операцию.
+// Записи партиции передаются строго по порядку offset, поэтому порядок сообщений с одним ключом сохраняется.
+//
+//nolint:funlen // цельная логика функции, много строк из-за логов
+func (s *Worker) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name":  s.config.name,
+		"topic": s.config.topic,
+		"group": s.config.group,
+	}).Info("kafka: start consume messages")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.WithField("name", s.config.name).Info("kafka: ctx done: stop consume messages")
+			return nil
+		case <-s.quitChan:
+			logrus.WithField("name", s.config.name).Info("kafka: quit chan: stop consume messages")
+			return nil
+		default:
+		}
+
+		s.applyRewinds()
+
+		epochs := s.offsets.epochs()
+
+		fetches := s.poll(ctx)
+		if fetches.IsClientClosed() {
+			logrus.WithField("name", s.config.name).Info("kafka: client closed: stop consume messages")
+			return nil
+		}
+
+		if !s.checkErrors(fetches) {
+			s.wait(ctx, s.config.retryInterval)
+			continue
+		}
+
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+			epoch := epochs[topicPartition{topic: p.Topic, partition: p.Partition}]
+
+			for _, record := range p.Records {
+				if !s.handle(ctx, record, epoch) {
+					return
+				}
+			}
+		})
+	}
+}
+
+// poll ждет новые записи. Ожидание прерывается, если операция попросила перемотать партицию.
+func (s *Worker) poll(ctx context.Context) kgo.Fetches {
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.cancelPoll = cancel
+	pending := len(s.rewinds)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.cancelPoll = nil
+		s.mu.Unlock()
+	}()
+
+	if pending > 0 { // перемотка запрошена после applyRewinds
+		return kgo.Fetches{}
+	}
+
+	go func() {
+		select {
+		case <-s.quitChan:
+			cancel()
+		case <-pollCtx.Done():
+		}
+	}()
+
+	return s.client.PollFetches(pollCtx)
+}
+
+// checkErrors логирует ошибки чтения и обновляет состояние соединения. Возвращает false, если чтение не удалось.
+func (s *Worker) checkErrors(fetches kgo.Fetches) bool {
+	ok := true
+
+	for _, fetchErr := range fetches.Errors() {
+		if errors.Is(fetchErr.Err, context.Canceled) {
+			continue
+		}
+
+		ok = false
+
+		logrus.WithError(fetchErr.Err).WithFields(logrus.Fields{
+			"name":      s.config.name,
+			"topic":     fetchErr.Topic,
+			"partition": fetchErr.Partition,
+		}).Warn("kafka: error fetch records")
+	}
+
+	switch {
+	case !ok && s.Connected():
+		s.setConnected(false)
+	case ok && !s.Connected():
+		if s.metrics != nil {
+			s.metrics.AddConnectionReconnects(s.config.name)
+		}
+
+		logrus.WithField("name", s.config.name).Info("kafka: reconnected")
+
+		s.setConnected(true)
+	}
+
+	return ok
+}
+
+// handle передает запись в операцию. Возвращает false, если остальные записи партиции из этого чтения
+// передавать не нужно: партиция перемотана или работа завершена.
+func (s *Worker) handle(ctx context.Context, record *kgo.Record, epoch int) bool {
+	token, ok := s.offsets.track(record, epoch)
+	if !ok {
+		return false
+	}
+
+	meta := metadata(record)
+
+	msg := worker.Message{
+		Meta:     meta,
+		Notifier: &commitNotifier{worker: s, token: token},
+	}
+
+	data, err := s.codec.Decode(meta.ContentType, record.Value)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":         s.config.name,
+			"partition":    record.Partition,
+			"offset":       record.Offset,
+			"content_type": meta.ContentType,
+		}).Error("kafka: error decode message")
+
+		// запись не удастся обработать и при повторном чтении: ее завершит диспетчер соединения
+		msg.Raw = record.Value
+		msg.DecodeErr = err
+	}
+
+	msg.Data = data
+
+	logrus.WithFields(logrus.Fields{
+		"name":      s.config.name,
+		"partition": record.Partition,
+		"offset":    record.Offset,
+		"key":       string(record.Key),
+		"message":   data,
+	}).Debug("kafka: received message")
+
+	select {
+	case s.msgChan <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	}
+}
+
+// metadata собирает метаданные записи: ключ, заголовки и время записи.
+// Айди сообщения - позиция записи в топике, correlation id берется из заголовка correlation_id,
+// формат тела - из content-type.
+func metadata(record *kgo.Record) worker.Metadata {
+	meta := worker.Metadata{
+		MessageID:  fmt.Sprintf("%s/%d/%d", record.Topic, record.Partition, record.Offset),
+		RoutingKey: string(record.Key),
+		Timestamp:  record.Timestamp,
+	}
+
+	if len(record.Headers) > 0 {
+		meta.Headers = make(map[string]any, len(record.Headers))
+
+		for _, h := range record.Headers {
+			meta.Headers[h.Key] = string(h.Value)
+
+			if strings.EqualFold(h.Key, correlationIDHeader) {
+				meta.CorrelationID = string(h.Value)
+			}
+
+			if strings.EqualFold(h.Key, contentTypeHeader) {
+				meta.ContentType = string(h.Value)
+			}
+		}
+	}
+
+	return meta
+}
+
+// markDone отмечает запись обработанной и помечает для коммита все записи партиции, обработанные без пропусков.
+func (s *Worker) markDone(token recordToken) {
+	if last := s.offsets.done(token); last != nil {
+		s.client.MarkCommitRecords(last)
+	}
+}
+
+// rewind перематывает партицию на необработанную запись: она и все следующие записи будут прочитаны повторно.
+func (s *Worker) rewind(token recordToken) {
+	if !s.offsets.fail(token) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rewinds[token.tp] = token.record
+
+	if s.cancelPoll != nil {
+		s.cancelPoll()
+	}
+}
+
+// applyRewinds перематывает партиции между чтениями и приостанавливает их чтение на retryInterval.
+func (s *Worker) applyRewinds() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rewinds) == 0 {
+		return
+	}
+
+	offsets := make(map[string]map[int32]kgo.EpochOffset, len(s.rewinds))
+	paused := make(map[string][]int32, len(s.rewinds))
+
+	for tp, record := range s.rewinds {
+		if offsets[tp.topic] == nil {
+			offsets[tp.topic] = make(map[int32]kgo.EpochOffset)
+		}
+
+		offsets[tp.topic][tp.partition] = kgo.EpochOffset{Epoch: record.LeaderEpoch, Offset: record.Offset}
+		paused[tp.topic] = append(paused[tp.topic], tp.partition)
+
+		logrus.WithFields(logrus.Fields{
+			"name":      s.config.name,
+			"topic":     tp.topic,
+			"partition": tp.partition,
+			"offset":    record.Offset,
+			"delay":     s.config.retryInterval,
+		}).Warn("kafka: rewind partition")
+	}
+
+	s.rewinds = make(map[topicPartition]*kgo.Record)
+
+	s.client.PauseFetchPartitions(paused)
+	s.client.SetOffsets(offsets)
+
+	time.AfterFunc(s.config.retryInterval, func() {
+		s.client.ResumeFetchPartitions(paused)
+	})
+}
+
+// wait ждет delay или завершения работы.
+func (s *Worker) wait(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-s.quitChan:
+	case <-timer.C:
+	}
+}
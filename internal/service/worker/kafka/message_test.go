@@ -0,0 +1,225 @@
+package kafka
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	testTopic = "notes"
+	testGroup = "db-worker"
+)
+
+func newTestCluster(t *testing.T, partitions int32) []string {
+	t.Helper()
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(partitions, testTopic))
+	require.NoError(t, err)
+
+	t.Cleanup(cluster.Close)
+
+	return cluster.ListenAddrs()
+}
+
+func produce(t *testing.T, brokers []string, records ...*kgo.Record) {
+	t.Helper()
+
+	client, err := kgo.NewClient(kgo.SeedBrokers(brokers...), kgo.DefaultProduceTopic(testTopic))
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	require.NoError(t, client.ProduceSync(t.Context(), records...).FirstErr())
+}
+
+func newTestWorker(t *testing.T, brokers []string) *Worker {
+	t.Helper()
+
+	w, err := New(
+		WithName("test"),
+		WithBrokers(brokers...),
+		WithTopic(testTopic),
+		WithGroup(testGroup),
+		WithCommitInterval(100*time.Millisecond),
+		WithRetryInterval(time.Millisecond),
+		WithInsertTimeout(1000),
+		WithReadTimeout(100),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Connect())
+
+	return w
+}
+
+// runWorker запускает чтение и возвращает функцию остановки воркера.
+func runWorker(t *testing.T, w *Worker) func() {
+	t.Helper()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, w.Run(t.Context()))
+	}()
+
+	var stopped bool
+
+	stop := func() {
+		if stopped {
+			return
+		}
+
+		stopped = true
+
+		require.NoError(t, w.Stop(context.Background()))
+		<-done
+	}
+
+	t.Cleanup(stop)
+
+	return stop
+}
+
+func receive(t *testing.T, w *Worker) worker.Message {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		return msg
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "message not received")
+	}
+
+	return worker.Message{}
+}
+
+func noteRecord(id int) *kgo.Record {
+	return &kgo.Record{Key: []byte("user-1"), Value: fmt.Appendf(nil, `{"id": %d}`, id)}
+}
+
+func TestRun_Order(t *testing.T) {
+	t.Parallel()
+
+	brokers := newTestCluster(t, 3)
+	produce(t, brokers, noteRecord(1), noteRecord(2), noteRecord(3))
+
+	w := newTestWorker(t, brokers)
+	runWorker(t, w)
+
+	// записи с одним ключом попадают в одну партицию и передаются по порядку
+	for id := 1; id <= 3; id++ {
+		msg := receive(t, w)
+		assert.Equal(t, map[string]any{"id": json.Number(strconv.Itoa(id))}, msg.Data)
+
+		msg.Done(worker.Result{})
+	}
+}
+
+func TestRun_CommitAfterSuccess(t *testing.T) {
+	t.Parallel()
+
+	brokers := newTestCluster(t, 1)
+	produce(t, brokers, noteRecord(1), noteRecord(2), &kgo.Record{Value: []byte("not json")}, noteRecord(3))
+
+	first := newTestWorker(t, brokers)
+	stop := runWorker(t, first)
+
+	msg := receive(t, first)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	// вторая запись не обработана: её offset не коммитится
+	msg = receive(t, first)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+
+	stop()
+
+	// новый потребитель группы начинает с необработанной записи
+	second := newTestWorker(t, brokers)
+	runWorker(t, second)
+
+	msg = receive(t, second)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: error validate message", worker.ErrInvalidMessage)})
+
+	// битая запись передается с исходным телом и ошибкой декодирования, диспетчер завершает ее как невалидную
+	msg = receive(t, second)
+	require.Error(t, msg.DecodeErr)
+	assert.Equal(t, []byte("not json"), msg.Raw)
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, msg.DecodeErr)})
+
+	msg = receive(t, second)
+	assert.Equal(t, map[string]any{"id": json.Number("3")}, msg.Data)
+	msg.Done(worker.Result{})
+}
+
+func TestRun_RewindOnError(t *testing.T) {
+	t.Parallel()
+
+	brokers := newTestCluster(t, 1)
+	produce(t, brokers, noteRecord(1), noteRecord(2))
+
+	w := newTestWorker(t, brokers)
+	runWorker(t, w)
+
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+	msg.Done(worker.Result{Err: errors.New("error exec requests")})
+
+	// запись, прочитанная до ошибки, может прийти еще раз до перемотки - уведомления по ней игнорируются
+	for {
+		msg = receive(t, w)
+		if msg.Data["id"] == json.Number("1") {
+			break
+		}
+
+		msg.Done(worker.Result{})
+	}
+
+	msg.Done(worker.Result{})
+
+	msg = receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+	msg.Done(worker.Result{})
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	meta := metadata(&kgo.Record{
+		Topic:     testTopic,
+		Partition: 1,
+		Offset:    10,
+		Key:       []byte("notes.create"),
+		Timestamp: ts,
+		Headers: []kgo.RecordHeader{
+			{Key: "correlation_id", Value: []byte("corr-1")},
+			{Key: "user_id", Value: []byte("42")},
+			{Key: "Content-Type", Value: []byte("application/msgpack")},
+		},
+	})
+
+	assert.Equal(t, worker.Metadata{
+		MessageID:     "notes/1/10",
+		CorrelationID: "corr-1",
+		RoutingKey:    "notes.create",
+		ContentType:   "application/msgpack",
+		Timestamp:     ts,
+		Headers:       map[string]any{"correlation_id": "corr-1", "user_id": "42", "Content-Type": "application/msgpack"},
+	}, meta)
+}
@@ -0,0 +1,148 @@
+package kafka
+
+import (
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// topicPartition - партиция топика.
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// offsetTracker следит за записями, переданными в операцию, и определяет, до какой записи можно коммитить offset:
+// коммитится только непрерывный префикс обработанных записей партиции, поэтому необработанная запись
+// никогда не окажется за закоммиченным offset.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+// partitionOffsets - записи партиции, которые еще не закоммичены, в порядке offset.
+type partitionOffsets struct {
+	epoch   int // увеличивается при перемотке или потере партиции: уведомления по старым записям игнорируются
+	records []*trackedRecord
+}
+
+type trackedRecord struct {
+	record *kgo.Record
+	done   bool
+}
+
+// recordToken связывает уведомление операции с записью.
+type recordToken struct {
+	tp     topicPartition
+	epoch  int
+	record *kgo.Record
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[topicPartition]*partitionOffsets),
+	}
+}
+
+func recordPartition(record *kgo.Record) topicPartition {
+	return topicPartition{topic: record.Topic, partition: record.Partition}
+}
+
+// epochs возвращает текущие эпохи партиций. Снимок берется до чтения записей:
+// записи, прочитанные до перемотки партиции, не будут приняты в track.
+func (t *offsetTracker) epochs() map[topicPartition]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	epochs := make(map[topicPartition]int, len(t.partitions))
+	for tp, p := range t.partitions {
+		epochs[tp] = p.epoch
+	}
+
+	return epochs
+}
+
+// track начинает отслеживать запись. Возвращает false, если с момента чтения записи партиция была перемотана или потеряна.
+func (t *offsetTracker) track(record *kgo.Record, epoch int) (recordToken, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tp := recordPartition(record)
+
+	p, ok := t.partitions[tp]
+	if !ok {
+		p = &partitionOffsets{}
+		t.partitions[tp] = p
+	}
+
+	if p.epoch != epoch {
+		return recordToken{}, false
+	}
+
+	p.records = append(p.records, &trackedRecord{record: record})
+
+	return recordToken{tp: tp, epoch: epoch, record: record}, true
+}
+
+// done отмечает запись обработанной. Возвращает последнюю запись непрерывного обработанного префикса партиции,
+// до которой можно коммитить offset, или nil, если коммитить пока нечего.
+func (t *offsetTracker) done(token recordToken) *kgo.Record {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[token.tp]
+	if !ok || p.epoch != token.epoch {
+		return nil
+	}
+
+	for _, r := range p.records {
+		if r.record == token.record {
+			r.done = true
+			break
+		}
+	}
+
+	var last *kgo.Record
+
+	for len(p.records) > 0 && p.records[0].done {
+		last = p.records[0].record
+		p.records = p.records[1:]
+	}
+
+	return last
+}
+
+// fail отмечает, что запись не обработана: партицию нужно перемотать на эту запись.
+// Все отслеживаемые записи партиции сбрасываются. Возвращает false, если уведомление устарело.
+func (t *offsetTracker) fail(token recordToken) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[token.tp]
+	if !ok || p.epoch != token.epoch {
+		return false
+	}
+
+	p.epoch++
+	p.records = nil
+
+	return true
+}
+
+// drop перестает отслеживать партиции, которые больше не назначены этому потребителю.
+func (t *offsetTracker) drop(topics map[string][]int32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for topic, partitions := range topics {
+		for _, partition := range partitions {
+			p, ok := t.partitions[topicPartition{topic: topic, partition: partition}]
+			if !ok {
+				continue
+			}
+
+			p.epoch++
+			p.records = nil
+		}
+	}
+}
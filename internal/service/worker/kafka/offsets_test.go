@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func testRecords(partition int32, offsets ...int64) []*kgo.Record {
+	records := make([]*kgo.Record, len(offsets))
+	for i, offset := range offsets {
+		records[i] = &kgo.Record{Topic: "notes", Partition: partition, Offset: offset}
+	}
+
+	return records
+}
+
+func TestOffsetTracker_Done(t *testing.T) {
+	t.Parallel()
+
+	tracker := newOffsetTracker()
+	records := testRecords(0, 10, 11, 12)
+
+	tokens := make([]recordToken, len(records))
+	for i, record := range records {
+		var ok bool
+
+		tokens[i], ok = tracker.track(record, 0)
+		require.True(t, ok)
+	}
+
+	// запись 10 еще не обработана: коммитить нечего
+	assert.Nil(t, tracker.done(tokens[1]))
+	assert.Nil(t, tracker.done(tokens[2]))
+
+	// после записи 10 можно коммитить всю партицию
+	assert.Equal(t, records[2], tracker.done(tokens[0]))
+
+	// повторное уведомление ничего не меняет
+	assert.Nil(t, tracker.done(tokens[0]))
+}
+
+func TestOffsetTracker_Partitions(t *testing.T) {
+	t.Parallel()
+
+	tracker := newOffsetTracker()
+	first := testRecords(0, 1)[0]
+	second := testRecords(1, 5)[0]
+
+	firstToken, ok := tracker.track(first, 0)
+	require.True(t, ok)
+
+	secondToken, ok := tracker.track(second, 0)
+	require.True(t, ok)
+
+	// партиции коммитятся независимо
+	assert.Equal(t, second, tracker.done(secondToken))
+	assert.Equal(t, first, tracker.done(firstToken))
+}
+
+func TestOffsetTracker_Fail(t *testing.T) {
+	t.Parallel()
+
+	tracker := newOffsetTracker()
+	records := testRecords(0, 1, 2)
+
+	epochs := tracker.epochs()
+
+	failed, ok := tracker.track(records[0], epochs[recordPartition(records[0])])
+	require.True(t, ok)
+
+	next, ok := tracker.track(records[1], epochs[recordPartition(records[1])])
+	require.True(t, ok)
+
+	require.True(t, tracker.fail(failed))
+
+	// повторное уведомление устарело
+	assert.False(t, tracker.fail(failed))
+
+	// уведомления по записям, прочитанным до перемотки, игнорируются
+	assert.Nil(t, tracker.done(next))
+
+	// записи, прочитанные до перемотки, не принимаются
+	_, ok = tracker.track(records[1], epochs[recordPartition(records[1])])
+	assert.False(t, ok)
+
+	// после перемотки запись читается заново
+	retried, ok := tracker.track(records[0], tracker.epochs()[recordPartition(records[0])])
+	require.True(t, ok)
+	assert.Equal(t, records[0], tracker.done(retried))
+}
+
+func TestOffsetTracker_Drop(t *testing.T) {
+	t.Parallel()
+
+	tracker := newOffsetTracker()
+	record := testRecords(3, 7)[0]
+
+	token, ok := tracker.track(record, 0)
+	require.True(t, ok)
+
+	tracker.drop(map[string][]int32{"notes": {3}, "other": {1}})
+
+	assert.Nil(t, tracker.done(token))
+	assert.False(t, tracker.fail(token))
+}
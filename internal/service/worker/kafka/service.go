@@ -0,0 +1,331 @@
+package kafka
+
+import (
+	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Worker читает сообщения из топика Kafka через группу потребителей.
+// Записи партиции передаются в операцию по порядку, offset коммитится только после успешной обработки записи
+// и всех предыдущих записей партиции.
+type Worker struct {
+	config struct {
+		name    string
+		brokers []string
+		topic   string
+		group   string
+
+		commitInterval time.Duration // как часто коммитить обработанные offset
+		retryInterval  time.Duration // пауза перед повторным чтением партиции после ошибки обработки
+	}
+
+	client *kgo.Client
+	codec  *codec.Registry // декодирует тела записей
+
+	msgChan  chan worker.Message
+	quitChan chan struct{}
+
+	offsets *offsetTracker
+
+	mu         sync.Mutex
+	rewinds    map[topicPartition]*kgo.Record // партиции, которые нужно перемотать на запись
+	cancelPoll context.CancelFunc             // прерывает ожидание новых записей, чтобы перемотать партиции
+
+	connected atomic.Bool
+	metrics   connectionMetrics
+
+	insertTimeout int
+	readTimeout   int
+}
+
+const (
+	defaultCommitInterval = time.Second
+	defaultRetryInterval  = time.Second
+
+	correlationIDHeader = "correlation_id"
+	contentTypeHeader   = "content-type"
+)
+
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type connectionMetrics interface {
+	SetConnectionStatus(name string, connected bool)
+	AddConnectionReconnects(name string)
+}
+
+// Option определяет опции для Worker.
+type Option func(*Worker)
+
+// WithName устанавливает имя соединения.
+func WithName(name string) Option {
+	return func(w *Worker) {
+		w.config.name = name
+	}
+}
+
+// WithBrokers устанавливает адреса брокеров.
+func WithBrokers(brokers ...string) Option {
+	return func(w *Worker) {
+		w.config.brokers = append(w.config.brokers, brokers...)
+	}
+}
+
+// WithTopic устанавливает топик.
+func WithTopic(topic string) Option {
+	return func(w *Worker) {
+		w.config.topic = topic
+	}
+}
+
+// WithGroup устанавливает группу потребителей.
+func WithGroup(group string) Option {
+	return func(w *Worker) {
+		w.config.group = group
+	}
+}
+
+// WithCommitInterval устанавливает, как часто коммитить обработанные offset.
+func WithCommitInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.commitInterval = interval
+	}
+}
+
+// WithRetryInterval устанавливает паузу перед повторным чтением партиции после ошибки обработки.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.retryInterval = interval
+	}
+}
+
+// WithCodec устанавливает форматы тела записей. По умолчанию JSON.
+func WithCodec(registry *codec.Registry) Option {
+	return func(w *Worker) {
+		w.codec = registry
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для отчета о состоянии соединения.
+func WithMetrics(metrics connectionMetrics) Option {
+	return func(w *Worker) {
+		w.metrics = metrics
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания коммита offset (мс).
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(w *Worker) {
+		w.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает, сколько брокер ждет новых записей за один запрос (мс).
+func WithReadTimeout(readTimeout int) Option {
+	return func(w *Worker) {
+		w.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Worker.
+func New(opts ...Option) (*Worker, error) {
+	w := &Worker{}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.config.name == "" {
+		return nil, fmt.Errorf("kafka: name is required")
+	}
+
+	if len(w.config.brokers) == 0 {
+		return nil, fmt.Errorf("kafka: brokers are required")
+	}
+
+	if w.config.topic == "" {
+		return nil, fmt.Errorf("kafka: topic is required")
+	}
+
+	if w.config.group == "" {
+		return nil, fmt.Errorf("kafka: group is required")
+	}
+
+	if w.insertTimeout == 0 {
+		return nil, fmt.Errorf("kafka: insert timeout is required")
+	}
+
+	if w.readTimeout == 0 {
+		return nil, fmt.Errorf("kafka: read timeout is required")
+	}
+
+	if w.config.commitInterval == 0 {
+		w.config.commitInterval = defaultCommitInterval
+	}
+
+	if w.config.retryInterval == 0 {
+		w.config.retryInterval = defaultRetryInterval
+	}
+
+	if w.codec == nil {
+		w.codec = codec.JSON()
+	}
+
+	w.msgChan = make(chan worker.Message)
+	w.quitChan = make(chan struct{})
+	w.offsets = newOffsetTracker()
+	w.rewinds = make(map[topicPartition]*kgo.Record)
+
+	return w, nil
+}
+
+// Connect создает клиент Kafka и проверяет доступность брокеров. В группу потребитель вступает при первом чтении.
+func (s *Worker) Connect() error {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(s.config.brokers...),
+		kgo.ConsumerGroup(s.config.group),
+		kgo.ConsumeTopics(s.config.topic),
+		kgo.FetchMaxWait(time.Duration(s.readTimeout)*time.Millisecond),
+		// коммитятся только записи, отмеченные после успешной обработки
+		kgo.AutoCommitMarks(),
+		kgo.AutoCommitInterval(s.config.commitInterval),
+		kgo.OnPartitionsRevoked(s.onRevoked),
+		kgo.OnPartitionsLost(s.onLost),
+	)
+	if err != nil {
+		return fmt.Errorf("kafka: error create client: %w", err)
+	}
+
+	ctx, cancel := s.commitCtx()
+	defer cancel()
+
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return fmt.Errorf("kafka: error ping brokers: %w", err)
+	}
+
+	s.client = client
+	s.setConnected(true)
+
+	logrus.WithFields(logrus.Fields{
+		"name":    s.config.name,
+		"brokers": s.config.brokers,
+		"topic":   s.config.topic,
+		"group":   s.config.group,
+	}).Info("kafka: connected")
+
+	return nil
+}
+
+// onRevoked коммитит обработанные записи перед тем, как партиции перейдут другому потребителю.
+func (s *Worker) onRevoked(ctx context.Context, client *kgo.Client, revoked map[string][]int32) {
+	if err := client.CommitMarkedOffsets(ctx); err != nil {
+		logrus.WithError(err).WithField("name", s.config.name).Error("kafka: error commit offsets on revoke")
+	}
+
+	s.dropPartitions(revoked)
+}
+
+// onLost перестает отслеживать потерянные партиции: коммит для них уже не пройдет.
+func (s *Worker) onLost(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+	s.dropPartitions(lost)
+}
+
+func (s *Worker) dropPartitions(partitions map[string][]int32) {
+	s.offsets.drop(partitions)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for topic, ps := range partitions {
+		for _, partition := range ps {
+			delete(s.rewinds, topicPartition{topic: topic, partition: partition})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":       s.config.name,
+		"partitions": partitions,
+	}).Info("kafka: partitions revoked")
+}
+
+// Stop коммитит обработанные записи и выходит из группы.
+func (s *Worker) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	s.setConnected(false)
+
+	if s.client == nil {
+		return nil
+	}
+
+	ctx, cancel := s.commitCtx()
+	defer cancel()
+
+	if err := s.client.CommitMarkedOffsets(ctx); err != nil {
+		logrus.WithError(err).WithField("name", s.config.name).Error("kafka: error commit offsets on stop")
+	}
+
+	s.client.Close()
+
+	return nil
+}
+
+func (s *Worker) setConnected(connected bool) {
+	s.connected.Store(connected)
+
+	if s.metrics != nil {
+		s.metrics.SetConnectionStatus(s.config.name, connected)
+	}
+}
+
+// Connected возвращает true, если последнее чтение из Kafka прошло без ошибок.
+func (s *Worker) Connected() bool {
+	return s.connected.Load()
+}
+
+func (s *Worker) commitCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.insertTimeout)*time.Millisecond)
+}
+
+// Name возвращает имя соединения.
+func (s *Worker) Name() string {
+	return s.config.name
+}
+
+// MsgChan возвращает канал для получения сообщений.
+func (s *Worker) MsgChan() chan worker.Message {
+	return s.msgChan
+}
+
+// Address возвращает адреса брокеров через запятую.
+func (s *Worker) Address() string {
+	return strings.Join(s.config.brokers, ",")
+}
+
+// Queue возвращает топик.
+func (s *Worker) Queue() string {
+	return s.config.topic
+}
+
+// RoutingKey возвращает группу потребителей.
+func (s *Worker) RoutingKey() string {
+	return s.config.group
+}
+
+// InsertTimeout для соответствия интерфейсу Worker.
+func (s *Worker) InsertTimeout() int {
+	return s.insertTimeout
+}
+
+// ReadTimeout для соответствия интерфейсу Worker.
+func (s *Worker) ReadTimeout() int {
+	return s.readTimeout
+}
@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	validOpts := func(opts ...Option) []Option {
+		return append([]Option{
+			WithName("test"),
+			WithBrokers("localhost:9092", "localhost:9093"),
+			WithTopic("notes"),
+			WithGroup("db-worker"),
+			WithInsertTimeout(1),
+			WithReadTimeout(1),
+		}, opts...)
+	}
+
+	tests := []struct {
+		name               string
+		opts               []Option
+		wantCommitInterval time.Duration
+		wantErr            require.ErrorAssertionFunc
+	}{
+		{
+			name:               "positive case: defaults",
+			opts:               validOpts(),
+			wantCommitInterval: defaultCommitInterval,
+			wantErr:            require.NoError,
+		},
+		{
+			name:               "positive case: custom commit interval",
+			opts:               validOpts(WithCommitInterval(5 * time.Second)),
+			wantCommitInterval: 5 * time.Second,
+			wantErr:            require.NoError,
+		},
+		{
+			name:    "negative case: name is empty",
+			opts:    validOpts(WithName("")),
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: brokers are empty",
+			opts: []Option{
+				WithName("test"),
+				WithTopic("notes"),
+				WithGroup("db-worker"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: topic is empty",
+			opts:    validOpts(WithTopic("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: group is empty",
+			opts:    validOpts(WithGroup("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    validOpts(WithInsertTimeout(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: read timeout is 0",
+			opts:    validOpts(WithReadTimeout(0)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantCommitInterval, got.config.commitInterval)
+			assert.Equal(t, "localhost:9092,localhost:9093", got.Address())
+			assert.Equal(t, "notes", got.Queue())
+			assert.Equal(t, "db-worker", got.RoutingKey())
+			assert.False(t, got.Connected())
+		})
+	}
+}
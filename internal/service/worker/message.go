@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidMessage - сообщение не прошло валидацию или по нему нельзя построить запросы.
+// Повторная обработка такого сообщения не поможет.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// ErrUnmatched - сообщение не подошло ни одной операции соединения.
+var ErrUnmatched = errors.New("message does not match any operation")
+
+// Message - сообщение, полученное воркером из источника запросов.
+type Message struct {
+	Data map[string]any // тело сообщения
+	Meta Metadata       // метаданные источника: заголовки, айди сообщения, correlation id
+
+	// Raw и DecodeErr заданы, если тело не удалось декодировать (Data в этом случае nil).
+	// Такое сообщение не попадает в операции: диспетчер соединения считает его и отправляет в dead_letter.
+	Raw       []byte
+	DecodeErr error
+
+	// Notifier получает уведомления о ходе обработки сообщения. Может быть nil, если источнику не нужен ответ.
+	Notifier Notifier
+}
+
+// Metadata - метаданные сообщения, которые источник передает вместе с телом.
+// Сохраняются в transactions.transactions, поля операции могут брать из них значения (source).
+type Metadata struct {
+	MessageID     string // айди сообщения у источника
+	CorrelationID string // сквозной айди запроса отправителя: попадает в логи и в транзакцию
+
+	// RoutingKey - ключ маршрутизации источника: routing key RabbitMQ, субъект NATS или ключ записи Kafka.
+	// По нему операции соединения выбирают свои сообщения. Пустой, если у источника нет ключа.
+	RoutingKey string
+
+	ContentType string         // формат тела, указанный отправителем
+	Timestamp   time.Time      // время отправки сообщения. Нулевое, если источник его не передает
+	Headers     map[string]any // заголовки сообщения
+}
+
+// Map возвращает непустые метаданные для сохранения в JSON.
+func (m Metadata) Map() map[string]any {
+	meta := make(map[string]any)
+
+	if m.MessageID != "" {
+		meta["message_id"] = m.MessageID
+	}
+
+	if m.CorrelationID != "" {
+		meta["correlation_id"] = m.CorrelationID
+	}
+
+	if m.RoutingKey != "" {
+		meta["routing_key"] = m.RoutingKey
+	}
+
+	if m.ContentType != "" {
+		meta["content_type"] = m.ContentType
+	}
+
+	if !m.Timestamp.IsZero() {
+		meta["timestamp"] = m.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	if len(m.Headers) > 0 {
+		meta["headers"] = m.Headers
+	}
+
+	return meta
+}
+
+// Notifier получает уведомления о ходе обработки сообщения, чтобы источник мог ответить отправителю.
+type Notifier interface {
+	// Persisted вызывается после сохранения сообщения в messages.messages. Если сохранить не удалось - err != nil.
+	Persisted(ids []uuid.UUID, err error)
+	// Done вызывается один раз после завершения обработки сообщения.
+	Done(res Result)
+}
+
+// Result - итог обработки сообщения.
+type Result struct {
+	Operation    string      // название операции (пустое, если сообщение не дошло до операции)
+	IDs          []uuid.UUID // айди сообщений в messages.messages
+	TxID         string      // айди транзакции (пустой, если до транзакции дело не дошло)
+	Status       string      // статус транзакции
+	FailedDriver string      // название драйвера, на котором упала транзакция
+	Duplicate    bool        // сообщение уже обработано раньше: TxID и Status - итог исходной транзакции
+	Err          error       // ошибка обработки
+}
+
+// Persisted передает уведомление о сохранении сообщения, если задан Notifier.
+func (m Message) Persisted(ids []uuid.UUID, err error) {
+	if m.Notifier == nil {
+		return
+	}
+
+	m.Notifier.Persisted(ids, err)
+}
+
+// Done передает итог обработки сообщения, если задан Notifier.
+func (m Message) Done(res Result) {
+	if m.Notifier == nil {
+		return
+	}
+
+	m.Notifier.Done(res)
+}
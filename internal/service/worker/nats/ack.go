@@ -0,0 +1,87 @@
+package nats
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ackMessage - действия с сообщением JetStream, которые нужны для подтверждения (реализует jetstream.Msg).
+type ackMessage interface {
+	Ack() error
+	NakWithDelay(delay time.Duration) error
+	Term() error
+}
+
+// ackNotifier подтверждает сообщение после успешного завершения транзакции.
+// Если обработка не удалась, сообщение доставляется повторно через nakDelay, но не больше max_deliver раз.
+type ackNotifier struct {
+	name     string
+	msg      ackMessage
+	nakDelay time.Duration
+
+	once sync.Once
+}
+
+func newAckNotifier(name string, msg ackMessage, nakDelay time.Duration) *ackNotifier {
+	return &ackNotifier{
+		name:     name,
+		msg:      msg,
+		nakDelay: nakDelay,
+	}
+}
+
+// Persisted реализует worker.Notifier. Если сообщение не удалось сохранить - оно доставляется повторно.
+func (n *ackNotifier) Persisted(_ []uuid.UUID, err error) {
+	if err != nil {
+		n.nak(err)
+	}
+}
+
+// Done реализует worker.Notifier.
+// Сообщения, не прошедшие валидацию, больше не доставляются: повторная обработка не поможет.
+func (n *ackNotifier) Done(res worker.Result) {
+	switch {
+	case res.Err == nil:
+		n.ack()
+	case errors.Is(res.Err, worker.ErrInvalidMessage):
+		n.term(res.Err)
+	default:
+		n.nak(res.Err)
+	}
+}
+
+func (n *ackNotifier) ack() {
+	n.once.Do(func() {
+		if err := n.msg.Ack(); err != nil {
+			logrus.WithError(err).WithField("name", n.name).Error("nats: error ack message")
+		}
+	})
+}
+
+func (n *ackNotifier) nak(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithFields(logrus.Fields{
+			"name":  n.name,
+			"delay": n.nakDelay,
+		}).Warn("nats: nak message")
+
+		if err := n.msg.NakWithDelay(n.nakDelay); err != nil {
+			logrus.WithError(err).WithField("name", n.name).Error("nats: error nak message")
+		}
+	})
+}
+
+func (n *ackNotifier) term(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithField("name", n.name).Warn("nats: term message")
+
+		if err := n.msg.Term(); err != nil {
+			logrus.WithError(err).WithField("name", n.name).Error("nats: error term message")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package nats
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ackCalls struct {
+	acks  int
+	naks  int
+	terms int
+	delay time.Duration
+}
+
+type fakeMsg struct {
+	mu    sync.Mutex
+	calls ackCalls
+}
+
+func (m *fakeMsg) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls.acks++
+
+	return nil
+}
+
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls.naks++
+	m.calls.delay = delay
+
+	return nil
+}
+
+func (m *fakeMsg) Term() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls.terms++
+
+	return nil
+}
+
+func TestAckNotifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		persistErr error
+		result     worker.Result
+		want       ackCalls
+	}{
+		{
+			name: "positive case: ack after commit",
+			want: ackCalls{acks: 1},
+		},
+		{
+			name:   "invalid message: term",
+			result: worker.Result{Err: fmt.Errorf("%w: error validate message", worker.ErrInvalidMessage)},
+			want:   ackCalls{terms: 1},
+		},
+		{
+			name:   "error exec requests: nak with delay",
+			result: worker.Result{Err: errors.New("error exec requests")},
+			want:   ackCalls{naks: 1, delay: time.Second},
+		},
+		{
+			name:       "error persist message: nak once",
+			persistErr: errors.New("error save message"),
+			result:     worker.Result{Err: errors.New("error save message")},
+			want:       ackCalls{naks: 1, delay: time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := &fakeMsg{}
+			n := newAckNotifier("test", msg, time.Second)
+
+			n.Persisted(nil, tt.persistErr)
+			n.Done(tt.result)
+			n.Done(tt.result) // повторное уведомление игнорируется
+
+			assert.Equal(t, tt.want, msg.calls)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package nats
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+)
+
+// Run читает сообщения consumer и передает их в операцию.
+func (s *Worker) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name":     s.config.name,
+		"stream":   s.config.stream,
+		"durable":  s.config.durable,
+		"subjects": s.config.subjects,
+	}).Info("nats: start consume messages")
+
+	msgs, err := s.consumer.Messages(jetstream.PullMaxMessages(s.config.batchSize))
+	if err != nil {
+		return fmt.Errorf("nats: error subscribe: %w", err)
+	}
+
+	// Next блокируется до нового сообщения: прерываем его при завершении работы
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.quitChan:
+		}
+
+		msgs.Stop()
+	}()
+
+	for {
+		msg, err := msgs.Next()
+		if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			logrus.WithField("name", s.config.name).Info("nats: stop consume messages")
+			return nil
+		}
+
+		if err != nil {
+			// например, пропали heartbeat при потере соединения: клиент переподключится сам
+			logrus.WithError(err).WithField("name", s.config.name).Warn("nats: error get message")
+
+			if !s.wait(ctx, s.config.retryInterval) {
+				return nil
+			}
+
+			continue
+		}
+
+		if !s.handle(ctx, msg) {
+			return nil
+		}
+	}
+}
+
+// handle передает сообщение в операцию. Возвращает false, если работа завершена.
+// Сообщение, тело которого не удалось декодировать, тоже передается: его завершит диспетчер соединения.
+func (s *Worker) handle(ctx context.Context, msg jetstream.Msg) bool {
+	meta := metadata(msg)
+
+	out := worker.Message{
+		Meta:     meta,
+		Notifier: newAckNotifier(s.config.name, msg, s.config.nakDelay),
+	}
+
+	data, err := s.codec.Decode(meta.ContentType, msg.Data())
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":         s.config.name,
+			"subject":      msg.Subject(),
+			"content_type": meta.ContentType,
+		}).Error("nats: error decode message")
+
+		out.Raw = msg.Data()
+		out.DecodeErr = err
+	}
+
+	out.Data = data
+
+	logrus.WithFields(logrus.Fields{
+		"name":    s.config.name,
+		"subject": msg.Subject(),
+		"message": data,
+	}).Debug("nats: received message")
+
+	select {
+	case s.msgChan <- out:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	}
+}
+
+// metadata собирает метаданные сообщения: субъект, заголовки и время публикации в стрим.
+// Айди сообщения берется из заголовка Nats-Msg-Id, correlation id - из X-Correlation-Id, формат тела - из Content-Type.
+func metadata(msg jetstream.Msg) worker.Metadata {
+	meta := worker.Metadata{RoutingKey: msg.Subject()}
+
+	if headers := msg.Headers(); len(headers) > 0 {
+		meta.MessageID = headers.Get(nats.MsgIdHdr)
+		meta.CorrelationID = headers.Get(correlationIDHeader)
+		meta.ContentType = headers.Get(contentTypeHeader)
+		meta.Headers = make(map[string]any, len(headers))
+
+		for key, values := range headers {
+			meta.Headers[key] = headerValue(values)
+		}
+	}
+
+	if md, err := msg.Metadata(); err == nil {
+		meta.Timestamp = md.Timestamp
+	}
+
+	return meta
+}
+
+// headerValue возвращает единственное значение заголовка строкой, несколько значений - списком.
+func headerValue(values []string) any {
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	list := make([]any, 0, len(values))
+	for _, v := range values {
+		list = append(list, v)
+	}
+
+	return list
+}
+
+// wait ждет delay. Возвращает false, если работа завершена раньше.
+func (s *Worker) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
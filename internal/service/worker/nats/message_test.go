@@ -0,0 +1,230 @@
+package nats
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const testStream = "NOTES"
+
+// newTestServer запускает встроенный nats-server с JetStream и создает стрим для субъектов notes.>.
+func newTestServer(t *testing.T) (string, jetstream.JetStream) {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+
+	t.Cleanup(srv.Shutdown)
+
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	_, err = js.CreateStream(t.Context(), jetstream.StreamConfig{
+		Name:     testStream,
+		Subjects: []string{"notes.>"},
+	})
+	require.NoError(t, err)
+
+	return srv.ClientURL(), js
+}
+
+func newTestWorker(t *testing.T, address, durable string, opts ...Option) *Worker {
+	t.Helper()
+
+	w, err := New(append([]Option{
+		WithName(durable),
+		WithAddress(address),
+		WithStream(testStream),
+		WithDurable(durable),
+		WithNakDelay(10 * time.Millisecond),
+		WithInsertTimeout(1000),
+		WithReadTimeout(1000),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Connect())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, w.Run(t.Context()))
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, w.Stop(context.Background()))
+		<-done
+	})
+
+	return w
+}
+
+func publish(t *testing.T, js jetstream.JetStream, subject, data string) {
+	t.Helper()
+
+	_, err := js.Publish(t.Context(), subject, []byte(data))
+	require.NoError(t, err)
+}
+
+func receive(t *testing.T, w *Worker) worker.Message {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		return msg
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "message not received")
+	}
+
+	return worker.Message{}
+}
+
+func noMessages(t *testing.T, w *Worker) {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		assert.Fail(t, "unexpected message", "%v", msg.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func ackPending(t *testing.T, js jetstream.JetStream, durable string) int {
+	t.Helper()
+
+	consumer, err := js.Consumer(t.Context(), testStream, durable)
+	require.NoError(t, err)
+
+	info, err := consumer.Info(t.Context())
+	require.NoError(t, err)
+
+	return info.NumAckPending
+}
+
+func TestRun_SubjectFilters(t *testing.T) {
+	t.Parallel()
+
+	address, js := newTestServer(t)
+
+	create := newTestWorker(t, address, "notes-create", WithSubjects("notes.create"))
+	deleteNotes := newTestWorker(t, address, "notes-delete", WithSubjects("notes.delete"))
+
+	publish(t, js, "notes.create", `{"id": 1}`)
+	publish(t, js, "notes.delete", `{"id": 2}`)
+
+	// каждое соединение получает только сообщения своих субъектов
+	msg := receive(t, create)
+	assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+	assert.Equal(t, "notes.create", msg.Meta.RoutingKey)
+	msg.Done(worker.Result{})
+
+	msg = receive(t, deleteNotes)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	noMessages(t, create)
+	noMessages(t, deleteNotes)
+
+	assert.Eventually(t, func() bool {
+		return ackPending(t, js, "notes-create") == 0 && ackPending(t, js, "notes-delete") == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_MaxDeliver(t *testing.T) {
+	t.Parallel()
+
+	address, js := newTestServer(t)
+
+	w := newTestWorker(t, address, "notes", WithSubjects("notes.create"), WithMaxDeliver(2))
+
+	publish(t, js, "notes.create", `{"id": 1}`)
+
+	// после ошибки сообщение доставляется повторно, но не больше max_deliver раз
+	for range 2 {
+		msg := receive(t, w)
+		assert.Equal(t, map[string]any{"id": json.Number("1")}, msg.Data)
+		msg.Done(worker.Result{Err: errors.New("error exec requests")})
+	}
+
+	noMessages(t, w)
+}
+
+func TestRun_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	address, js := newTestServer(t)
+
+	w := newTestWorker(t, address, "notes", WithSubjects("notes.create"))
+
+	publish(t, js, "notes.create", `not json`)
+	publish(t, js, "notes.create", `{"id": 2}`)
+
+	// битое сообщение передается с исходным телом и ошибкой декодирования,
+	// диспетчер завершает его как невалидное - оно больше не доставляется
+	msg := receive(t, w)
+	require.Error(t, msg.DecodeErr)
+	assert.Equal(t, []byte("not json"), msg.Raw)
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, msg.DecodeErr)})
+
+	msg = receive(t, w)
+	assert.Equal(t, map[string]any{"id": json.Number("2")}, msg.Data)
+	msg.Done(worker.Result{})
+
+	assert.Eventually(t, func() bool {
+		return ackPending(t, js, "notes") == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_ContentType(t *testing.T) {
+	t.Parallel()
+
+	address, js := newTestServer(t)
+
+	w := newTestWorker(t, address, "notes", WithSubjects("notes.create"))
+
+	body, err := msgpack.Marshal(map[string]any{"id": 1})
+	require.NoError(t, err)
+
+	_, err = js.PublishMsg(t.Context(), &nats.Msg{
+		Subject: "notes.create",
+		Data:    body,
+		Header:  nats.Header{"Content-Type": []string{"application/msgpack"}},
+	})
+	require.NoError(t, err)
+
+	// формат тела выбирается по заголовку Content-Type, числа приводятся к float64, как в JSON
+	msg := receive(t, w)
+	require.NoError(t, msg.DecodeErr)
+	assert.Equal(t, map[string]any{"id": float64(1)}, msg.Data)
+	assert.Equal(t, "application/msgpack", msg.Meta.ContentType)
+	msg.Done(worker.Result{})
+}
@@ -0,0 +1,351 @@
+package nats
+
+import (
+	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+)
+
+// Worker читает сообщения из NATS JetStream через durable pull consumer.
+// Стрим должен существовать, consumer создается (или обновляется) при подключении.
+type Worker struct {
+	config struct {
+		name     string
+		address  string
+		stream   string
+		durable  string
+		subjects []string // фильтр субъектов: какие сообщения стрима получает consumer
+
+		batchSize     int           // сколько сообщений запрашивать за раз
+		maxDeliver    int           // сколько раз доставлять сообщение. -1 - без ограничений
+		ackWait       time.Duration // сколько сервер ждет подтверждения, прежде чем доставить сообщение повторно
+		nakDelay      time.Duration // через сколько доставить сообщение повторно после ошибки обработки
+		retryInterval time.Duration // пауза между попытками переподключения
+	}
+
+	conn     *nats.Conn
+	consumer jetstream.Consumer
+	codec    *codec.Registry // декодирует тела сообщений
+
+	msgChan  chan worker.Message
+	quitChan chan struct{}
+
+	connected atomic.Bool
+	metrics   connectionMetrics
+
+	insertTimeout int
+	readTimeout   int
+}
+
+const (
+	defaultBatchSize     = 10
+	defaultAckWait       = 30 * time.Second
+	defaultNakDelay      = 5 * time.Second
+	defaultRetryInterval = time.Second
+
+	correlationIDHeader = "X-Correlation-Id"
+	contentTypeHeader   = "Content-Type"
+)
+
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type connectionMetrics interface {
+	SetConnectionStatus(name string, connected bool)
+	AddConnectionReconnects(name string)
+}
+
+// Option определяет опции для Worker.
+type Option func(*Worker)
+
+// WithName устанавливает имя соединения.
+func WithName(name string) Option {
+	return func(w *Worker) {
+		w.config.name = name
+	}
+}
+
+// WithAddress устанавливает адрес сервера NATS.
+func WithAddress(address string) Option {
+	return func(w *Worker) {
+		w.config.address = address
+	}
+}
+
+// WithStream устанавливает название стрима.
+func WithStream(stream string) Option {
+	return func(w *Worker) {
+		w.config.stream = stream
+	}
+}
+
+// WithDurable устанавливает имя durable consumer. Экземпляры сервиса с одним именем делят сообщения между собой.
+func WithDurable(durable string) Option {
+	return func(w *Worker) {
+		w.config.durable = durable
+	}
+}
+
+// WithSubjects устанавливает фильтр субъектов consumer.
+func WithSubjects(subjects ...string) Option {
+	return func(w *Worker) {
+		w.config.subjects = append(w.config.subjects, subjects...)
+	}
+}
+
+// WithBatchSize устанавливает, сколько сообщений запрашивать за раз.
+func WithBatchSize(batchSize int) Option {
+	return func(w *Worker) {
+		w.config.batchSize = batchSize
+	}
+}
+
+// WithMaxDeliver устанавливает, сколько раз доставлять сообщение.
+func WithMaxDeliver(maxDeliver int) Option {
+	return func(w *Worker) {
+		w.config.maxDeliver = maxDeliver
+	}
+}
+
+// WithAckWait устанавливает, сколько сервер ждет подтверждения сообщения.
+func WithAckWait(ackWait time.Duration) Option {
+	return func(w *Worker) {
+		w.config.ackWait = ackWait
+	}
+}
+
+// WithNakDelay устанавливает задержку повторной доставки после ошибки обработки.
+func WithNakDelay(nakDelay time.Duration) Option {
+	return func(w *Worker) {
+		w.config.nakDelay = nakDelay
+	}
+}
+
+// WithRetryInterval устанавливает паузу между попытками переподключения.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.retryInterval = interval
+	}
+}
+
+// WithCodec устанавливает форматы тела сообщений. По умолчанию JSON.
+func WithCodec(registry *codec.Registry) Option {
+	return func(w *Worker) {
+		w.codec = registry
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для отчета о состоянии соединения.
+func WithMetrics(metrics connectionMetrics) Option {
+	return func(w *Worker) {
+		w.metrics = metrics
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания запросов к JetStream (мс).
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(w *Worker) {
+		w.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout для соответствия остальным соединениям.
+func WithReadTimeout(readTimeout int) Option {
+	return func(w *Worker) {
+		w.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Worker.
+func New(opts ...Option) (*Worker, error) {
+	w := &Worker{}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.config.name == "" {
+		return nil, fmt.Errorf("nats: name is required")
+	}
+
+	if w.config.address == "" {
+		return nil, fmt.Errorf("nats: address is required")
+	}
+
+	if w.config.stream == "" {
+		return nil, fmt.Errorf("nats: stream is required")
+	}
+
+	if w.config.durable == "" {
+		return nil, fmt.Errorf("nats: durable is required")
+	}
+
+	if w.insertTimeout == 0 {
+		return nil, fmt.Errorf("nats: insert timeout is required")
+	}
+
+	if w.readTimeout == 0 {
+		return nil, fmt.Errorf("nats: read timeout is required")
+	}
+
+	if w.config.batchSize == 0 {
+		w.config.batchSize = defaultBatchSize
+	}
+
+	if w.config.ackWait == 0 {
+		w.config.ackWait = defaultAckWait
+	}
+
+	if w.config.nakDelay == 0 {
+		w.config.nakDelay = defaultNakDelay
+	}
+
+	if w.config.retryInterval == 0 {
+		w.config.retryInterval = defaultRetryInterval
+	}
+
+	if w.codec == nil {
+		w.codec = codec.JSON()
+	}
+
+	w.msgChan = make(chan worker.Message)
+	w.quitChan = make(chan struct{})
+
+	return w, nil
+}
+
+// Connect подключается к NATS и создает durable pull consumer. Клиент NATS сам переподключается при потере соединения.
+func (s *Worker) Connect() error {
+	conn, err := nats.Connect(s.config.address,
+		nats.Name(s.config.name),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(s.config.retryInterval),
+		nats.DisconnectErrHandler(s.onDisconnect),
+		nats.ReconnectHandler(s.onReconnect),
+	)
+	if err != nil {
+		return fmt.Errorf("nats: error connect: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("nats: error create jetstream context: %w", err)
+	}
+
+	ctx, cancel := s.requestCtx()
+	defer cancel()
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, s.config.stream, jetstream.ConsumerConfig{
+		Durable:        s.config.durable,
+		FilterSubjects: s.config.subjects,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        s.config.ackWait,
+		MaxDeliver:     s.config.maxDeliver,
+	})
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("nats: error create consumer: %w", err)
+	}
+
+	s.conn = conn
+	s.consumer = consumer
+	s.setConnected(true)
+
+	logrus.WithFields(logrus.Fields{
+		"name":     s.config.name,
+		"address":  s.config.address,
+		"stream":   s.config.stream,
+		"durable":  s.config.durable,
+		"subjects": s.config.subjects,
+	}).Info("nats: connected")
+
+	return nil
+}
+
+func (s *Worker) onDisconnect(_ *nats.Conn, err error) {
+	s.setConnected(false)
+
+	logrus.WithError(err).WithField("name", s.config.name).Warn("nats: connection lost")
+}
+
+func (s *Worker) onReconnect(_ *nats.Conn) {
+	if s.metrics != nil {
+		s.metrics.AddConnectionReconnects(s.config.name)
+	}
+
+	s.setConnected(true)
+
+	logrus.WithField("name", s.config.name).Info("nats: reconnected")
+}
+
+// Stop прекращает чтение и закрывает соединение.
+func (s *Worker) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	if s.conn != nil {
+		// сообщения, которые сейчас обрабатываются, не подтвердятся и будут доставлены повторно после ack_wait
+		s.conn.Close()
+	}
+
+	s.setConnected(false)
+
+	return nil
+}
+
+func (s *Worker) setConnected(connected bool) {
+	s.connected.Store(connected)
+
+	if s.metrics != nil {
+		s.metrics.SetConnectionStatus(s.config.name, connected)
+	}
+}
+
+// Connected возвращает true, если соединение с NATS установлено.
+func (s *Worker) Connected() bool {
+	return s.connected.Load()
+}
+
+func (s *Worker) requestCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.insertTimeout)*time.Millisecond)
+}
+
+// Name возвращает имя соединения.
+func (s *Worker) Name() string {
+	return s.config.name
+}
+
+// MsgChan возвращает канал для получения сообщений.
+func (s *Worker) MsgChan() chan worker.Message {
+	return s.msgChan
+}
+
+// Address возвращает адрес сервера NATS.
+func (s *Worker) Address() string {
+	return s.config.address
+}
+
+// Queue возвращает название стрима.
+func (s *Worker) Queue() string {
+	return s.config.stream
+}
+
+// RoutingKey возвращает имя durable consumer.
+func (s *Worker) RoutingKey() string {
+	return s.config.durable
+}
+
+// InsertTimeout для соответствия интерфейсу Worker.
+func (s *Worker) InsertTimeout() int {
+	return s.insertTimeout
+}
+
+// ReadTimeout для соответствия интерфейсу Worker.
+func (s *Worker) ReadTimeout() int {
+	return s.readTimeout
+}
@@ -0,0 +1,93 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	validOpts := func(opts ...Option) []Option {
+		return append([]Option{
+			WithName("test"),
+			WithAddress("nats://localhost:4222"),
+			WithStream("NOTES"),
+			WithDurable("db-worker"),
+			WithSubjects("notes.create"),
+			WithInsertTimeout(1),
+			WithReadTimeout(1),
+		}, opts...)
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantAckWait time.Duration
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:        "positive case: defaults",
+			opts:        validOpts(),
+			wantAckWait: defaultAckWait,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "positive case: custom ack wait",
+			opts:        validOpts(WithAckWait(time.Minute), WithMaxDeliver(5)),
+			wantAckWait: time.Minute,
+			wantErr:     require.NoError,
+		},
+		{
+			name:    "negative case: name is empty",
+			opts:    validOpts(WithName("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: address is empty",
+			opts:    validOpts(WithAddress("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: stream is empty",
+			opts:    validOpts(WithStream("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: durable is empty",
+			opts:    validOpts(WithDurable("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    validOpts(WithInsertTimeout(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: read timeout is 0",
+			opts:    validOpts(WithReadTimeout(0)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantAckWait, got.config.ackWait)
+			assert.Equal(t, []string{"notes.create"}, got.config.subjects)
+			assert.Equal(t, "NOTES", got.Queue())
+			assert.Equal(t, "db-worker", got.RoutingKey())
+		})
+	}
+}
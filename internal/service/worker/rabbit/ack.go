@@ -0,0 +1,118 @@
+package rabbit
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// AckPolicy - момент подтверждения сообщения.
+type AckPolicy string
+
+const (
+	// AckOnReceive - сообщение подтверждается сразу после передачи в операцию.
+	AckOnReceive AckPolicy = "on_receive"
+	// AckOnPersisted - сообщение подтверждается после сохранения в messages.messages.
+	AckOnPersisted AckPolicy = "on_persisted"
+	// AckOnCommit - сообщение подтверждается после успешного завершения транзакции.
+	AckOnCommit AckPolicy = "on_commit"
+)
+
+// ackNotifier подтверждает или отклоняет сообщение в зависимости от хода его обработки.
+// Сообщение подтверждается (или отклоняется) ровно один раз, последующие уведомления игнорируются.
+type ackNotifier struct {
+	delivery amqp.Delivery
+	policy   AckPolicy
+	requeue  bool // возвращать ли сообщение в очередь при ошибке обработки
+
+	// reply публикует итог обработки отправителю. nil, если отправитель не ждет ответа
+	reply func(res worker.Result)
+
+	once sync.Once
+}
+
+func newAckNotifier(delivery amqp.Delivery, policy AckPolicy, requeue bool) *ackNotifier {
+	return &ackNotifier{
+		delivery: delivery,
+		policy:   policy,
+		requeue:  requeue,
+	}
+}
+
+// Persisted реализует worker.Notifier. Если сообщение не удалось сохранить - оно отклоняется при любой политике,
+// кроме on_receive: до сохранения сообщение нигде не записано.
+func (n *ackNotifier) Persisted(_ []uuid.UUID, err error) {
+	if n.policy == AckOnReceive {
+		return
+	}
+
+	if err != nil {
+		n.nack(err)
+		return
+	}
+
+	if n.policy == AckOnPersisted {
+		n.ack()
+	}
+}
+
+// Done реализует worker.Notifier.
+// Сообщения, не прошедшие валидацию, отклоняются без возврата в очередь: повторная обработка не поможет.
+// При on_persisted сохраненное сообщение уже подтверждено в Persisted, поэтому Done завершает только сообщения,
+// которые не дошли до сохранения (например, не подошли ни одной операции).
+// Ответ отправителю публикуется после подтверждения при любой политике.
+func (n *ackNotifier) Done(res worker.Result) {
+	if n.reply != nil {
+		defer n.reply(res)
+	}
+
+	if n.policy == AckOnReceive {
+		return
+	}
+
+	switch {
+	case res.Err == nil:
+		n.ack()
+	case errors.Is(res.Err, worker.ErrInvalidMessage):
+		n.reject(res.Err)
+	default:
+		n.nack(res.Err)
+	}
+}
+
+func (n *ackNotifier) ack() {
+	n.once.Do(func() {
+		if err := n.delivery.Ack(false); err != nil {
+			logrus.WithError(err).WithField("delivery_tag", n.delivery.DeliveryTag).Error("rabbit: error ack message")
+		}
+	})
+}
+
+// nack возвращает сообщение в очередь, если задан requeue. Иначе сообщение отклоняется.
+func (n *ackNotifier) nack(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithFields(logrus.Fields{
+			"delivery_tag": n.delivery.DeliveryTag,
+			"requeue":      n.requeue,
+		}).Warn("rabbit: nack message")
+
+		if err := n.delivery.Nack(false, n.requeue); err != nil {
+			logrus.WithError(err).WithField("delivery_tag", n.delivery.DeliveryTag).Error("rabbit: error nack message")
+		}
+	})
+}
+
+// reject отклоняет сообщение без возврата в очередь.
+func (n *ackNotifier) reject(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithField("delivery_tag", n.delivery.DeliveryTag).Warn("rabbit: reject message")
+
+		if err := n.delivery.Reject(false); err != nil {
+			logrus.WithError(err).WithField("delivery_tag", n.delivery.DeliveryTag).Error("rabbit: error reject message")
+		}
+	})
+}
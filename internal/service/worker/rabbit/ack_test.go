@@ -0,0 +1,243 @@
+package rabbit
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAcknowledger запоминает вызовы подтверждения сообщения.
+type fakeAcknowledger struct {
+	mu sync.Mutex
+	ackCalls
+}
+
+type ackCalls struct {
+	acks    int
+	nacks   int
+	rejects int
+	requeue bool
+}
+
+func (a *fakeAcknowledger) Ack(_ uint64, _ bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.acks++
+
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nacks++
+	a.requeue = requeue
+
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(_ uint64, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rejects++
+	a.requeue = requeue
+
+	return nil
+}
+
+//nolint:funlen // это тест
+func TestAckNotifier(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.New()}
+	errInvalid := fmt.Errorf("%w: error validate message", worker.ErrInvalidMessage)
+
+	tests := []struct {
+		name    string
+		policy  AckPolicy
+		requeue bool
+		notify  func(n *ackNotifier)
+		want    ackCalls
+	}{
+		{
+			name:   "on_receive: notifications are ignored",
+			policy: AckOnReceive,
+			notify: func(n *ackNotifier) {
+				n.Persisted(nil, errors.New("error"))
+				n.Done(worker.Result{Err: errors.New("error")})
+			},
+			want: ackCalls{},
+		},
+		{
+			name:   "on_persisted: ack after persisted",
+			policy: AckOnPersisted,
+			notify: func(n *ackNotifier) {
+				n.Persisted(ids, nil)
+				n.Done(worker.Result{IDs: ids, Err: errors.New("error")})
+			},
+			want: ackCalls{acks: 1},
+		},
+		{
+			name:    "on_persisted: nack with requeue if not persisted",
+			policy:  AckOnPersisted,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				n.Persisted(nil, errors.New("error"))
+				n.Done(worker.Result{Err: errors.New("error")})
+			},
+			want: ackCalls{nacks: 1, requeue: true},
+		},
+		{
+			name:    "on_persisted: reject unmatched message",
+			policy:  AckOnPersisted,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				// диспетчер завершает сообщение без Persisted: unmatched drop или dead_letter
+				n.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, worker.ErrUnmatched)})
+			},
+			want: ackCalls{rejects: 1},
+		},
+		{
+			name:    "on_persisted: nack unmatched message",
+			policy:  AckOnPersisted,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				// unmatched fail или ошибка отправки в dead_letter
+				n.Done(worker.Result{Err: worker.ErrUnmatched})
+			},
+			want: ackCalls{nacks: 1, requeue: true},
+		},
+		{
+			name:   "on_commit: ack after commit",
+			policy: AckOnCommit,
+			notify: func(n *ackNotifier) {
+				n.Persisted(ids, nil)
+				n.Done(worker.Result{IDs: ids, TxID: "tx"})
+			},
+			want: ackCalls{acks: 1},
+		},
+		{
+			name:    "on_commit: reject invalid message without requeue",
+			policy:  AckOnCommit,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				n.Persisted(ids, nil)
+				n.Done(worker.Result{IDs: ids, Err: errInvalid})
+			},
+			want: ackCalls{rejects: 1},
+		},
+		{
+			name:   "on_commit: nack without requeue if transaction failed",
+			policy: AckOnCommit,
+			notify: func(n *ackNotifier) {
+				n.Persisted(ids, nil)
+				n.Done(worker.Result{IDs: ids, TxID: "tx", Err: errors.New("error")})
+			},
+			want: ackCalls{nacks: 1},
+		},
+		{
+			name:    "on_commit: nack with requeue if transaction failed",
+			policy:  AckOnCommit,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				n.Persisted(ids, nil)
+				n.Done(worker.Result{IDs: ids, TxID: "tx", Err: errors.New("error")})
+			},
+			want: ackCalls{nacks: 1, requeue: true},
+		},
+		{
+			name:    "on_commit: settled only once",
+			policy:  AckOnCommit,
+			requeue: true,
+			notify: func(n *ackNotifier) {
+				n.Persisted(nil, errors.New("error"))
+				n.Done(worker.Result{Err: errors.New("error")})
+				n.Done(worker.Result{})
+			},
+			want: ackCalls{nacks: 1, requeue: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			acknowledger := &fakeAcknowledger{}
+
+			n := newAckNotifier(amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1}, tt.policy, tt.requeue)
+			tt.notify(n)
+
+			assert.Equal(t, tt.want, acknowledger.ackCalls)
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   AckPolicy
+		wantAcks int
+	}{
+		{
+			name:     "on_receive: ack after send",
+			policy:   AckOnReceive,
+			wantAcks: 1,
+		},
+		{
+			name:     "on_commit: no ack after send",
+			policy:   AckOnCommit,
+			wantAcks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := testWorker(tt.policy)
+
+			acknowledger := &fakeAcknowledger{}
+
+			w.send(t.Context(), amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1}, map[string]any{"field": "value"}, nil)
+
+			msg := <-w.MsgChan()
+			assert.Equal(t, map[string]any{"field": "value"}, msg.Data)
+			require.NotNil(t, msg.Notifier)
+
+			assert.Equal(t, tt.wantAcks, acknowledger.acks)
+		})
+	}
+}
+
+func TestSend_DecodeError(t *testing.T) {
+	t.Parallel()
+
+	// даже при on_receive сообщение подтверждается только по итогу обработки диспетчером
+	w := testWorker(AckOnReceive)
+
+	acknowledger := &fakeAcknowledger{}
+	decodeErr := errors.New("invalid body")
+
+	w.send(t.Context(), amqp.Delivery{Acknowledger: acknowledger, DeliveryTag: 1, Body: []byte("{")}, nil, decodeErr)
+
+	msg := <-w.MsgChan()
+	assert.Nil(t, msg.Data)
+	assert.Equal(t, []byte("{"), msg.Raw)
+	require.ErrorIs(t, msg.DecodeErr, decodeErr)
+	assert.Equal(t, 0, acknowledger.acks)
+
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, decodeErr)})
+	assert.Equal(t, ackCalls{rejects: 1}, acknowledger.ackCalls)
+}
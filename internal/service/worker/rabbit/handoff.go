@@ -0,0 +1,129 @@
+package rabbit
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"sync"
+)
+
+// handoff передает сообщения в операцию по одному, в порядке получения.
+// Одновременно в обработке не больше maxInFlight сообщений: место освобождается, когда операция завершает сообщение.
+// Пока мест нет, чтение очереди останавливается, и брокер перестает присылать сообщения сверх prefetch.
+//
+// Если задан ключ порядка, сообщения с одним ключом обрабатываются строго по очереди: следующее передается
+// только после завершения предыдущего, сообщения с другими ключами при этом не ждут.
+type handoff struct {
+	out  chan<- worker.Message
+	quit <-chan struct{}
+
+	slots chan struct{} // свободные места для сообщений в обработке
+
+	mu      sync.Mutex
+	pending map[string][]worker.Message // ключи с сообщением в обработке и очередь следующих сообщений с этим ключом
+}
+
+func newHandoff(out chan<- worker.Message, quit <-chan struct{}, maxInFlight int) *handoff {
+	return &handoff{
+		out:     out,
+		quit:    quit,
+		slots:   make(chan struct{}, maxInFlight),
+		pending: make(map[string][]worker.Message),
+	}
+}
+
+// push передает сообщение в операцию. Блокируется, пока в обработке maxInFlight сообщений.
+// Сообщение с ключом, по которому еще обрабатывается предыдущее сообщение, встает за ним в очередь.
+// Пустой ключ - порядок не важен. Возвращает false, если работа завершена раньше, чем сообщение удалось передать.
+func (h *handoff) push(ctx context.Context, msg worker.Message, key string) bool {
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	case <-h.quit:
+		return false
+	}
+
+	msg.Notifier = &releaseNotifier{Notifier: msg.Notifier, release: func() { h.release(key) }}
+
+	if key != "" {
+		h.mu.Lock()
+
+		if queue, busy := h.pending[key]; busy {
+			h.pending[key] = append(queue, msg)
+			h.mu.Unlock()
+
+			return true
+		}
+
+		h.pending[key] = nil
+		h.mu.Unlock()
+	}
+
+	return h.deliver(ctx, msg)
+}
+
+// deliver отправляет сообщение в канал операции.
+func (h *handoff) deliver(ctx context.Context, msg worker.Message) bool {
+	select {
+	case h.out <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-h.quit:
+		return false
+	}
+}
+
+// release освобождает место завершенного сообщения и передает следующее сообщение с тем же ключом.
+func (h *handoff) release(key string) {
+	<-h.slots
+
+	if key == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	queue := h.pending[key]
+	if len(queue) == 0 {
+		delete(h.pending, key)
+		return
+	}
+
+	h.pending[key] = queue[1:]
+
+	// release вызывается из операции: передаем отдельно, чтобы операция не ждала канал, который через диспетчер
+	// читает она же. Таких горутин не больше maxInFlight: сообщение в очереди уже занимает место
+	go h.deliver(context.Background(), queue[0])
+}
+
+// releaseNotifier освобождает место сообщения в handoff после завершения его обработки.
+type releaseNotifier struct {
+	worker.Notifier
+
+	release func()
+	once    sync.Once
+}
+
+// Done реализует worker.Notifier.
+func (n *releaseNotifier) Done(res worker.Result) {
+	n.Notifier.Done(res)
+
+	n.once.Do(n.release)
+}
+
+// orderKey возвращает значение поля порядка сообщения. Пустое, если поле не задано или его нет в сообщении.
+func orderKey(field string, data map[string]any) string {
+	if field == "" {
+		return ""
+	}
+
+	value, ok := data[field]
+	if !ok || value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
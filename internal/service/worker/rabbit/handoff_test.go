@@ -0,0 +1,203 @@
+package rabbit
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWorker создает Worker, который передает сообщения в буферизованный канал.
+func testWorker(policy AckPolicy) *Worker {
+	w := &Worker{
+		msgChan:   make(chan worker.Message, 1),
+		quitChan:  make(chan struct{}),
+		ackPolicy: policy,
+	}
+
+	w.handoff = newHandoff(w.msgChan, w.quitChan, 10)
+
+	return w
+}
+
+// noopNotifier ничего не делает с уведомлениями.
+type noopNotifier struct{}
+
+func (noopNotifier) Persisted(_ []uuid.UUID, _ error) {}
+
+func (noopNotifier) Done(_ worker.Result) {}
+
+func keyedMessage(n int) worker.Message {
+	return worker.Message{Data: map[string]any{"n": n}, Notifier: noopNotifier{}}
+}
+
+// receive читает сообщение из канала или возвращает false, если сообщения нет.
+func receive(t *testing.T, ch <-chan worker.Message) (worker.Message, bool) {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg, true
+	case <-time.After(50 * time.Millisecond):
+		return worker.Message{}, false
+	}
+}
+
+func TestHandoff_Order(t *testing.T) {
+	t.Parallel()
+
+	out := make(chan worker.Message, 10)
+	h := newHandoff(out, make(chan struct{}), 10)
+
+	for i := range 5 {
+		require.True(t, h.push(t.Context(), keyedMessage(i), ""))
+	}
+
+	for i := range 5 {
+		msg, ok := receive(t, out)
+		require.True(t, ok)
+		assert.Equal(t, i, msg.Data["n"])
+	}
+}
+
+func TestHandoff_MaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	out := make(chan worker.Message, 10)
+	h := newHandoff(out, make(chan struct{}), 2)
+
+	require.True(t, h.push(t.Context(), keyedMessage(0), ""))
+	require.True(t, h.push(t.Context(), keyedMessage(1), ""))
+
+	// третье сообщение ждет, пока освободится место
+	pushed := make(chan bool)
+
+	go func() {
+		pushed <- h.push(t.Context(), keyedMessage(2), "")
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("message pushed over max in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first, ok := receive(t, out)
+	require.True(t, ok)
+
+	first.Done(worker.Result{})
+
+	assert.True(t, <-pushed)
+}
+
+func TestHandoff_Stop(t *testing.T) {
+	t.Parallel()
+
+	quit := make(chan struct{})
+	h := newHandoff(make(chan worker.Message), quit, 1)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	// канал никто не читает: push завершается вместе с работой
+	assert.False(t, h.push(ctx, keyedMessage(0), ""))
+
+	close(quit)
+	assert.False(t, h.push(t.Context(), keyedMessage(1), ""))
+}
+
+func TestHandoff_OrderKey(t *testing.T) {
+	t.Parallel()
+
+	out := make(chan worker.Message, 10)
+	h := newHandoff(out, make(chan struct{}), 10)
+
+	require.True(t, h.push(t.Context(), keyedMessage(0), "user-1"))
+	require.True(t, h.push(t.Context(), keyedMessage(1), "user-1"))
+	require.True(t, h.push(t.Context(), keyedMessage(2), "user-2"))
+	require.True(t, h.push(t.Context(), keyedMessage(3), "user-1"))
+
+	// сообщения с другим ключом не ждут
+	first, ok := receive(t, out)
+	require.True(t, ok)
+	assert.Equal(t, 0, first.Data["n"])
+
+	other, ok := receive(t, out)
+	require.True(t, ok)
+	assert.Equal(t, 2, other.Data["n"])
+
+	// следующее сообщение user-1 передается только после завершения предыдущего
+	_, ok = receive(t, out)
+	require.False(t, ok)
+
+	first.Done(worker.Result{})
+
+	second, ok := receive(t, out)
+	require.True(t, ok)
+	assert.Equal(t, 1, second.Data["n"])
+
+	second.Done(worker.Result{})
+
+	third, ok := receive(t, out)
+	require.True(t, ok)
+	assert.Equal(t, 3, third.Data["n"])
+
+	third.Done(worker.Result{})
+	other.Done(worker.Result{})
+
+	h.mu.Lock()
+	assert.Empty(t, h.pending)
+	h.mu.Unlock()
+
+	assert.Empty(t, h.slots)
+}
+
+func TestOrderKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		field string
+		data  map[string]any
+		want  string
+	}{
+		{
+			name:  "positive case: string",
+			field: "user_id",
+			data:  map[string]any{"user_id": "u-1"},
+			want:  "u-1",
+		},
+		{
+			name:  "positive case: number",
+			field: "user_id",
+			data:  map[string]any{"user_id": float64(42)},
+			want:  "42",
+		},
+		{
+			name: "order key not configured",
+			data: map[string]any{"user_id": "u-1"},
+		},
+		{
+			name:  "no field in message",
+			field: "user_id",
+			data:  map[string]any{"text": "note"},
+		},
+		{
+			name:  "field is null",
+			field: "user_id",
+			data:  map[string]any{"user_id": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, orderKey(tt.field, tt.data))
+		})
+	}
+}
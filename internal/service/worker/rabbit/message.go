@@ -2,16 +2,17 @@ package rabbit
 
 import (
 	"context"
-	"encoding/json"
+	"db-worker/internal/service/worker"
 
 	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
 )
 
 func (s *Worker) connectChannel() error {
 	msgs, err := s.channel.Consume(
 		s.queue.Name, // queue
 		"",           // consumer
-		true,         // auto-ack
+		false,        // auto-ack: подтверждаем вручную согласно ackPolicy
 		false,        // exclusive
 		false,        // no-local
 		false,        // no-wait
@@ -26,7 +27,9 @@ func (s *Worker) connectChannel() error {
 	return nil
 }
 
-// Run запускает чтение сообщений из очереди.
+// Run запускает чтение сообщений из очереди. При потере соединения переподключается и продолжает чтение.
+//
+//nolint:funlen // цельная логика функции, много строк из-за логов
 func (s *Worker) Run(ctx context.Context) error {
 	logrus.WithFields(logrus.Fields{
 		"routing_key": s.config.routingKey,
@@ -51,30 +54,109 @@ func (s *Worker) Run(ctx context.Context) error {
 
 			return nil
 
-		case msg := <-s.msgs:
+		case err := <-s.connClose:
+			if !s.handleDisconnect(ctx, "connection", err) {
+				return nil
+			}
+
+		case err := <-s.channelClose:
+			if !s.handleDisconnect(ctx, "channel", err) {
+				return nil
+			}
+
+		case msg, ok := <-s.msgs:
+			if !ok {
+				if !s.handleDisconnect(ctx, "deliveries", nil) {
+					return nil
+				}
+
+				continue
+			}
+
 			logrus.WithFields(logrus.Fields{
 				"message":     string(msg.Body),
 				"routing_key": msg.RoutingKey,
 				"queue":       s.queue.Name,
 			}).Debug("rabbit: received message")
 
-			var mapMsg map[string]interface{}
-
-			err := json.Unmarshal(msg.Body, &mapMsg)
+			data, err := s.codec.Decode(msg.ContentType, msg.Body)
 			if err != nil {
-				logrus.WithError(err).Error("rabbit: error marshal message")
-				continue
+				logrus.WithError(err).WithFields(logrus.Fields{
+					"routing_key":  msg.RoutingKey,
+					"content_type": msg.ContentType,
+					"queue":        s.queue.Name,
+				}).Error("rabbit: error decode message")
+			}
+
+			// передаем по одному: порядок сохраняется, а пока операция занята, новые сообщения не читаются
+			if !s.send(ctx, msg, data, err) {
+				return nil
 			}
+		}
+	}
+}
+
+// send передает сообщение в операцию через handoff. При политике AckOnReceive подтверждает сообщение сразу после
+// передачи, иначе - по уведомлению операции. Возвращает false, если работа завершена раньше, чем сообщение удалось передать.
+// Сообщение, тело которого не удалось декодировать, подтверждается или отклоняется по итогу его обработки
+// диспетчером соединения при любой политике.
+// Если у сообщения заданы reply_to и correlation_id, итог обработки публикуется отправителю.
+func (s *Worker) send(ctx context.Context, delivery amqp.Delivery, data map[string]any, decodeErr error) bool {
+	notifier := newAckNotifier(delivery, s.ackPolicy, s.requeue)
+
+	msg := worker.Message{
+		Data: data,
+		Meta: metadata(delivery),
+	}
 
-			go func() {
-				s.msgChan <- mapMsg
+	if decodeErr != nil {
+		msg.Raw = delivery.Body
+		msg.DecodeErr = decodeErr
+		notifier = newAckNotifier(delivery, AckOnCommit, s.requeue)
+	}
 
-				logrus.WithFields(logrus.Fields{
-					"message":     string(msg.Body),
-					"routing_key": msg.RoutingKey,
-					"queue":       s.queue.Name,
-				}).Debug("rabbit: sent message to channel")
-			}()
+	if replyNeeded(delivery) {
+		notifier.reply = func(res worker.Result) {
+			s.reply(delivery, res)
 		}
 	}
+
+	msg.Notifier = notifier
+
+	if !s.handoff.push(ctx, msg, orderKey(s.orderKey, data)) {
+		return false
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"message":     string(delivery.Body),
+		"routing_key": delivery.RoutingKey,
+		"queue":       s.queue.Name,
+	}).Debug("rabbit: sent message to channel")
+
+	if s.ackPolicy != AckOnReceive || decodeErr != nil {
+		return true
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		logrus.WithError(err).WithField("delivery_tag", delivery.DeliveryTag).Error("rabbit: error ack message")
+	}
+
+	return true
+}
+
+// metadata собирает метаданные сообщения из свойств доставки.
+func metadata(delivery amqp.Delivery) worker.Metadata {
+	meta := worker.Metadata{
+		MessageID:     delivery.MessageId,
+		CorrelationID: delivery.CorrelationId,
+		RoutingKey:    delivery.RoutingKey,
+		ContentType:   delivery.ContentType,
+		Timestamp:     delivery.Timestamp,
+	}
+
+	if len(delivery.Headers) > 0 {
+		meta.Headers = map[string]any(delivery.Headers)
+	}
+
+	return meta
 }
@@ -0,0 +1,55 @@
+package rabbit
+
+import (
+	"db-worker/internal/service/worker"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		delivery amqp.Delivery
+		want     worker.Metadata
+	}{
+		{
+			name: "positive case: all properties",
+			delivery: amqp.Delivery{
+				MessageId:     "msg-1",
+				CorrelationId: "corr-1",
+				RoutingKey:    "notes.create",
+				ContentType:   "application/json",
+				Timestamp:     ts,
+				Headers:       amqp.Table{"user_id": int32(42)},
+			},
+			want: worker.Metadata{
+				MessageID:     "msg-1",
+				CorrelationID: "corr-1",
+				RoutingKey:    "notes.create",
+				ContentType:   "application/json",
+				Timestamp:     ts,
+				Headers:       map[string]any{"user_id": int32(42)},
+			},
+		},
+		{
+			name:     "positive case: no headers",
+			delivery: amqp.Delivery{RoutingKey: "notes.create"},
+			want:     worker.Metadata{RoutingKey: "notes.create"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, metadata(tt.delivery))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package rabbit
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// handleDisconnect обрабатывает потерю соединения: переподключается и возвращает true, если чтение можно продолжить.
+// source - что именно закрылось (соединение, канал или поток сообщений).
+func (s *Worker) handleDisconnect(ctx context.Context, source string, cause *amqp.Error) bool {
+	s.setConnected(false)
+
+	logrus.WithFields(logrus.Fields{
+		"name":   s.config.name,
+		"source": source,
+		"cause":  cause,
+	}).Warn("rabbit: connection lost")
+
+	return s.reconnectWithBackoff(ctx)
+}
+
+// reconnectWithBackoff переподключается к RabbitMQ с экспоненциальной задержкой и джиттером, пока не получится.
+// Возвращает false, если работа завершена раньше, чем удалось переподключиться.
+func (s *Worker) reconnectWithBackoff(ctx context.Context) bool {
+	s.closeConnection()
+
+	for attempt := 0; ; attempt++ {
+		delay := s.backoff(attempt)
+
+		logrus.WithFields(logrus.Fields{
+			"name":    s.config.name,
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Info("rabbit: reconnecting")
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-s.quitChan:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+
+		if s.metrics != nil {
+			s.metrics.AddConnectionReconnects(s.config.name)
+		}
+
+		if err := s.connectFn(); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"name":    s.config.name,
+				"attempt": attempt + 1,
+			}).Warn("rabbit: error reconnecting")
+
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"name":    s.config.name,
+			"attempt": attempt + 1,
+		}).Info("rabbit: reconnected")
+
+		return true
+	}
+}
+
+// backoff возвращает задержку перед попыткой переподключения с номером attempt (с нуля):
+// задержка удваивается с каждой попыткой, но не превышает maxInterval.
+// Половина задержки случайна, чтобы несколько экземпляров не переподключались одновременно.
+func (s *Worker) backoff(attempt int) time.Duration {
+	delay := s.reconnect.maxInterval
+
+	// ограничиваем сдвиг, чтобы не получить переполнение
+	if attempt < 32 {
+		if d := s.reconnect.minInterval << attempt; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	half := delay / 2
+
+	return half + rand.N(delay-half+1) //nolint:gosec // для джиттера не нужен криптостойкий генератор
+}
@@ -0,0 +1,183 @@
+package rabbit
+
+import (
+	"context"
+	"db-worker/internal/service/worker/rabbit/mocks"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWorker(t *testing.T, opts ...Option) *Worker {
+	t.Helper()
+
+	w, err := New(append([]Option{
+		WithName("test"),
+		WithAddress("amqp://localhost:5672"),
+		WithExchange("test"),
+		WithQueue("test"),
+		WithRoutingKey("test"),
+		WithInsertTimeout(1),
+		WithReadTimeout(1),
+		WithReconnectInterval(time.Millisecond, 4*time.Millisecond),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	return w
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWorker(t, WithReconnectInterval(100*time.Millisecond, time.Second))
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 1, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 3, min: 400 * time.Millisecond, max: 800 * time.Millisecond},
+		{attempt: 4, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 100, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 100 {
+			got := w.backoff(tt.attempt)
+			assert.GreaterOrEqual(t, got, tt.min, "attempt %d", tt.attempt)
+			assert.LessOrEqual(t, got, tt.max, "attempt %d", tt.attempt)
+		}
+	}
+}
+
+func TestNew_ReconnectInterval(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWorker(t, WithReconnectInterval(0, 0))
+	assert.Equal(t, defaultReconnectMinInterval, w.reconnect.minInterval)
+	assert.Equal(t, defaultReconnectMaxInterval, w.reconnect.maxInterval)
+
+	_, err := New(
+		WithName("test"),
+		WithAddress("amqp://localhost:5672"),
+		WithExchange("test"),
+		WithQueue("test"),
+		WithRoutingKey("test"),
+		WithInsertTimeout(1),
+		WithReadTimeout(1),
+		WithReconnectInterval(time.Minute, time.Second),
+	)
+	require.Error(t, err)
+}
+
+func TestReconnectWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockconnectionMetrics(ctrl)
+
+	w := newTestWorker(t, WithMetrics(metrics))
+
+	attempts := 0
+	w.connectFn = func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+
+		w.setConnected(true)
+
+		return nil
+	}
+
+	metrics.EXPECT().AddConnectionReconnects("test").Times(3)
+	metrics.EXPECT().SetConnectionStatus("test", true).Times(1)
+
+	require.True(t, w.reconnectWithBackoff(context.Background()))
+	assert.Equal(t, 3, attempts)
+	assert.True(t, w.Connected())
+}
+
+func TestReconnectWithBackoff_Stopped(t *testing.T) {
+	t.Parallel()
+
+	w := newTestWorker(t, WithReconnectInterval(time.Hour, time.Hour))
+	w.connectFn = func() error {
+		t.Fatal("connect must not be called")
+		return nil
+	}
+
+	close(w.quitChan)
+
+	assert.False(t, w.reconnectWithBackoff(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w = newTestWorker(t, WithReconnectInterval(time.Hour, time.Hour))
+	assert.False(t, w.reconnectWithBackoff(ctx))
+}
+
+// TestRun_Reconnect проверяет, что после потери соединения чтение сообщений продолжается без перезапуска.
+func TestRun_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	metrics := mocks.NewMockconnectionMetrics(ctrl)
+
+	metrics.EXPECT().SetConnectionStatus("test", false).Times(1)
+	metrics.EXPECT().SetConnectionStatus("test", true).Times(1)
+	metrics.EXPECT().AddConnectionReconnects("test").Times(1)
+
+	w := newTestWorker(t, WithMetrics(metrics), WithAckPolicy(AckOnReceive))
+
+	connClose := make(chan *amqp.Error, 1)
+	w.connClose = connClose
+
+	deliveries := make(chan amqp.Delivery, 1)
+	w.connectFn = func() error {
+		w.msgs = deliveries
+		w.connClose = make(chan *amqp.Error)
+		w.setConnected(true)
+
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() {
+		done <- w.Run(ctx)
+	}()
+
+	connClose <- amqp.ErrClosed
+
+	deliveries <- amqp.Delivery{Acknowledger: &fakeAcknowledger{}, Body: []byte(`{"field": "value"}`)}
+
+	select {
+	case msg := <-w.MsgChan():
+		assert.Equal(t, map[string]any{"field": "value"}, msg.Data)
+	case <-time.After(time.Second):
+		t.Fatal("message was not received after reconnect")
+	}
+
+	assert.True(t, w.Connected())
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run did not stop")
+	}
+}
@@ -0,0 +1,67 @@
+package rabbit
+
+import (
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// replyNeeded возвращает true, если отправитель ждет итог обработки: у сообщения заданы reply_to и correlation_id.
+func replyNeeded(delivery amqp.Delivery) bool {
+	return delivery.ReplyTo != "" && delivery.CorrelationId != ""
+}
+
+// reply публикует итог обработки сообщения в очередь reply_to через exchange по умолчанию.
+// Ошибка публикации только логируется: сообщение уже обработано.
+func (s *Worker) reply(delivery amqp.Delivery, res worker.Result) {
+	body, err := json.Marshal(res.Event(metadata(delivery)))
+	if err != nil {
+		logrus.WithError(err).WithField("name", s.config.name).Error("rabbit: error marshal reply")
+		return
+	}
+
+	msg := amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: delivery.CorrelationId,
+		Timestamp:     time.Now(),
+		Body:          body,
+	}
+
+	if err := s.publishFn("", delivery.ReplyTo, msg); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":           s.config.name,
+			"reply_to":       delivery.ReplyTo,
+			"correlation_id": delivery.CorrelationId,
+		}).Error("rabbit: error publish reply")
+
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"name":           s.config.name,
+		"reply_to":       delivery.ReplyTo,
+		"correlation_id": delivery.CorrelationId,
+		"transaction_id": res.TxID,
+	}).Debug("rabbit: reply published")
+}
+
+// publish публикует сообщение в канал, из которого читается очередь.
+func (s *Worker) publish(exchange, key string, msg amqp.Publishing) error {
+	s.mu.Lock()
+	ch := s.channel
+	s.mu.Unlock()
+
+	if ch == nil {
+		return fmt.Errorf("rabbit: channel is not opened")
+	}
+
+	if err := ch.Publish(exchange, key, false, false, msg); err != nil {
+		return fmt.Errorf("rabbit: error publish: %w", err)
+	}
+
+	return nil
+}
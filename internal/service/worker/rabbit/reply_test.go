@@ -0,0 +1,173 @@
+package rabbit
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReplies запоминает опубликованные ответы.
+type fakeReplies struct {
+	mu        sync.Mutex
+	err       error // ошибка, которую вернет publish
+	exchanges []string
+	keys      []string
+	published []amqp.Publishing
+}
+
+func (r *fakeReplies) publish(exchange, key string, msg amqp.Publishing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	r.exchanges = append(r.exchanges, exchange)
+	r.keys = append(r.keys, key)
+	r.published = append(r.published, msg)
+
+	return nil
+}
+
+//nolint:funlen // это тест
+func TestSend_Reply(t *testing.T) {
+	t.Parallel()
+
+	ids := []uuid.UUID{uuid.MustParse("8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60")}
+
+	tests := []struct {
+		name      string
+		policy    AckPolicy
+		delivery  amqp.Delivery
+		res       worker.Result
+		wantReply string // тело ответа. Пустое - ответ не публикуется
+		wantAcks  ackCalls
+	}{
+		{
+			name:   "positive case: committed",
+			policy: AckOnCommit,
+			delivery: amqp.Delivery{
+				MessageId:     "msg-1",
+				CorrelationId: "corr-1",
+				ReplyTo:       "amq.rabbitmq.reply-to",
+			},
+			res: worker.Result{Operation: "create_notes", IDs: ids, TxID: "tx-1", Status: "SUCCESS"},
+			wantReply: `{
+				"operation": "create_notes",
+				"message_id": "msg-1",
+				"correlation_id": "corr-1",
+				"transaction_id": "tx-1",
+				"status": "SUCCESS",
+				"message_ids": ["8c2f1f2a-7a8f-4a8e-9a57-1b2c3d4e5f60"]
+			}`,
+			wantAcks: ackCalls{acks: 1},
+		},
+		{
+			name:   "positive case: failed transaction",
+			policy: AckOnCommit,
+			delivery: amqp.Delivery{
+				CorrelationId: "corr-1",
+				ReplyTo:       "replies",
+			},
+			res: worker.Result{Operation: "create_notes", TxID: "tx-1", Status: "FAILED", FailedDriver: "notes", Err: errors.New("duplicate key")},
+			wantReply: `{
+				"operation": "create_notes",
+				"correlation_id": "corr-1",
+				"transaction_id": "tx-1",
+				"status": "FAILED",
+				"failed_driver": "notes",
+				"error": "duplicate key"
+			}`,
+			wantAcks: ackCalls{nacks: 1},
+		},
+		{
+			name:   "positive case: reply with on_receive policy",
+			policy: AckOnReceive,
+			delivery: amqp.Delivery{
+				CorrelationId: "corr-1",
+				ReplyTo:       "replies",
+			},
+			res:       worker.Result{Operation: "create_notes", TxID: "tx-1", Status: "SUCCESS"},
+			wantReply: `{"operation": "create_notes", "correlation_id": "corr-1", "transaction_id": "tx-1", "status": "SUCCESS"}`,
+			wantAcks:  ackCalls{acks: 1},
+		},
+		{
+			name:     "no reply without correlation id",
+			policy:   AckOnCommit,
+			delivery: amqp.Delivery{ReplyTo: "replies"},
+			res:      worker.Result{TxID: "tx-1", Status: "SUCCESS"},
+			wantAcks: ackCalls{acks: 1},
+		},
+		{
+			name:     "no reply without reply_to",
+			policy:   AckOnCommit,
+			delivery: amqp.Delivery{CorrelationId: "corr-1"},
+			res:      worker.Result{TxID: "tx-1", Status: "SUCCESS"},
+			wantAcks: ackCalls{acks: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			replies := &fakeReplies{}
+
+			w := testWorker(tt.policy)
+			w.publishFn = replies.publish
+
+			acknowledger := &fakeAcknowledger{}
+			tt.delivery.Acknowledger = acknowledger
+			tt.delivery.DeliveryTag = 1
+
+			w.send(t.Context(), tt.delivery, map[string]any{"field": "value"}, nil)
+
+			msg := <-w.MsgChan()
+			msg.Done(tt.res)
+
+			assert.Equal(t, tt.wantAcks, acknowledger.ackCalls)
+
+			if tt.wantReply == "" {
+				assert.Empty(t, replies.published)
+				return
+			}
+
+			require.Len(t, replies.published, 1)
+
+			// ответ уходит через exchange по умолчанию прямо в очередь reply_to
+			assert.Empty(t, replies.exchanges[0])
+			assert.Equal(t, tt.delivery.ReplyTo, replies.keys[0])
+			assert.Equal(t, tt.delivery.CorrelationId, replies.published[0].CorrelationId)
+			assert.Equal(t, "application/json", replies.published[0].ContentType)
+			assert.JSONEq(t, tt.wantReply, string(replies.published[0].Body))
+		})
+	}
+}
+
+func TestReply_PublishError(t *testing.T) {
+	t.Parallel()
+
+	replies := &fakeReplies{err: errors.New("channel closed")}
+
+	w := &Worker{publishFn: replies.publish}
+
+	// ошибка публикации только логируется
+	w.reply(amqp.Delivery{CorrelationId: "corr-1", ReplyTo: "replies"}, worker.Result{TxID: "tx-1"})
+
+	assert.Empty(t, replies.published)
+}
+
+func TestPublish_NotConnected(t *testing.T) {
+	t.Parallel()
+
+	w := &Worker{}
+
+	require.Error(t, w.publish("", "replies", amqp.Publishing{}))
+}
@@ -2,7 +2,13 @@ package rabbit
 
 import (
 	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
@@ -17,23 +23,83 @@ type Worker struct {
 		exchange   string
 		queue      string
 		routingKey string
+
+		exchangeType ExchangeType
+		bindingKeys  []string
+		bindingArgs  amqp.Table
+		queueArgs    amqp.Table
+		passive      bool // не объявлять exchange, очередь и привязки
 	}
 
-	msgChan  chan map[string]interface{}
+	msgChan  chan worker.Message
 	quitChan chan struct{}
 
-	msgs <-chan amqp.Delivery
+	msgs  <-chan amqp.Delivery
+	codec *codec.Registry // декодирует тела сообщений
+
+	handoff     *handoff // передает сообщения в операцию по порядку
+	maxInFlight int      // сколько сообщений одновременно в обработке
+	prefetch    int      // сколько неподтвержденных сообщений брокер присылает заранее (basic.qos)
+	orderKey    string   // поле сообщения: сообщения с одним значением обрабатываются строго по очереди
+
+	// закрываются библиотекой при потере соединения или канала
+	connClose    chan *amqp.Error
+	channelClose chan *amqp.Error
 
 	// queues
 	queue amqp.Queue
 
+	mu      sync.Mutex // защищает conn и channel
 	conn    *amqp.Connection
 	channel *amqp.Channel
 
+	connected atomic.Bool
+	metrics   connectionMetrics
+
+	// connectFn подключается к RabbitMQ: по умолчанию connect, в тестах подменяется
+	connectFn func() error
+	// publishFn публикует ответы отправителям: по умолчанию publish, в тестах подменяется
+	publishFn func(exchange, key string, msg amqp.Publishing) error
+
+	reconnect struct {
+		minInterval time.Duration // задержка перед первой попыткой переподключения
+		maxInterval time.Duration // максимальная задержка между попытками
+	}
+
 	insertTimeout int
 	readTimeout   int
+
+	ackPolicy AckPolicy
+	requeue   bool // возвращать ли сообщение в очередь при ошибке обработки
 }
 
+const (
+	defaultMaxInFlight = 100
+
+	defaultReconnectMinInterval = time.Second
+	defaultReconnectMaxInterval = 30 * time.Second
+)
+
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type connectionMetrics interface {
+	SetConnectionStatus(name string, connected bool)
+	AddConnectionReconnects(name string)
+}
+
+// ExchangeType - тип exchange.
+type ExchangeType string
+
+const (
+	// ExchangeDirect - direct exchange.
+	ExchangeDirect ExchangeType = amqp.ExchangeDirect
+	// ExchangeTopic - topic exchange.
+	ExchangeTopic ExchangeType = amqp.ExchangeTopic
+	// ExchangeFanout - fanout exchange.
+	ExchangeFanout ExchangeType = amqp.ExchangeFanout
+	// ExchangeHeaders - headers exchange.
+	ExchangeHeaders ExchangeType = amqp.ExchangeHeaders
+)
+
 // Option определяет опции для Worker.
 type Option func(*Worker)
 
@@ -72,6 +138,42 @@ func WithRoutingKey(routingKey string) Option {
 	}
 }
 
+// WithExchangeType устанавливает тип exchange.
+func WithExchangeType(exchangeType ExchangeType) Option {
+	return func(w *Worker) {
+		w.config.exchangeType = exchangeType
+	}
+}
+
+// WithBindingKeys устанавливает дополнительные ключи привязки очереди к exchange.
+func WithBindingKeys(keys ...string) Option {
+	return func(w *Worker) {
+		w.config.bindingKeys = append(w.config.bindingKeys, keys...)
+	}
+}
+
+// WithBindingArgs устанавливает аргументы привязки очереди к exchange (например, x-match для headers exchange).
+func WithBindingArgs(args map[string]any) Option {
+	return func(w *Worker) {
+		w.config.bindingArgs = args
+	}
+}
+
+// WithQueueArgs устанавливает аргументы очереди (например, x-queue-type, x-message-ttl, x-dead-letter-exchange).
+func WithQueueArgs(args map[string]any) Option {
+	return func(w *Worker) {
+		w.config.queueArgs = args
+	}
+}
+
+// WithPassive включает пассивный режим: exchange, очередь и привязки не объявляются,
+// воркер читает существующую очередь.
+func WithPassive(passive bool) Option {
+	return func(w *Worker) {
+		w.config.passive = passive
+	}
+}
+
 // WithInsertTimeout устанавливает время ожидания вставки.
 func WithInsertTimeout(insertTimeout int) Option {
 	return func(w *Worker) {
@@ -86,7 +188,69 @@ func WithReadTimeout(readTimeout int) Option {
 	}
 }
 
-// New создает новый экземпляр Worker.
+// WithAckPolicy устанавливает момент подтверждения сообщения.
+func WithAckPolicy(policy AckPolicy) Option {
+	return func(w *Worker) {
+		w.ackPolicy = policy
+	}
+}
+
+// WithRequeue устанавливает, возвращать ли сообщение в очередь при ошибке обработки.
+// Если false - сообщение отклоняется и попадает в DLX, если она настроена для очереди.
+func WithRequeue(requeue bool) Option {
+	return func(w *Worker) {
+		w.requeue = requeue
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для состояния соединения.
+func WithMetrics(metrics connectionMetrics) Option {
+	return func(w *Worker) {
+		w.metrics = metrics
+	}
+}
+
+// WithCodec устанавливает форматы тела сообщений. По умолчанию JSON.
+func WithCodec(registry *codec.Registry) Option {
+	return func(w *Worker) {
+		w.codec = registry
+	}
+}
+
+// WithMaxInFlight устанавливает, сколько сообщений одновременно передано в операцию и еще не обработано.
+// Пока предел достигнут, новые сообщения из очереди не читаются.
+func WithMaxInFlight(maxInFlight int) Option {
+	return func(w *Worker) {
+		w.maxInFlight = maxInFlight
+	}
+}
+
+// WithPrefetch устанавливает, сколько неподтвержденных сообщений брокер присылает заранее (basic.qos).
+func WithPrefetch(prefetch int) Option {
+	return func(w *Worker) {
+		w.prefetch = prefetch
+	}
+}
+
+// WithOrderKey устанавливает поле сообщения, сообщения с одним значением которого обрабатываются в порядке получения.
+func WithOrderKey(field string) Option {
+	return func(w *Worker) {
+		w.orderKey = field
+	}
+}
+
+// WithReconnectInterval устанавливает минимальную и максимальную задержку между попытками переподключения.
+func WithReconnectInterval(minInterval, maxInterval time.Duration) Option {
+	return func(w *Worker) {
+		w.reconnect.minInterval = minInterval
+		w.reconnect.maxInterval = maxInterval
+	}
+}
+
+// New создает новый экземпляр Worker. Если тип exchange не задан - используется ExchangeTopic.
+// Если политика подтверждения не задана - используется AckOnCommit.
+// Если не задан предел сообщений в обработке - используется 100, prefetch по умолчанию равен этому пределу.
+// Если не заданы задержки переподключения - используются 1s и 30s.
 func New(opts ...Option) (*Worker, error) {
 	w := &Worker{}
 
@@ -110,36 +274,85 @@ func New(opts ...Option) (*Worker, error) {
 		return nil, fmt.Errorf("rabbit: address is required")
 	}
 
-	if w.config.exchange == "" {
-		return nil, fmt.Errorf("rabbit: exchange is required")
-	}
-
 	if w.config.queue == "" {
 		return nil, fmt.Errorf("rabbit: queue is required")
 	}
 
-	if w.config.routingKey == "" {
-		return nil, fmt.Errorf("rabbit: routing key is required")
+	if err := w.validateBindings(); err != nil {
+		return nil, err
+	}
+
+	if w.ackPolicy == "" {
+		w.ackPolicy = AckOnCommit
+	}
+
+	if w.ackPolicy != AckOnReceive && w.ackPolicy != AckOnPersisted && w.ackPolicy != AckOnCommit {
+		return nil, fmt.Errorf("rabbit: unknown ack policy: %s", w.ackPolicy)
+	}
+
+	if w.codec == nil {
+		w.codec = codec.JSON()
+	}
+
+	if w.maxInFlight < 0 {
+		return nil, fmt.Errorf("rabbit: max in flight must not be negative: %d", w.maxInFlight)
+	}
+
+	if w.prefetch < 0 {
+		return nil, fmt.Errorf("rabbit: prefetch must not be negative: %d", w.prefetch)
+	}
+
+	if w.maxInFlight == 0 {
+		w.maxInFlight = defaultMaxInFlight
+	}
+
+	if w.prefetch == 0 {
+		w.prefetch = w.maxInFlight
+	}
+
+	if w.reconnect.minInterval == 0 {
+		w.reconnect.minInterval = defaultReconnectMinInterval
+	}
+
+	if w.reconnect.maxInterval == 0 {
+		w.reconnect.maxInterval = defaultReconnectMaxInterval
 	}
 
-	w.msgChan = make(chan map[string]interface{})
+	if w.reconnect.minInterval > w.reconnect.maxInterval {
+		return nil, fmt.Errorf("rabbit: reconnect min interval %s is greater than max interval %s", w.reconnect.minInterval, w.reconnect.maxInterval)
+	}
+
+	w.connectFn = w.connect
+	w.publishFn = w.publish
+
+	w.msgChan = make(chan worker.Message)
 	w.quitChan = make(chan struct{})
+	w.handoff = newHandoff(w.msgChan, w.quitChan, w.maxInFlight)
 
 	return w, nil
 }
 
-// Connect соединяется с RabbitMQ.
+// Connect соединяется с RabbitMQ. При потере соединения Run переподключается самостоятельно.
 func (s *Worker) Connect() error {
+	return s.connectFn()
+}
+
+// connect открывает соединение и канал, объявляет exchange и очередь и начинает чтение сообщений.
+func (s *Worker) connect() error {
 	err := s.connectQueue()
 	if err != nil {
+		s.closeConnection()
 		return fmt.Errorf("rabbit: error connecting queue: %w", err)
 	}
 
 	err = s.connectChannel()
 	if err != nil {
+		s.closeConnection()
 		return fmt.Errorf("rabbit: error connecting channel: %w", err)
 	}
 
+	s.setConnected(true)
+
 	return nil
 }
 
@@ -149,52 +362,42 @@ func (s *Worker) connectQueue() error {
 		return fmt.Errorf("rabbit: error creating connection: %w", err)
 	}
 
+	s.mu.Lock()
 	s.conn = conn
+	s.mu.Unlock()
 
 	ch, err := conn.Channel()
 	if err != nil {
 		return fmt.Errorf("rabbit: error creating channel: %w", err)
 	}
 
+	s.mu.Lock()
 	s.channel = ch
+	s.mu.Unlock()
 
-	// Объявляем exchange
-	err = ch.ExchangeDeclare(
-		s.config.exchange, // name
-		"topic",           // type
-		true,              // durable
-		false,             // auto-delete
-		false,             // internal
-		false,             // no-wait
-		nil,               // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("error creating exchange %s: %w", s.config.exchange, err)
-	}
-
-	// Создаем queue
-	s.queue, err = ch.QueueDeclare(
-		s.config.queue, // name
-		true,           // durable
-		false,          // delete when unused
-		false,          // exclusive
-		false,          // no-wait
-		nil,            // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("error creating queue: %w", err)
+	s.connClose = conn.NotifyClose(make(chan *amqp.Error, 1))
+	s.channelClose = ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	// брокер не присылает больше prefetch неподтвержденных сообщений
+	if err := ch.Qos(s.prefetch, 0, false); err != nil {
+		return fmt.Errorf("rabbit: error set prefetch: %w", err)
 	}
 
-	// Привязываем queue к exchange с routing key
-	err = ch.QueueBind(
-		s.queue.Name,        // queue name
-		s.config.routingKey, // routing key
-		s.config.exchange,   // exchange
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("error binding queue to exchange: %w", err)
+	if s.config.passive {
+		// проверяем, что очередь существует
+		s.queue, err = ch.QueueDeclarePassive(
+			s.config.queue, // name
+			true,           // durable
+			false,          // delete when unused
+			false,          // exclusive
+			false,          // no-wait
+			nil,            // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("error checking queue %s: %w", s.config.queue, err)
+		}
+	} else if err := s.declareQueue(ch); err != nil {
+		return err
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -203,8 +406,16 @@ func (s *Worker) connectQueue() error {
 		"exchange":       s.config.exchange,
 		"queue":          s.config.queue,
 		"routing_key":    s.config.routingKey,
+		"exchange_type":  s.config.exchangeType,
+		"binding_keys":   s.bindingKeys(),
+		"passive":        s.config.passive,
 		"insert_timeout": s.insertTimeout,
 		"read_timeout":   s.readTimeout,
+		"ack_policy":     s.ackPolicy,
+		"requeue":        s.requeue,
+		"max_in_flight":  s.maxInFlight,
+		"prefetch":       s.prefetch,
+		"order_key":      s.orderKey,
 	}).Info("successfully connected rabbit")
 
 	return nil
@@ -216,7 +427,7 @@ func (s *Worker) Name() string {
 }
 
 // MsgChan возвращает канал для получения сообщений.
-func (s *Worker) MsgChan() chan map[string]interface{} {
+func (s *Worker) MsgChan() chan worker.Message {
 	return s.msgChan
 }
 
@@ -245,18 +456,54 @@ func (s *Worker) Address() string {
 	return s.config.address
 }
 
+// Connected возвращает true, если соединение с RabbitMQ установлено.
+func (s *Worker) Connected() bool {
+	return s.connected.Load()
+}
+
+func (s *Worker) setConnected(connected bool) {
+	s.connected.Store(connected)
+
+	if s.metrics != nil {
+		s.metrics.SetConnectionStatus(s.config.name, connected)
+	}
+}
+
 // Stop закрывает соединение с RabbitMQ.
 func (s *Worker) Stop(_ context.Context) error {
-	err := s.channel.Close()
-	if err != nil {
-		return fmt.Errorf("worker: error closing channel rabbit mq: %w", err)
-	}
+	// сначала прекращаем чтение, чтобы Run не начал переподключаться после закрытия соединения
+	close(s.quitChan)
 
-	if err := s.conn.Close(); err != nil {
-		return fmt.Errorf("worker: error closing connection rabbit mq: %w", err)
+	s.setConnected(false)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.channel != nil {
+		if err := s.channel.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			return fmt.Errorf("worker: error closing channel rabbit mq: %w", err)
+		}
 	}
 
-	close(s.quitChan)
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			return fmt.Errorf("worker: error closing connection rabbit mq: %w", err)
+		}
+	}
 
 	return nil
 }
+
+// closeConnection закрывает соединение после его потери. Ошибки не важны: соединение уже не работает.
+func (s *Worker) closeConnection() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.channel != nil {
+		_ = s.channel.Close()
+	}
+
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+}
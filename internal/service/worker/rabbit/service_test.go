@@ -1,6 +1,7 @@
 package rabbit
 
 import (
+	"db-worker/internal/service/worker"
 	"testing"
 
 	"github.com/streadway/amqp"
@@ -31,11 +32,16 @@ func TestNew(t *testing.T) {
 			},
 			want: &Worker{
 				config: struct {
-					address    string
-					name       string
-					exchange   string
-					queue      string
-					routingKey string
+					address      string
+					name         string
+					exchange     string
+					queue        string
+					routingKey   string
+					exchangeType ExchangeType
+					bindingKeys  []string
+					bindingArgs  amqp.Table
+					queueArgs    amqp.Table
+					passive      bool
 				}{
 					address:    "address",
 					name:       "name",
@@ -43,7 +49,7 @@ func TestNew(t *testing.T) {
 					queue:      "queue",
 					routingKey: "routingKey",
 				},
-				msgChan: make(chan map[string]interface{}),
+				msgChan: make(chan worker.Message),
 				queue: amqp.Queue{
 					Name: "queue",
 				},
@@ -54,6 +60,48 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: require.NoError,
 		},
+		{
+			name: "negative case: unknown ack policy",
+			opts: []Option{
+				WithName("test"),
+				WithAddress("test"),
+				WithExchange("test"),
+				WithRoutingKey("test"),
+				WithQueue("test"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+				WithAckPolicy("unknown"),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: max in flight is negative",
+			opts: []Option{
+				WithName("test"),
+				WithAddress("test"),
+				WithExchange("test"),
+				WithRoutingKey("test"),
+				WithQueue("test"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+				WithMaxInFlight(-1),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: prefetch is negative",
+			opts: []Option{
+				WithName("test"),
+				WithAddress("test"),
+				WithExchange("test"),
+				WithRoutingKey("test"),
+				WithQueue("test"),
+				WithInsertTimeout(1),
+				WithReadTimeout(1),
+				WithPrefetch(-1),
+			},
+			wantErr: require.Error,
+		},
 		{
 			name: "negative case: read timeout is 0",
 			opts: []Option{
@@ -133,11 +181,43 @@ func TestNew(t *testing.T) {
 				assert.ObjectsAreEqual(tt.want, got)
 				assert.NotNil(t, got.msgChan)
 				assert.NotNil(t, got.quitChan)
+				assert.Equal(t, AckOnCommit, got.ackPolicy)
 			}
 		})
 	}
 }
 
+func TestNew_InFlight(t *testing.T) {
+	t.Parallel()
+
+	opts := []Option{
+		WithName("test"),
+		WithAddress("test"),
+		WithExchange("test"),
+		WithQueue("test"),
+		WithRoutingKey("test"),
+		WithInsertTimeout(1),
+		WithReadTimeout(1),
+	}
+
+	// prefetch по умолчанию равен пределу сообщений в обработке
+	w, err := New(opts...)
+	require.NoError(t, err)
+	assert.Equal(t, defaultMaxInFlight, w.maxInFlight)
+	assert.Equal(t, defaultMaxInFlight, w.prefetch)
+	assert.Equal(t, defaultMaxInFlight, cap(w.handoff.slots))
+
+	w, err = New(append(opts, WithMaxInFlight(5))...)
+	require.NoError(t, err)
+	assert.Equal(t, 5, w.prefetch)
+
+	w, err = New(append(opts, WithMaxInFlight(5), WithPrefetch(20), WithOrderKey("user_id"))...)
+	require.NoError(t, err)
+	assert.Equal(t, 5, w.maxInFlight)
+	assert.Equal(t, 20, w.prefetch)
+	assert.Equal(t, "user_id", w.orderKey)
+}
+
 func TestName(t *testing.T) {
 	t.Parallel()
 
@@ -149,6 +229,12 @@ func TestName(t *testing.T) {
 			exchange   string
 			queue      string
 			routingKey string
+
+			exchangeType ExchangeType
+			bindingKeys  []string
+			bindingArgs  amqp.Table
+			queueArgs    amqp.Table
+			passive      bool
 		}{
 			address:    "address",
 			name:       "name",
@@ -164,7 +250,7 @@ func TestName(t *testing.T) {
 func TestMsgChan(t *testing.T) {
 	t.Parallel()
 
-	msgChan := make(chan map[string]interface{})
+	msgChan := make(chan worker.Message)
 
 	rabbit := &Worker{
 		msgChan: msgChan,
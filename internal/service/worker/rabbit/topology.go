@@ -0,0 +1,110 @@
+package rabbit
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/streadway/amqp"
+)
+
+// validateBindings проверяет настройки exchange и привязок. Устанавливает тип exchange по умолчанию.
+func (s *Worker) validateBindings() error {
+	if err := s.config.queueArgs.Validate(); err != nil {
+		return fmt.Errorf("rabbit: invalid queue args: %w", err)
+	}
+
+	// в пассивном режиме exchange и привязки не используются
+	if s.config.passive {
+		return nil
+	}
+
+	if s.config.exchange == "" {
+		return fmt.Errorf("rabbit: exchange is required")
+	}
+
+	if s.config.exchangeType == "" {
+		s.config.exchangeType = ExchangeTopic
+	}
+
+	switch s.config.exchangeType {
+	case ExchangeDirect, ExchangeTopic:
+		if len(s.bindingKeys()) == 0 {
+			return fmt.Errorf("rabbit: routing key is required for %s exchange", s.config.exchangeType)
+		}
+	case ExchangeFanout, ExchangeHeaders:
+	default:
+		return fmt.Errorf("rabbit: unknown exchange type: %s", s.config.exchangeType)
+	}
+
+	if err := s.config.bindingArgs.Validate(); err != nil {
+		return fmt.Errorf("rabbit: invalid binding args: %w", err)
+	}
+
+	return nil
+}
+
+// bindingKeys возвращает ключи привязки очереди к exchange: routing key и дополнительные ключи без повторов.
+func (s *Worker) bindingKeys() []string {
+	keys := make([]string, 0, len(s.config.bindingKeys)+1)
+
+	if s.config.routingKey != "" {
+		keys = append(keys, s.config.routingKey)
+	}
+
+	for _, key := range s.config.bindingKeys {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// declareQueue объявляет exchange и очередь и привязывает очередь ко всем ключам.
+// Для fanout и headers exchange без ключей очередь привязывается с пустым ключом.
+func (s *Worker) declareQueue(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		s.config.exchange,             // name
+		string(s.config.exchangeType), // type
+		true,                          // durable
+		false,                         // auto-delete
+		false,                         // internal
+		false,                         // no-wait
+		nil,                           // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("error creating exchange %s: %w", s.config.exchange, err)
+	}
+
+	s.queue, err = ch.QueueDeclare(
+		s.config.queue,     // name
+		true,               // durable
+		false,              // delete when unused
+		false,              // exclusive
+		false,              // no-wait
+		s.config.queueArgs, // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("error creating queue: %w", err)
+	}
+
+	keys := s.bindingKeys()
+	if len(keys) == 0 {
+		keys = []string{""}
+	}
+
+	for _, key := range keys {
+		err = ch.QueueBind(
+			s.queue.Name,         // queue name
+			key,                  // routing key
+			s.config.exchange,    // exchange
+			false,                // no-wait
+			s.config.bindingArgs, // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("error binding queue to exchange with key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package rabbit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // это тест
+func TestNew_Bindings(t *testing.T) {
+	t.Parallel()
+
+	base := []Option{
+		WithName("test"),
+		WithAddress("amqp://localhost:5672"),
+		WithQueue("test"),
+		WithInsertTimeout(1),
+		WithReadTimeout(1),
+	}
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantType     ExchangeType
+		wantBindings []string
+		wantErr      require.ErrorAssertionFunc
+	}{
+		{
+			name:         "positive case: default topic exchange",
+			opts:         []Option{WithExchange("notes"), WithRoutingKey("create")},
+			wantType:     ExchangeTopic,
+			wantBindings: []string{"create"},
+			wantErr:      require.NoError,
+		},
+		{
+			name: "positive case: direct exchange with several keys",
+			opts: []Option{
+				WithExchange("notes"),
+				WithExchangeType(ExchangeDirect),
+				WithRoutingKey("create"),
+				WithBindingKeys("update", "create", "", "delete"),
+			},
+			wantType:     ExchangeDirect,
+			wantBindings: []string{"create", "update", "delete"},
+			wantErr:      require.NoError,
+		},
+		{
+			name:         "positive case: fanout exchange without keys",
+			opts:         []Option{WithExchange("notes"), WithExchangeType(ExchangeFanout)},
+			wantType:     ExchangeFanout,
+			wantBindings: []string{},
+			wantErr:      require.NoError,
+		},
+		{
+			name: "positive case: headers exchange with binding args",
+			opts: []Option{
+				WithExchange("notes"),
+				WithExchangeType(ExchangeHeaders),
+				WithBindingArgs(map[string]any{"x-match": "all", "operation": "create"}),
+			},
+			wantType:     ExchangeHeaders,
+			wantBindings: []string{},
+			wantErr:      require.NoError,
+		},
+		{
+			name: "positive case: queue args",
+			opts: []Option{
+				WithExchange("notes"),
+				WithRoutingKey("create"),
+				WithQueueArgs(map[string]any{
+					"x-queue-type":           "quorum",
+					"x-message-ttl":          60000,
+					"x-max-length":           1000,
+					"x-dead-letter-exchange": "notes.dlx",
+				}),
+			},
+			wantType:     ExchangeTopic,
+			wantBindings: []string{"create"},
+			wantErr:      require.NoError,
+		},
+		{
+			name:         "positive case: passive mode without exchange and keys",
+			opts:         []Option{WithPassive(true)},
+			wantBindings: []string{},
+			wantErr:      require.NoError,
+		},
+		{
+			name:    "negative case: direct exchange without keys",
+			opts:    []Option{WithExchange("notes"), WithExchangeType(ExchangeDirect)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: unknown exchange type",
+			opts:    []Option{WithExchange("notes"), WithExchangeType("unknown"), WithRoutingKey("create")},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: unsupported queue arg",
+			opts: []Option{
+				WithExchange("notes"),
+				WithRoutingKey("create"),
+				WithQueueArgs(map[string]any{"x-arg": map[any]any{"nested": 1}}),
+			},
+			wantErr: require.Error,
+		},
+		{
+			name: "negative case: unsupported binding arg",
+			opts: []Option{
+				WithExchange("notes"),
+				WithExchangeType(ExchangeHeaders),
+				WithBindingArgs(map[string]any{"x-arg": struct{}{}}),
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append(append([]Option{}, base...), tt.opts...)
+
+			w, err := New(opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantType, w.config.exchangeType)
+			assert.Equal(t, tt.wantBindings, w.bindingKeys())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package redisstream
+
+import (
+	"db-worker/internal/service/worker"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ackNotifier подтверждает запись стрима (XACK) после успешной обработки.
+// Если обработка не удалась, запись остается в pending и позже забирается через XAUTOCLAIM.
+type ackNotifier struct {
+	worker *Worker
+	id     string
+
+	once sync.Once
+}
+
+// Persisted реализует worker.Notifier. Запись подтверждается только после завершения транзакции.
+func (n *ackNotifier) Persisted(_ []uuid.UUID, err error) {
+	if err != nil {
+		n.release(err)
+	}
+}
+
+// Done реализует worker.Notifier.
+// Сообщения, не прошедшие валидацию, тоже подтверждаются: повторная обработка не поможет.
+func (n *ackNotifier) Done(res worker.Result) {
+	switch {
+	case res.Err == nil:
+		n.once.Do(func() { n.worker.ack(n.id) })
+	case errors.Is(res.Err, worker.ErrInvalidMessage):
+		n.once.Do(func() {
+			logrus.WithError(res.Err).WithFields(logrus.Fields{
+				"name": n.worker.config.name,
+				"id":   n.id,
+			}).Warn("redis stream: drop invalid message")
+
+			n.worker.ack(n.id)
+		})
+	default:
+		n.release(res.Err)
+	}
+}
+
+// release оставляет запись неподтвержденной: её заберет XAUTOCLAIM после claimMinIdle.
+func (n *ackNotifier) release(cause error) {
+	n.once.Do(func() {
+		logrus.WithError(cause).WithFields(logrus.Fields{
+			"name": n.worker.config.name,
+			"id":   n.id,
+		}).Warn("redis stream: message left pending")
+
+		n.worker.finishProcessing(n.id)
+	})
+}
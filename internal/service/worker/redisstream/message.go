@@ -0,0 +1,290 @@
+package redisstream
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Run читает новые записи стрима и периодически забирает записи, брошенные другими потребителями группы.
+// При ошибке Redis ждет retryInterval и повторяет запрос.
+func (s *Worker) Run(ctx context.Context) error {
+	logrus.WithFields(logrus.Fields{
+		"name":     s.config.name,
+		"stream":   s.config.stream,
+		"group":    s.config.group,
+		"consumer": s.config.consumer,
+	}).Info("redis stream: start consume messages")
+
+	var lastClaim time.Time // нулевое значение: при запуске сразу забираем свои необработанные записи
+
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.WithField("name", s.config.name).Info("redis stream: ctx done: stop consume messages")
+			return nil
+		case <-s.quitChan:
+			logrus.WithField("name", s.config.name).Info("redis stream: quit chan: stop consume messages")
+			return nil
+		default:
+		}
+
+		var err error
+
+		if time.Since(lastClaim) >= s.config.claimInterval {
+			err = s.claim(ctx)
+			if err == nil {
+				lastClaim = time.Now()
+			}
+		}
+
+		if err == nil {
+			err = s.read(ctx)
+		}
+
+		if err != nil {
+			if !s.handleError(ctx, err) {
+				return nil
+			}
+
+			continue
+		}
+
+		if !s.Connected() {
+			if s.metrics != nil {
+				s.metrics.AddConnectionReconnects(s.config.name)
+			}
+
+			logrus.WithField("name", s.config.name).Info("redis stream: reconnected")
+
+			s.setConnected(true)
+		}
+	}
+}
+
+// read читает новые записи стрима. Если записей нет - ждет их не дольше readTimeout.
+func (s *Worker) read(ctx context.Context) error {
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.config.group,
+		Consumer: s.config.consumer,
+		Streams:  []string{s.config.stream, ">"},
+		Count:    s.config.batchSize,
+		Block:    time.Duration(s.readTimeout) * time.Millisecond,
+	}).Result()
+	if errors.Is(err, redis.Nil) { // новых записей нет
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error read group: %w", err)
+	}
+
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			s.handle(ctx, msg)
+		}
+	}
+
+	return nil
+}
+
+// claim забирает записи, которые дольше claimMinIdle не подтверждены потребителями группы:
+// потребитель мог упасть, не обработав их.
+func (s *Worker) claim(ctx context.Context) error {
+	start := "0-0"
+
+	for {
+		msgs, next, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   s.config.stream,
+			Group:    s.config.group,
+			Consumer: s.config.consumer,
+			MinIdle:  s.config.claimMinIdle,
+			Start:    start,
+			Count:    s.config.batchSize,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("error auto claim: %w", err)
+		}
+
+		if len(msgs) > 0 {
+			logrus.WithFields(logrus.Fields{
+				"name":  s.config.name,
+				"count": len(msgs),
+			}).Info("redis stream: claimed pending messages")
+		}
+
+		for _, msg := range msgs {
+			s.handle(ctx, msg)
+		}
+
+		if next == "0-0" || next == "" {
+			return nil
+		}
+
+		start = next
+	}
+}
+
+// handle передает запись в операцию. Запись, которую нельзя разобрать, тоже передается:
+// ее завершит диспетчер соединения.
+func (s *Worker) handle(ctx context.Context, msg redis.XMessage) {
+	if !s.startProcessing(msg.ID) {
+		return // запись уже обрабатывается: XAUTOCLAIM вернул её повторно
+	}
+
+	meta := s.metadata(msg)
+
+	out := worker.Message{
+		Meta:     meta,
+		Notifier: &ackNotifier{worker: s, id: msg.ID},
+	}
+
+	data, raw, err := s.decode(msg, meta.ContentType)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name":         s.config.name,
+			"id":           msg.ID,
+			"content_type": meta.ContentType,
+		}).Error("redis stream: error decode message")
+
+		out.Raw = raw
+		out.DecodeErr = err
+	}
+
+	out.Data = data
+
+	logrus.WithFields(logrus.Fields{
+		"name":    s.config.name,
+		"id":      msg.ID,
+		"message": data,
+	}).Debug("redis stream: received message")
+
+	select {
+	case s.msgChan <- out:
+	case <-ctx.Done():
+		s.finishProcessing(msg.ID) // запись остается в pending и будет забрана повторно
+	case <-s.quitChan:
+		s.finishProcessing(msg.ID)
+	}
+}
+
+// decode декодирует тело записи. Возвращает и исходное тело, если поле с ним найдено.
+func (s *Worker) decode(msg redis.XMessage, contentType string) (map[string]any, []byte, error) {
+	value, ok := msg.Values[s.config.field]
+	if !ok {
+		return nil, nil, fmt.Errorf("field %q not found", s.config.field)
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("field %q: unexpected type %T", s.config.field, value)
+	}
+
+	raw := []byte(str)
+
+	data, err := s.codec.Decode(contentType, raw)
+	if err != nil {
+		return nil, raw, fmt.Errorf("field %q: %w", s.config.field, err)
+	}
+
+	return data, raw, nil
+}
+
+// metadata собирает метаданные записи: айди и время из айди записи, остальные поля записи - заголовки.
+func (s *Worker) metadata(msg redis.XMessage) worker.Metadata {
+	meta := worker.Metadata{MessageID: msg.ID}
+
+	if ms, _, ok := strings.Cut(msg.ID, "-"); ok {
+		if n, err := strconv.ParseInt(ms, 10, 64); err == nil {
+			meta.Timestamp = time.UnixMilli(n)
+		}
+	}
+
+	for key, value := range msg.Values {
+		if key == s.config.field {
+			continue
+		}
+
+		if meta.Headers == nil {
+			meta.Headers = make(map[string]any, len(msg.Values)-1)
+		}
+
+		meta.Headers[key] = value
+
+		switch key {
+		case correlationIDField:
+			meta.CorrelationID, _ = value.(string)
+		case contentTypeField:
+			meta.ContentType, _ = value.(string)
+		}
+	}
+
+	return meta
+}
+
+// ack подтверждает запись и убирает её из списка обрабатываемых.
+func (s *Worker) ack(id string) {
+	defer s.finishProcessing(id)
+
+	ctx, cancel := s.writeCtx()
+	defer cancel()
+
+	if err := s.client.XAck(ctx, s.config.stream, s.config.group, id).Err(); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"name": s.config.name,
+			"id":   id,
+		}).Error("redis stream: error ack message")
+	}
+}
+
+func (s *Worker) startProcessing(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inFlight[id]; ok {
+		return false
+	}
+
+	s.inFlight[id] = struct{}{}
+
+	return true
+}
+
+func (s *Worker) finishProcessing(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inFlight, id)
+}
+
+// handleError помечает соединение разорванным и ждет retryInterval.
+// Возвращает false, если работа завершена раньше.
+func (s *Worker) handleError(ctx context.Context, err error) bool {
+	if s.Connected() {
+		s.setConnected(false)
+	}
+
+	logrus.WithError(err).WithFields(logrus.Fields{
+		"name":  s.config.name,
+		"delay": s.config.retryInterval,
+	}).Warn("redis stream: error consume messages")
+
+	timer := time.NewTimer(s.config.retryInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.quitChan:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,275 @@
+package redisstream
+
+import (
+	"context"
+	"db-worker/internal/service/worker"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStream = "notes"
+	testGroup  = "db-worker"
+)
+
+func newTestWorker(t *testing.T, client redis.UniversalClient, consumer string, opts ...Option) *Worker {
+	t.Helper()
+
+	w, err := New(append([]Option{
+		WithName("test"),
+		WithClient(client),
+		WithStream(testStream),
+		WithGroup(testGroup),
+		WithConsumer(consumer),
+		WithInsertTimeout(1000),
+		WithReadTimeout(10),
+		WithRetryInterval(time.Millisecond),
+	}, opts...)...)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Connect())
+
+	return w
+}
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return srv, client
+}
+
+func addMessage(t *testing.T, client *redis.Client, data string) string {
+	t.Helper()
+
+	id, err := client.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: testStream,
+		Values: map[string]any{defaultField: data},
+	}).Result()
+	require.NoError(t, err)
+
+	return id
+}
+
+func pendingCount(t *testing.T, client *redis.Client) int64 {
+	t.Helper()
+
+	pending, err := client.XPending(t.Context(), testStream, testGroup).Result()
+	require.NoError(t, err)
+
+	return pending.Count
+}
+
+func receive(t *testing.T, w *Worker) worker.Message {
+	t.Helper()
+
+	select {
+	case msg := <-w.MsgChan():
+		return msg
+	case <-time.After(time.Second):
+		require.FailNow(t, "message not received")
+	}
+
+	return worker.Message{}
+}
+
+func runWorker(t *testing.T, w *Worker) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		assert.NoError(t, w.Run(ctx))
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestConnect_ExistingGroup(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+
+	newTestWorker(t, client, "consumer-1")
+
+	// группа уже создана первым экземпляром
+	w := newTestWorker(t, client, "consumer-2")
+	assert.True(t, w.Connected())
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		result      error
+		wantPending int64
+	}{
+		{
+			name:        "positive case: message acked",
+			wantPending: 0,
+		},
+		{
+			name:        "invalid message acked",
+			result:      fmt.Errorf("%w: error validate message", worker.ErrInvalidMessage),
+			wantPending: 0,
+		},
+		{
+			name:        "error exec requests: message left pending",
+			result:      errors.New("error exec requests"),
+			wantPending: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newTestClient(t)
+			w := newTestWorker(t, client, "consumer-1")
+
+			runWorker(t, w)
+
+			addMessage(t, client, `{"user_id": 1, "text": "note"}`)
+
+			msg := receive(t, w)
+			assert.Equal(t, map[string]any{"user_id": json.Number("1"), "text": "note"}, msg.Data)
+			assert.Equal(t, int64(1), pendingCount(t, client))
+
+			msg.Persisted(nil, nil)
+			msg.Done(worker.Result{Err: tt.result})
+
+			assert.Equal(t, tt.wantPending, pendingCount(t, client))
+		})
+	}
+}
+
+func TestRun_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+	w := newTestWorker(t, client, "consumer-1")
+
+	runWorker(t, w)
+
+	addMessage(t, client, `not json`)
+	addMessage(t, client, `{"user_id": 2}`)
+
+	// битая запись передается с исходным телом и ошибкой декодирования,
+	// диспетчер завершает ее как невалидную - она подтверждается
+	msg := receive(t, w)
+	require.Error(t, msg.DecodeErr)
+	assert.Equal(t, []byte("not json"), msg.Raw)
+	msg.Done(worker.Result{Err: fmt.Errorf("%w: %w", worker.ErrInvalidMessage, msg.DecodeErr)})
+
+	msg = receive(t, w)
+	assert.Equal(t, map[string]any{"user_id": json.Number("2")}, msg.Data)
+
+	msg.Done(worker.Result{})
+
+	assert.Equal(t, int64(0), pendingCount(t, client))
+}
+
+func TestRun_ContentType(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+	w := newTestWorker(t, client, "consumer-1")
+
+	runWorker(t, w)
+
+	body, err := cbor.Marshal(map[string]any{"user_id": 1})
+	require.NoError(t, err)
+
+	_, err = client.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: testStream,
+		Values: map[string]any{defaultField: string(body), contentTypeField: "application/cbor"},
+	}).Result()
+	require.NoError(t, err)
+
+	// формат тела выбирается по полю content_type записи
+	msg := receive(t, w)
+	require.NoError(t, msg.DecodeErr)
+	assert.Equal(t, map[string]any{"user_id": float64(1)}, msg.Data)
+	assert.Equal(t, "application/cbor", msg.Meta.ContentType)
+
+	msg.Done(worker.Result{})
+}
+
+func TestRun_ClaimPending(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestClient(t)
+
+	// первый потребитель прочитал запись и упал, не подтвердив её
+	dead := newTestWorker(t, client, "consumer-dead")
+	id := addMessage(t, client, `{"user_id": 3}`)
+
+	_, err := client.XReadGroup(t.Context(), &redis.XReadGroupArgs{
+		Group:    testGroup,
+		Consumer: dead.config.consumer,
+		Streams:  []string{testStream, ">"},
+	}).Result()
+	require.NoError(t, err)
+
+	w := newTestWorker(t, client, "consumer-alive", WithClaim(time.Millisecond, 10*time.Millisecond))
+
+	time.Sleep(5 * time.Millisecond) // запись должна пролежать дольше claimMinIdle
+
+	runWorker(t, w)
+
+	msg := receive(t, w)
+	assert.Equal(t, map[string]any{"user_id": json.Number("3")}, msg.Data)
+
+	pending, err := client.XPendingExt(t.Context(), &redis.XPendingExtArgs{
+		Stream: testStream,
+		Group:  testGroup,
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, id, pending[0].ID)
+	assert.Equal(t, "consumer-alive", pending[0].Consumer)
+
+	msg.Done(worker.Result{})
+
+	assert.Equal(t, int64(0), pendingCount(t, client))
+}
+
+func TestRun_RedisUnavailable(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestClient(t)
+	w := newTestWorker(t, client, "consumer-1")
+
+	srv.SetError("LOADING")
+
+	runWorker(t, w)
+
+	assert.Eventually(t, func() bool { return !w.Connected() }, time.Second, time.Millisecond)
+
+	srv.SetError("")
+
+	assert.Eventually(t, w.Connected, time.Second, time.Millisecond)
+}
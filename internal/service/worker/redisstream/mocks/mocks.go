@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockconnectionMetrics is a mock of connectionMetrics interface.
+type MockconnectionMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockconnectionMetricsMockRecorder
+}
+
+// MockconnectionMetricsMockRecorder is the mock recorder for MockconnectionMetrics.
+type MockconnectionMetricsMockRecorder struct {
+	mock *MockconnectionMetrics
+}
+
+// NewMockconnectionMetrics creates a new mock instance.
+func NewMockconnectionMetrics(ctrl *gomock.Controller) *MockconnectionMetrics {
+	mock := &MockconnectionMetrics{ctrl: ctrl}
+	mock.recorder = &MockconnectionMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockconnectionMetrics) EXPECT() *MockconnectionMetricsMockRecorder {
+	return m.recorder
+}
+
+// AddConnectionReconnects mocks base method.
+func (m *MockconnectionMetrics) AddConnectionReconnects(name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddConnectionReconnects", name)
+}
+
+// AddConnectionReconnects indicates an expected call of AddConnectionReconnects.
+func (mr *MockconnectionMetricsMockRecorder) AddConnectionReconnects(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConnectionReconnects", reflect.TypeOf((*MockconnectionMetrics)(nil).AddConnectionReconnects), name)
+}
+
+// SetConnectionStatus mocks base method.
+func (m *MockconnectionMetrics) SetConnectionStatus(name string, connected bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConnectionStatus", name, connected)
+}
+
+// SetConnectionStatus indicates an expected call of SetConnectionStatus.
+func (mr *MockconnectionMetricsMockRecorder) SetConnectionStatus(name, connected interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConnectionStatus", reflect.TypeOf((*MockconnectionMetrics)(nil).SetConnectionStatus), name, connected)
+}
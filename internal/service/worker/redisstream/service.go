@@ -0,0 +1,312 @@
+package redisstream
+
+import (
+	"context"
+	"db-worker/internal/service/codec"
+	"db-worker/internal/service/worker"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Worker читает сообщения из Redis Stream через группу потребителей.
+type Worker struct {
+	config struct {
+		name     string
+		stream   string
+		group    string
+		consumer string
+		field    string // поле записи, в котором лежит тело сообщения
+
+		batchSize     int64         // сколько записей читать за один запрос
+		claimMinIdle  time.Duration // через сколько необработанная запись считается брошенной
+		claimInterval time.Duration // как часто забирать брошенные записи
+		retryInterval time.Duration // пауза перед повтором после ошибки Redis
+	}
+
+	client redis.UniversalClient
+	codec  *codec.Registry // декодирует тела записей
+
+	msgChan  chan worker.Message
+	quitChan chan struct{}
+
+	// записи, которые сейчас обрабатываются: XAUTOCLAIM может вернуть их повторно
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+
+	connected atomic.Bool
+	metrics   connectionMetrics
+
+	insertTimeout int
+	readTimeout   int
+}
+
+const (
+	defaultField         = "data"
+	defaultBatchSize     = 10
+	defaultClaimMinIdle  = time.Minute
+	defaultClaimInterval = 30 * time.Second
+	defaultRetryInterval = time.Second
+
+	correlationIDField = "correlation_id"
+	contentTypeField   = "content_type"
+)
+
+//go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=mocks
+type connectionMetrics interface {
+	SetConnectionStatus(name string, connected bool)
+	AddConnectionReconnects(name string)
+}
+
+// Option определяет опции для Worker.
+type Option func(*Worker)
+
+// WithName устанавливает имя соединения.
+func WithName(name string) Option {
+	return func(w *Worker) {
+		w.config.name = name
+	}
+}
+
+// WithClient устанавливает клиент Redis.
+func WithClient(client redis.UniversalClient) Option {
+	return func(w *Worker) {
+		w.client = client
+	}
+}
+
+// WithStream устанавливает название стрима.
+func WithStream(stream string) Option {
+	return func(w *Worker) {
+		w.config.stream = stream
+	}
+}
+
+// WithGroup устанавливает название группы потребителей.
+func WithGroup(group string) Option {
+	return func(w *Worker) {
+		w.config.group = group
+	}
+}
+
+// WithConsumer устанавливает имя потребителя в группе. Имя должно быть уникальным для экземпляра сервиса.
+func WithConsumer(consumer string) Option {
+	return func(w *Worker) {
+		w.config.consumer = consumer
+	}
+}
+
+// WithField устанавливает поле записи, в котором лежит тело сообщения. По умолчанию data.
+func WithField(field string) Option {
+	return func(w *Worker) {
+		w.config.field = field
+	}
+}
+
+// WithBatchSize устанавливает, сколько записей читать за один запрос.
+func WithBatchSize(batchSize int64) Option {
+	return func(w *Worker) {
+		w.config.batchSize = batchSize
+	}
+}
+
+// WithClaim устанавливает, через сколько необработанная запись считается брошенной
+// и как часто забирать такие записи у других потребителей.
+func WithClaim(minIdle, interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.claimMinIdle = minIdle
+		w.config.claimInterval = interval
+	}
+}
+
+// WithRetryInterval устанавливает паузу перед повтором после ошибки Redis.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.config.retryInterval = interval
+	}
+}
+
+// WithCodec устанавливает форматы тела записей. По умолчанию JSON.
+func WithCodec(registry *codec.Registry) Option {
+	return func(w *Worker) {
+		w.codec = registry
+	}
+}
+
+// WithMetrics устанавливает сервис метрик для отчета о состоянии соединения.
+func WithMetrics(metrics connectionMetrics) Option {
+	return func(w *Worker) {
+		w.metrics = metrics
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания записи в Redis (мс).
+func WithInsertTimeout(insertTimeout int) Option {
+	return func(w *Worker) {
+		w.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает, сколько ждать новых записей за один запрос (мс).
+func WithReadTimeout(readTimeout int) Option {
+	return func(w *Worker) {
+		w.readTimeout = readTimeout
+	}
+}
+
+// New создает новый экземпляр Worker.
+func New(opts ...Option) (*Worker, error) {
+	w := &Worker{}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.client == nil {
+		return nil, fmt.Errorf("redis stream: client is required")
+	}
+
+	if w.config.name == "" {
+		return nil, fmt.Errorf("redis stream: name is required")
+	}
+
+	if w.config.stream == "" {
+		return nil, fmt.Errorf("redis stream: stream is required")
+	}
+
+	if w.config.group == "" {
+		return nil, fmt.Errorf("redis stream: group is required")
+	}
+
+	if w.config.consumer == "" {
+		return nil, fmt.Errorf("redis stream: consumer is required")
+	}
+
+	if w.insertTimeout == 0 {
+		return nil, fmt.Errorf("redis stream: insert timeout is required")
+	}
+
+	if w.readTimeout == 0 {
+		return nil, fmt.Errorf("redis stream: read timeout is required")
+	}
+
+	if w.config.field == "" {
+		w.config.field = defaultField
+	}
+
+	if w.codec == nil {
+		w.codec = codec.JSON()
+	}
+
+	if w.config.batchSize == 0 {
+		w.config.batchSize = defaultBatchSize
+	}
+
+	if w.config.claimMinIdle == 0 {
+		w.config.claimMinIdle = defaultClaimMinIdle
+	}
+
+	if w.config.claimInterval == 0 {
+		w.config.claimInterval = defaultClaimInterval
+	}
+
+	if w.config.retryInterval == 0 {
+		w.config.retryInterval = defaultRetryInterval
+	}
+
+	w.msgChan = make(chan worker.Message)
+	w.quitChan = make(chan struct{})
+	w.inFlight = make(map[string]struct{})
+
+	return w, nil
+}
+
+// Connect создает группу потребителей (и стрим, если его нет). Если группа уже есть - использует её.
+// Новая группа читает стрим с начала, чтобы не потерять сообщения, записанные до первого запуска.
+func (s *Worker) Connect() error {
+	ctx, cancel := s.writeCtx()
+	defer cancel()
+
+	err := s.client.XGroupCreateMkStream(ctx, s.config.stream, s.config.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("redis stream: error create group: %w", err)
+	}
+
+	s.setConnected(true)
+
+	logrus.WithFields(logrus.Fields{
+		"name":     s.config.name,
+		"stream":   s.config.stream,
+		"group":    s.config.group,
+		"consumer": s.config.consumer,
+	}).Info("redis stream: connected")
+
+	return nil
+}
+
+// Stop прекращает чтение стрима. Клиент Redis закрывает его владелец.
+func (s *Worker) Stop(_ context.Context) error {
+	close(s.quitChan)
+
+	s.setConnected(false)
+
+	return nil
+}
+
+func (s *Worker) setConnected(connected bool) {
+	s.connected.Store(connected)
+
+	if s.metrics != nil {
+		s.metrics.SetConnectionStatus(s.config.name, connected)
+	}
+}
+
+// Connected возвращает true, если последний запрос к Redis прошел успешно.
+func (s *Worker) Connected() bool {
+	return s.connected.Load()
+}
+
+// writeCtx возвращает контекст для коротких запросов к Redis (создание группы, XACK).
+func (s *Worker) writeCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(s.insertTimeout)*time.Millisecond)
+}
+
+// Name возвращает имя соединения.
+func (s *Worker) Name() string {
+	return s.config.name
+}
+
+// MsgChan возвращает канал для получения сообщений.
+func (s *Worker) MsgChan() chan worker.Message {
+	return s.msgChan
+}
+
+// Address для соответствия интерфейсу Worker. Возвращает название стрима.
+func (s *Worker) Address() string {
+	return s.config.stream
+}
+
+// Queue возвращает название группы потребителей.
+func (s *Worker) Queue() string {
+	return s.config.group
+}
+
+// RoutingKey возвращает имя потребителя в группе.
+func (s *Worker) RoutingKey() string {
+	return s.config.consumer
+}
+
+// InsertTimeout для соответствия интерфейсу Worker.
+func (s *Worker) InsertTimeout() int {
+	return s.insertTimeout
+}
+
+// ReadTimeout для соответствия интерфейсу Worker.
+func (s *Worker) ReadTimeout() int {
+	return s.readTimeout
+}
@@ -0,0 +1,111 @@
+package redisstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen // это тест
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+
+	validOpts := func(opts ...Option) []Option {
+		return append([]Option{
+			WithName("test"),
+			WithClient(client),
+			WithStream("notes"),
+			WithGroup("db-worker"),
+			WithConsumer("db-worker-1"),
+			WithInsertTimeout(1),
+			WithReadTimeout(1),
+		}, opts...)
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantField string
+		wantBatch int64
+		wantIdle  time.Duration
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "positive case: defaults",
+			opts:      validOpts(),
+			wantField: defaultField,
+			wantBatch: defaultBatchSize,
+			wantIdle:  defaultClaimMinIdle,
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "positive case: custom settings",
+			opts:      validOpts(WithField("payload"), WithBatchSize(100), WithClaim(time.Second, time.Second)),
+			wantField: "payload",
+			wantBatch: 100,
+			wantIdle:  time.Second,
+			wantErr:   require.NoError,
+		},
+		{
+			name:    "negative case: client is nil",
+			opts:    validOpts(WithClient(nil)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: stream is empty",
+			opts:    validOpts(WithStream("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: group is empty",
+			opts:    validOpts(WithGroup("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: consumer is empty",
+			opts:    validOpts(WithConsumer("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: name is empty",
+			opts:    validOpts(WithName("")),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    validOpts(WithInsertTimeout(0)),
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: read timeout is 0",
+			opts:    validOpts(WithReadTimeout(0)),
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantField, got.config.field)
+			assert.Equal(t, tt.wantBatch, got.config.batchSize)
+			assert.Equal(t, tt.wantIdle, got.config.claimMinIdle)
+			assert.Equal(t, "notes", got.Address())
+			assert.Equal(t, "db-worker", got.Queue())
+			assert.Equal(t, "db-worker-1", got.RoutingKey())
+			assert.NotNil(t, got.MsgChan())
+		})
+	}
+}
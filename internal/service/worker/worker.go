@@ -10,10 +10,11 @@ type Worker interface {
 	Connect() error
 	Run(ctx context.Context) error
 	Stop(ctx context.Context) error
-	MsgChan() chan map[string]interface{}
+	MsgChan() chan Message
 	Address() string
 	Queue() string
 	RoutingKey() string
 	InsertTimeout() int
 	ReadTimeout() int
+	Connected() bool // установлено ли соединение с источником сообщений
 }
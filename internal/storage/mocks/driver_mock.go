@@ -204,6 +204,20 @@ func (mr *MockDriverMockRecorder) ReadTimeout() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTimeout", reflect.TypeOf((*MockDriver)(nil).ReadTimeout))
 }
 
+// ReleaseSavepoint mocks base method.
+func (m *MockDriver) ReleaseSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSavepoint indicates an expected call of ReleaseSavepoint.
+func (mr *MockDriverMockRecorder) ReleaseSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSavepoint", reflect.TypeOf((*MockDriver)(nil).ReleaseSavepoint), ctx, id, name)
+}
+
 // Rollback mocks base method.
 func (m *MockDriver) Rollback(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -218,6 +232,20 @@ func (mr *MockDriverMockRecorder) Rollback(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockDriver)(nil).Rollback), ctx, id)
 }
 
+// RollbackToSavepoint mocks base method.
+func (m *MockDriver) RollbackToSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackToSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackToSavepoint indicates an expected call of RollbackToSavepoint.
+func (mr *MockDriverMockRecorder) RollbackToSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackToSavepoint", reflect.TypeOf((*MockDriver)(nil).RollbackToSavepoint), ctx, id, name)
+}
+
 // RoutingKey mocks base method.
 func (m *MockDriver) RoutingKey() string {
 	m.ctrl.T.Helper()
@@ -246,6 +274,20 @@ func (mr *MockDriverMockRecorder) Run(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockDriver)(nil).Run), ctx)
 }
 
+// Savepoint mocks base method.
+func (m *MockDriver) Savepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Savepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Savepoint indicates an expected call of Savepoint.
+func (mr *MockDriverMockRecorder) Savepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Savepoint", reflect.TypeOf((*MockDriver)(nil).Savepoint), ctx, id, name)
+}
+
 // Stop mocks base method.
 func (m *MockDriver) Stop(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -367,6 +409,20 @@ func (mr *MocktransactionEditorMockRecorder) FinishTx(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishTx", reflect.TypeOf((*MocktransactionEditor)(nil).FinishTx), ctx, id)
 }
 
+// ReleaseSavepoint mocks base method.
+func (m *MocktransactionEditor) ReleaseSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSavepoint indicates an expected call of ReleaseSavepoint.
+func (mr *MocktransactionEditorMockRecorder) ReleaseSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSavepoint", reflect.TypeOf((*MocktransactionEditor)(nil).ReleaseSavepoint), ctx, id, name)
+}
+
 // Rollback mocks base method.
 func (m *MocktransactionEditor) Rollback(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -381,6 +437,99 @@ func (mr *MocktransactionEditorMockRecorder) Rollback(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MocktransactionEditor)(nil).Rollback), ctx, id)
 }
 
+// RollbackToSavepoint mocks base method.
+func (m *MocktransactionEditor) RollbackToSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackToSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackToSavepoint indicates an expected call of RollbackToSavepoint.
+func (mr *MocktransactionEditorMockRecorder) RollbackToSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackToSavepoint", reflect.TypeOf((*MocktransactionEditor)(nil).RollbackToSavepoint), ctx, id, name)
+}
+
+// Savepoint mocks base method.
+func (m *MocktransactionEditor) Savepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Savepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Savepoint indicates an expected call of Savepoint.
+func (mr *MocktransactionEditorMockRecorder) Savepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Savepoint", reflect.TypeOf((*MocktransactionEditor)(nil).Savepoint), ctx, id, name)
+}
+
+// Mocksavepointer is a mock of savepointer interface.
+type Mocksavepointer struct {
+	ctrl     *gomock.Controller
+	recorder *MocksavepointerMockRecorder
+}
+
+// MocksavepointerMockRecorder is the mock recorder for Mocksavepointer.
+type MocksavepointerMockRecorder struct {
+	mock *Mocksavepointer
+}
+
+// NewMocksavepointer creates a new mock instance.
+func NewMocksavepointer(ctrl *gomock.Controller) *Mocksavepointer {
+	mock := &Mocksavepointer{ctrl: ctrl}
+	mock.recorder = &MocksavepointerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocksavepointer) EXPECT() *MocksavepointerMockRecorder {
+	return m.recorder
+}
+
+// ReleaseSavepoint mocks base method.
+func (m *Mocksavepointer) ReleaseSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSavepoint indicates an expected call of ReleaseSavepoint.
+func (mr *MocksavepointerMockRecorder) ReleaseSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSavepoint", reflect.TypeOf((*Mocksavepointer)(nil).ReleaseSavepoint), ctx, id, name)
+}
+
+// RollbackToSavepoint mocks base method.
+func (m *Mocksavepointer) RollbackToSavepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackToSavepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackToSavepoint indicates an expected call of RollbackToSavepoint.
+func (mr *MocksavepointerMockRecorder) RollbackToSavepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackToSavepoint", reflect.TypeOf((*Mocksavepointer)(nil).RollbackToSavepoint), ctx, id, name)
+}
+
+// Savepoint mocks base method.
+func (m *Mocksavepointer) Savepoint(ctx context.Context, id, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Savepoint", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Savepoint indicates an expected call of Savepoint.
+func (mr *MocksavepointerMockRecorder) Savepoint(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Savepoint", reflect.TypeOf((*Mocksavepointer)(nil).Savepoint), ctx, id, name)
+}
+
 // Mockrunner is a mock of runner interface.
 type Mockrunner struct {
 	ctrl     *gomock.Controller
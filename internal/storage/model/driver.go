@@ -20,6 +20,17 @@ type transactionEditor interface {
 	Begin(ctx context.Context, id string) error
 	// FinishTx завершает транзакцию. Используется, если не удалось начать транзакцию в одном из драйверов.
 	FinishTx(ctx context.Context, id string) error
+	savepointer
+}
+
+// savepointer - точки сохранения внутри транзакции: позволяют откатить часть запросов и закоммитить остальные.
+type savepointer interface {
+	// Savepoint создает точку сохранения name в транзакции id.
+	Savepoint(ctx context.Context, id, name string) error
+	// RollbackToSavepoint откатывает запросы после точки сохранения name. Транзакция остается открытой.
+	RollbackToSavepoint(ctx context.Context, id, name string) error
+	// ReleaseSavepoint удаляет точку сохранения name, запросы после нее остаются в транзакции.
+	ReleaseSavepoint(ctx context.Context, id, name string) error
 }
 
 type runner interface {
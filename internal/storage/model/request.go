@@ -8,3 +8,11 @@ type Request struct {
 	Args any
 	Raw  map[string]any
 }
+
+// Copy - запрос на запись строк через COPY: передается в Request.Val вместо SQL-запроса.
+// Используется для больших пачек в create операциях, где multi-row INSERT упирается в количество параметров.
+type Copy struct {
+	Table   string   // таблица, может быть со схемой: schema.table
+	Columns []string // колонки в порядке значений строк
+	Rows    [][]any  // значения строк
+}
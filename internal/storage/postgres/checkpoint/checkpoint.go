@@ -0,0 +1,45 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Get возвращает количество обработанных строк файла. Если файл еще не читался - 0.
+func (r *Repo) Get(ctx context.Context, connection, file string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.readTimeout)*time.Millisecond)
+	defer cancel()
+
+	query := "SELECT line FROM " + r.table + " WHERE connection = $1 AND file = $2"
+
+	var line int64
+
+	err := r.db.QueryRowContext(ctx, query, connection, file).Scan(&line)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("checkpoint: error getting checkpoint: %w", err)
+	}
+
+	return line, nil
+}
+
+// Save сохраняет количество обработанных строк файла.
+func (r *Repo) Save(ctx context.Context, connection, file string, line int64) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.insertTimeout)*time.Millisecond)
+	defer cancel()
+
+	query := "INSERT INTO " + r.table + " (connection, file, line, updated_at) VALUES ($1, $2, $3, now()) " +
+		"ON CONFLICT (connection, file) DO UPDATE SET line = EXCLUDED.line, updated_at = EXCLUDED.updated_at"
+
+	if _, err := r.db.ExecContext(ctx, query, connection, file, line); err != nil {
+		return fmt.Errorf("checkpoint: error saving checkpoint: %w", err)
+	}
+
+	return nil
+}
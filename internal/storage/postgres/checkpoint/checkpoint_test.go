@@ -0,0 +1,119 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepo(t *testing.T) (*Repo, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return &Repo{
+		db:            db,
+		table:         DefaultTable,
+		insertTimeout: 1000,
+		readTimeout:   1000,
+	}, mock
+}
+
+func TestRepo_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      int64
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT line FROM messages.file_checkpoints WHERE connection = \$1 AND file = \$2`).
+					WithArgs("replay", "data/notes.jsonl").
+					WillReturnRows(sqlmock.NewRows([]string{"line"}).AddRow(42))
+			},
+			want:    42,
+			wantErr: require.NoError,
+		},
+		{
+			name: "positive case: file was not read",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT line FROM messages.file_checkpoints`).
+					WillReturnRows(sqlmock.NewRows([]string{"line"}))
+			},
+			want:    0,
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: query error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT line FROM messages.file_checkpoints`).WillReturnError(errors.New("query error"))
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, mock := newTestRepo(t)
+			tt.setupMock(mock)
+
+			got, err := repo.Get(context.Background(), "replay", "data/notes.jsonl")
+			tt.wantErr(t, err)
+
+			assert.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepo_Save(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "positive case",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO messages.file_checkpoints \(connection, file, line, updated_at\) VALUES \(\$1, \$2, \$3, now\(\)\) ON CONFLICT \(connection, file\) DO UPDATE SET line = EXCLUDED.line`).
+					WithArgs("replay", "data/notes.jsonl", int64(42)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "negative case: exec error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO messages.file_checkpoints`).WillReturnError(errors.New("exec error"))
+			},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, mock := newTestRepo(t)
+			tt.setupMock(mock)
+
+			tt.wantErr(t, repo.Save(context.Background(), "replay", "data/notes.jsonl", 42))
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	_ "github.com/lib/pq" // postgres driver
+)
+
+// DefaultTable - таблица с позициями чтения файлов.
+const DefaultTable = "messages.file_checkpoints"
+
+// Repo хранит позиции чтения файлов в базе данных сервиса.
+type Repo struct {
+	addr  string
+	table string
+	db    *sql.DB
+
+	insertTimeout int
+	readTimeout   int
+}
+
+// RepoOption определяет опции для репозитория.
+type RepoOption func(*Repo)
+
+// WithAddr устанавливает адрес базы данных.
+func WithAddr(addr string) RepoOption {
+	return func(r *Repo) {
+		r.addr = addr
+	}
+}
+
+// WithTable устанавливает таблицу. По умолчанию DefaultTable.
+func WithTable(table string) RepoOption {
+	return func(r *Repo) {
+		r.table = table
+	}
+}
+
+// WithInsertTimeout устанавливает время ожидания записи (мс).
+func WithInsertTimeout(insertTimeout int) RepoOption {
+	return func(r *Repo) {
+		r.insertTimeout = insertTimeout
+	}
+}
+
+// WithReadTimeout устанавливает время ожидания чтения (мс).
+func WithReadTimeout(readTimeout int) RepoOption {
+	return func(r *Repo) {
+		r.readTimeout = readTimeout
+	}
+}
+
+// New создает новый репозиторий.
+func New(opts ...RepoOption) (*Repo, error) {
+	r := &Repo{}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.addr == "" {
+		return nil, errors.New("checkpoint: addr is required")
+	}
+
+	if r.insertTimeout == 0 {
+		return nil, errors.New("checkpoint: insert timeout is required")
+	}
+
+	if r.readTimeout == 0 {
+		return nil, errors.New("checkpoint: read timeout is required")
+	}
+
+	if r.table == "" {
+		r.table = DefaultTable
+	}
+
+	db, err := sql.Open("postgres", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: error opening db: %w", err)
+	}
+
+	r.db = db
+
+	return r, nil
+}
+
+// Run проверяет соединение с базой данных.
+func (r *Repo) Run(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("checkpoint: error pinging db: %w", err)
+	}
+
+	logrus.WithField("table", r.table).Info("checkpoint: successfully connected postgres")
+
+	return nil
+}
+
+// Stop закрывает соединение с базой данных.
+func (r *Repo) Stop(_ context.Context) error {
+	return r.db.Close()
+}
@@ -0,0 +1,62 @@
+package checkpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []RepoOption
+		wantTable string
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name:      "positive case: default table",
+			opts:      []RepoOption{WithAddr("postgres://localhost:5432/test"), WithInsertTimeout(1), WithReadTimeout(1)},
+			wantTable: DefaultTable,
+			wantErr:   require.NoError,
+		},
+		{
+			name:      "positive case: custom table",
+			opts:      []RepoOption{WithAddr("postgres://localhost:5432/test"), WithTable("replay.checkpoints"), WithInsertTimeout(1), WithReadTimeout(1)},
+			wantTable: "replay.checkpoints",
+			wantErr:   require.NoError,
+		},
+		{
+			name:    "negative case: addr is empty",
+			opts:    []RepoOption{WithInsertTimeout(1), WithReadTimeout(1)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: insert timeout is 0",
+			opts:    []RepoOption{WithAddr("postgres://localhost:5432/test"), WithReadTimeout(1)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "negative case: read timeout is 0",
+			opts:    []RepoOption{WithAddr("postgres://localhost:5432/test"), WithInsertTimeout(1)},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := New(tt.opts...)
+			tt.wantErr(t, err)
+
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantTable, got.table)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"db-worker/internal/storage"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Begin начинает транзакцию.
@@ -26,16 +30,23 @@ func (db *Repo) Begin(ctx context.Context, id string) error {
 	return nil
 }
 
-// Exec выполняет запрос.
+// Exec выполняет запрос. Val запроса - SQL-запрос с аргументами в Args или storage.Copy для записи строк через COPY.
 func (db *Repo) Exec(ctx context.Context, req *storage.Request, id string) error {
 	tx, err := db.getTx(id)
 	if err != nil {
 		return fmt.Errorf("error getting transaction: %w", err)
 	}
 
+	if rows, ok := req.Val.(storage.Copy); ok {
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(db.insertTimeout)*time.Millisecond)
+		defer cancel()
+
+		return execCopy(ctx, tx, rows)
+	}
+
 	sql, ok := req.Val.(string)
 	if !ok {
-		return fmt.Errorf("request value is not a string")
+		return fmt.Errorf("request value is not a string or copy")
 	}
 
 	args, ok := req.Args.([]any)
@@ -67,6 +78,34 @@ func (db *Repo) Exec(ctx context.Context, req *storage.Request, id string) error
 	return nil
 }
 
+// execCopy записывает строки в таблицу через COPY FROM STDIN в рамках транзакции.
+func execCopy(ctx context.Context, tx *sql.Tx, rows storage.Copy) error {
+	query := pq.CopyIn(rows.Table, rows.Columns...)
+	if schema, table, ok := strings.Cut(rows.Table, "."); ok {
+		query = pq.CopyInSchema(schema, table, rows.Columns...)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error preparing copy: %w", err)
+	}
+
+	defer stmt.Close()
+
+	for _, row := range rows.Rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return fmt.Errorf("error copying row: %w", err)
+		}
+	}
+
+	// пустой вызов отправляет накопленные строки на сервер
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("error executing copy: %w", err)
+	}
+
+	return nil
+}
+
 // Commit коммитит транзакцию.
 //
 //nolint:dupl // одинаковая логика для таймаутов.
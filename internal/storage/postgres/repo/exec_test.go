@@ -811,3 +811,82 @@ func TestRepo_Exec_Timeout(t *testing.T) {
 	require.NoError(t, db.Close())
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepo_Exec_Copy(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := &Repo{
+		db:            db,
+		insertTimeout: 1000,
+		transaction: struct {
+			mu sync.Mutex
+			tx map[string]*sql.Tx
+		}{
+			mu: sync.Mutex{},
+			tx: make(map[string]*sql.Tx),
+		},
+	}
+
+	txID := "test-tx-copy"
+
+	mock.ExpectBegin()
+
+	prepare := mock.ExpectPrepare(`COPY "notes"."notes" \("text", "user_id"\) FROM STDIN`)
+	prepare.ExpectExec().WithArgs("first", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepare.ExpectExec().WithArgs("second", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	prepare.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 2))
+	prepare.WillBeClosed()
+
+	require.NoError(t, repo.Begin(t.Context(), txID))
+
+	err = repo.Exec(t.Context(), &storage.Request{
+		Val: storage.Copy{
+			Table:   "notes.notes",
+			Columns: []string{"text", "user_id"},
+			Rows:    [][]any{{"first", 1}, {"second", 2}},
+		},
+	}, txID)
+	require.NoError(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_Exec_CopyError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := &Repo{
+		db:            db,
+		insertTimeout: 1000,
+		transaction: struct {
+			mu sync.Mutex
+			tx map[string]*sql.Tx
+		}{
+			mu: sync.Mutex{},
+			tx: make(map[string]*sql.Tx),
+		},
+	}
+
+	txID := "test-tx-copy-error"
+
+	mock.ExpectBegin()
+
+	prepare := mock.ExpectPrepare(`COPY "notes" \("text"\) FROM STDIN`)
+	prepare.ExpectExec().WithArgs("first").WillReturnError(errors.New("invalid input syntax"))
+
+	require.NoError(t, repo.Begin(t.Context(), txID))
+
+	err = repo.Exec(t.Context(), &storage.Request{
+		Val: storage.Copy{
+			Table:   "notes",
+			Columns: []string{"text"},
+			Rows:    [][]any{{"first"}},
+		},
+	}, txID)
+	require.ErrorContains(t, err, "error copying row")
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Savepoint создает точку сохранения в транзакции.
+func (db *Repo) Savepoint(ctx context.Context, id, name string) error {
+	return db.execSavepoint(ctx, id, "SAVEPOINT "+pq.QuoteIdentifier(name))
+}
+
+// RollbackToSavepoint откатывает запросы транзакции после точки сохранения. Транзакция остается открытой.
+func (db *Repo) RollbackToSavepoint(ctx context.Context, id, name string) error {
+	return db.execSavepoint(ctx, id, "ROLLBACK TO SAVEPOINT "+pq.QuoteIdentifier(name))
+}
+
+// ReleaseSavepoint удаляет точку сохранения, запросы после нее остаются в транзакции.
+func (db *Repo) ReleaseSavepoint(ctx context.Context, id, name string) error {
+	return db.execSavepoint(ctx, id, "RELEASE SAVEPOINT "+pq.QuoteIdentifier(name))
+}
+
+func (db *Repo) execSavepoint(ctx context.Context, id, query string) error {
+	tx, err := db.getTx(id)
+	if err != nil {
+		return fmt.Errorf("error getting transaction: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(db.insertTimeout)*time.Millisecond)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error executing %q: %w", query, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo_Savepoint(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := &Repo{
+		db:            db,
+		insertTimeout: 1000,
+		transaction: struct {
+			mu sync.Mutex
+			tx map[string]*sql.Tx
+		}{
+			mu: sync.Mutex{},
+			tx: make(map[string]*sql.Tx),
+		},
+	}
+
+	txID := "test-tx-savepoint"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT "row_0"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`RELEASE SAVEPOINT "row_0"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT "row_1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT "row_1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.Begin(t.Context(), txID))
+
+	require.NoError(t, repo.Savepoint(t.Context(), txID, "row_0"))
+	require.NoError(t, repo.ReleaseSavepoint(t.Context(), txID, "row_0"))
+	require.NoError(t, repo.Savepoint(t.Context(), txID, "row_1"))
+	require.NoError(t, repo.RollbackToSavepoint(t.Context(), txID, "row_1"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_Savepoint_Error(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := &Repo{
+		db:            db,
+		insertTimeout: 1000,
+		transaction: struct {
+			mu sync.Mutex
+			tx map[string]*sql.Tx
+		}{
+			mu: sync.Mutex{},
+			tx: make(map[string]*sql.Tx),
+		},
+	}
+
+	// транзакция не начата
+	require.ErrorContains(t, repo.Savepoint(t.Context(), "unknown-tx", "row_0"), "error getting transaction")
+
+	txID := "test-tx-savepoint-error"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT "row_0"`).WillReturnError(errors.New("current transaction is aborted"))
+
+	require.NoError(t, repo.Begin(t.Context(), txID))
+	require.ErrorContains(t, repo.Savepoint(t.Context(), txID, "row_0"), "current transaction is aborted")
+}
@@ -55,3 +55,8 @@ func (c *client) Close(ctx context.Context) error {
 
 	return c.cache.Close()
 }
+
+// Client возвращает клиент go-redis для выполнения команд.
+func (c *client) Client() redis.UniversalClient {
+	return c.cache
+}
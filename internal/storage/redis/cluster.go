@@ -52,3 +52,8 @@ func (c *cluster) Close(ctx context.Context) error {
 
 	return c.cache.Close()
 }
+
+// Client возвращает клиент go-redis для выполнения команд.
+func (c *cluster) Client() redis.UniversalClient {
+	return c.cache
+}
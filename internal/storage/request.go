@@ -11,6 +11,9 @@ import (
 // Перенаправляем на тип из пакета interfaces для избежания циклических импортов.
 type Request = model.Request
 
+// Copy - запрос на запись строк через COPY.
+type Copy = model.Copy
+
 // RequestModel - модель запроса, которая нужна для его хранения в БД.
 type RequestModel struct {
 	ID         uuid.UUID
@@ -21,6 +21,8 @@ type TestTransaction struct {
 	operationHash []byte // хеш операции
 	originalTx    storage.TransactionEditor
 	rawReq        map[string]any
+	correlationID string
+	metadata      map[string]any
 }
 
 type option func(*TestTransaction)
@@ -95,6 +97,14 @@ func WithRawReq(rawReq map[string]any) option {
 	}
 }
 
+// WithMetadata устанавливает correlation id и метаданные сообщения.
+func WithMetadata(correlationID string, metadata map[string]any) option {
+	return func(tx *TestTransaction) {
+		tx.correlationID = correlationID
+		tx.metadata = metadata
+	}
+}
+
 // NewTestTransaction создает новый экземпляр TestTransaction с заданными опциями.
 func NewTestTransaction(opts ...option) *TestTransaction {
 	tx := &TestTransaction{
@@ -233,3 +243,13 @@ func (tx *TestTransaction) FailedDriverName() string {
 func (tx *TestTransaction) RawReq() map[string]any {
 	return tx.rawReq
 }
+
+// CorrelationID возвращает correlation id сообщения.
+func (tx *TestTransaction) CorrelationID() string {
+	return tx.correlationID
+}
+
+// Metadata возвращает метаданные сообщения.
+func (tx *TestTransaction) Metadata() map[string]any {
+	return tx.metadata
+}
@@ -49,6 +49,10 @@ type TransactionEditor interface {
 	FailedDriverName() string
 	// RawReq возвращает raw запросы транзакции.
 	RawReq() map[string]any
+	// CorrelationID возвращает correlation id сообщения, из которого создана транзакция.
+	CorrelationID() string
+	// Metadata возвращает метаданные сообщения, из которого создана транзакция.
+	Metadata() map[string]any
 }
 
 // Transaction - реализация сущности транзакции.
@@ -62,6 +66,9 @@ type Transaction struct {
 	err    error
 	rawReq map[string]any
 
+	correlationID string         // correlation id сообщения
+	metadata      map[string]any // метаданные сообщения: заголовки, айди сообщения и т.д.
+
 	requests map[Driver]*Request
 	begun    map[Driver]struct{} // драйвера, в которых транзакция была успешно начата.
 
@@ -201,6 +208,27 @@ func (tx *Transaction) RawReq() map[string]any {
 	return tx.rawReq
 }
 
+// SetID устанавливает айди транзакции, заранее записанный в данные сообщения (generator: tx_id).
+func (tx *Transaction) SetID(id string) {
+	tx.id = id
+}
+
+// SetMetadata устанавливает correlation id и метаданные сообщения, из которого создана транзакция.
+func (tx *Transaction) SetMetadata(correlationID string, metadata map[string]any) {
+	tx.correlationID = correlationID
+	tx.metadata = metadata
+}
+
+// CorrelationID возвращает correlation id сообщения, из которого создана транзакция.
+func (tx *Transaction) CorrelationID() string {
+	return tx.correlationID
+}
+
+// Metadata возвращает метаданные сообщения, из которого создана транзакция.
+func (tx *Transaction) Metadata() map[string]any {
+	return tx.metadata
+}
+
 // FailedDriver возвращает "сломанный" драйвер транзакции.
 func (tx *Transaction) FailedDriver() Driver {
 	return tx.failedDriver
@@ -472,3 +500,17 @@ func (ux *utilityTransaction) RawReq() map[string]any {
 
 	return ux.originalTx.rawReq
 }
+
+func (ux *utilityTransaction) CorrelationID() string {
+	ux.mu.RLock()
+	defer ux.mu.RUnlock()
+
+	return ux.originalTx.correlationID
+}
+
+func (ux *utilityTransaction) Metadata() map[string]any {
+	ux.mu.RLock()
+	defer ux.mu.RUnlock()
+
+	return ux.originalTx.metadata
+}
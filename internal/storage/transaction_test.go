@@ -475,6 +475,26 @@ func TestTransaction_RawReq(t *testing.T) {
 	assert.Equal(t, rawReq, tx.RawReq())
 }
 
+func TestTransaction_SetMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]any{
+		"correlation_id": "corr-1",
+		"headers":        map[string]any{"user_id": "42"},
+	}
+
+	tx := &Transaction{}
+	tx.SetMetadata("corr-1", metadata)
+
+	assert.Equal(t, "corr-1", tx.CorrelationID())
+	assert.Equal(t, metadata, tx.Metadata())
+
+	ux := &utilityTransaction{originalTx: tx}
+
+	assert.Equal(t, "corr-1", ux.CorrelationID())
+	assert.Equal(t, metadata, ux.Metadata())
+}
+
 func TestTransaction_FailedDriver(t *testing.T) {
 	t.Parallel()
 
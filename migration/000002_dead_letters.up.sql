@@ -0,0 +1,12 @@
+-- таблица с сообщениями, которые не удалось обработать (dead_letter с type: postgres)
+CREATE TABLE IF NOT EXISTS messages.dead_letters (
+    id UUID PRIMARY KEY,
+    operation varchar NOT NULL, -- название операции
+    stage varchar NOT NULL, -- этап, на котором произошла ошибка: validation, build, exec
+    error varchar NOT NULL,
+    message_ids JSONB NOT NULL, -- айди сообщений в messages.messages
+    payload JSONB NOT NULL, -- исходное сообщение
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now() -- время ошибки в UTC
+);
+
+CREATE INDEX IF NOT EXISTS dead_letters_operation_idx ON messages.dead_letters(operation);
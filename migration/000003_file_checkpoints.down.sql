@@ -0,0 +1 @@
+DROP TABLE IF EXISTS messages.file_checkpoints;
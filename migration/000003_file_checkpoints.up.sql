@@ -0,0 +1,8 @@
+-- позиция чтения файлов соединений с типом file: сколько строк файла уже обработано
+CREATE TABLE IF NOT EXISTS messages.file_checkpoints (
+    connection varchar NOT NULL, -- название соединения
+    file varchar NOT NULL, -- путь к файлу
+    line bigint NOT NULL, -- количество обработанных строк с начала файла
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- время обновления в UTC
+    PRIMARY KEY (connection, file)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS transactions.transactions_correlation_id_idx;
+
+ALTER TABLE transactions.transactions
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,6 @@
+-- метаданные сообщения, из которого создана транзакция
+ALTER TABLE transactions.transactions
+    ADD COLUMN IF NOT EXISTS correlation_id varchar, -- сквозной айди запроса отправителя (если есть)
+    ADD COLUMN IF NOT EXISTS metadata JSONB; -- заголовки, айди сообщения, ключ маршрутизации и время отправки
+
+CREATE INDEX IF NOT EXISTS transactions_correlation_id_idx ON transactions.transactions(correlation_id);